@@ -0,0 +1,303 @@
+// Package cargoimp renders air waybills as IATA Cargo-IMP text (FWB, FHL) and
+// Cargo-XML (XFWB, XFZB) messages for airline e-AWB filing.
+package cargoimp
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// lineBreak ends every Cargo-IMP line, as type B messages are exchanged with CR LF.
+const lineBreak = "\r\n"
+
+// Waybill is a master air waybill with its rating, as printed on the AWB form.
+type Waybill struct {
+	Prefix      string
+	Serial      string
+	Origin      string
+	Destination string
+	Pieces      int64
+	Weight      float64
+	WeightUnit  string // K or L
+	Volume      float64
+	Flights     []Flight
+	Routing     []Route
+	Shipper     Party
+	Consignee   Party
+	Agent       Agent
+
+	HandlingInfo   string
+	AccountingInfo string
+
+	Currency         string
+	ChargeCode       string
+	WtValPrepaid     bool
+	OtherPrepaid     bool
+	DeclaredCarriage string
+	DeclaredCustoms  string
+	Insurance        string
+
+	Rates        []RateLine
+	OtherCharges []OtherCharge
+	Summary      ChargeSummary
+
+	ShipperSignature string
+	IssueDate        time.Time
+	IssuePlace       string
+	IssuedBy         string
+}
+
+// Number returns the AWB number as 618-12345675.
+func (w *Waybill) Number() string {
+	return w.Prefix + "-" + w.Serial
+}
+
+// Party is a shipper or consignee: a name followed by free address lines.
+type Party struct {
+	Account string
+	Name    string
+	Address []string
+	Country string
+}
+
+type Agent struct {
+	Name      string
+	Place     string
+	IATACode  string
+	CASSCode  string
+	AccountNo string
+}
+
+// Flight is a booked flight such as TG920 on the 15th.
+type Flight struct {
+	Carrier string
+	Number  string
+	Day     int
+}
+
+// Route is one leg of the routing: the airport to and the carrier by.
+type Route struct {
+	To string
+	By string
+}
+
+type RateLine struct {
+	Pieces           int64
+	Weight           float64
+	WeightUnit       string
+	RateClass        string
+	ChargeableWeight float64
+	Rate             float64
+	Total            float64
+	Description      string
+	Volume           float64
+	Dims             []Dim
+}
+
+// Dim is a group of identical pieces measured in centimetres.
+type Dim struct {
+	Length int64
+	Width  int64
+	Height int64
+	Count  int64
+}
+
+// OtherCharge is an IATA other charge such as AWC (AWB fee due carrier).
+type OtherCharge struct {
+	Code        string // two letter charge code
+	Entitlement string // A due agent, C due carrier
+	Amount      float64
+}
+
+// ChargeSummary holds the charge totals of a waybill. Weight charge, valuation charge and tax
+// fall in the column of WtValPrepaid, the other charges in the column of OtherPrepaid.
+type ChargeSummary struct {
+	WeightCharge    float64
+	ValuationCharge float64
+	Tax             float64
+	DueAgent        float64
+	DueCarrier      float64
+}
+
+// chargeColumn is the prepaid or the collect column of the charges summary.
+type chargeColumn struct {
+	Prepaid         bool
+	WtVal           bool // weight charge, valuation charge and tax are in this column
+	WeightCharge    float64
+	ValuationCharge float64
+	Tax             float64
+	DueAgent        float64
+	DueCarrier      float64
+}
+
+func (c chargeColumn) Total() float64 {
+	return c.WeightCharge + c.ValuationCharge + c.Tax + c.DueAgent + c.DueCarrier
+}
+
+// chargeColumns splits the summary into its prepaid and collect columns, prepaid first. A
+// column is left out when it holds nothing: with PC, WT/VAL is prepaid and other charges
+// collect; with other charges of zero only the WT/VAL column is printed.
+func (w *Waybill) chargeColumns() []chargeColumn {
+	s := w.Summary
+	columns := []chargeColumn{}
+	for _, prepaid := range []bool{true, false} {
+		c := chargeColumn{Prepaid: prepaid}
+		if w.WtValPrepaid == prepaid {
+			c.WtVal = true
+			c.WeightCharge, c.ValuationCharge, c.Tax = s.WeightCharge, s.ValuationCharge, s.Tax
+		}
+		if w.OtherPrepaid == prepaid {
+			c.DueAgent, c.DueCarrier = s.DueAgent, s.DueCarrier
+		}
+		if c.WtVal || c.DueAgent > 0 || c.DueCarrier > 0 {
+			columns = append(columns, c)
+		}
+	}
+	return columns
+}
+
+// House is one house waybill consolidated under a master.
+type House struct {
+	HAWB        string
+	Origin      string
+	Destination string
+	Pieces      int64
+	Weight      float64
+	Description string
+	Shipper     Party
+	Consignee   Party
+}
+
+var awbNumberPattern = regexp.MustCompile(`^(\d{3})[\s-]?(\d{4})\s?(\d{4})$`)
+
+// ParseAWBNumber splits an AWB number typed as 618-12345675, 618-1234 5675 or 61812345675
+// into the airline prefix and the 8 digit serial.
+func ParseAWBNumber(value string) (prefix, serial string, err error) {
+	m := awbNumberPattern.FindStringSubmatch(strings.TrimSpace(value))
+	if m == nil {
+		return "", "", fmt.Errorf("invalid AWB number '%s'", value)
+	}
+	return m[1], m[2] + m[3], nil
+}
+
+// NormalizeAWBNumber checks an AWB number typed in any of the forms ParseAWBNumber accepts and
+// returns it as 618-12345675. The last digit of the serial must be the first seven modulo 7.
+func NormalizeAWBNumber(value string) (string, error) {
+	prefix, serial, err := ParseAWBNumber(value)
+	if err != nil {
+		return "", err
+	}
+	number, _ := strconv.ParseInt(serial[:7], 10, 64)
+	if want := AWBNumber(prefix, number); prefix+"-"+serial != want {
+		return "", fmt.Errorf("AWB number '%s' fails the check digit, expected %s", value, want)
+	}
+	return prefix + "-" + serial, nil
+}
+
+// AWBNumber returns the AWB number with the seven digit serial and its check digit, as 618-12345675.
+func AWBNumber(prefix string, serial int64) string {
+	return fmt.Sprintf("%s-%07d%d", prefix, serial, serial%7)
+}
+
+var airportPattern = regexp.MustCompile(`\b[A-Z]{3}\b`)
+
+// AirportCode finds the IATA airport code in values such as "BKK", "BANGKOK (BKK)" or "bkk".
+func AirportCode(value string) string {
+	value = strings.ToUpper(strings.TrimSpace(value))
+	if len(value) == 3 {
+		return value
+	}
+	matches := airportPattern.FindAllString(value, -1)
+	if len(matches) == 0 {
+		return ""
+	}
+	return matches[len(matches)-1]
+}
+
+// SplitNameAndAddress reads a name and address block typed on the AWB form: the first line
+// is the name and the rest is the address. A last line holding only an ISO country code, as
+// the address book writes it, is the country.
+func SplitNameAndAddress(text string) Party {
+	lines := []string{}
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r", ""), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) == 0 {
+		return Party{}
+	}
+	p := Party{Name: lines[0], Address: lines[1:]}
+	if n := len(p.Address); n > 0 && countryPattern.MatchString(p.Address[n-1]) {
+		p.Country = p.Address[n-1]
+		p.Address = p.Address[:n-1]
+	}
+	return p
+}
+
+var countryPattern = regexp.MustCompile(`^[A-Z]{2}$`)
+
+// problems collects everything that keeps a message from being filed.
+type problems []string
+
+func (p *problems) require(ok bool, format string, args ...interface{}) {
+	if !ok {
+		*p = append(*p, fmt.Sprintf(format, args...))
+	}
+}
+
+func (p problems) err() error {
+	if len(p) == 0 {
+		return nil
+	}
+	return errors.New(strings.Join(p, ", "))
+}
+
+var textReplacer = regexp.MustCompile(`[^A-Z0-9 .\-]+`)
+
+// text upper-cases s and drops characters the Cargo-IMP character set does not allow,
+// cutting the result to max characters.
+func text(s string, max int) string {
+	s = textReplacer.ReplaceAllString(strings.ToUpper(s), " ")
+	s = strings.Join(strings.Fields(s), " ")
+	if len(s) > max {
+		s = strings.TrimSpace(s[:max])
+	}
+	return s
+}
+
+func formatWeight(v float64) string {
+	return strconv.FormatFloat(roundTo(v, 1), 'f', -1, 64)
+}
+
+func formatVolume(v float64) string {
+	return strconv.FormatFloat(roundTo(v, 2), 'f', -1, 64)
+}
+
+func formatAmount(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
+
+func roundTo(v float64, places int) float64 {
+	p, _ := strconv.ParseFloat(strconv.FormatFloat(v, 'f', places, 64), 64)
+	return p
+}
+
+func weightUnit(unit string) string {
+	if strings.HasPrefix(strings.ToUpper(strings.TrimSpace(unit)), "L") {
+		return "L"
+	}
+	return "K"
+}
+
+// paymentCode is the P or C indicator of a prepaid flag.
+func paymentCode(prepaid bool) string {
+	if prepaid {
+		return "P"
+	}
+	return "C"
+}
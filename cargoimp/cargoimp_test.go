@@ -0,0 +1,348 @@
+package cargoimp
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+func sampleWaybill() *Waybill {
+	return &Waybill{
+		Prefix:      "217",
+		Serial:      "12345675",
+		Origin:      "BKK",
+		Destination: "FRA",
+		Pieces:      10,
+		Weight:      250.5,
+		WeightUnit:  "K",
+		Volume:      1.44,
+		Flights:     []Flight{{Carrier: "TG", Number: "920", Day: 15}},
+		Routing:     []Route{{To: "FRA", By: "TG"}},
+		Shipper: Party{
+			Name:    "HPC Express Co., Ltd.",
+			Address: []string{"99/9 Moo 4 Bang Na-Trad Road", "Bang Phli", "Samut Prakan 10540"},
+			Country: "TH",
+		},
+		Consignee: Party{
+			Name:    "Euro Parcel GmbH",
+			Address: []string{"Cargo City Sued 555", "Frankfurt"},
+			Country: "DE",
+		},
+		Agent: Agent{
+			Name:      "HPC Express",
+			Place:     "Bangkok",
+			IATACode:  "1234567",
+			CASSCode:  "0001",
+			AccountNo: "HPC001",
+		},
+		HandlingInfo:   "Please notify consignee immediately on arrival. Documents attached to the AWB.",
+		AccountingInfo: "Freight prepaid",
+		Currency:       "THB",
+		ChargeCode:     "PP",
+		WtValPrepaid:   true,
+		OtherPrepaid:   true,
+		Rates: []RateLine{
+			{
+				Pieces:           10,
+				Weight:           250.5,
+				WeightUnit:       "K",
+				RateClass:        "Q",
+				ChargeableWeight: 251,
+				Rate:             85,
+				Total:            21335,
+				Description:      "Consolidation as per attached manifest",
+				Volume:           1.44,
+				Dims:             []Dim{{Length: 120, Width: 80, Height: 60, Count: 2}, {Length: 60, Width: 40, Height: 40, Count: 8}},
+			},
+		},
+		OtherCharges: []OtherCharge{
+			{Code: "AW", Entitlement: "C", Amount: 50},
+			{Code: "MY", Entitlement: "C", Amount: 125.5},
+			{Code: "CG", Entitlement: "A", Amount: 300},
+		},
+		Summary: ChargeSummary{
+			WeightCharge: 21335,
+			DueAgent:     300,
+			DueCarrier:   175.5,
+		},
+		ShipperSignature: "Somchai J.",
+		IssueDate:        time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC),
+		IssuePlace:       "Bangkok",
+		IssuedBy:         "HPC Express",
+	}
+}
+
+func sampleHouses() []House {
+	return []House{
+		{
+			HAWB:        "HPC0001",
+			Pieces:      6,
+			Weight:      150.2,
+			Description: "Garments",
+			Shipper:     Party{Name: "Siam Textile", Address: []string{"12 Sukhumvit 21", "Bangkok"}},
+			Consignee:   Party{Name: "Mode Handel KG", Address: []string{"Zeil 10", "Frankfurt"}, Country: "DE"},
+		},
+		{
+			HAWB:        "HPC0002",
+			Destination: "MUC",
+			Pieces:      4,
+			Weight:      100.3,
+			Description: "Spare parts",
+			Shipper:     Party{Name: "Thai Auto Parts"},
+			Consignee:   Party{Name: "Bayern Motoren Service"},
+		},
+	}
+}
+
+var messageTime = time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC)
+
+func TestBuildFWB(t *testing.T) {
+	got, err := BuildFWB(sampleWaybill())
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, "fwb.golden", []byte(got))
+}
+
+func TestBuildFWBMissingData(t *testing.T) {
+	_, err := BuildFWB(&Waybill{Prefix: "217", Serial: "12345675", Currency: "THB"})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	want := "airport of departure must be an IATA airport code, airport of destination must be an IATA airport code, " +
+		"number of pieces is required, gross weight is required, shipper name is required, shipper country is required, " +
+		"consignee name is required, consignee country is required, " +
+		"at least one rate line is required"
+	if err.Error() != want {
+		t.Errorf("got %q, want %q", err.Error(), want)
+	}
+}
+
+func TestBuildFWBMixedCharges(t *testing.T) {
+	tests := []struct {
+		name         string
+		wtValPrepaid bool
+		otherPrepaid bool
+		want         string
+	}{
+		{"PC", true, false, "PPD/WT21335.00\r\n/CT21335.00\r\nCOL\r\n/OA300.00/OC175.50/CT475.50\r\n"},
+		{"CP", false, true, "PPD\r\n/OA300.00/OC175.50/CT475.50\r\nCOL/WT21335.00\r\n/CT21335.00\r\n"},
+		{"CC", false, false, "COL/WT21335.00\r\n/OA300.00/OC175.50/CT21810.50\r\n"},
+	}
+	for _, tt := range tests {
+		w := sampleWaybill()
+		w.WtValPrepaid, w.OtherPrepaid = tt.wtValPrepaid, tt.otherPrepaid
+
+		got, err := BuildFWB(w)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(got, "\r\n"+tt.want) {
+			t.Errorf("%s: charge summary missing %q in\n%s", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestSplitNameAndAddress(t *testing.T) {
+	got := SplitNameAndAddress("Euro Parcel GmbH\nCargo City Sued 555\nFrankfurt 60549\nDE\n")
+	if got.Name != "Euro Parcel GmbH" || got.Country != "DE" || strings.Join(got.Address, "|") != "Cargo City Sued 555|Frankfurt 60549" {
+		t.Errorf("got %+v", got)
+	}
+
+	got = SplitNameAndAddress("Siam Textile\n12 Sukhumvit 21\nBangkok")
+	if got.Country != "" || len(got.Address) != 2 {
+		t.Errorf("a block without a country line: got %+v", got)
+	}
+}
+
+func TestBuildFHL(t *testing.T) {
+	got, err := BuildFHL(sampleWaybill(), sampleHouses())
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, "fhl.golden", []byte(got))
+}
+
+func TestBuildXFWB(t *testing.T) {
+	got, err := BuildXFWB(sampleWaybill(), messageTime)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, "xfwb.golden", got)
+}
+
+func TestBuildXFZB(t *testing.T) {
+	got, err := BuildXFZB(sampleWaybill(), sampleHouses()[1], messageTime)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, "xfzb.golden", got)
+}
+
+func TestParseAWBNumber(t *testing.T) {
+	for _, value := range []string{"217-12345675", "21712345675", "217-1234 5675", " 217 12345675 "} {
+		prefix, serial, err := ParseAWBNumber(value)
+		if err != nil {
+			t.Errorf("%q: %v", value, err)
+			continue
+		}
+		if prefix != "217" || serial != "12345675" {
+			t.Errorf("%q: got %s-%s", value, prefix, serial)
+		}
+	}
+	if _, _, err := ParseAWBNumber("217-1234567"); err == nil {
+		t.Error("expected an error for a 7 digit serial")
+	}
+}
+
+func TestNormalizeAWBNumber(t *testing.T) {
+	for value, want := range map[string]string{
+		"217-12345675":  "217-12345675",
+		"21712345675":   "217-12345675",
+		"618 4567 8905": "618-45678905",
+		"618-45678850":  "618-45678850",
+	} {
+		got, err := NormalizeAWBNumber(value)
+		if err != nil {
+			t.Errorf("%q: %v", value, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("%q: got %q, want %q", value, got, want)
+		}
+	}
+	for _, value := range []string{"123-45678901", "217-12345676", "21-712345675", "217-1234567A"} {
+		if _, err := NormalizeAWBNumber(value); err == nil {
+			t.Errorf("%q: expected an error", value)
+		}
+	}
+}
+
+func TestAWBNumber(t *testing.T) {
+	for serial, want := range map[int64]string{1234567: "217-12345675", 4567885: "217-45678850", 42: "217-00000420"} {
+		if got := AWBNumber("217", serial); got != want {
+			t.Errorf("%d: got %q, want %q", serial, got, want)
+		}
+	}
+}
+
+func TestAirportCode(t *testing.T) {
+	for value, want := range map[string]string{
+		"BKK":                  "BKK",
+		"bkk":                  "BKK",
+		"BANGKOK (BKK)":        "BKK",
+		"FRANKFURT, GERMANY":   "",
+		"SUVARNABHUMI BKK, TH": "BKK",
+	} {
+		if got := AirportCode(value); got != want {
+			t.Errorf("%q: got %q, want %q", value, got, want)
+		}
+	}
+}
+
+func checkGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("%s does not match, run go test -update to review the change\ngot:\n%s\nwant:\n%s", name, got, want)
+	}
+}
+
+func TestParseFFM(t *testing.T) {
+	message := "FFM/8\r\n" +
+		"1/TG920/15OCT/FRA/HSTGA\r\n" +
+		"BKK/16OCT0610\r\n" +
+		"ULD/PMC12345TG\r\n" +
+		"217-12345675FRABKK/T10K250.5MC1.44/CONSOLIDATION\r\n" +
+		"SSR/KEEP DRY\r\n" +
+		"217-11111116FRABKK/P4K40T6/SPARE PARTS\r\n" +
+		"ULD/PMC67890TG\r\n" +
+		"217-11111116FRABKK/P2K20T6/SPARE PARTS\r\n" +
+		"LAST\r\n"
+
+	m, err := ParseFFM(message, messageTime)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Flight() != "TG920" || m.Origin != "FRA" || m.Registration != "HSTGA" {
+		t.Errorf("got flight %s from %s on %s", m.Flight(), m.Origin, m.Registration)
+	}
+	if want := time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC); !m.FlightDate.Equal(want) {
+		t.Errorf("got flight date %v, want %v", m.FlightDate, want)
+	}
+	if len(m.Consignments) != 3 {
+		t.Fatalf("got %d consignments, want 3", len(m.Consignments))
+	}
+
+	c := m.Consignments[0]
+	if want := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC); !c.ArrivalDate.Equal(want) {
+		t.Errorf("got arrival date %v, want %v", c.ArrivalDate, want)
+	}
+	if c.Number() != "217-12345675" || c.UnloadingPoint != "BKK" || c.Pieces != 10 || c.Weight != 250.5 || c.Volume != 1.44 || c.Description != "CONSOLIDATION" {
+		t.Errorf("got %+v", c)
+	}
+	c = m.Consignments[2]
+	if c.ShipmentCode != "P" || c.Pieces != 2 || c.TotalPieces != 6 || c.Weight != 20 {
+		t.Errorf("got %+v", c)
+	}
+}
+
+func TestParseFWB(t *testing.T) {
+	message, err := os.ReadFile(filepath.Join("testdata", "fwb.golden"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := ParseFWB(string(message))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w.Number() != "217-12345675" || w.Origin != "BKK" || w.Destination != "FRA" || w.Pieces != 10 || w.Weight != 250.5 {
+		t.Errorf("got %s %s%s %d pieces %v kg", w.Number(), w.Origin, w.Destination, w.Pieces, w.Weight)
+	}
+	if len(w.Flights) != 1 || w.Flights[0] != (Flight{Carrier: "TG", Number: "920", Day: 15}) {
+		t.Errorf("got flights %+v", w.Flights)
+	}
+	if len(w.Routing) != 1 || w.Routing[0] != (Route{To: "FRA", By: "TG"}) {
+		t.Errorf("got routing %+v", w.Routing)
+	}
+	if w.Shipper.Name != "HPC EXPRESS CO. LTD." || w.Consignee.Name != "EURO PARCEL GMBH" || w.Consignee.Country != "DE" {
+		t.Errorf("got shipper %+v, consignee %+v", w.Shipper, w.Consignee)
+	}
+
+	if _, err := ParseFWB("FFM/8\r\n"); err == nil {
+		t.Error("expected an error for an FFM")
+	}
+}
+
+func TestNearestDate(t *testing.T) {
+	now := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+	for _, tt := range []struct {
+		day   int
+		month time.Month
+		want  time.Time
+	}{
+		{30, time.December, time.Date(2025, 12, 30, 0, 0, 0, 0, time.UTC)},
+		{3, time.January, time.Date(2026, 1, 3, 0, 0, 0, 0, time.UTC)},
+		{31, 0, time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC)},
+		{5, 0, time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)},
+	} {
+		if got := NearestDate(tt.day, tt.month, now); !got.Equal(tt.want) {
+			t.Errorf("%d %v: got %v, want %v", tt.day, tt.month, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package cargoimp
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FHLVersion is the Cargo-IMP FHL message version generated by BuildFHL.
+const FHLVersion = "4"
+
+// BuildFHL renders the house waybills consolidated under w as a Cargo-IMP FHL house list.
+func BuildFHL(w *Waybill, houses []House) (string, error) {
+	var p problems
+	p.require(len(w.Prefix) == 3 && len(w.Serial) == 8, "AWB number is required")
+	p.require(len(w.Origin) == 3, "airport of departure must be an IATA airport code")
+	p.require(len(w.Destination) == 3, "airport of destination must be an IATA airport code")
+	p.require(len(houses) > 0, "at least one house waybill is required")
+	for _, h := range houses {
+		p.require(h.HAWB != "", "HAWB number is required")
+		p.require(h.Pieces > 0, "HAWB %s: number of pieces is required", h.HAWB)
+		p.require(h.Weight > 0, "HAWB %s: gross weight is required", h.HAWB)
+	}
+	if err := p.err(); err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	line := func(format string, args ...interface{}) {
+		b.WriteString(fmt.Sprintf(format, args...))
+		b.WriteString(lineBreak)
+	}
+
+	line("FHL/%s", FHLVersion)
+	line("MBI/%s%s", w.Number(), consignmentDetail(w.Origin, w.Destination, w.Pieces, w.Weight, w.WeightUnit, 0))
+	for _, h := range houses {
+		origin, destination := h.Origin, h.Destination
+		if origin == "" {
+			origin = w.Origin
+		}
+		if destination == "" {
+			destination = w.Destination
+		}
+		line("HBS/%s/%s%s/%d/%s%s//%s",
+			text(h.HAWB, 12), origin, destination, h.Pieces, weightUnit(w.WeightUnit), formatWeight(h.Weight), text(h.Description, 15))
+		if h.Shipper.Name != "" {
+			writeParty(&b, "SHP", h.Shipper)
+		}
+		if h.Consignee.Name != "" {
+			writeParty(&b, "CNE", h.Consignee)
+		}
+	}
+
+	return b.String(), nil
+}
@@ -0,0 +1,205 @@
+package cargoimp
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FWBVersion is the Cargo-IMP FWB message version generated by BuildFWB.
+const FWBVersion = "16"
+
+// BuildFWB renders w as a Cargo-IMP FWB message. Missing mandatory data is reported all at once.
+func BuildFWB(w *Waybill) (string, error) {
+	if err := validateWaybill(w); err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	line := func(format string, args ...interface{}) {
+		b.WriteString(fmt.Sprintf(format, args...))
+		b.WriteString(lineBreak)
+	}
+
+	line("FWB/%s", FWBVersion)
+	line("%s%s", w.Number(), consignmentDetail(w.Origin, w.Destination, w.Pieces, w.Weight, w.WeightUnit, w.Volume))
+
+	if len(w.Flights) > 0 {
+		parts := []string{}
+		for _, f := range w.Flights {
+			parts = append(parts, fmt.Sprintf("%s%s/%02d", f.Carrier, f.Number, f.Day))
+		}
+		line("FLT/%s", strings.Join(parts, "/"))
+	}
+
+	if len(w.Routing) > 0 {
+		parts := []string{}
+		for _, r := range w.Routing {
+			parts = append(parts, r.To+r.By)
+		}
+		line("RTG/%s", strings.Join(parts, "/"))
+	}
+
+	writeParty(&b, "SHP", w.Shipper)
+	writeParty(&b, "CNE", w.Consignee)
+
+	if w.Agent.Name != "" || w.Agent.IATACode != "" {
+		line("AGT/%s/%s/%s", text(w.Agent.AccountNo, 14), w.Agent.IATACode, w.Agent.CASSCode)
+		if name := text(w.Agent.Name, 35); name != "" {
+			line("/%s", name)
+		}
+		if place := text(w.Agent.Place, 17); place != "" {
+			line("/%s", place)
+		}
+	}
+
+	if handling := wrap(w.HandlingInfo, 65, 3); len(handling) > 0 {
+		line("SSR/%s", handling[0])
+		for _, v := range handling[1:] {
+			line("/%s", v)
+		}
+	}
+	if accounting := wrap(w.AccountingInfo, 34, 1); len(accounting) > 0 {
+		line("ACC/GEN/%s", accounting[0])
+	}
+
+	line("CVD/%s/%s/%s%s/%s/%s/%s",
+		w.Currency, w.ChargeCode, paymentCode(w.WtValPrepaid), paymentCode(w.OtherPrepaid),
+		orDefault(w.DeclaredCarriage, "NVD"), orDefault(w.DeclaredCustoms, "NCV"), orDefault(w.Insurance, "XXX"))
+
+	for i, r := range w.Rates {
+		n := i + 1
+		line("RTD/%d/P%d/%s%s/C%s/W%s/R%s/T%s",
+			n, r.Pieces, weightUnit(r.WeightUnit), formatWeight(r.Weight), r.RateClass,
+			formatWeight(r.ChargeableWeight), formatAmount(r.Rate), formatAmount(r.Total))
+		if desc := text(r.Description, 20); desc != "" {
+			line("/NG/%s", desc)
+		}
+		for _, d := range r.Dims {
+			line("/%d/ND//CMT%d-%d-%d/%d", n, d.Length, d.Width, d.Height, d.Count)
+		}
+		if r.Volume > 0 {
+			line("/%d/NV/MC%s", n, formatVolume(r.Volume))
+		}
+	}
+
+	if len(w.OtherCharges) > 0 {
+		charges := ""
+		for _, c := range w.OtherCharges {
+			charges += c.Code + c.Entitlement + formatAmount(c.Amount)
+		}
+		line("OTH/%s/%s", paymentCode(w.OtherPrepaid), charges)
+	}
+
+	for _, c := range w.chargeColumns() {
+		totals := "COL"
+		if c.Prepaid {
+			totals = "PPD"
+		}
+		if c.WtVal {
+			totals += "/WT" + formatAmount(c.WeightCharge)
+			if c.ValuationCharge > 0 {
+				totals += "/VC" + formatAmount(c.ValuationCharge)
+			}
+			if c.Tax > 0 {
+				totals += "/TX" + formatAmount(c.Tax)
+			}
+		}
+		line("%s", totals)
+		others := ""
+		if c.DueAgent > 0 {
+			others += "/OA" + formatAmount(c.DueAgent)
+		}
+		if c.DueCarrier > 0 {
+			others += "/OC" + formatAmount(c.DueCarrier)
+		}
+		line("%s/CT%s", others, formatAmount(c.Total()))
+	}
+
+	if signature := text(w.ShipperSignature, 20); signature != "" {
+		line("CER/%s", signature)
+	}
+	issueDate := ""
+	if !w.IssueDate.IsZero() {
+		issueDate = strings.ToUpper(w.IssueDate.Format("02Jan06"))
+	}
+	line("ISU/%s/%s/%s", issueDate, text(w.IssuePlace, 17), text(w.IssuedBy, 20))
+
+	return b.String(), nil
+}
+
+func validateWaybill(w *Waybill) error {
+	var p problems
+	p.require(len(w.Prefix) == 3 && len(w.Serial) == 8, "AWB number is required")
+	p.require(len(w.Origin) == 3, "airport of departure must be an IATA airport code")
+	p.require(len(w.Destination) == 3, "airport of destination must be an IATA airport code")
+	p.require(w.Pieces > 0, "number of pieces is required")
+	p.require(w.Weight > 0, "gross weight is required")
+	p.require(w.Shipper.Name != "", "shipper name is required")
+	p.require(countryPattern.MatchString(w.Shipper.Country), "shipper country is required")
+	p.require(w.Consignee.Name != "", "consignee name is required")
+	p.require(countryPattern.MatchString(w.Consignee.Country), "consignee country is required")
+	p.require(len(w.Currency) == 3, "currency is required")
+	p.require(len(w.Rates) > 0, "at least one rate line is required")
+	return p.err()
+}
+
+// consignmentDetail renders BKKFRA/T10K250.5MC1.25, the route and totals of a waybill.
+func consignmentDetail(origin, destination string, pieces int64, weight float64, unit string, volume float64) string {
+	s := fmt.Sprintf("%s%s/T%d%s%s", origin, destination, pieces, weightUnit(unit), formatWeight(weight))
+	if volume > 0 {
+		s += "MC" + formatVolume(volume)
+	}
+	return s
+}
+
+// writeParty renders a SHP or CNE block: name, street, place and country.
+func writeParty(b *strings.Builder, tag string, p Party) {
+	b.WriteString(tag)
+	if p.Account != "" {
+		b.WriteString("/" + text(p.Account, 14))
+	}
+	b.WriteString(lineBreak)
+	b.WriteString("/" + text(p.Name, 35) + lineBreak)
+	if len(p.Address) > 0 {
+		b.WriteString("/" + text(p.Address[0], 35) + lineBreak)
+	}
+	if len(p.Address) > 1 {
+		b.WriteString("/" + text(strings.Join(p.Address[1:], " "), 17) + lineBreak)
+	}
+	if p.Country != "" {
+		b.WriteString("/" + p.Country + lineBreak)
+	}
+}
+
+// wrap cleans s and splits it into at most n lines of width characters.
+func wrap(s string, width, n int) []string {
+	lines := []string{}
+	current := ""
+	for _, word := range strings.Fields(text(s, width*n)) {
+		if len(word) > width {
+			word = word[:width]
+		}
+		if current != "" && len(current)+1+len(word) > width {
+			lines = append(lines, current)
+			current = ""
+		}
+		if current != "" {
+			current += " "
+		}
+		current += word
+	}
+	if current != "" {
+		lines = append(lines, current)
+	}
+	if len(lines) > n {
+		lines = lines[:n]
+	}
+	return lines
+}
+
+func orDefault(v, def string) string {
+	if v = text(v, 12); v == "" {
+		return def
+	}
+	return v
+}
@@ -0,0 +1,273 @@
+package cargoimp
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FlightManifest is a Cargo-IMP FFM: the consignments loaded on one flight.
+type FlightManifest struct {
+	Carrier      string
+	FlightNumber string
+	FlightDate   time.Time
+	Origin       string
+	Registration string
+	Consignments []Consignment
+}
+
+// Flight returns the flight as TG920.
+func (m *FlightManifest) Flight() string {
+	return m.Carrier + m.FlightNumber
+}
+
+// Consignment is one AWB line of an FFM. A split shipment appears once per ULD with the
+// pieces loaded there and, on P lines, the total pieces of the AWB.
+type Consignment struct {
+	Prefix         string
+	Serial         string
+	Origin         string
+	Destination    string
+	UnloadingPoint string
+	ArrivalDate    time.Time // scheduled arrival at the point of unloading, when given
+	ShipmentCode   string    // T total, P part, S split, D divided
+	Pieces         int64
+	Weight         float64
+	WeightUnit     string
+	TotalPieces    int64
+	Volume         float64
+	Description    string
+}
+
+// Number returns the AWB number as 618-12345675.
+func (c *Consignment) Number() string {
+	return c.Prefix + "-" + c.Serial
+}
+
+// consignmentPattern reads 618-12345675BKKFRA/T10K250.5MC1.44/GARMENTS and the P5K120T10
+// form used for part shipments.
+var consignmentPattern = regexp.MustCompile(`^(\d{3})-(\d{8})([A-Z]{3})([A-Z]{3})/([TPSD])(\d+)([KL])(\d+(?:\.\d+)?)(?:T(\d+))?(?:MC(\d+(?:\.\d+)?))?(?:/(.*))?$`)
+
+// ffmLineTags are the three letter tags of FFM lines that are not points of unloading.
+var ffmLineTags = map[string]bool{"ULD": true, "SSR": true, "OSI": true, "OCI": true, "DIM": true, "COR": true, "SCI": true, "SPH": true}
+
+var flightDatePattern = regexp.MustCompile(`^(\d{2})([A-Z]{3})`)
+
+// ParseFFM reads a Cargo-IMP FFM flight manifest. The flight date carries no year, so the
+// one nearest to now is used.
+func ParseFFM(message string, now time.Time) (*FlightManifest, error) {
+	lines := messageLines(message)
+	if len(lines) < 2 || !strings.HasPrefix(lines[0], "FFM/") {
+		return nil, errors.New("not an FFM message")
+	}
+
+	// 1/TG920/15OCT/BKK/HSTGA
+	fields := strings.Split(lines[1], "/")
+	if len(fields) < 4 || len(fields[1]) < 3 {
+		return nil, fmt.Errorf("invalid flight line '%s'", lines[1])
+	}
+	m := &FlightManifest{
+		Carrier:      fields[1][:2],
+		FlightNumber: fields[1][2:],
+		Origin:       fields[3],
+	}
+	if len(fields) > 4 {
+		m.Registration = fields[4]
+	}
+	var ok bool
+	if m.FlightDate, ok = parseDayMonth(fields[2], now); !ok {
+		return nil, fmt.Errorf("invalid flight date '%s'", fields[2])
+	}
+
+	unloadingPoint := ""
+	var arrivalDate time.Time
+	for _, line := range lines[2:] {
+		if line == "LAST" || line == "CONT" {
+			break
+		}
+		if c, ok := parseConsignment(line); ok {
+			c.UnloadingPoint = unloadingPoint
+			c.ArrivalDate = arrivalDate
+			m.Consignments = append(m.Consignments, c)
+			continue
+		}
+		// A point of unloading is a line of its own, optionally followed by the arrival date: BKK/16OCT0610
+		parts := strings.Split(line, "/")
+		if code := parts[0]; len(code) == 3 && airportPattern.MatchString(code) && !ffmLineTags[code] {
+			unloadingPoint = code
+			arrivalDate = time.Time{}
+			if len(parts) > 1 {
+				arrivalDate, _ = parseDayMonth(parts[1], now)
+			}
+		}
+	}
+
+	if len(m.Consignments) == 0 {
+		return nil, errors.New("FFM lists no consignments")
+	}
+	return m, nil
+}
+
+// ParseFWB reads the routing, totals and parties of a Cargo-IMP FWB. Rating and charges are
+// not read back.
+func ParseFWB(message string) (*Waybill, error) {
+	lines := messageLines(message)
+	if len(lines) < 2 || !strings.HasPrefix(lines[0], "FWB/") {
+		return nil, errors.New("not an FWB message")
+	}
+
+	c, ok := parseConsignment(lines[1])
+	if !ok {
+		return nil, fmt.Errorf("invalid AWB line '%s'", lines[1])
+	}
+	w := &Waybill{
+		Prefix:      c.Prefix,
+		Serial:      c.Serial,
+		Origin:      c.Origin,
+		Destination: c.Destination,
+		Pieces:      c.Pieces,
+		Weight:      c.Weight,
+		WeightUnit:  c.WeightUnit,
+		Volume:      c.Volume,
+	}
+
+	for i, line := range lines[2:] {
+		tag, value, _ := strings.Cut(line, "/")
+		switch tag {
+		case "FLT":
+			// FLT/TG920/15/TG921/16
+			parts := strings.Split(value, "/")
+			for j := 0; j+1 < len(parts); j += 2 {
+				if len(parts[j]) < 3 {
+					continue
+				}
+				day, _ := strconv.Atoi(parts[j+1])
+				w.Flights = append(w.Flights, Flight{Carrier: parts[j][:2], Number: parts[j][2:], Day: day})
+			}
+		case "RTG":
+			for _, leg := range strings.Split(value, "/") {
+				if len(leg) >= 5 {
+					w.Routing = append(w.Routing, Route{To: leg[:3], By: leg[3:]})
+				}
+			}
+		case "SHP":
+			w.Shipper = parsePartyBlock(lines[2+i+1:])
+		case "CNE":
+			w.Consignee = parsePartyBlock(lines[2+i+1:])
+		}
+	}
+
+	return w, nil
+}
+
+// parseDayMonth reads a date such as 15OCT or 15OCT0610.
+func parseDayMonth(value string, now time.Time) (time.Time, bool) {
+	date := flightDatePattern.FindStringSubmatch(value)
+	if date == nil {
+		return time.Time{}, false
+	}
+	month, err := time.Parse("Jan", date[2][:1]+strings.ToLower(date[2][1:]))
+	if err != nil {
+		return time.Time{}, false
+	}
+	day, _ := strconv.Atoi(date[1])
+	return NearestDate(day, month.Month(), now), true
+}
+
+func parseConsignment(line string) (Consignment, bool) {
+	m := consignmentPattern.FindStringSubmatch(line)
+	if m == nil {
+		return Consignment{}, false
+	}
+	c := Consignment{
+		Prefix:       m[1],
+		Serial:       m[2],
+		Origin:       m[3],
+		Destination:  m[4],
+		ShipmentCode: m[5],
+		WeightUnit:   m[7],
+		Description:  m[11],
+	}
+	c.Pieces, _ = strconv.ParseInt(m[6], 10, 64)
+	c.Weight, _ = strconv.ParseFloat(m[8], 64)
+	c.TotalPieces = c.Pieces
+	if m[9] != "" {
+		c.TotalPieces, _ = strconv.ParseInt(m[9], 10, 64)
+	}
+	if m[10] != "" {
+		c.Volume, _ = strconv.ParseFloat(m[10], 64)
+	}
+	return c, true
+}
+
+// parsePartyBlock reads the /NAME, /STREET, /PLACE and /COUNTRY lines after SHP or CNE.
+func parsePartyBlock(lines []string) Party {
+	values := []string{}
+	for _, line := range lines {
+		if !strings.HasPrefix(line, "/") {
+			break
+		}
+		values = append(values, strings.TrimPrefix(line, "/"))
+	}
+	if len(values) == 0 {
+		return Party{}
+	}
+	p := Party{Name: values[0]}
+	for _, v := range values[1:] {
+		if len(v) == 2 {
+			p.Country = v
+			continue
+		}
+		p.Address = append(p.Address, v)
+	}
+	return p
+}
+
+// messageLines splits a message into trimmed, non-empty lines.
+func messageLines(message string) []string {
+	lines := []string{}
+	for _, line := range strings.Split(strings.ReplaceAll(message, "\r", ""), "\n") {
+		if line = strings.ToUpper(strings.TrimSpace(line)); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// NearestDate completes a day, or a day and month, with the month or year that puts it
+// nearest to now. month 0 means the month is unknown as well.
+func NearestDate(day int, month time.Month, now time.Time) time.Time {
+	year := now.Year()
+	candidates := []time.Time{}
+	if month == 0 {
+		for offset := -1; offset <= 1; offset++ {
+			candidates = append(candidates, time.Date(year, now.Month()+time.Month(offset), day, 0, 0, 0, 0, time.UTC))
+		}
+	} else {
+		for offset := -1; offset <= 1; offset++ {
+			candidates = append(candidates, time.Date(year+offset, month, day, 0, 0, 0, 0, time.UTC))
+		}
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	var nearest time.Time
+	for _, t := range candidates {
+		if t.Day() != day {
+			continue // 31 of a shorter month
+		}
+		if nearest.IsZero() || absDuration(t.Sub(today)) < absDuration(nearest.Sub(today)) {
+			nearest = t
+		}
+	}
+	return nearest
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
@@ -0,0 +1,413 @@
+package cargoimp
+
+import (
+	"encoding/xml"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cargo-XML namespaces. The prefixes are written literally as encoding/xml cannot choose them.
+const (
+	waybillNamespace      = "iata:waybill:1"
+	houseWaybillNamespace = "iata:housewaybill:1"
+	dataModelNamespace    = "iata:datamodel:3"
+	cargoXMLVersion       = "3.00"
+)
+
+type xmlWaybill struct {
+	XMLName           xml.Name             `xml:"rsm:Waybill"`
+	RsmNamespace      string               `xml:"xmlns:rsm,attr"`
+	RamNamespace      string               `xml:"xmlns:ram,attr"`
+	MessageHeader     xmlMessageHeader     `xml:"rsm:MessageHeaderDocument"`
+	BusinessHeader    xmlBusinessHeader    `xml:"rsm:BusinessHeaderDocument"`
+	MasterConsignment xmlMasterConsignment `xml:"rsm:MasterConsignment"`
+}
+
+type xmlHouseWaybill struct {
+	XMLName           xml.Name                  `xml:"rsm:HouseWaybill"`
+	RsmNamespace      string                    `xml:"xmlns:rsm,attr"`
+	RamNamespace      string                    `xml:"xmlns:ram,attr"`
+	MessageHeader     xmlMessageHeader          `xml:"rsm:MessageHeaderDocument"`
+	BusinessHeader    xmlBusinessHeader         `xml:"rsm:BusinessHeaderDocument"`
+	MasterConsignment xmlHouseMasterConsignment `xml:"rsm:MasterConsignment"`
+}
+
+type xmlMessageHeader struct {
+	ID            string `xml:"ram:ID"`
+	Name          string `xml:"ram:Name"`
+	TypeCode      string `xml:"ram:TypeCode"`
+	IssueDateTime string `xml:"ram:IssueDateTime"`
+	PurposeCode   string `xml:"ram:PurposeCode"`
+	VersionID     string `xml:"ram:VersionID"`
+}
+
+type xmlBusinessHeader struct {
+	ID                 string                    `xml:"ram:ID"`
+	ConsignorSignature *xmlSignatory             `xml:"ram:SignatoryConsignorAuthentication,omitempty"`
+	CarrierSignature   *xmlCarrierAuthentication `xml:"ram:SignatoryCarrierAuthentication,omitempty"`
+}
+
+type xmlSignatory struct {
+	Signatory string `xml:"ram:Signatory"`
+}
+
+type xmlCarrierAuthentication struct {
+	ActualDateTime string      `xml:"ram:ActualDateTime,omitempty"`
+	Signatory      string      `xml:"ram:Signatory"`
+	Location       xmlLocation `xml:"ram:IssueAuthenticationLocation"`
+}
+
+type xmlLocation struct {
+	ID   string `xml:"ram:ID,omitempty"`
+	Name string `xml:"ram:Name,omitempty"`
+}
+
+type xmlMeasure struct {
+	UnitCode string `xml:"unitCode,attr"`
+	Value    string `xml:",chardata"`
+}
+
+type xmlAmount struct {
+	CurrencyID string `xml:"currencyID,attr"`
+	Value      string `xml:",chardata"`
+}
+
+type xmlParty struct {
+	AccountID string           `xml:"ram:AccountID,omitempty"`
+	Name      string           `xml:"ram:Name"`
+	Address   xmlPostalAddress `xml:"ram:PostalStructuredAddress"`
+}
+
+type xmlPostalAddress struct {
+	StreetName string `xml:"ram:StreetName,omitempty"`
+	CityName   string `xml:"ram:CityName,omitempty"`
+	CountryID  string `xml:"ram:CountryID,omitempty"`
+}
+
+type xmlAgentParty struct {
+	AccountID    string `xml:"ram:AccountID,omitempty"`
+	Name         string `xml:"ram:Name"`
+	CargoAgentID string `xml:"ram:CargoAgentID,omitempty"`
+	CASSID       string `xml:"ram:AgentCASSID,omitempty"`
+	CityName     string `xml:"ram:PostalStructuredAddress>ram:CityName,omitempty"`
+}
+
+type xmlMovement struct {
+	StageCode   string `xml:"ram:StageCode"`
+	ID          string `xml:"ram:ID"`
+	CarrierName string `xml:"ram:UsedLogisticsTransportMeans>ram:Name"`
+	ArrivalCode string `xml:"ram:ArrivalEvent>ram:OccurrenceArrivalLocation>ram:ID,omitempty"`
+}
+
+type xmlAllowanceCharge struct {
+	PrepaidIndicator bool      `xml:"ram:PrepaidIndicator"`
+	PartyTypeCode    string    `xml:"ram:PartyTypeCode"`
+	ID               string    `xml:"ram:ID"`
+	ActualAmount     xmlAmount `xml:"ram:ActualAmount"`
+}
+
+type xmlRatingItem struct {
+	SequenceNumeric   int              `xml:"ram:SequenceNumeric"`
+	GrossWeight       xmlMeasure       `xml:"ram:GrossWeightMeasure"`
+	GrossVolume       *xmlMeasure      `xml:"ram:GrossVolumeMeasure,omitempty"`
+	PieceQuantity     int64            `xml:"ram:PieceQuantity"`
+	NatureIdentity    string           `xml:"ram:NatureIdentificationTransportCargo>ram:Identification"`
+	Dimensions        []xmlDimensions  `xml:"ram:TransportLogisticsPackage,omitempty"`
+	FreightRateCharge xmlFreightCharge `xml:"ram:ApplicableFreightRateServiceCharge"`
+}
+
+type xmlDimensions struct {
+	ItemQuantity int64      `xml:"ram:ItemQuantity"`
+	Width        xmlMeasure `xml:"ram:LinearSpatialDimension>ram:WidthMeasure"`
+	Length       xmlMeasure `xml:"ram:LinearSpatialDimension>ram:LengthMeasure"`
+	Height       xmlMeasure `xml:"ram:LinearSpatialDimension>ram:HeightMeasure"`
+}
+
+type xmlFreightCharge struct {
+	CategoryCode     string     `xml:"ram:CategoryCode"`
+	ChargeableWeight xmlMeasure `xml:"ram:ChargeableWeightMeasure"`
+	AppliedRate      string     `xml:"ram:AppliedRate"`
+	AppliedAmount    xmlAmount  `xml:"ram:AppliedAmount"`
+}
+
+type xmlRating struct {
+	TypeCode    string          `xml:"ram:TypeCode"`
+	TotalCharge xmlAmount       `xml:"ram:TotalChargeAmount"`
+	Items       []xmlRatingItem `xml:"ram:IncludedMasterConsignmentItem"`
+}
+
+type xmlTotalRating struct {
+	TypeCode   string         `xml:"ram:TypeCode"`
+	Summations []xmlSummation `xml:"ram:ApplicablePrepaidCollectMonetarySummation"`
+}
+
+type xmlSummation struct {
+	PrepaidIndicator bool       `xml:"ram:PrepaidIndicator"`
+	WeightCharge     *xmlAmount `xml:"ram:WeightChargeTotalAmount,omitempty"`
+	ValuationCharge  *xmlAmount `xml:"ram:ValuationChargeTotalAmount,omitempty"`
+	Tax              *xmlAmount `xml:"ram:TaxTotalAmount,omitempty"`
+	DueAgent         *xmlAmount `xml:"ram:AgentTotalDuePayableAmount,omitempty"`
+	DueCarrier       *xmlAmount `xml:"ram:CarrierTotalDuePayableAmount,omitempty"`
+	GrandTotal       xmlAmount  `xml:"ram:GrandTotalAmount"`
+}
+
+type xmlMasterConsignment struct {
+	DeclaredCarriage string               `xml:"ram:DeclaredValueForCarriageAmount"`
+	DeclaredCustoms  string               `xml:"ram:DeclaredValueForCustomsAmount"`
+	Insurance        string               `xml:"ram:InsuranceValueAmount"`
+	GrossWeight      xmlMeasure           `xml:"ram:IncludedTareGrossWeightMeasure"`
+	GrossVolume      *xmlMeasure          `xml:"ram:GrossVolumeMeasure,omitempty"`
+	TotalPieces      int64                `xml:"ram:TotalPieceQuantity"`
+	Consignor        xmlParty             `xml:"ram:ConsignorParty"`
+	Consignee        xmlParty             `xml:"ram:ConsigneeParty"`
+	FreightForwarder *xmlAgentParty       `xml:"ram:FreightForwarderParty,omitempty"`
+	Origin           xmlLocation          `xml:"ram:OriginLocation"`
+	Destination      xmlLocation          `xml:"ram:FinalDestinationLocation"`
+	Movements        []xmlMovement        `xml:"ram:SpecifiedLogisticsTransportMovement"`
+	Handling         []string             `xml:"ram:HandlingSSRInstructions>ram:Description,omitempty"`
+	Accounting       string               `xml:"ram:IncludedAccountingNote>ram:Content,omitempty"`
+	CurrencyCode     string               `xml:"ram:ApplicableOriginCurrencyExchange>ram:SourceCurrencyCode"`
+	PaymentMethod    string               `xml:"ram:ApplicableLogisticsServiceCharge>ram:TransportPaymentMethodCode"`
+	OtherCharges     []xmlAllowanceCharge `xml:"ram:ApplicableLogisticsAllowanceCharge"`
+	Rating           xmlRating            `xml:"ram:ApplicableRating"`
+	TotalRating      xmlTotalRating       `xml:"ram:ApplicableTotalRating"`
+}
+
+type xmlHouseMasterConsignment struct {
+	TransportContract string              `xml:"ram:TransportContractDocument>ram:ID"`
+	Origin            xmlLocation         `xml:"ram:OriginLocation"`
+	Destination       xmlLocation         `xml:"ram:FinalDestinationLocation"`
+	House             xmlHouseConsignment `xml:"ram:IncludedHouseConsignment"`
+}
+
+type xmlHouseConsignment struct {
+	GrossWeight xmlMeasure  `xml:"ram:IncludedTareGrossWeightMeasure"`
+	TotalPieces int64       `xml:"ram:TotalPieceQuantity"`
+	Summary     string      `xml:"ram:SummaryDescription,omitempty"`
+	Consignor   xmlParty    `xml:"ram:ConsignorParty"`
+	Consignee   xmlParty    `xml:"ram:ConsigneeParty"`
+	Origin      xmlLocation `xml:"ram:OriginLocation"`
+	Destination xmlLocation `xml:"ram:FinalDestinationLocation"`
+}
+
+// BuildXFWB renders w as a Cargo-XML XFWB message created at now.
+func BuildXFWB(w *Waybill, now time.Time) ([]byte, error) {
+	if err := validateWaybill(w); err != nil {
+		return nil, err
+	}
+
+	unit := xmlWeightUnit(w.WeightUnit)
+	m := xmlMasterConsignment{
+		DeclaredCarriage: orDefault(w.DeclaredCarriage, "NVD"),
+		DeclaredCustoms:  orDefault(w.DeclaredCustoms, "NCV"),
+		Insurance:        orDefault(w.Insurance, "XXX"),
+		GrossWeight:      xmlMeasure{UnitCode: unit, Value: formatWeight(w.Weight)},
+		TotalPieces:      w.Pieces,
+		Consignor:        newXMLParty(w.Shipper),
+		Consignee:        newXMLParty(w.Consignee),
+		Origin:           xmlLocation{ID: w.Origin},
+		Destination:      xmlLocation{ID: w.Destination},
+		Handling:         wrap(w.HandlingInfo, 65, 3),
+		Accounting:       text(w.AccountingInfo, 34),
+		CurrencyCode:     w.Currency,
+		PaymentMethod:    paymentCode(w.WtValPrepaid) + paymentCode(w.OtherPrepaid),
+		Rating: xmlRating{
+			TypeCode:    "F",
+			TotalCharge: xmlAmount{CurrencyID: w.Currency, Value: formatAmount(w.Summary.WeightCharge)},
+		},
+		TotalRating: xmlTotalRating{
+			TypeCode: "F",
+		},
+	}
+	for _, c := range w.chargeColumns() {
+		summation := xmlSummation{
+			PrepaidIndicator: c.Prepaid,
+			ValuationCharge:  optionalAmount(w.Currency, c.ValuationCharge),
+			Tax:              optionalAmount(w.Currency, c.Tax),
+			DueAgent:         optionalAmount(w.Currency, c.DueAgent),
+			DueCarrier:       optionalAmount(w.Currency, c.DueCarrier),
+			GrandTotal:       xmlAmount{CurrencyID: w.Currency, Value: formatAmount(c.Total())},
+		}
+		if c.WtVal {
+			summation.WeightCharge = &xmlAmount{CurrencyID: w.Currency, Value: formatAmount(c.WeightCharge)}
+		}
+		m.TotalRating.Summations = append(m.TotalRating.Summations, summation)
+	}
+	if w.Volume > 0 {
+		m.GrossVolume = &xmlMeasure{UnitCode: "MTQ", Value: formatVolume(w.Volume)}
+	}
+	if w.Agent.Name != "" || w.Agent.IATACode != "" {
+		m.FreightForwarder = &xmlAgentParty{
+			AccountID:    text(w.Agent.AccountNo, 14),
+			Name:         text(w.Agent.Name, 35),
+			CargoAgentID: w.Agent.IATACode,
+			CASSID:       w.Agent.CASSCode,
+			CityName:     text(w.Agent.Place, 17),
+		}
+	}
+
+	for i, f := range w.Flights {
+		movement := xmlMovement{
+			StageCode:   "Main-Carriage",
+			ID:          f.Carrier + f.Number,
+			CarrierName: f.Carrier,
+		}
+		if i < len(w.Routing) {
+			movement.ArrivalCode = w.Routing[i].To
+		}
+		m.Movements = append(m.Movements, movement)
+	}
+
+	for _, c := range w.OtherCharges {
+		m.OtherCharges = append(m.OtherCharges, xmlAllowanceCharge{
+			PrepaidIndicator: w.OtherPrepaid,
+			PartyTypeCode:    c.Entitlement,
+			ID:               c.Code,
+			ActualAmount:     xmlAmount{CurrencyID: w.Currency, Value: formatAmount(c.Amount)},
+		})
+	}
+
+	for i, r := range w.Rates {
+		item := xmlRatingItem{
+			SequenceNumeric: i + 1,
+			GrossWeight:     xmlMeasure{UnitCode: xmlWeightUnit(r.WeightUnit), Value: formatWeight(r.Weight)},
+			PieceQuantity:   r.Pieces,
+			NatureIdentity:  text(r.Description, 20),
+			FreightRateCharge: xmlFreightCharge{
+				CategoryCode:     r.RateClass,
+				ChargeableWeight: xmlMeasure{UnitCode: xmlWeightUnit(r.WeightUnit), Value: formatWeight(r.ChargeableWeight)},
+				AppliedRate:      formatAmount(r.Rate),
+				AppliedAmount:    xmlAmount{CurrencyID: w.Currency, Value: formatAmount(r.Total)},
+			},
+		}
+		if r.Volume > 0 {
+			item.GrossVolume = &xmlMeasure{UnitCode: "MTQ", Value: formatVolume(r.Volume)}
+		}
+		for _, d := range r.Dims {
+			item.Dimensions = append(item.Dimensions, xmlDimensions{
+				ItemQuantity: d.Count,
+				Width:        xmlMeasure{UnitCode: "CMT", Value: strconv.FormatInt(d.Width, 10)},
+				Length:       xmlMeasure{UnitCode: "CMT", Value: strconv.FormatInt(d.Length, 10)},
+				Height:       xmlMeasure{UnitCode: "CMT", Value: strconv.FormatInt(d.Height, 10)},
+			})
+		}
+		m.Rating.Items = append(m.Rating.Items, item)
+	}
+
+	doc := xmlWaybill{
+		RsmNamespace:      waybillNamespace,
+		RamNamespace:      dataModelNamespace,
+		MessageHeader:     newXMLMessageHeader(w.Number(), "Master Air Waybill", "740", now),
+		BusinessHeader:    newXMLBusinessHeader(w.Number(), w),
+		MasterConsignment: m,
+	}
+	return marshalXML(doc)
+}
+
+// BuildXFZB renders h, consolidated under w, as a Cargo-XML XFZB house waybill created at now.
+func BuildXFZB(w *Waybill, h House, now time.Time) ([]byte, error) {
+	var p problems
+	p.require(len(w.Prefix) == 3 && len(w.Serial) == 8, "AWB number is required")
+	p.require(h.HAWB != "", "HAWB number is required")
+	p.require(h.Pieces > 0, "HAWB %s: number of pieces is required", h.HAWB)
+	p.require(h.Weight > 0, "HAWB %s: gross weight is required", h.HAWB)
+	if err := p.err(); err != nil {
+		return nil, err
+	}
+
+	origin, destination := h.Origin, h.Destination
+	if origin == "" {
+		origin = w.Origin
+	}
+	if destination == "" {
+		destination = w.Destination
+	}
+
+	doc := xmlHouseWaybill{
+		RsmNamespace:   houseWaybillNamespace,
+		RamNamespace:   dataModelNamespace,
+		MessageHeader:  newXMLMessageHeader(h.HAWB, "House Waybill", "703", now),
+		BusinessHeader: xmlBusinessHeader{ID: h.HAWB},
+		MasterConsignment: xmlHouseMasterConsignment{
+			TransportContract: w.Number(),
+			Origin:            xmlLocation{ID: w.Origin},
+			Destination:       xmlLocation{ID: w.Destination},
+			House: xmlHouseConsignment{
+				GrossWeight: xmlMeasure{UnitCode: xmlWeightUnit(w.WeightUnit), Value: formatWeight(h.Weight)},
+				TotalPieces: h.Pieces,
+				Summary:     text(h.Description, 70),
+				Consignor:   newXMLParty(h.Shipper),
+				Consignee:   newXMLParty(h.Consignee),
+				Origin:      xmlLocation{ID: origin},
+				Destination: xmlLocation{ID: destination},
+			},
+		},
+	}
+	return marshalXML(doc)
+}
+
+func newXMLMessageHeader(id, name, typeCode string, now time.Time) xmlMessageHeader {
+	return xmlMessageHeader{
+		ID:            id,
+		Name:          name,
+		TypeCode:      typeCode,
+		IssueDateTime: now.Format("2006-01-02T15:04:05"),
+		PurposeCode:   "Creation",
+		VersionID:     cargoXMLVersion,
+	}
+}
+
+func newXMLBusinessHeader(id string, w *Waybill) xmlBusinessHeader {
+	h := xmlBusinessHeader{ID: id}
+	if signature := text(w.ShipperSignature, 20); signature != "" {
+		h.ConsignorSignature = &xmlSignatory{Signatory: signature}
+	}
+	if w.IssuedBy != "" || w.IssuePlace != "" {
+		h.CarrierSignature = &xmlCarrierAuthentication{
+			Signatory: text(w.IssuedBy, 20),
+			Location:  xmlLocation{Name: text(w.IssuePlace, 17)},
+		}
+		if !w.IssueDate.IsZero() {
+			h.CarrierSignature.ActualDateTime = w.IssueDate.Format("2006-01-02T15:04:05")
+		}
+	}
+	return h
+}
+
+func newXMLParty(p Party) xmlParty {
+	party := xmlParty{
+		AccountID: text(p.Account, 14),
+		Name:      text(p.Name, 70),
+		Address:   xmlPostalAddress{CountryID: p.Country},
+	}
+	if len(p.Address) > 0 {
+		party.Address.StreetName = text(p.Address[0], 70)
+	}
+	if len(p.Address) > 1 {
+		party.Address.CityName = text(strings.Join(p.Address[1:], " "), 70)
+	}
+	return party
+}
+
+func optionalAmount(currency string, v float64) *xmlAmount {
+	if v <= 0 {
+		return nil
+	}
+	return &xmlAmount{CurrencyID: currency, Value: formatAmount(v)}
+}
+
+func xmlWeightUnit(unit string) string {
+	if weightUnit(unit) == "L" {
+		return "LBR"
+	}
+	return "KGM"
+}
+
+func marshalXML(v interface{}) ([]byte, error) {
+	body, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), body...), nil
+}
@@ -0,0 +1,195 @@
+package common
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// ExchangeRateHistory is one currency of a customs rate table, valid from EffectiveFrom to EffectiveTo inclusive.
+type ExchangeRateHistory struct {
+	tableName          struct{}  `pg:"ship2cu.customs_exchange_rate_history,alias:cerh"`
+	ID                 int64     `json:"id" pg:"id,pk"`
+	CountryCode        string    `json:"countryCode" pg:"country_code"`
+	CurrencyCode       string    `json:"currencyCode" pg:"currency_code"`
+	CurrencyName       string    `json:"currencyName" pg:"currency_name"`
+	ImportExchangeRate float64   `json:"importExchangeRate" pg:"import_exchange_rate,use_zero"`
+	ExportExchangeRate float64   `json:"exportExchangeRate" pg:"export_exchange_rate,use_zero"`
+	Ratio              float64   `json:"ratio" pg:"ratio,use_zero"`
+	EffectiveFrom      time.Time `json:"effectiveFrom" pg:"effective_from,type:date"`
+	EffectiveTo        time.Time `json:"effectiveTo" pg:"effective_to,type:date"`
+	SourceFileName     string    `json:"sourceFileName" pg:"source_file_name"`
+	CreatedAt          time.Time `json:"createdAt" pg:"created_at"`
+}
+
+// ImportExchangeRateModel is a customs rate table file for the week starting at EffectiveFrom.
+type ImportExchangeRateModel struct {
+	FileName      string
+	FileBytes     []byte
+	EffectiveFrom time.Time
+	EffectiveTo   time.Time
+}
+
+type ImportExchangeRateResultModel struct {
+	EffectiveFrom string `json:"effectiveFrom"`
+	EffectiveTo   string `json:"effectiveTo"`
+	Total         int    `json:"total"`
+}
+
+// ExchangeRateOnModel is the rate per one unit of currency that customs applies on a given date.
+// Source is always "history": the rate comes from an imported rate table.
+type ExchangeRateOnModel struct {
+	CurrencyCode       string  `json:"currencyCode"`
+	ImportExchangeRate float64 `json:"importExchangeRate"`
+	ExportExchangeRate float64 `json:"exportExchangeRate"`
+	EffectiveFrom      string  `json:"effectiveFrom"`
+	EffectiveTo        string  `json:"effectiveTo"`
+	Source             string  `json:"source"`
+}
+
+// exchangeRateColumns maps the normalised header names of a customs rate table onto its fields.
+var exchangeRateColumns = map[string]string{
+	"countrycode":        "country_code",
+	"currencycode":       "currency_code",
+	"currency":           "currency_code",
+	"currencyname":       "currency_name",
+	"importrate":         "import_exchange_rate",
+	"importexchangerate": "import_exchange_rate",
+	"exportrate":         "export_exchange_rate",
+	"exportexchangerate": "export_exchange_rate",
+	"ratio":              "ratio",
+	"unit":               "ratio",
+}
+
+// parseExchangeRateTable reads a customs rate table from a CSV or Excel file.
+// Headers are matched by name; a missing ratio means the rate is per one unit.
+func parseExchangeRateTable(fileName string, fileBytes []byte) ([]*ExchangeRateHistory, error) {
+	if len(fileBytes) == 0 {
+		return nil, errors.New("empty")
+	}
+
+	var rows [][]string
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".csv":
+		r := csv.NewReader(bytes.NewReader(fileBytes))
+		r.FieldsPerRecord = -1
+		r.TrimLeadingSpace = true
+		for {
+			record, err := r.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			rows = append(rows, record)
+		}
+	case ".xlsx", ".xlsm":
+		x, err := excelize.OpenReader(bytes.NewReader(fileBytes))
+		if err != nil {
+			return nil, err
+		}
+		defer x.Close()
+
+		rows, err = x.GetRows(x.GetSheetName(0))
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported file type '%s'", filepath.Ext(fileName))
+	}
+
+	if len(rows) < 2 {
+		return nil, errors.New("rate table is empty")
+	}
+
+	columnIndex := map[string]int{}
+	for i, header := range rows[0] {
+		key := strings.NewReplacer(" ", "", "_", "", "-", "").Replace(strings.ToLower(strings.TrimSpace(header)))
+		if column, ok := exchangeRateColumns[key]; ok {
+			if _, exists := columnIndex[column]; !exists {
+				columnIndex[column] = i
+			}
+		}
+	}
+	for _, column := range []string{"currency_code", "import_exchange_rate", "export_exchange_rate"} {
+		if _, ok := columnIndex[column]; !ok {
+			return nil, fmt.Errorf("missing column '%s'", column)
+		}
+	}
+
+	cell := func(row []string, column string) string {
+		idx, ok := columnIndex[column]
+		if !ok || idx >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(row[idx])
+	}
+	number := func(rowNumber int, row []string, column string) (float64, error) {
+		value := strings.ReplaceAll(cell(row, column), ",", "")
+		if value == "" {
+			return 0, nil
+		}
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return 0, fmt.Errorf("row %d: '%s' is not a number", rowNumber, column)
+		}
+		return f, nil
+	}
+
+	list := []*ExchangeRateHistory{}
+	seen := map[string]int{}
+	for i := 1; i < len(rows); i++ {
+		row := rows[i]
+		currencyCode := strings.ToUpper(cell(row, "currency_code"))
+		if currencyCode == "" {
+			continue
+		}
+		if first, exists := seen[currencyCode]; exists {
+			return nil, fmt.Errorf("row %d: currency '%s' already listed in row %d", i+1, currencyCode, first)
+		}
+		seen[currencyCode] = i + 1
+
+		importRate, err := number(i+1, row, "import_exchange_rate")
+		if err != nil {
+			return nil, err
+		}
+		exportRate, err := number(i+1, row, "export_exchange_rate")
+		if err != nil {
+			return nil, err
+		}
+		ratio, err := number(i+1, row, "ratio")
+		if err != nil {
+			return nil, err
+		}
+		if ratio == 0 {
+			ratio = 1
+		}
+		if importRate <= 0 && exportRate <= 0 {
+			return nil, fmt.Errorf("row %d: currency '%s' has no rate", i+1, currencyCode)
+		}
+
+		list = append(list, &ExchangeRateHistory{
+			CountryCode:        strings.ToUpper(cell(row, "country_code")),
+			CurrencyCode:       currencyCode,
+			CurrencyName:       cell(row, "currency_name"),
+			ImportExchangeRate: importRate,
+			ExportExchangeRate: exportRate,
+			Ratio:              ratio,
+		})
+	}
+
+	if len(list) == 0 {
+		return nil, errors.New("rate table is empty")
+	}
+
+	return list, nil
+}
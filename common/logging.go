@@ -40,3 +40,39 @@ func (s *loggingService) GetAllConvertTemplates(ctx context.Context, param strin
 	}(time.Now())
 	return s.next.GetAllConvertTemplates(ctx, param)
 }
+
+func (s *loggingService) ImportExchangeRates(ctx context.Context, data *ImportExchangeRateModel) (result *ImportExchangeRateResultModel, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "import_exchange_rates",
+			"file_name", data.FileName,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.ImportExchangeRates(ctx, data)
+}
+
+func (s *loggingService) GetExchangeRateHistories(ctx context.Context, currencyCode string) (result []*ExchangeRateHistory, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "get_exchange_rate_histories",
+			"total", len(result),
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.GetExchangeRateHistories(ctx, currencyCode)
+}
+
+func (s *loggingService) RateOn(ctx context.Context, currencyCode string, on time.Time) (result *ExchangeRateOnModel, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "rate_on",
+			"currency_code", currencyCode,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.RateOn(ctx, currencyCode, on)
+}
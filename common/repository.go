@@ -3,11 +3,16 @@ package common
 import (
 	"context"
 	"time"
+
+	"github.com/go-pg/pg/v9"
 )
 
 type Repository interface {
 	GetAllExchangeRates(ctx context.Context) ([]*GetExchangeRateModel, error)
 	GetAllConvertTemplates(ctx context.Context, category string) ([]*GetAllConvertTemplateModel, error)
+	ReplaceExchangeRateHistories(ctx context.Context, effectiveFrom, effectiveTo time.Time, rates []*ExchangeRateHistory) error
+	GetExchangeRateHistories(ctx context.Context, currencyCode string) ([]*ExchangeRateHistory, error)
+	GetExchangeRateOn(ctx context.Context, currencyCode string, on time.Time) (*ExchangeRateOnModel, error)
 }
 
 type repository struct {
@@ -82,3 +87,79 @@ func (r repository) GetAllConvertTemplates(ctx context.Context, category string)
 
 	return list, nil
 }
+
+// ReplaceExchangeRateHistories stores the rate table of one week, replacing a table imported earlier for the same week.
+func (r repository) ReplaceExchangeRateHistories(ctx context.Context, effectiveFrom, effectiveTo time.Time, rates []*ExchangeRateHistory) error {
+	db, err := GetQer(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Model(&ExchangeRateHistory{}).
+		Where("effective_from = ?", effectiveFrom.Format("2006-01-02")).
+		Where("effective_to = ?", effectiveTo.Format("2006-01-02")).
+		Delete()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, rate := range rates {
+		rate.EffectiveFrom = effectiveFrom
+		rate.EffectiveTo = effectiveTo
+		rate.CreatedAt = now
+	}
+
+	_, err = db.Model(&rates).Insert()
+	return err
+}
+
+func (r repository) GetExchangeRateHistories(ctx context.Context, currencyCode string) ([]*ExchangeRateHistory, error) {
+	db, err := GetQer(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var list []*ExchangeRateHistory
+	q := db.Model(&list).Order("effective_from DESC", "currency_code")
+	if currencyCode != "" {
+		q = q.Where("currency_code = ?", currencyCode)
+	}
+	err = q.Select()
+
+	return list, err
+}
+
+// GetExchangeRateOn returns the imported rate whose week covers on, or nil when no rate table covers that date.
+// When weeks overlap the most recently started one wins.
+func (r repository) GetExchangeRateOn(ctx context.Context, currencyCode string, on time.Time) (*ExchangeRateOnModel, error) {
+	db, err := GetQer(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	x := ExchangeRateOnModel{}
+	_, err = db.QueryOne(&x, `
+		SELECT
+			cerh.currency_code,
+			cerh.import_exchange_rate / cerh.ratio AS import_exchange_rate,
+			cerh.export_exchange_rate / cerh.ratio AS export_exchange_rate,
+			to_char(cerh.effective_from, 'YYYY-MM-DD') AS effective_from,
+			to_char(cerh.effective_to, 'YYYY-MM-DD') AS effective_to,
+			'history' AS source
+		FROM ship2cu.customs_exchange_rate_history cerh
+		WHERE cerh.currency_code = ?0
+		AND cerh.effective_from <= ?1
+		AND cerh.effective_to >= ?1
+		ORDER BY cerh.effective_from DESC, cerh.id DESC
+		LIMIT 1
+	`, currencyCode, on.Format("2006-01-02"))
+	if err != nil {
+		if err == pg.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &x, nil
+}
@@ -2,12 +2,18 @@ package common
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
 	"time"
 )
 
 type Service interface {
 	GetAllExchangeRates(ctx context.Context) ([]*GetExchangeRateModel, error)
 	GetAllConvertTemplates(ctx context.Context, category string) ([]*GetAllConvertTemplateModel, error)
+	ImportExchangeRates(ctx context.Context, data *ImportExchangeRateModel) (*ImportExchangeRateResultModel, error)
+	GetExchangeRateHistories(ctx context.Context, currencyCode string) ([]*ExchangeRateHistory, error)
+	RateOn(ctx context.Context, currencyCode string, on time.Time) (*ExchangeRateOnModel, error)
 }
 
 type service struct {
@@ -48,3 +54,75 @@ func (s *service) GetAllConvertTemplates(ctx context.Context, category string) (
 
 	return result, nil
 }
+
+// ImportExchangeRates loads a customs rate table for one week. EffectiveTo defaults to
+// six days after EffectiveFrom; importing the same week again replaces the earlier table.
+func (s *service) ImportExchangeRates(ctx context.Context, data *ImportExchangeRateModel) (*ImportExchangeRateResultModel, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	if data.EffectiveFrom.IsZero() {
+		return nil, errors.New("effectiveFrom is required")
+	}
+	effectiveTo := data.EffectiveTo
+	if effectiveTo.IsZero() {
+		effectiveTo = data.EffectiveFrom.AddDate(0, 0, 6)
+	}
+	if effectiveTo.Before(data.EffectiveFrom) {
+		return nil, errors.New("effectiveTo must not be before effectiveFrom")
+	}
+
+	rates, err := parseExchangeRateTable(data.FileName, data.FileBytes)
+	if err != nil {
+		return nil, err
+	}
+	for _, rate := range rates {
+		rate.SourceFileName = data.FileName
+	}
+
+	tx, txCtx, err := BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := s.selfRepo.ReplaceExchangeRateHistories(txCtx, data.EffectiveFrom, effectiveTo, rates); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &ImportExchangeRateResultModel{
+		EffectiveFrom: data.EffectiveFrom.Format("2006-01-02"),
+		EffectiveTo:   effectiveTo.Format("2006-01-02"),
+		Total:         len(rates),
+	}, nil
+}
+
+func (s *service) GetExchangeRateHistories(ctx context.Context, currencyCode string) ([]*ExchangeRateHistory, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	return s.selfRepo.GetExchangeRateHistories(ctx, strings.ToUpper(strings.TrimSpace(currencyCode)))
+}
+
+// RateOn returns the customs rate of currencyCode in force on the given date.
+// A date no imported rate table covers is an error; the rate of another week would misvalue the manifest.
+func (s *service) RateOn(ctx context.Context, currencyCode string, on time.Time) (*ExchangeRateOnModel, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	currencyCode = strings.ToUpper(strings.TrimSpace(currencyCode))
+
+	rate, err := s.selfRepo.GetExchangeRateOn(ctx, currencyCode, on)
+	if err != nil {
+		return nil, err
+	}
+	if rate == nil {
+		return nil, fmt.Errorf("no customs exchange rate of '%s' covers %s", currencyCode, on.Format("2006-01-02"))
+	}
+
+	return rate, nil
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
 
 	"github.com/joho/godotenv"
 )
@@ -18,6 +19,8 @@ type Config struct {
 	PostgreSQLSSLMode  string
 	GCSProjectID       string
 	GCSBucketName      string
+	HsMatchThreshold   float64
+	VolumetricDivisor  float64
 }
 
 func LoadConfig() *Config {
@@ -41,5 +44,11 @@ func LoadConfig() *Config {
 		GCSBucketName:      os.Getenv("GCS_BUCKET_NAME"),
 	}
 
+	// Minimum fuzzy match score for assigning an HS code to goods automatically
+	config.HsMatchThreshold, _ = strconv.ParseFloat(os.Getenv("HS_MATCH_THRESHOLD"), 64)
+
+	// Cubic centimetres per kilogram of volumetric weight on draft MAWB items
+	config.VolumetricDivisor, _ = strconv.ParseFloat(os.Getenv("VOLUMETRIC_DIVISOR"), 64)
+
 	return &config
 }
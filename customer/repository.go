@@ -6,12 +6,15 @@ import (
 
 	"github.com/go-pg/pg/v9"
 
+	"hpc-express-service/common"
 	"hpc-express-service/constant"
 )
 
 type Repository interface {
 	GetAll(ctx context.Context) ([]*GetAllModel, error)
 	GetAllDropdown(ctx context.Context) ([]*constant.DropdownModel, error)
+	GetByUUID(ctx context.Context, uuid string) (*GetAllModel, error)
+	GetAllByName(ctx context.Context, name string) ([]*GetAllModel, error)
 }
 
 type repository struct {
@@ -68,3 +71,46 @@ func (r repository) GetAllDropdown(ctx context.Context) ([]*constant.DropdownMod
 
 	return list, nil
 }
+
+// GetByUUID returns the customer, or nil when there is none.
+func (r repository) GetByUUID(ctx context.Context, uuid string) (*GetAllModel, error) {
+	db, err := common.GetQer(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &GetAllModel{}
+	_, err = db.QueryOne(result, `
+		SELECT "uuid", "name", to_char(created_at, 'DD-MM-YYYY') as created_date
+		FROM public.tbl_customers
+		WHERE "uuid" = ? AND deleted_at IS NULL
+	`, uuid)
+	if err == pg.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// GetAllByName returns the customers named name, ignoring case and surrounding spaces.
+func (r repository) GetAllByName(ctx context.Context, name string) ([]*GetAllModel, error) {
+	db, err := common.GetQer(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var list []*GetAllModel
+	_, err = db.Query(&list, `
+		SELECT "uuid", "name", to_char(created_at, 'DD-MM-YYYY') as created_date
+		FROM public.tbl_customers
+		WHERE lower(trim("name")) = lower(trim(?)) AND deleted_at IS NULL
+	`, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
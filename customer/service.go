@@ -2,14 +2,19 @@ package customer
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"time"
 
+	"github.com/google/uuid"
+
 	"hpc-express-service/constant"
 )
 
 type Service interface {
 	GetAll(ctx context.Context) ([]*GetAllModel, error)
 	GetAllDropdown(ctx context.Context) ([]*constant.DropdownModel, error)
+	ResolveCustomer(ctx context.Context, customerUUID, name string) (*GetAllModel, error)
 }
 
 type service struct {
@@ -50,3 +55,40 @@ func (s *service) GetAllDropdown(ctx context.Context) ([]*constant.DropdownModel
 
 	return result, nil
 }
+
+// ResolveCustomer returns the customer picked by UUID or, without one, the only customer with
+// the name. It returns nil when neither is given or no customer has the name, and fails when
+// the UUID is unknown or several customers share the name.
+func (s *service) ResolveCustomer(ctx context.Context, customerUUID, name string) (*GetAllModel, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	if customerUUID = strings.TrimSpace(customerUUID); customerUUID != "" {
+		if _, err := uuid.Parse(customerUUID); err != nil {
+			return nil, fmt.Errorf("customer '%s' not found", customerUUID)
+		}
+		customer, err := s.selfRepo.GetByUUID(ctx, customerUUID)
+		if err != nil {
+			return nil, err
+		}
+		if customer == nil {
+			return nil, fmt.Errorf("customer '%s' not found", customerUUID)
+		}
+		return customer, nil
+	}
+
+	if name = strings.TrimSpace(name); name == "" {
+		return nil, nil
+	}
+	customers, err := s.selfRepo.GetAllByName(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	switch len(customers) {
+	case 0:
+		return nil, nil
+	case 1:
+		return customers[0], nil
+	}
+	return nil, fmt.Errorf("%d customers are named '%s', pick one by customerUuid", len(customers), name)
+}
@@ -0,0 +1,91 @@
+package ecustoms
+
+import (
+	"embed"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+//go:embed schema/*.xsd
+var schemaFiles embed.FS
+
+// MessageVersion is the version attribute of every generated declaration.
+const MessageVersion = "1.0"
+
+// Marshaller renders declarations and checks each one against its schema, so a file that
+// would be rejected never leaves the service.
+type Marshaller struct {
+	importSchema *Schema
+	exportSchema *Schema
+}
+
+// NewMarshaller loads the import and export declaration schemas bundled in schema/.
+func NewMarshaller() (*Marshaller, error) {
+	importSchema, err := loadBundledSchema("import_declaration.xsd")
+	if err != nil {
+		return nil, err
+	}
+	exportSchema, err := loadBundledSchema("export_declaration.xsd")
+	if err != nil {
+		return nil, err
+	}
+
+	return &Marshaller{
+		importSchema: importSchema,
+		exportSchema: exportSchema,
+	}, nil
+}
+
+func loadBundledSchema(name string) (*Schema, error) {
+	data, err := schemaFiles.ReadFile("schema/" + name)
+	if err != nil {
+		return nil, err
+	}
+	s, err := LoadSchema(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	return s, nil
+}
+
+// marshal renders a declaration and checks it against schema.
+func marshal(v interface{}, schema *Schema) ([]byte, error) {
+	body, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	data := append([]byte(xml.Header), body...)
+
+	if err := schema.Validate(data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// ChargeModel is an amount with its currency, such as freight or insurance.
+type ChargeModel struct {
+	Amount       string `xml:"Amount"`
+	CurrencyCode string `xml:"CurrencyCode"`
+}
+
+// FormatAmount formats a money amount with 2 decimals.
+func FormatAmount(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
+
+// FormatWeight formats a weight or quantity with 3 decimals.
+func FormatWeight(v float64) string {
+	return strconv.FormatFloat(v, 'f', 3, 64)
+}
+
+// FormatRate formats an exchange rate with 6 decimals.
+func FormatRate(v float64) string {
+	return strconv.FormatFloat(v, 'f', 6, 64)
+}
+
+// FormatMessageDate formats the creation time of a message.
+func FormatMessageDate(t time.Time) string {
+	return t.Format("2006-01-02T15:04:05")
+}
@@ -0,0 +1,96 @@
+package ecustoms
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func sampleImportDeclaration() *ImportDeclaration {
+	return &ImportDeclaration{
+		DocumentControl: &ImportDocumentControl{
+			ReferenceNumber: "21712345675-H001",
+			DocumentType:    "EXPRESS_IMPORT",
+			MessageDate:     "2026-10-17T09:30:00",
+		},
+		Header: &ImportHeader{
+			MasterAirWaybill:       "217-12345675",
+			HouseAirWaybill:        "H001",
+			Category:               "2",
+			ArrivalDate:            "2026-10-16",
+			DischargePort:          "1190",
+			ConsignmentCountryCode: "CN",
+			TotalPackage:           1,
+			TotalGrossWeight:       "1.200",
+			GrossWeightUnitCode:    "KGM",
+		},
+		Consignee: &ImportConsignee{
+			Name:             "Somchai Jaidee",
+			StreetAndAddress: "1 Sukhumvit Road, Bangkok",
+			CountryCode:      "TH",
+		},
+		Shipper: &ImportShipper{
+			Name:        "Shenzhen Trading Co.",
+			CountryCode: "CN",
+		},
+		Items: []*ImportDeclarationItem{
+			{
+				ItemNumber:          1,
+				Tariff:              &TariffModel{TariffCode: "39269099"},
+				DescriptionEnglish:  "MOBILE PHONE CASE",
+				Quantity:            FormatWeight(2),
+				QuantityUnitCode:    "C62",
+				NetWeight:           FormatWeight(1),
+				NetWeightUnitCode:   "KGM",
+				GrossWeight:         FormatWeight(1.2),
+				GrossWeightUnitCode: "KGM",
+				Valuation: &ImportValuation{
+					CurrencyCode: "USD",
+					ExchangeRate: FormatRate(36.5),
+					FobForeign:   FormatAmount(20),
+					FobBaht:      FormatAmount(730),
+					CifBaht:      FormatAmount(880),
+				},
+			},
+		},
+	}
+}
+
+func newTestMarshaller(t *testing.T) *Marshaller {
+	t.Helper()
+	m, err := NewMarshaller()
+	if err != nil {
+		t.Fatalf("NewMarshaller: %v", err)
+	}
+	return m
+}
+
+func TestMarshalImportDeclaration(t *testing.T) {
+	data, err := newTestMarshaller(t).MarshalImportDeclaration(sampleImportDeclaration())
+	if err != nil {
+		t.Fatalf("MarshalImportDeclaration: %v", err)
+	}
+	for _, want := range []string{
+		`<ImportDeclaration xmlns="urn:hpc-express:declaration:import:1" version="1.0">`,
+		`<CifBaht>880.00</CifBaht>`,
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("declaration does not contain %s:\n%s", want, data)
+		}
+	}
+}
+
+func TestMarshalImportDeclarationRejectsInvalid(t *testing.T) {
+	d := sampleImportDeclaration()
+	d.Header.Category = "5"
+	d.Shipper.CountryCode = ""
+
+	_, err := newTestMarshaller(t).MarshalImportDeclaration(d)
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("MarshalImportDeclaration() = %v, want a *ValidationError", err)
+	}
+	if len(verr.Problems) != 2 {
+		t.Errorf("problems = %q, want the category and the shipper country", verr.Problems)
+	}
+}
@@ -0,0 +1,97 @@
+package ecustoms
+
+import "encoding/xml"
+
+// ExportDeclaration is the e-Export declaration message of one house air waybill.
+type ExportDeclaration struct {
+	XMLName         xml.Name                 `xml:"urn:hpc-express:declaration:export:1 ExportDeclaration"`
+	Version         string                   `xml:"version,attr"`
+	DocumentControl *ExportDocumentControl   `xml:"DocumentControl"`
+	Header          *ExportHeader            `xml:"Header"`
+	Exporter        *ExportExporter          `xml:"Exporter"`
+	Consignee       *ExportConsignee         `xml:"Consignee"`
+	Items           []*ExportDeclarationItem `xml:"Item"`
+}
+
+type ExportDocumentControl struct {
+	ReferenceNumber string `xml:"ReferenceNumber"`
+	DocumentType    string `xml:"DocumentType"`
+	MessageDate     string `xml:"MessageDate"`
+}
+
+type ExportHeader struct {
+	MasterAirWaybill       string `xml:"MasterAirWaybill,omitempty"`
+	HouseAirWaybill        string `xml:"HouseAirWaybill"`
+	Category               string `xml:"Category"`
+	VesselName             string `xml:"VesselName,omitempty"`
+	DepartureDate          string `xml:"DepartureDate"`
+	ReleasePort            string `xml:"ReleasePort"`
+	LoadingPort            string `xml:"LoadingPort"`
+	PurchaseCountryCode    string `xml:"PurchaseCountryCode"`
+	DestinationCountryCode string `xml:"DestinationCountryCode"`
+	TotalPackage           int64  `xml:"TotalPackage"`
+	PackageUnitCode        string `xml:"PackageUnitCode,omitempty"`
+	TotalNetWeight         string `xml:"TotalNetWeight"`
+	TotalGrossWeight       string `xml:"TotalGrossWeight"`
+	WeightUnitCode         string `xml:"WeightUnitCode"`
+}
+
+type ExportExporter struct {
+	TaxNumber        string `xml:"TaxNumber"`
+	Branch           string `xml:"Branch,omitempty"`
+	Name             string `xml:"Name"`
+	StreetAndAddress string `xml:"StreetAndAddress"`
+	District         string `xml:"District,omitempty"`
+	SubProvince      string `xml:"SubProvince,omitempty"`
+	Province         string `xml:"Province,omitempty"`
+	Postcode         string `xml:"Postcode,omitempty"`
+	Email            string `xml:"Email,omitempty"`
+}
+
+type ExportConsignee struct {
+	Name             string `xml:"Name"`
+	StreetAndAddress string `xml:"StreetAndAddress"`
+	District         string `xml:"District,omitempty"`
+	SubProvince      string `xml:"SubProvince,omitempty"`
+	Province         string `xml:"Province,omitempty"`
+	Postcode         string `xml:"Postcode,omitempty"`
+	CountryCode      string `xml:"CountryCode"`
+	Email            string `xml:"Email,omitempty"`
+}
+
+type ExportDeclarationItem struct {
+	ItemNumber          int64            `xml:"ItemNumber"`
+	Tariff              *TariffModel     `xml:"Tariff"`
+	DescriptionEnglish  string           `xml:"DescriptionEnglish"`
+	DescriptionThai     string           `xml:"DescriptionThai,omitempty"`
+	Quantity            string           `xml:"Quantity"`
+	QuantityUnitCode    string           `xml:"QuantityUnitCode"`
+	NetWeight           string           `xml:"NetWeight"`
+	NetWeightUnitCode   string           `xml:"NetWeightUnitCode"`
+	GrossWeight         string           `xml:"GrossWeight"`
+	GrossWeightUnitCode string           `xml:"GrossWeightUnitCode"`
+	PackageAmount       int64            `xml:"PackageAmount,omitempty"`
+	PackageUnitCode     string           `xml:"PackageUnitCode,omitempty"`
+	Remark              string           `xml:"Remark,omitempty"`
+	Valuation           *ExportValuation `xml:"Valuation"`
+}
+
+// ExportValuation is the FOB value of one item in THB, with the invoice currency figures when
+// the goods were sold in another currency.
+type ExportValuation struct {
+	FobBaht      string       `xml:"FobBaht"`
+	FobForeign   string       `xml:"FobForeign,omitempty"`
+	CurrencyCode string       `xml:"CurrencyCode,omitempty"`
+	ExchangeRate string       `xml:"ExchangeRate,omitempty"`
+	Freight      *ChargeModel `xml:"Freight,omitempty"`
+	Insurance    *ChargeModel `xml:"Insurance,omitempty"`
+}
+
+// MarshalExportDeclaration renders d as XML after checking it against the export schema.
+// A *ValidationError lists every field the schema rejects.
+func (m *Marshaller) MarshalExportDeclaration(d *ExportDeclaration) ([]byte, error) {
+	if d.Version == "" {
+		d.Version = MessageVersion
+	}
+	return marshal(d, m.exportSchema)
+}
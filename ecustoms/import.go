@@ -0,0 +1,107 @@
+package ecustoms
+
+import "encoding/xml"
+
+// ImportDeclaration is the e-Import declaration message of one house air waybill.
+type ImportDeclaration struct {
+	XMLName         xml.Name                 `xml:"urn:hpc-express:declaration:import:1 ImportDeclaration"`
+	Version         string                   `xml:"version,attr"`
+	DocumentControl *ImportDocumentControl   `xml:"DocumentControl"`
+	Header          *ImportHeader            `xml:"Header"`
+	Consignee       *ImportConsignee         `xml:"Consignee"`
+	Shipper         *ImportShipper           `xml:"Shipper"`
+	Invoice         *ImportInvoice           `xml:"Invoice,omitempty"`
+	Items           []*ImportDeclarationItem `xml:"Item"`
+}
+
+type ImportDocumentControl struct {
+	ReferenceNumber string `xml:"ReferenceNumber"`
+	DocumentType    string `xml:"DocumentType"`
+	MessageDate     string `xml:"MessageDate"`
+}
+
+type ImportHeader struct {
+	MasterAirWaybill       string `xml:"MasterAirWaybill"`
+	HouseAirWaybill        string `xml:"HouseAirWaybill"`
+	Category               string `xml:"Category"`
+	VesselName             string `xml:"VesselName,omitempty"`
+	ArrivalDate            string `xml:"ArrivalDate"`
+	DischargePort          string `xml:"DischargePort"`
+	ConsignmentCountryCode string `xml:"ConsignmentCountryCode"`
+	TotalPackage           int64  `xml:"TotalPackage"`
+	PackageUnitCode        string `xml:"PackageUnitCode,omitempty"`
+	TotalGrossWeight       string `xml:"TotalGrossWeight"`
+	GrossWeightUnitCode    string `xml:"GrossWeightUnitCode"`
+}
+
+type ImportConsignee struct {
+	TaxNumber        string `xml:"TaxNumber,omitempty"`
+	Branch           string `xml:"Branch,omitempty"`
+	Name             string `xml:"Name"`
+	StreetAndAddress string `xml:"StreetAndAddress"`
+	District         string `xml:"District,omitempty"`
+	SubProvince      string `xml:"SubProvince,omitempty"`
+	Province         string `xml:"Province,omitempty"`
+	Postcode         string `xml:"Postcode,omitempty"`
+	CountryCode      string `xml:"CountryCode"`
+	Email            string `xml:"Email,omitempty"`
+	PhoneNumber      string `xml:"PhoneNumber,omitempty"`
+}
+
+type ImportShipper struct {
+	Name             string `xml:"Name"`
+	StreetAndAddress string `xml:"StreetAndAddress,omitempty"`
+	District         string `xml:"District,omitempty"`
+	SubProvince      string `xml:"SubProvince,omitempty"`
+	Province         string `xml:"Province,omitempty"`
+	Postcode         string `xml:"Postcode,omitempty"`
+	CountryCode      string `xml:"CountryCode"`
+	Email            string `xml:"Email,omitempty"`
+	PhoneNumber      string `xml:"PhoneNumber,omitempty"`
+}
+
+type ImportInvoice struct {
+	Number string `xml:"Number"`
+	Date   string `xml:"Date,omitempty"`
+}
+
+type ImportDeclarationItem struct {
+	ItemNumber          int64            `xml:"ItemNumber"`
+	Tariff              *TariffModel     `xml:"Tariff"`
+	DescriptionEnglish  string           `xml:"DescriptionEnglish"`
+	DescriptionThai     string           `xml:"DescriptionThai,omitempty"`
+	Quantity            string           `xml:"Quantity"`
+	QuantityUnitCode    string           `xml:"QuantityUnitCode"`
+	NetWeight           string           `xml:"NetWeight"`
+	NetWeightUnitCode   string           `xml:"NetWeightUnitCode"`
+	GrossWeight         string           `xml:"GrossWeight"`
+	GrossWeightUnitCode string           `xml:"GrossWeightUnitCode"`
+	Valuation           *ImportValuation `xml:"Valuation"`
+}
+
+type TariffModel struct {
+	TariffCode      string `xml:"TariffCode"`
+	TariffSequence  string `xml:"TariffSequence,omitempty"`
+	StatisticalCode string `xml:"StatisticalCode,omitempty"`
+}
+
+// ImportValuation is the customs value of one item: FOB in the invoice currency and in THB,
+// the freight and insurance charged on it and the resulting CIF in THB.
+type ImportValuation struct {
+	CurrencyCode string       `xml:"CurrencyCode"`
+	ExchangeRate string       `xml:"ExchangeRate"`
+	FobForeign   string       `xml:"FobForeign"`
+	FobBaht      string       `xml:"FobBaht"`
+	Freight      *ChargeModel `xml:"Freight,omitempty"`
+	Insurance    *ChargeModel `xml:"Insurance,omitempty"`
+	CifBaht      string       `xml:"CifBaht"`
+}
+
+// MarshalImportDeclaration renders d as XML after checking it against the import schema.
+// A *ValidationError lists every field the schema rejects.
+func (m *Marshaller) MarshalImportDeclaration(d *ImportDeclaration) ([]byte, error) {
+	if d.Version == "" {
+		d.Version = MessageVersion
+	}
+	return marshal(d, m.importSchema)
+}
@@ -0,0 +1,521 @@
+package ecustoms
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schema is the subset of XML Schema used by the bundled e-Customs schemas: global and local
+// elements, named and anonymous complex types with a sequence of elements and attributes,
+// and simple types restricted by length, pattern, enumeration, digits and range facets.
+type Schema struct {
+	targetNamespace string
+	qualified       bool // elementFormDefault="qualified"
+	elements        map[string]*elementDecl
+	types           map[string]*typeDef
+}
+
+type elementDecl struct {
+	name      string
+	namespace string
+	typeName  string
+	typ       *typeDef
+	minOccurs int
+	maxOccurs int // -1 is unbounded
+}
+
+type attributeDecl struct {
+	name     string
+	typeName string
+	typ      *typeDef
+	required bool
+}
+
+type typeDef struct {
+	// complex types
+	complex    bool
+	sequence   []*elementDecl
+	attributes []*attributeDecl
+
+	// simple types
+	base           string
+	enumeration    []string
+	patterns       []*regexp.Regexp
+	length         *int
+	minLength      *int
+	maxLength      *int
+	totalDigits    *int
+	fractionDigits *int
+	minInclusive   *float64
+	maxInclusive   *float64
+}
+
+// ValidationError lists every place where a document breaks its schema.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	const max = 10
+	if len(e.Problems) > max {
+		return fmt.Sprintf("%s (and %d more)", strings.Join(e.Problems[:max], "; "), len(e.Problems)-max)
+	}
+	return strings.Join(e.Problems, "; ")
+}
+
+type node struct {
+	name     xml.Name
+	attrs    []xml.Attr
+	children []*node
+	text     string
+}
+
+func (n *node) attr(name string) string {
+	for _, a := range n.attrs {
+		if a.Name.Local == name && a.Name.Space == "" {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+func parseTree(data []byte) (*node, error) {
+	d := xml.NewDecoder(bytes.NewReader(data))
+	var root *node
+	stack := []*node{}
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			n := &node{name: t.Name, attrs: t.Attr}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, n)
+			} else if root == nil {
+				root = n
+			}
+			stack = append(stack, n)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text += string(t)
+			}
+		}
+	}
+
+	if root == nil {
+		return nil, fmt.Errorf("no root element")
+	}
+	return root, nil
+}
+
+// LoadSchema reads an XSD document.
+func LoadSchema(data []byte) (*Schema, error) {
+	root, err := parseTree(data)
+	if err != nil {
+		return nil, err
+	}
+	if root.name.Local != "schema" {
+		return nil, fmt.Errorf("root element '%s' is not a schema", root.name.Local)
+	}
+
+	s := &Schema{
+		targetNamespace: root.attr("targetNamespace"),
+		qualified:       root.attr("elementFormDefault") == "qualified",
+		elements:        map[string]*elementDecl{},
+		types:           map[string]*typeDef{},
+	}
+	for _, child := range root.children {
+		switch child.name.Local {
+		case "element":
+			e, err := s.loadElement(child, true)
+			if err != nil {
+				return nil, err
+			}
+			s.elements[e.name] = e
+		case "complexType", "simpleType":
+			name := child.attr("name")
+			if name == "" {
+				return nil, fmt.Errorf("global %s without a name", child.name.Local)
+			}
+			t, err := s.loadType(child)
+			if err != nil {
+				return nil, fmt.Errorf("type '%s': %v", name, err)
+			}
+			s.types[name] = t
+		}
+	}
+
+	return s, nil
+}
+
+// loadElement reads an element declaration. Global elements always belong to the target
+// namespace; local ones only when qualified by their form or the schema's elementFormDefault.
+func (s *Schema) loadElement(n *node, global bool) (*elementDecl, error) {
+	e := &elementDecl{
+		name:      n.attr("name"),
+		typeName:  localName(n.attr("type")),
+		minOccurs: 1,
+		maxOccurs: 1,
+	}
+	if e.name == "" {
+		return nil, fmt.Errorf("element without a name")
+	}
+	switch form := n.attr("form"); {
+	case global, form == "qualified", form == "" && s.qualified:
+		e.namespace = s.targetNamespace
+	}
+
+	if v := n.attr("minOccurs"); v != "" {
+		i, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("element '%s': invalid minOccurs '%s'", e.name, v)
+		}
+		e.minOccurs = i
+	}
+	if v := n.attr("maxOccurs"); v == "unbounded" {
+		e.maxOccurs = -1
+	} else if v != "" {
+		i, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("element '%s': invalid maxOccurs '%s'", e.name, v)
+		}
+		e.maxOccurs = i
+	}
+
+	for _, child := range n.children {
+		if child.name.Local == "complexType" || child.name.Local == "simpleType" {
+			t, err := s.loadType(child)
+			if err != nil {
+				return nil, fmt.Errorf("element '%s': %v", e.name, err)
+			}
+			e.typ = t
+		}
+	}
+	if e.typ == nil && e.typeName == "" {
+		e.typeName = "string"
+	}
+
+	return e, nil
+}
+
+func (s *Schema) loadType(n *node) (*typeDef, error) {
+	t := &typeDef{}
+	if n.name.Local == "complexType" {
+		t.complex = true
+		for _, child := range n.children {
+			switch child.name.Local {
+			case "sequence":
+				for _, item := range child.children {
+					if item.name.Local != "element" {
+						return nil, fmt.Errorf("unsupported sequence particle '%s'", item.name.Local)
+					}
+					e, err := s.loadElement(item, false)
+					if err != nil {
+						return nil, err
+					}
+					t.sequence = append(t.sequence, e)
+				}
+			case "attribute":
+				a := &attributeDecl{
+					name:     child.attr("name"),
+					typeName: localName(child.attr("type")),
+					required: child.attr("use") == "required",
+				}
+				for _, st := range child.children {
+					if st.name.Local == "simpleType" {
+						typ, err := s.loadType(st)
+						if err != nil {
+							return nil, fmt.Errorf("attribute '%s': %v", a.name, err)
+						}
+						a.typ = typ
+					}
+				}
+				if a.typ == nil && a.typeName == "" {
+					a.typeName = "string"
+				}
+				t.attributes = append(t.attributes, a)
+			default:
+				return nil, fmt.Errorf("unsupported complex type content '%s'", child.name.Local)
+			}
+		}
+		return t, nil
+	}
+
+	var restriction *node
+	for _, child := range n.children {
+		if child.name.Local == "restriction" {
+			restriction = child
+		}
+	}
+	if restriction == nil {
+		return nil, fmt.Errorf("simple type without a restriction")
+	}
+
+	t.base = localName(restriction.attr("base"))
+	for _, facet := range restriction.children {
+		value := facet.attr("value")
+		switch facet.name.Local {
+		case "enumeration":
+			t.enumeration = append(t.enumeration, value)
+		case "pattern":
+			re, err := regexp.Compile("^(?:" + value + ")$")
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern '%s': %v", value, err)
+			}
+			t.patterns = append(t.patterns, re)
+		case "length", "minLength", "maxLength", "totalDigits", "fractionDigits":
+			i, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s '%s'", facet.name.Local, value)
+			}
+			switch facet.name.Local {
+			case "length":
+				t.length = &i
+			case "minLength":
+				t.minLength = &i
+			case "maxLength":
+				t.maxLength = &i
+			case "totalDigits":
+				t.totalDigits = &i
+			case "fractionDigits":
+				t.fractionDigits = &i
+			}
+		case "minInclusive", "maxInclusive":
+			f, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s '%s'", facet.name.Local, value)
+			}
+			if facet.name.Local == "minInclusive" {
+				t.minInclusive = &f
+			} else {
+				t.maxInclusive = &f
+			}
+		default:
+			return nil, fmt.Errorf("unsupported facet '%s'", facet.name.Local)
+		}
+	}
+
+	return t, nil
+}
+
+// Validate checks an XML document against the schema and returns a *ValidationError listing every problem found.
+func (s *Schema) Validate(doc []byte) error {
+	root, err := parseTree(doc)
+	if err != nil {
+		return err
+	}
+
+	v := &validator{schema: s}
+	decl, ok := s.elements[root.name.Local]
+	switch {
+	case !ok:
+		v.fail("/"+root.name.Local, "element is not declared by the schema")
+	case root.name.Space != decl.namespace:
+		v.fail("/"+root.name.Local, fmt.Sprintf("namespace '%s' should be '%s'", root.name.Space, decl.namespace))
+	default:
+		v.element("/"+root.name.Local, root, decl)
+	}
+
+	if len(v.problems) > 0 {
+		return &ValidationError{Problems: v.problems}
+	}
+	return nil
+}
+
+type validator struct {
+	schema   *Schema
+	problems []string
+}
+
+func (v *validator) fail(path, message string) {
+	v.problems = append(v.problems, path+": "+message)
+}
+
+func (v *validator) resolve(t *typeDef, typeName string) (*typeDef, string) {
+	if t != nil {
+		return t, ""
+	}
+	if named, ok := v.schema.types[typeName]; ok {
+		return named, ""
+	}
+	return nil, typeName
+}
+
+func (v *validator) element(path string, n *node, decl *elementDecl) {
+	t, builtin := v.resolve(decl.typ, decl.typeName)
+	if t == nil || !t.complex {
+		if len(n.children) > 0 {
+			v.fail(path, "simple content must not have child elements")
+			return
+		}
+		v.value(path, strings.TrimSpace(n.text), t, builtin)
+		return
+	}
+
+	for _, a := range t.attributes {
+		value := n.attr(a.name)
+		if value == "" {
+			if a.required {
+				v.fail(path+"/@"+a.name, "attribute is required")
+			}
+			continue
+		}
+		at, atBuiltin := v.resolve(a.typ, a.typeName)
+		v.value(path+"/@"+a.name, value, at, atBuiltin)
+	}
+
+	i := 0
+	for _, child := range t.sequence {
+		count := 0
+		for i < len(n.children) && n.children[i].name.Local == child.name {
+			count++
+			childPath := path + "/" + child.name
+			if child.maxOccurs != 1 {
+				childPath = fmt.Sprintf("%s[%d]", childPath, count)
+			}
+			switch space := n.children[i].name.Space; {
+			case space != child.namespace:
+				v.fail(childPath, fmt.Sprintf("namespace '%s' should be '%s'", space, child.namespace))
+			case child.maxOccurs != -1 && count > child.maxOccurs:
+				v.fail(childPath, fmt.Sprintf("element occurs more than %d times", child.maxOccurs))
+			default:
+				v.element(childPath, n.children[i], child)
+			}
+			i++
+		}
+		if count < child.minOccurs {
+			v.fail(path+"/"+child.name, "element is required")
+		}
+	}
+	for ; i < len(n.children); i++ {
+		v.fail(path+"/"+n.children[i].name.Local, "element is not expected here")
+	}
+}
+
+var (
+	decimalPattern = regexp.MustCompile(`^[+-]?(\d+(\.\d*)?|\.\d+)$`)
+	integerPattern = regexp.MustCompile(`^[+-]?\d+$`)
+)
+
+func (v *validator) value(path, value string, t *typeDef, builtin string) {
+	if t == nil {
+		if err := checkBuiltin(value, builtin); err != nil {
+			v.fail(path, err.Error())
+		}
+		return
+	}
+
+	base, baseBuiltin := v.resolve(nil, t.base)
+	v.value(path, value, base, baseBuiltin)
+
+	length := len([]rune(value))
+	if t.length != nil && length != *t.length {
+		v.fail(path, fmt.Sprintf("'%s' must be %d characters long", value, *t.length))
+	}
+	if t.minLength != nil && length < *t.minLength {
+		v.fail(path, fmt.Sprintf("'%s' is shorter than %d characters", value, *t.minLength))
+	}
+	if t.maxLength != nil && length > *t.maxLength {
+		v.fail(path, fmt.Sprintf("'%s' is longer than %d characters", value, *t.maxLength))
+	}
+	if len(t.enumeration) > 0 {
+		found := false
+		for _, e := range t.enumeration {
+			if value == e {
+				found = true
+				break
+			}
+		}
+		if !found {
+			v.fail(path, fmt.Sprintf("'%s' is not one of %s", value, strings.Join(t.enumeration, ", ")))
+		}
+	}
+	for _, re := range t.patterns {
+		if !re.MatchString(value) {
+			v.fail(path, fmt.Sprintf("'%s' does not match pattern '%s'", value, strings.TrimSuffix(strings.TrimPrefix(re.String(), "^(?:"), ")$")))
+		}
+	}
+
+	if t.totalDigits != nil || t.fractionDigits != nil {
+		digits := strings.TrimLeft(strings.TrimLeft(value, "+-"), "0")
+		fraction := ""
+		if i := strings.Index(digits, "."); i >= 0 {
+			fraction = strings.TrimRight(digits[i+1:], "0")
+			digits = digits[:i] + fraction
+		}
+		if t.totalDigits != nil && len(digits) > *t.totalDigits {
+			v.fail(path, fmt.Sprintf("'%s' has more than %d digits", value, *t.totalDigits))
+		}
+		if t.fractionDigits != nil && len(fraction) > *t.fractionDigits {
+			v.fail(path, fmt.Sprintf("'%s' has more than %d decimals", value, *t.fractionDigits))
+		}
+	}
+	if t.minInclusive != nil || t.maxInclusive != nil {
+		f, err := strconv.ParseFloat(value, 64)
+		if err == nil && t.minInclusive != nil && f < *t.minInclusive {
+			v.fail(path, fmt.Sprintf("'%s' is below %v", value, *t.minInclusive))
+		}
+		if err == nil && t.maxInclusive != nil && f > *t.maxInclusive {
+			v.fail(path, fmt.Sprintf("'%s' is above %v", value, *t.maxInclusive))
+		}
+	}
+}
+
+func checkBuiltin(value, typeName string) error {
+	switch typeName {
+	case "string", "normalizedString", "token", "anySimpleType":
+		return nil
+	case "decimal":
+		if !decimalPattern.MatchString(value) {
+			return fmt.Errorf("'%s' is not a decimal", value)
+		}
+	case "integer", "int", "long", "nonNegativeInteger", "positiveInteger":
+		if !integerPattern.MatchString(value) {
+			return fmt.Errorf("'%s' is not an integer", value)
+		}
+		i, _ := strconv.ParseInt(value, 10, 64)
+		if (typeName == "nonNegativeInteger" && i < 0) || (typeName == "positiveInteger" && i < 1) {
+			return fmt.Errorf("'%s' is not a %s", value, typeName)
+		}
+	case "date":
+		if _, err := time.Parse("2006-01-02", value); err != nil {
+			return fmt.Errorf("'%s' is not a date", value)
+		}
+	case "dateTime":
+		if _, err := time.Parse("2006-01-02T15:04:05", strings.TrimSuffix(value, "Z")); err != nil {
+			if _, err := time.Parse(time.RFC3339, value); err != nil {
+				return fmt.Errorf("'%s' is not a date time", value)
+			}
+		}
+	case "boolean":
+		if value != "true" && value != "false" && value != "1" && value != "0" {
+			return fmt.Errorf("'%s' is not a boolean", value)
+		}
+	default:
+		return fmt.Errorf("type '%s' is not declared", typeName)
+	}
+	return nil
+}
+
+// localName drops the namespace prefix of a QName such as "xs:string".
+func localName(qname string) string {
+	if i := strings.Index(qname, ":"); i >= 0 {
+		return qname[i+1:]
+	}
+	return qname
+}
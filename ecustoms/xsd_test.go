@@ -0,0 +1,144 @@
+package ecustoms
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const testSchema = `<?xml version="1.0" encoding="UTF-8"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema"
+           xmlns="urn:test:order"
+           targetNamespace="urn:test:order"
+           elementFormDefault="qualified">
+
+  <xs:simpleType name="Code2">
+    <xs:restriction base="xs:string">
+      <xs:pattern value="[A-Z]{2}"/>
+    </xs:restriction>
+  </xs:simpleType>
+
+  <xs:simpleType name="Amount">
+    <xs:restriction base="xs:decimal">
+      <xs:totalDigits value="6"/>
+      <xs:fractionDigits value="2"/>
+      <xs:minInclusive value="0"/>
+    </xs:restriction>
+  </xs:simpleType>
+
+  <xs:complexType name="LineType">
+    <xs:sequence>
+      <xs:element name="Sku">
+        <xs:simpleType>
+          <xs:restriction base="xs:string">
+            <xs:length value="4"/>
+          </xs:restriction>
+        </xs:simpleType>
+      </xs:element>
+      <xs:element name="Price" type="Amount"/>
+    </xs:sequence>
+  </xs:complexType>
+
+  <xs:element name="Order">
+    <xs:complexType>
+      <xs:sequence>
+        <xs:element name="Status">
+          <xs:simpleType>
+            <xs:restriction base="xs:string">
+              <xs:enumeration value="OPEN"/>
+              <xs:enumeration value="CLOSED"/>
+            </xs:restriction>
+          </xs:simpleType>
+        </xs:element>
+        <xs:element name="OrderDate" type="xs:date"/>
+        <xs:element name="CountryCode" type="Code2"/>
+        <xs:element name="Note" minOccurs="0" form="unqualified"/>
+        <xs:element name="Line" type="LineType" maxOccurs="2"/>
+      </xs:sequence>
+      <xs:attribute name="version" type="xs:string" use="required"/>
+    </xs:complexType>
+  </xs:element>
+</xs:schema>`
+
+const validOrder = `<Order xmlns="urn:test:order" version="1">
+  <Status>OPEN</Status>
+  <OrderDate>2026-10-17</OrderDate>
+  <CountryCode>TH</CountryCode>
+  <Note xmlns="">leave at gate</Note>
+  <Line><Sku>AB12</Sku><Price>10.50</Price></Line>
+  <Line><Sku>CD34</Sku><Price>1000</Price></Line>
+</Order>`
+
+func loadTestSchema(t *testing.T) *Schema {
+	t.Helper()
+	s, err := LoadSchema([]byte(testSchema))
+	if err != nil {
+		t.Fatalf("LoadSchema: %v", err)
+	}
+	return s
+}
+
+func TestValidateAcceptsValidDocument(t *testing.T) {
+	if err := loadTestSchema(t).Validate([]byte(validOrder)); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+}
+
+func TestValidateReportsProblems(t *testing.T) {
+	tests := []struct {
+		name    string
+		old     string
+		new     string
+		problem string
+	}{
+		{"root namespace", `<Order xmlns="urn:test:order"`, `<Order xmlns="urn:test:other"`, "/Order: namespace 'urn:test:other' should be 'urn:test:order'"},
+		{"child namespace", `<CountryCode>TH</CountryCode>`, `<CountryCode xmlns="urn:test:other">TH</CountryCode>`, "/Order/CountryCode: namespace 'urn:test:other' should be 'urn:test:order'"},
+		{"unqualified child", `<Note xmlns="">`, `<Note>`, "/Order/Note: namespace 'urn:test:order' should be ''"},
+		{"missing attribute", ` version="1"`, ``, "/Order/@version: attribute is required"},
+		{"missing element", `<OrderDate>2026-10-17</OrderDate>`, ``, "/Order/OrderDate: element is required"},
+		{"enumeration", `<Status>OPEN</Status>`, `<Status>PENDING</Status>`, "/Order/Status: 'PENDING' is not one of OPEN, CLOSED"},
+		{"date", `2026-10-17`, `17/10/2026`, "/Order/OrderDate: '17/10/2026' is not a date"},
+		{"pattern", `<CountryCode>TH</CountryCode>`, `<CountryCode>th</CountryCode>`, "/Order/CountryCode: 'th' does not match pattern '[A-Z]{2}'"},
+		{"length", `<Sku>AB12</Sku>`, `<Sku>AB1</Sku>`, "/Order/Line[1]/Sku: 'AB1' must be 4 characters long"},
+		{"fraction digits", `<Price>10.50</Price>`, `<Price>10.505</Price>`, "/Order/Line[1]/Price: '10.505' has more than 2 decimals"},
+		{"total digits", `<Price>1000</Price>`, `<Price>1000000</Price>`, "/Order/Line[2]/Price: '1000000' has more than 6 digits"},
+		{"min inclusive", `<Price>10.50</Price>`, `<Price>-1</Price>`, "/Order/Line[1]/Price: '-1' is below 0"},
+		{"decimal", `<Price>10.50</Price>`, `<Price>ten</Price>`, "/Order/Line[1]/Price: 'ten' is not a decimal"},
+		{"max occurs", `</Order>`, `<Line><Sku>EF56</Sku><Price>1</Price></Line></Order>`, "/Order/Line[3]: element occurs more than 2 times"},
+		{"unexpected element", `<Note xmlns="">leave at gate</Note>`, `<Remark>leave at gate</Remark>`, "/Order/Remark: element is not expected here"},
+	}
+
+	s := loadTestSchema(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := strings.Replace(validOrder, tt.old, tt.new, 1)
+			err := s.Validate([]byte(doc))
+
+			var verr *ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("Validate() = %v, want a *ValidationError", err)
+			}
+			for _, p := range verr.Problems {
+				if p == tt.problem {
+					return
+				}
+			}
+			t.Errorf("problems = %q, want %q", verr.Problems, tt.problem)
+		})
+	}
+}
+
+func TestLoadSchemaRejectsUnsupportedContent(t *testing.T) {
+	tests := map[string]string{
+		"not a schema":        `<root/>`,
+		"choice particle":     `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema"><xs:complexType name="T"><xs:sequence><xs:choice/></xs:sequence></xs:complexType></xs:schema>`,
+		"unknown facet":       `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema"><xs:simpleType name="T"><xs:restriction base="xs:string"><xs:whiteSpace value="collapse"/></xs:restriction></xs:simpleType></xs:schema>`,
+		"invalid pattern":     `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema"><xs:simpleType name="T"><xs:restriction base="xs:string"><xs:pattern value="[A-Z"/></xs:restriction></xs:simpleType></xs:schema>`,
+		"unnamed global type": `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema"><xs:simpleType><xs:restriction base="xs:string"/></xs:simpleType></xs:schema>`,
+	}
+	for name, doc := range tests {
+		if _, err := LoadSchema([]byte(doc)); err == nil {
+			t.Errorf("%s: LoadSchema() succeeded, want an error", name)
+		}
+	}
+}
@@ -16,7 +16,6 @@ import (
 	"hpc-express-service/outbound/mawbinfo"
 	"hpc-express-service/setting"
 	"hpc-express-service/ship2cu"
-	"hpc-express-service/shopee"
 	"hpc-express-service/tools/compare"
 	"hpc-express-service/uploadlog"
 	"hpc-express-service/user"
@@ -34,7 +33,6 @@ type RepositoryFactory struct {
 	UploadlogRepo                 uploadlog.Repository
 	OutboundExpressRepositoryRepo outboundExpress.OutboundExpressRepository
 	OutboundMawbRepositoryRepo    outboundMawb.OutboundMawbRepository
-	ShopeeRepo                    shopee.Repository
 	MawbRepo                      mawb.Repository
 	MawbInfoRepo                  mawbinfo.Repository
 	CustomerRepo                  customer.Repository
@@ -44,6 +42,14 @@ type RepositoryFactory struct {
 	CargoManifestRepo             cargoManifest.CargoManifestRepository
 	DraftMAWBRepo                 draftMawb.DraftMAWBRepository
 	MasterStatusRepo              setting.MasterStatusRepository
+	ConvertTemplateRepo           setting.ConvertTemplateRepository
+	FeeScheduleRepo               setting.FeeScheduleRepository
+	HsCodeAliasRepo               setting.HsCodeAliasRepository
+	ExporterProfileRepo           setting.ExporterProfileRepository
+	AirlineRepo                   setting.AirlineRepository
+	AwbStockRepo                  setting.AwbStockRepository
+	RateCardRepo                  setting.RateCardRepository
+	PartyRepo                     setting.PartyRepository
 }
 
 func NewRepositoryFactory() *RepositoryFactory {
@@ -59,7 +65,6 @@ func NewRepositoryFactory() *RepositoryFactory {
 		UploadlogRepo:                 uploadlog.NewRepository(timeoutContext),
 		OutboundExpressRepositoryRepo: outboundExpress.NewOutboundExpressRepository(timeoutContext),
 		OutboundMawbRepositoryRepo:    outboundMawb.NewOutboundMawbRepository(timeoutContext),
-		ShopeeRepo:                    shopee.NewRepository(timeoutContext),
 		MawbRepo:                      mawb.NewRepository(timeoutContext),
 		MawbInfoRepo:                  mawbinfo.NewRepository(timeoutContext),
 		CustomerRepo:                  customer.NewRepository(timeoutContext),
@@ -70,5 +75,13 @@ func NewRepositoryFactory() *RepositoryFactory {
 		CargoManifestRepo:             cargoManifest.NewCargoManifestRepository(),
 		DraftMAWBRepo:                 draftMawb.NewDraftMAWBRepository(),
 		MasterStatusRepo:              setting.NewMasterStatusRepository(),
+		ConvertTemplateRepo:           setting.NewConvertTemplateRepository(),
+		FeeScheduleRepo:               setting.NewFeeScheduleRepository(),
+		HsCodeAliasRepo:               setting.NewHsCodeAliasRepository(),
+		ExporterProfileRepo:           setting.NewExporterProfileRepository(),
+		AirlineRepo:                   setting.NewAirlineRepository(),
+		AwbStockRepo:                  setting.NewAwbStockRepository(),
+		RateCardRepo:                  setting.NewRateCardRepository(),
+		PartyRepo:                     setting.NewPartyRepository(),
 	}
 }
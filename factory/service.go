@@ -9,9 +9,11 @@ import (
 	"hpc-express-service/customer"
 	"hpc-express-service/dashboard"
 	"hpc-express-service/dropdown"
+	"hpc-express-service/ecustoms"
 	"hpc-express-service/gcs"
 	inbound "hpc-express-service/inbound/express"
 	seaWaybill "hpc-express-service/inbound/seawaybill"
+	"hpc-express-service/lazada"
 	"hpc-express-service/mawb"
 	cargoManifest "hpc-express-service/outbound/cargoManifest"
 	draftMawb "hpc-express-service/outbound/draftMawb"
@@ -21,6 +23,7 @@ import (
 	"hpc-express-service/setting"
 	"hpc-express-service/ship2cu"
 	"hpc-express-service/shopee"
+	"hpc-express-service/tiktok"
 	"hpc-express-service/tools/compare"
 	"hpc-express-service/uploadlog"
 	"hpc-express-service/user"
@@ -38,6 +41,8 @@ type ServiceFactory struct {
 	OutboundExpressServiceSvc outboundExpress.OutboundExpressService
 	OutboundMawbServiceSvc    outboundMawb.OutboundMawbService
 	ShopeeSvc                 shopee.Service
+	LazadaSvc                 lazada.Service
+	TiktokSvc                 tiktok.Service
 	MawbSvc                   mawb.Service
 	MawbInfoSvc               mawbinfo.Service
 	CustomerSvc               customer.Service
@@ -47,9 +52,17 @@ type ServiceFactory struct {
 	CargoManifestSvc          cargoManifest.CargoManifestService
 	DraftMAWBSvc              draftMawb.DraftMAWBService
 	MasterStatusSvc           setting.MasterStatusService
+	ConvertTemplateSvc        setting.ConvertTemplateService
+	FeeScheduleSvc            setting.FeeScheduleService
+	HsCodeAliasSvc            setting.HsCodeAliasService
+	ExporterProfileSvc        setting.ExporterProfileService
+	AirlineSvc                setting.AirlineService
+	AwbStockSvc               setting.AwbStockService
+	RateCardSvc               setting.RateCardService
+	PartySvc                  setting.PartyService
 }
 
-func NewServiceFactory(repo *RepositoryFactory, gcsClient *gcs.Client, conf *config.Config) *ServiceFactory {
+func NewServiceFactory(repo *RepositoryFactory, gcsClient *gcs.Client, conf *config.Config, declarations *ecustoms.Marshaller) *ServiceFactory {
 	timeoutContext := time.Duration(60) * time.Second
 
 	/*
@@ -68,16 +81,86 @@ func NewServiceFactory(repo *RepositoryFactory, gcsClient *gcs.Client, conf *con
 		timeoutContext,
 	)
 
+	// ConvertTemplate
+	convertTemplateSvc := setting.NewConvertTemplateService(
+		repo.ConvertTemplateRepo,
+		timeoutContext,
+	)
+
+	// FeeSchedule
+	feeScheduleSvc := setting.NewFeeScheduleService(
+		repo.FeeScheduleRepo,
+		timeoutContext,
+	)
+
+	// HsCodeAlias
+	hsCodeAliasSvc := setting.NewHsCodeAliasService(
+		repo.HsCodeAliasRepo,
+		timeoutContext,
+	)
+
+	// ExporterProfile
+	exporterProfileSvc := setting.NewExporterProfileService(
+		repo.ExporterProfileRepo,
+		timeoutContext,
+	)
+
+	// Airline
+	airlineSvc := setting.NewAirlineService(
+		repo.AirlineRepo,
+		timeoutContext,
+	)
+
+	// AWB Stock
+	awbStockSvc := setting.NewAwbStockService(
+		repo.AwbStockRepo,
+		repo.AirlineRepo,
+		timeoutContext,
+	)
+
+	// Rate Card
+	rateCardSvc := setting.NewRateCardService(
+		repo.RateCardRepo,
+		timeoutContext,
+	)
+
+	// Party
+	partySvc := setting.NewPartyService(
+		repo.PartyRepo,
+		timeoutContext,
+	)
+
+	// Common
+	commonSvc := common.NewService(
+		repo.CommonRepo,
+		timeoutContext,
+	)
+
 	// Ship2cu
 	ship2cuSvc := ship2cu.NewService(
 		repo.Ship2cuRepo,
 		timeoutContext,
+		commonSvc,
+		hsCodeAliasSvc,
+		conf.HsMatchThreshold,
 	)
 
 	// Shopee
 	shopeeSvc := shopee.NewService(
-		repo.ShopeeRepo,
 		timeoutContext,
+		exporterProfileSvc,
+	)
+
+	// Lazada
+	lazadaSvc := lazada.NewService(
+		timeoutContext,
+		exporterProfileSvc,
+	)
+
+	// TikTok Shop
+	tiktokSvc := tiktok.NewService(
+		timeoutContext,
+		exporterProfileSvc,
 	)
 
 	// Upload Logging
@@ -111,12 +194,14 @@ func NewServiceFactory(repo *RepositoryFactory, gcsClient *gcs.Client, conf *con
 		timeoutContext,
 		gcsClient,
 		conf,
+		airlineSvc,
+		awbStockSvc,
 	)
 	/*
 	* Sharing Services
 	 */
 	// Compare Service
-	compareSvc := compare.NewExcelService(repo.CompareRepo)
+	compareSvc := compare.NewExcelService(repo.CompareRepo, conf.HsMatchThreshold)
 	// Auth
 	authSvc := auth.NewService(
 		repo.AuthRepo,
@@ -129,12 +214,6 @@ func NewServiceFactory(repo *RepositoryFactory, gcsClient *gcs.Client, conf *con
 		timeoutContext,
 	)
 
-	// Common
-	commonSvc := common.NewService(
-		repo.CommonRepo,
-		timeoutContext,
-	)
-
 	// Dropdown
 	dropdownSvc := dropdown.NewService(
 		repo.DropdownRepo,
@@ -145,9 +224,19 @@ func NewServiceFactory(repo *RepositoryFactory, gcsClient *gcs.Client, conf *con
 	inboundExpressServiceSvc := inbound.NewInboundExpressService(
 		repo.InboundExpressRepositoryRepo,
 		timeoutContext,
-		ship2cuSvc,
+		inbound.NewConverterRegistry(
+			ship2cuSvc,
+			ship2cuSvc,
+		),
+		convertTemplateSvc,
+		feeScheduleSvc,
 		uploadlogSvc,
-		repo.Ship2cuRepo,
+		commonSvc,
+		customerSvc,
+		hsCodeAliasSvc,
+		airlineSvc,
+		partySvc,
+		declarations,
 	)
 
 	seaWaybillDetailSvc := seaWaybill.NewService(
@@ -161,8 +250,14 @@ func NewServiceFactory(repo *RepositoryFactory, gcsClient *gcs.Client, conf *con
 	outboundExpressServiceSvc := outboundExpress.NewOutboundExpressService(
 		repo.OutboundExpressRepositoryRepo,
 		timeoutContext,
-		shopeeSvc,
+		outboundExpress.NewConverterRegistry(
+			shopeeSvc,
+			lazadaSvc,
+			tiktokSvc,
+		),
+		convertTemplateSvc,
 		uploadlogSvc,
+		declarations,
 	)
 
 	// Outbound Mawb
@@ -174,10 +269,10 @@ func NewServiceFactory(repo *RepositoryFactory, gcsClient *gcs.Client, conf *con
 	)
 
 	// Cargo Manifest
-	cargoManifestSvc := cargoManifest.NewCargoManifestService(repo.CargoManifestRepo, masterStatusSvc)
+	cargoManifestSvc := cargoManifest.NewCargoManifestService(repo.CargoManifestRepo, masterStatusSvc, airlineSvc, partySvc)
 
 	// Draft MAWB
-	draftMAWBSvc := draftMawb.NewDraftMAWBService(repo.DraftMAWBRepo, masterStatusSvc)
+	draftMAWBSvc := draftMawb.NewDraftMAWBService(repo.DraftMAWBRepo, masterStatusSvc, airlineSvc, rateCardSvc, partySvc, conf.VolumetricDivisor)
 
 	return &ServiceFactory{
 		AuthSvc:                   authSvc,
@@ -190,6 +285,8 @@ func NewServiceFactory(repo *RepositoryFactory, gcsClient *gcs.Client, conf *con
 		OutboundExpressServiceSvc: outboundExpressServiceSvc,
 		OutboundMawbServiceSvc:    outboundMawbServiceSvc,
 		ShopeeSvc:                 shopeeSvc,
+		LazadaSvc:                 lazadaSvc,
+		TiktokSvc:                 tiktokSvc,
 		MawbSvc:                   mawbSvc,
 		MawbInfoSvc:               mawbInfoSvc,
 		CustomerSvc:               customerSvc,
@@ -200,5 +297,13 @@ func NewServiceFactory(repo *RepositoryFactory, gcsClient *gcs.Client, conf *con
 		CargoManifestSvc:          cargoManifestSvc,
 		DraftMAWBSvc:              draftMAWBSvc,
 		MasterStatusSvc:           masterStatusSvc,
+		ConvertTemplateSvc:        convertTemplateSvc,
+		FeeScheduleSvc:            feeScheduleSvc,
+		HsCodeAliasSvc:            hsCodeAliasSvc,
+		ExporterProfileSvc:        exporterProfileSvc,
+		AirlineSvc:                airlineSvc,
+		AwbStockSvc:               awbStockSvc,
+		RateCardSvc:               rateCardSvc,
+		PartySvc:                  partySvc,
 	}
 }
@@ -0,0 +1,284 @@
+package hsmatch
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const (
+	DefaultThreshold = 0.85
+	DefaultTopN      = 5
+)
+
+// tokenMatchFloor is the similarity above which two tokens count as the same word (typos, plurals).
+const tokenMatchFloor = 0.75
+
+// Candidate is one master entry ranked against a goods description. Score is between 0 and 1.
+type Candidate[T any] struct {
+	Item  T       `json:"item"`
+	Text  string  `json:"text"`
+	Score float64 `json:"score"`
+}
+
+type entry[T any] struct {
+	item       T
+	text       string
+	normalized string
+	tokens     []string
+}
+
+// Matcher ranks master entries by how closely their description matches a goods description.
+// Entries are normalised once; results per description are cached, so a Matcher is meant
+// to live for one conversion or comparison and is not safe for concurrent use.
+type Matcher[T any] struct {
+	Threshold float64
+	TopN      int
+	entries   []*entry[T]
+	exact     map[string]*entry[T]
+	cache     map[string][]*Candidate[T]
+}
+
+// NewMatcher indexes items by the description text returns for each of them.
+func NewMatcher[T any](items []T, text func(T) string) *Matcher[T] {
+	m := &Matcher[T]{
+		Threshold: DefaultThreshold,
+		TopN:      DefaultTopN,
+		entries:   make([]*entry[T], 0, len(items)),
+		exact:     map[string]*entry[T]{},
+		cache:     map[string][]*Candidate[T]{},
+	}
+
+	for _, item := range items {
+		e := &entry[T]{item: item, text: text(item)}
+		e.normalized = Normalize(e.text)
+		if e.normalized == "" {
+			continue
+		}
+		e.tokens = Tokens(e.normalized)
+		m.entries = append(m.entries, e)
+		if _, exists := m.exact[e.normalized]; !exists {
+			m.exact[e.normalized] = e
+		}
+	}
+
+	return m
+}
+
+// Match returns up to TopN candidates for description, best first.
+func (m *Matcher[T]) Match(description string) []*Candidate[T] {
+	normalized := Normalize(description)
+	if normalized == "" {
+		return []*Candidate[T]{}
+	}
+	if cached, ok := m.cache[normalized]; ok {
+		return cached
+	}
+
+	result := []*Candidate[T]{}
+	if e, ok := m.exact[normalized]; ok {
+		result = append(result, &Candidate[T]{Item: e.item, Text: e.text, Score: 1})
+	}
+
+	tokens := Tokens(normalized)
+	for _, e := range m.entries {
+		if e.normalized == normalized {
+			continue
+		}
+		score := similarity(normalized, tokens, e)
+		if score <= 0 {
+			continue
+		}
+		result = append(result, &Candidate[T]{Item: e.item, Text: e.text, Score: score})
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Score > result[j].Score
+	})
+	topN := m.TopN
+	if topN <= 0 {
+		topN = DefaultTopN
+	}
+	if len(result) > topN {
+		result = result[:topN]
+	}
+
+	m.cache[normalized] = result
+	return result
+}
+
+// Best returns the top candidate when its score reaches Threshold, which makes it safe to assign automatically.
+func (m *Matcher[T]) Best(description string) (*Candidate[T], bool) {
+	candidates := m.Match(description)
+	if len(candidates) == 0 || candidates[0].Score < m.Threshold {
+		return nil, false
+	}
+	return candidates[0], true
+}
+
+// similarity blends token overlap, which tolerates word order and extra words, with
+// edit distance over the whole text, which tolerates typos inside words. The text is
+// compared with its tokens sorted, so word order costs nothing on either side.
+func similarity[T any](normalized string, tokens []string, e *entry[T]) float64 {
+	overlap := tokenOverlap(tokens, e.tokens)
+
+	// Edit distance is the expensive part; skip it for texts that share nothing and differ a lot in length
+	la, lb := len([]rune(normalized)), len([]rune(e.normalized))
+	if overlap == 0 && (la > 2*lb || lb > 2*la) {
+		return 0
+	}
+
+	edit := editSimilarity(sortedText(tokens), sortedText(e.tokens))
+	score := 0.6*overlap + 0.4*edit
+	if score < 0.3 {
+		return 0
+	}
+	return round(score)
+}
+
+// tokenOverlap is a fuzzy Dice coefficient: every token counts with its best similarity to a token of the other side.
+func tokenOverlap(a, b []string) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+
+	sum := 0.0
+	for _, x := range a {
+		sum += bestTokenSimilarity(x, b)
+	}
+	for _, y := range b {
+		sum += bestTokenSimilarity(y, a)
+	}
+
+	return sum / float64(len(a)+len(b))
+}
+
+func bestTokenSimilarity(token string, others []string) float64 {
+	best := 0.0
+	for _, other := range others {
+		if token == other {
+			return 1
+		}
+		if s := editSimilarity(token, other); s >= tokenMatchFloor && s > best {
+			best = s
+		}
+	}
+	return best
+}
+
+// sortedText joins tokens in sorted order without spaces.
+func sortedText(tokens []string) string {
+	sorted := append([]string(nil), tokens...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, "")
+}
+
+// editSimilarity is one minus the edit distance relative to the longer text.
+func editSimilarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(editDistance(ra, rb))/float64(longest)
+}
+
+// editDistance is the optimal string alignment distance: Levenshtein plus the swap of two
+// adjacent characters as one edit, the most common typo in typed descriptions.
+func editDistance(a, b []rune) int {
+	prevPrev := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				curr[j] = min(curr[j], prevPrev[j-2]+1)
+			}
+		}
+		prevPrev, prev, curr = prev, curr, prevPrev
+	}
+
+	return prev[len(b)]
+}
+
+// thaiReplacer folds Thai spellings that look identical but are encoded differently,
+// drops invisible characters and maps Thai digits onto Arabic digits.
+var thaiReplacer = strings.NewReplacer(
+	"\u0e4d\u0e32", "\u0e33", // nikhahit + sara aa typed instead of sara am
+	"\u200b", "", // zero width space
+	"\u200c", "",
+	"\u200d", "",
+	"\ufeff", "",
+	"๐", "0", "๑", "1", "๒", "2", "๓", "3", "๔", "4",
+	"๕", "5", "๖", "6", "๗", "7", "๘", "8", "๙", "9",
+)
+
+// Normalize lower-cases a description, folds Thai encoding variants, turns punctuation into
+// spaces, separates Thai from Latin text and collapses whitespace.
+func Normalize(s string) string {
+	s = thaiReplacer.Replace(strings.ToLower(s))
+
+	var b strings.Builder
+	var prevThai, prevSet bool
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.Is(unicode.Mn, r) {
+			b.WriteRune(' ')
+			prevSet = false
+			continue
+		}
+
+		thai := unicode.Is(unicode.Thai, r)
+		if prevSet && thai != prevThai {
+			b.WriteRune(' ')
+		}
+		b.WriteRune(r)
+		prevThai, prevSet = thai, true
+	}
+
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// Tokens splits a normalised description into words, reducing English plurals to their singular.
+func Tokens(normalized string) []string {
+	fields := strings.Fields(normalized)
+	tokens := make([]string, 0, len(fields))
+	for _, f := range fields {
+		tokens = append(tokens, singular(f))
+	}
+	return tokens
+}
+
+func singular(word string) string {
+	if len(word) <= 3 || unicode.Is(unicode.Thai, []rune(word)[0]) {
+		return word
+	}
+	switch {
+	case strings.HasSuffix(word, "ies"):
+		return strings.TrimSuffix(word, "ies") + "y"
+	case strings.HasSuffix(word, "sses"), strings.HasSuffix(word, "xes"), strings.HasSuffix(word, "zzes"),
+		strings.HasSuffix(word, "ches"), strings.HasSuffix(word, "shes"):
+		return strings.TrimSuffix(word, "es")
+	case strings.HasSuffix(word, "ss"), strings.HasSuffix(word, "us"), strings.HasSuffix(word, "is"):
+		return word
+	case strings.HasSuffix(word, "s"):
+		return strings.TrimSuffix(word, "s")
+	}
+	return word
+}
+
+func round(v float64) float64 {
+	return float64(int(v*1000+0.5)) / 1000
+}
@@ -0,0 +1,141 @@
+package hsmatch
+
+import "testing"
+
+type hsCode struct {
+	Goods string
+	Code  string
+}
+
+var master = []hsCode{
+	{Goods: "MOBILE PHONE CASE", Code: "39269099"},
+	{Goods: "T-SHIRT", Code: "61091010"},
+	{Goods: "DRESS", Code: "62044200"},
+	{Goods: "WRIST WATCH", Code: "91021100"},
+	{Goods: "LITHIUM BATTERY", Code: "85065000"},
+	{Goods: "เสื้อยืด T-SHIRT", Code: "61091020"},
+	{Goods: "น้ำหอม", Code: "33030000"},
+}
+
+func newTestMatcher() *Matcher[hsCode] {
+	return NewMatcher(master, func(v hsCode) string { return v.Goods })
+}
+
+func TestBestMatchesVariants(t *testing.T) {
+	tests := []struct {
+		name        string
+		description string
+		want        string
+	}{
+		{"exact", "MOBILE PHONE CASE", "39269099"},
+		{"case and whitespace", "  mobile   phone\tcase ", "39269099"},
+		{"punctuation", "Mobile-Phone, Case.", "39269099"},
+		{"plural", "MOBILE PHONE CASES", "39269099"},
+		{"word order", "phone case mobile", "39269099"},
+		{"typo", "MOBILE PHONE CASR", "39269099"},
+		{"transposition", "T-SHRIT", "61091010"},
+		{"es plural", "DRESSES", "62044200"},
+		{"ches plural", "WRIST WATCHES", "91021100"},
+		{"ies plural", "LITHIUM BATTERIES", "85065000"},
+		{"thai and english mixed", "เสื้อยืดT-shirt", "61091020"},
+		{"thai encoding", "น้ําหอม", "33030000"},
+	}
+
+	m := newTestMatcher()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := m.Best(tt.description)
+			if !ok {
+				t.Fatalf("Best(%q) found no match, candidates %v", tt.description, scores(m.Match(tt.description)))
+			}
+			if got.Item.Code != tt.want {
+				t.Errorf("Best(%q) = %s (%s, %.3f), want %s", tt.description, got.Item.Code, got.Text, got.Score, tt.want)
+			}
+		})
+	}
+}
+
+func TestBestRejectsUnrelatedGoods(t *testing.T) {
+	m := newTestMatcher()
+	for _, description := range []string{"LAPTOP COMPUTER", "PHONE", "DRESS SHOES"} {
+		if got, ok := m.Best(description); ok {
+			t.Errorf("Best(%q) = %s (%.3f), want no automatic match", description, got.Text, got.Score)
+		}
+	}
+}
+
+func TestMatchRanksExactFirst(t *testing.T) {
+	m := newTestMatcher()
+	candidates := m.Match("t-shirt")
+	if len(candidates) == 0 || candidates[0].Item.Code != "61091010" || candidates[0].Score != 1 {
+		t.Fatalf("Match(t-shirt) = %v, want T-SHIRT first with score 1", scores(candidates))
+	}
+	if len(candidates) > DefaultTopN {
+		t.Errorf("got %d candidates, want at most %d", len(candidates), DefaultTopN)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := map[string]string{
+		"  Mobile-Phone,  CASE ": "mobile phone case",
+		"เสื้อยืดT-shirt":        "เสื้อยืด t shirt",
+		"น้ําหอม":                "น้ำหอม",
+		"ขนาด ๑๒ นิ้ว":           "ขนาด 12 นิ้ว",
+		"usb\u200bcable":         "usbcable",
+	}
+	for input, want := range tests {
+		if got := Normalize(input); got != want {
+			t.Errorf("Normalize(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestSingular(t *testing.T) {
+	tests := map[string]string{
+		"dresses":   "dress",
+		"glasses":   "glass",
+		"boxes":     "box",
+		"watches":   "watch",
+		"brushes":   "brush",
+		"batteries": "battery",
+		"cases":     "case",
+		"shoes":     "shoe",
+		"shirts":    "shirt",
+		"dress":     "dress",
+		"cactus":    "cactus",
+		"bus":       "bus",
+		"analysis":  "analysis",
+		"เสื้อ":     "เสื้อ",
+	}
+	for word, want := range tests {
+		if got := singular(word); got != want {
+			t.Errorf("singular(%q) = %q, want %q", word, got, want)
+		}
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"shirt", "shirt", 0},
+		{"shirt", "shrit", 1},
+		{"shirt", "short", 1},
+		{"kitten", "sitting", 3},
+		{"", "abc", 3},
+	}
+	for _, tt := range tests {
+		if got := editDistance([]rune(tt.a), []rune(tt.b)); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func scores(candidates []*Candidate[hsCode]) map[string]float64 {
+	result := map[string]float64{}
+	for _, c := range candidates {
+		result[c.Text] = c.Score
+	}
+	return result
+}
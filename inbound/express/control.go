@@ -0,0 +1,164 @@
+package inbound
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"hpc-express-service/utils"
+)
+
+const (
+	ControlFlagFobBelow    = "FOB_BELOW_CONTROL"
+	ControlFlagCifBelow    = "CIF_BELOW_CONTROL"
+	ControlFlagWeightAbove = "WEIGHT_ABOVE_CONTROL"
+)
+
+// weightToKgm converts the UN/ECE weight units used by the HS master into kilograms.
+var weightToKgm = map[string]float64{
+	"KGM": 1,
+	"GRM": 0.001,
+	"TNE": 1000,
+	"LBR": 0.45359237,
+}
+
+// checkControls flags every detail that breaks a control limit of its matched HS code:
+// FOB or CIF below the control price, or net weight above the control weight.
+// The FOB control is a unit price, so it is compared against the line FOB for its quantity.
+// Prices are compared in THB at the customs rate on arrivalDate. A control with a
+// country code only applies to lines from (FOB) or to (CIF) that country.
+func (s *service) checkControls(ctx context.Context, details []*utils.InsertPreImportDetailManifestModel, arrivalDate time.Time) error {
+	list, err := s.selfRepo.GetHsCodeControls(ctx, tariffCodes(details))
+	if err != nil {
+		return err
+	}
+	controls := newHsCodeIndex(list, func(v *GetHsCodeControlModel) (string, string, string) {
+		return v.TariffCode, v.StatisticalCode, v.TariffSequence
+	})
+
+	rates := map[string]float64{"THB": 1, "": 1}
+	toTHB := func(amount float64, currencyCode string) (float64, error) {
+		currencyCode = strings.ToUpper(strings.TrimSpace(currencyCode))
+		rate, ok := rates[currencyCode]
+		if !ok {
+			x, err := s.commonSvc.RateOn(ctx, currencyCode, arrivalDate)
+			if err != nil {
+				return 0, err
+			}
+			rate = x.ImportExchangeRate
+			rates[currencyCode] = rate
+		}
+		return amount * rate, nil
+	}
+
+	for _, d := range details {
+		d.ControlFlags = nil
+
+		c, ok := controls.Get(d.TariffCode, d.StatisticalCode, d.TariffSequence)
+		if !ok {
+			continue
+		}
+
+		if c.FobPriceControl > 0 && d.CurrencyCode != "" && appliesToCountry(c.FobPriceControlOriginCountryCode, d.ShipperCountryCode) {
+			controlCurrency := c.FobPriceControlOriginCurrencyCode
+			if controlCurrency == "" {
+				controlCurrency = d.CurrencyCode
+			}
+			fob, err := toTHB(d.FobValueForeign, d.CurrencyCode)
+			if err != nil {
+				return err
+			}
+			quantity := d.Quantity
+			if quantity < 1 {
+				quantity = 1
+			}
+			limit, err := toTHB(c.FobPriceControl*float64(quantity), controlCurrency)
+			if err != nil {
+				return err
+			}
+			if fob < limit {
+				d.ControlFlags = append(d.ControlFlags, &utils.ControlFlag{
+					Code:     ControlFlagFobBelow,
+					Message:  fmt.Sprintf("FOB %.2f THB for %d units is below the control price %.2f %s per unit", fob, quantity, c.FobPriceControl, controlCurrency),
+					Declared: fob,
+					Limit:    limit,
+				})
+			}
+		}
+
+		if c.CifControl > 0 && appliesToCountry(c.CifControlDestinationCountryCode, d.ConsigneeCountryCode) {
+			controlCurrency := c.CifControlDestinationCurrencyCode
+			if controlCurrency == "" {
+				controlCurrency = "THB"
+			}
+			limit, err := toTHB(c.CifControl, controlCurrency)
+			if err != nil {
+				return err
+			}
+			cif := d.CifValueForeign
+			if !isValuedInTHB(d.FreightCurrencyCode, d.InsuranceCurrencyCode) {
+				if cif, err = toTHB(d.CifValueForeign, d.CurrencyCode); err != nil {
+					return err
+				}
+			}
+			if cif < limit {
+				d.ControlFlags = append(d.ControlFlags, &utils.ControlFlag{
+					Code:     ControlFlagCifBelow,
+					Message:  fmt.Sprintf("CIF %.2f THB is below the control price %.2f %s", cif, c.CifControl, controlCurrency),
+					Declared: cif,
+					Limit:    limit,
+				})
+			}
+		}
+
+		if c.WeightControl > 0 {
+			limit := toKgm(c.WeightControl, c.WeightControlUnitCode)
+			weight := toKgm(d.NetWeight, d.NetWeightUnitCode)
+			if weight > limit {
+				d.ControlFlags = append(d.ControlFlags, &utils.ControlFlag{
+					Code:     ControlFlagWeightAbove,
+					Message:  fmt.Sprintf("net weight %.3f KGM is above the control weight %.3f KGM", weight, limit),
+					Declared: weight,
+					Limit:    limit,
+				})
+			}
+		}
+	}
+
+	return nil
+}
+
+// summarizeControlFlags counts flagged HAWBs overall and per flag code.
+func summarizeControlFlags(list []*GetSummaryModel) *ControlFlagSummaryModel {
+	result := &ControlFlagSummaryModel{
+		ByCode:       map[string]int64{},
+		FlaggedHawbs: []string{},
+	}
+
+	for _, v := range list {
+		if len(v.ControlFlags) == 0 {
+			continue
+		}
+		result.TotalFlaggedHawb++
+		result.FlaggedHawbs = append(result.FlaggedHawbs, v.Hawb)
+		for _, flag := range v.ControlFlags {
+			result.ByCode[flag.Code]++
+		}
+	}
+
+	return result
+}
+
+func appliesToCountry(controlCountryCode, countryCode string) bool {
+	controlCountryCode = strings.TrimSpace(controlCountryCode)
+	return controlCountryCode == "" || strings.EqualFold(controlCountryCode, strings.TrimSpace(countryCode))
+}
+
+// toKgm converts weight to kilograms; an unknown or missing unit is taken as kilograms.
+func toKgm(weight float64, unitCode string) float64 {
+	if factor, ok := weightToKgm[strings.ToUpper(strings.TrimSpace(unitCode))]; ok {
+		return weight * factor
+	}
+	return weight
+}
@@ -0,0 +1,111 @@
+package inbound
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"hpc-express-service/utils"
+)
+
+// PreImportConverter converts an uploaded customer manifest into pre-import detail rows.
+// Each converter owns its template code and column mapping; the mapping also drives upload validation.
+// arrivalDate is the header arrival date, which decides the customs exchange rate used for valuation.
+type PreImportConverter interface {
+	TemplateCode() string
+	ColumnMapping(ctx context.Context) (*utils.ColumnMappingTemplate, error)
+	ConvertToPreImportDetails(ctx context.Context, uploadLogUUID string, arrivalDate time.Time, fileBytes []byte) ([]*utils.InsertPreImportDetailManifestModel, error)
+}
+
+// PreImportRowConverter values and classifies rows read with a template's column mapping: HS
+// code, customs exchange rate on arrivalDate, freight, insurance and CIF.
+type PreImportRowConverter interface {
+	ConvertRows(ctx context.Context, uploadLogUUID string, arrivalDate time.Time, rows []*utils.InsertPreImportDetailManifestModel) ([]*utils.InsertPreImportDetailManifestModel, error)
+}
+
+// ConverterRegistry resolves the converter for a template code.
+type ConverterRegistry struct {
+	converters map[string]PreImportConverter
+	rows       PreImportRowConverter
+}
+
+// NewConverterRegistry registers converters. rows converts the rows of templates that only
+// have a column mapping stored in master_convert_templates.
+func NewConverterRegistry(rows PreImportRowConverter, converters ...PreImportConverter) *ConverterRegistry {
+	r := &ConverterRegistry{
+		converters: make(map[string]PreImportConverter),
+		rows:       rows,
+	}
+
+	for _, c := range converters {
+		r.Register(c)
+	}
+
+	return r
+}
+
+// Register adds a converter, replacing any converter already registered under the same template code.
+func (r *ConverterRegistry) Register(c PreImportConverter) {
+	r.converters[c.TemplateCode()] = c
+}
+
+func (r *ConverterRegistry) Get(templateCode string) (PreImportConverter, error) {
+	c, ok := r.converters[templateCode]
+	if !ok {
+		return nil, errors.New("not found template")
+	}
+
+	return c, nil
+}
+
+// Mapped returns the converter of a template that only has a column mapping.
+func (r *ConverterRegistry) Mapped(templateCode string, mapping *utils.ColumnMappingTemplate) PreImportConverter {
+	return newMappedConverter(templateCode, mapping, r.rows)
+}
+
+func (r *ConverterRegistry) TemplateCodes() []string {
+	codes := make([]string, 0, len(r.converters))
+	for code := range r.converters {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	return codes
+}
+
+// mappedConverter reads a manifest from a column mapping stored in master_convert_templates
+// and values its rows with rows.
+type mappedConverter struct {
+	templateCode string
+	mapping      *utils.ColumnMappingTemplate
+	rows         PreImportRowConverter
+}
+
+func newMappedConverter(templateCode string, mapping *utils.ColumnMappingTemplate, rows PreImportRowConverter) PreImportConverter {
+	return &mappedConverter{
+		templateCode: templateCode,
+		mapping:      mapping,
+		rows:         rows,
+	}
+}
+
+func (c *mappedConverter) TemplateCode() string {
+	return c.templateCode
+}
+
+func (c *mappedConverter) ColumnMapping(ctx context.Context) (*utils.ColumnMappingTemplate, error) {
+	return c.mapping, nil
+}
+
+func (c *mappedConverter) ConvertToPreImportDetails(ctx context.Context, uploadLogUUID string, arrivalDate time.Time, fileBytes []byte) ([]*utils.InsertPreImportDetailManifestModel, error) {
+	rows, report, err := utils.ReadMappedSheet[utils.InsertPreImportDetailManifestModel](fileBytes, c.mapping)
+	if err != nil {
+		return nil, err
+	}
+	if report.HasIssues() {
+		return nil, report
+	}
+
+	return c.rows.ConvertRows(ctx, uploadLogUUID, arrivalDate, utils.MappedData(rows))
+}
@@ -0,0 +1,160 @@
+package inbound
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"hpc-express-service/ecustoms"
+)
+
+// buildImportDeclarations maps a pre-import header onto e-Import declarations, one per HAWB
+// in the order the HAWBs first appear. Shipper and consignee come from the HAWB's first line.
+func buildImportDeclarations(data *GetPreImportManifestModel, now time.Time) ([]*ecustoms.ImportDeclaration, error) {
+	arrivalDate, ok := parseDeclarationDate(data.ArrivalDate)
+	if !ok {
+		return nil, errors.New("arrival date is required to declare the MAWB")
+	}
+
+	declarations := []*ecustoms.ImportDeclaration{}
+	byHawb := map[string]*ecustoms.ImportDeclaration{}
+	grossWeights := map[string]float64{}
+	for _, v := range data.Details {
+		d, ok := byHawb[v.HouseAirWaybill]
+		if !ok {
+			d = newImportDeclaration(data, v, arrivalDate, now)
+			byHawb[v.HouseAirWaybill] = d
+			declarations = append(declarations, d)
+		}
+
+		if pkg, err := strconv.ParseInt(strings.TrimSpace(v.Package), 10, 64); err == nil {
+			d.Header.TotalPackage += pkg
+		}
+		grossWeights[v.HouseAirWaybill] += toKgm(v.GrossWeight, v.GrossWeightUnitCode)
+
+		valuation := &ecustoms.ImportValuation{
+			CurrencyCode: v.CurrencyCode,
+			ExchangeRate: ecustoms.FormatRate(v.ExchangeRate),
+			FobForeign:   ecustoms.FormatAmount(v.FobValueForeign),
+			FobBaht:      ecustoms.FormatAmount(v.FobValueForeign * v.ExchangeRate),
+			CifBaht:      ecustoms.FormatAmount(cifBaht(v.CifValueForeign, v.ExchangeRate, v.FreightCurrencyCode, v.InsuranceCurrencyCode)),
+		}
+		if v.FreightValueForeign > 0 {
+			valuation.Freight = &ecustoms.ChargeModel{Amount: ecustoms.FormatAmount(v.FreightValueForeign), CurrencyCode: v.FreightCurrencyCode}
+		}
+		if v.InsuranceValueForeign > 0 {
+			valuation.Insurance = &ecustoms.ChargeModel{Amount: ecustoms.FormatAmount(v.InsuranceValueForeign), CurrencyCode: v.InsuranceCurrencyCode}
+		}
+
+		d.Items = append(d.Items, &ecustoms.ImportDeclarationItem{
+			ItemNumber: int64(len(d.Items) + 1),
+			Tariff: &ecustoms.TariffModel{
+				TariffCode:      digitsOnly(v.TariffCode),
+				TariffSequence:  strings.TrimSpace(v.TariffSequence),
+				StatisticalCode: strings.TrimSpace(v.StatisticalCode),
+			},
+			DescriptionEnglish:  strings.TrimSpace(v.EnglishDescriptionOfGood),
+			DescriptionThai:     strings.TrimSpace(v.ThaiDescriptionOfGood),
+			Quantity:            ecustoms.FormatWeight(float64(v.Quantity)),
+			QuantityUnitCode:    v.QuantityUnitCode,
+			NetWeight:           ecustoms.FormatWeight(v.NetWeight),
+			NetWeightUnitCode:   v.NetWeightUnitCode,
+			GrossWeight:         ecustoms.FormatWeight(v.GrossWeight),
+			GrossWeightUnitCode: v.GrossWeightUnitCode,
+			Valuation:           valuation,
+		})
+	}
+
+	if len(declarations) == 0 {
+		return nil, errors.New("no details to declare")
+	}
+	for _, d := range declarations {
+		d.Header.TotalGrossWeight = ecustoms.FormatWeight(grossWeights[d.Header.HouseAirWaybill])
+	}
+
+	return declarations, nil
+}
+
+func newImportDeclaration(data *GetPreImportManifestModel, v *GetPreImportManifestDetilModel, arrivalDate, now time.Time) *ecustoms.ImportDeclaration {
+	mawb := v.MasterAirWaybill
+	if mawb == "" {
+		mawb = data.Mawb
+	}
+	consignmentCountry := v.ConsignmentCountry
+	if consignmentCountry == "" {
+		consignmentCountry = v.ShipperCountryCode
+	}
+
+	d := &ecustoms.ImportDeclaration{
+		DocumentControl: &ecustoms.ImportDocumentControl{
+			ReferenceNumber: v.HouseAirWaybill,
+			DocumentType:    "EXPRESS_IMPORT",
+			MessageDate:     ecustoms.FormatMessageDate(now),
+		},
+		Header: &ecustoms.ImportHeader{
+			MasterAirWaybill:       mawb,
+			HouseAirWaybill:        v.HouseAirWaybill,
+			Category:               v.Category,
+			VesselName:             data.VasselName,
+			ArrivalDate:            arrivalDate.Format("2006-01-02"),
+			DischargePort:          data.DischargePort,
+			ConsignmentCountryCode: consignmentCountry,
+			PackageUnitCode:        v.PackageUnitCode,
+			GrossWeightUnitCode:    "KGM",
+		},
+		Consignee: &ecustoms.ImportConsignee{
+			TaxNumber:        v.ConsigneeTax,
+			Branch:           v.ConsigneeBranch,
+			Name:             v.ConsigneeName,
+			StreetAndAddress: v.ConsigneeAddress,
+			District:         v.ConsigneeDistrict,
+			SubProvince:      v.ConsigneeSubprovince,
+			Province:         v.ConsigneeProvince,
+			Postcode:         v.ConsigneePostcode,
+			CountryCode:      v.ConsigneeCountryCode,
+			Email:            v.ConsigneeEmail,
+			PhoneNumber:      v.ConsigneePhoneNumber,
+		},
+		Shipper: &ecustoms.ImportShipper{
+			Name:             v.ShipperName,
+			StreetAndAddress: v.ShipperAddress,
+			District:         v.ShipperDistrict,
+			SubProvince:      v.ShipperSubprovince,
+			Province:         v.ShipperProvince,
+			Postcode:         v.ShipperPostcode,
+			CountryCode:      v.ShipperCountryCode,
+			Email:            v.ShipperEmail,
+			PhoneNumber:      v.ShipperPhoneNumber,
+		},
+	}
+
+	if v.InvoiceNo != "" {
+		d.Invoice = &ecustoms.ImportInvoice{Number: v.InvoiceNo}
+		if invoiceDate, ok := parseDeclarationDate(v.InvoiceDate); ok {
+			d.Invoice.Date = invoiceDate.Format("2006-01-02")
+		}
+	}
+
+	return d
+}
+
+// parseDeclarationDate reads the date formats found in headers and invoices.
+func parseDeclarationDate(value string) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	for _, layout := range []string{"2006-01-02", time.RFC3339, "2006-01-02 15:04:05", "02/01/2006", "20060102"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func digitsOnly(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, s)
+}
@@ -2,6 +2,7 @@ package inbound
 
 import (
 	"fmt"
+	"hpc-express-service/utils"
 	"net/http"
 
 	"github.com/shopspring/decimal"
@@ -12,6 +13,7 @@ type InsertPreImportHeaderManifestModel struct {
 	DischargePort      string `json:"dischargePort"`
 	VasselName         string `json:"vasselName"`
 	ArrivalDate        string `json:"arrivalDate"`
+	CustomerUUID       string `json:"customerUuid"`
 	CustomerName       string `json:"customerName"`
 	FlightNo           string `json:"flightNo"`
 	OriginCountryCode  string `json:"originCountryCode"`
@@ -33,6 +35,7 @@ type UpdatePreImportHeaderManifestModel struct {
 	DischargePort      string `json:"dischargePort"`
 	VasselName         string `json:"vasselName"`
 	ArrivalDate        string `json:"arrivalDate"`
+	CustomerUUID       string `json:"customerUuid"`
 	CustomerName       string `json:"customerName"`
 	FlightNo           string `json:"flightNo"`
 	OriginCountryCode  string `json:"originCountryCode"`
@@ -105,10 +108,13 @@ func (o *UpdatePreImportHeaderManifestModel) Render(w http.ResponseWriter, r *ht
 type GetPreImportManifestModel struct {
 	UUID               string                            `json:"uuid"`
 	Mawb               string                            `json:"mawb"`
+	AirlineName        string                            `json:"airlineName,omitempty"`
+	AirlineLogo        string                            `json:"airlineLogo,omitempty"`
 	UploadLoggingUUID  string                            `json:"uploadLoggingUUID"`
 	DischargePort      string                            `json:"dischargePort"`
 	VasselName         string                            `json:"vasselName"`
 	ArrivalDate        string                            `json:"arrivalDate"`
+	CustomerUUID       string                            `json:"customerUuid"`
 	CustomerName       string                            `json:"customerName"`
 	FlightNo           string                            `json:"flightNo"`
 	OriginCountryCode  string                            `json:"originCountryCode"`
@@ -117,66 +123,69 @@ type GetPreImportManifestModel struct {
 	CreatedAt          string                            `json:"createdAt"`
 	UpdatedAt          string                            `json:"updatedAt"`
 	Details            []*GetPreImportManifestDetilModel `json:"details,omitempty"`
+	PreAlert           *PreAlertModel                    `json:"preAlert,omitempty"`
 	// Summary   string // TODO:
 }
 
 type GetPreImportManifestDetilModel struct {
-	UUID                     string  `json:"uuid"`
-	MasterAirWaybill         string  `json:"masterAirWaybill"`
-	HouseAirWaybill          string  `json:"houseAirWaybill"`
-	Category                 string  `json:"category"`
-	ConsigneeTax             string  `json:"consigneeTax"`
-	ConsigneeBranch          string  `json:"consigneeBranch"`
-	ConsigneeName            string  `json:"consigneeName"`
-	ConsigneeAddress         string  `json:"consigneeAddress"`
-	ConsigneeDistrict        string  `json:"consigneeDistrict"`
-	ConsigneeSubprovince     string  `json:"consigneeSubprovince"`
-	ConsigneeProvince        string  `json:"consigneeProvince"`
-	ConsigneePostcode        string  `json:"consigneePostcode"`
-	ConsigneeCountryCode     string  `json:"consigneeCountryCode"`
-	ConsigneeEmail           string  `json:"consigneeEmail"`
-	ConsigneePhoneNumber     string  `json:"consigneePhoneNumber"`
-	ShipperName              string  `json:"shipperName"`
-	ShipperAddress           string  `json:"shipperAddress"`
-	ShipperDistrict          string  `json:"shipperDistrict"`
-	ShipperSubprovince       string  `json:"shipperSubprovince"`
-	ShipperProvince          string  `json:"shipperProvince"`
-	ShipperPostcode          string  `json:"shipperPostcode"`
-	ShipperCountryCode       string  `json:"shipperCountryCode"`
-	ShipperEmail             string  `json:"shipperEmail"`
-	ShipperPhoneNumber       string  `json:"shipperPhoneNumber"`
-	TariffCode               string  `json:"tariffCode"`
-	TariffSequence           string  `json:"tariffSequence"`
-	StatisticalCode          string  `json:"statisticalCode"`
-	EnglishDescriptionOfGood string  `json:"englishDescriptionOfGood"`
-	ThaiDescriptionOfGood    string  `json:"thaiDescriptionOfGood"`
-	Quantity                 int64   `json:"quantity"`
-	QuantityUnitCode         string  `json:"quantityUnitCode"`
-	NetWeight                float64 `json:"netWeight"`
-	NetWeightUnitCode        string  `json:"netWeightUnitCode"`
-	GrossWeight              float64 `json:"grossWeight"`
-	GrossWeightUnitCode      string  `json:"grossWeightUnitCode"`
-	Package                  string  `json:"package"`
-	PackageUnitCode          string  `json:"packageUnitCode"`
-	CifValueForeign          float64 `json:"cifValueForeign"`
-	FobValueForeign          float64 `json:"fobValueForeign"`
-	ExchangeRate             float64 `json:"exchangeRate"`
-	CurrencyCode             string  `json:"currencyCode"`
-	ShippingMark             string  `json:"shippingMark"`
-	ConsignmentCountry       string  `json:"consignmentCountry"`
-	FreightValueForeign      float64 `json:"freightValueForeign"`
-	FreightCurrencyCode      string  `json:"freightCurrencyCode"`
-	InsuranceValueForeign    float64 `json:"insuranceValueForeign"`
-	InsuranceCurrencyCode    string  `json:"insuranceCurrencyCode"`
-	OtherChargeValueForeign  string  `json:"otherChargeValueForeign"`
-	OtherChargeCurrencyCode  string  `json:"otherChargeCurrencyCode"`
-	InvoiceNo                string  `json:"invoiceNo"`
-	InvoiceDate              string  `json:"invoiceDate"`
-	CreatedAt                string  `json:"createdAt"`
-	UpdatedAt                string  `json:"updatedAt"`
-	Vat                      float64 `json:"vat"`
-	Duty                     float64 `json:"duty"`
-	IsGoodsMatched           bool    `json:"isGoodsMatched"`
+	UUID                     string               `json:"uuid"`
+	MasterAirWaybill         string               `json:"masterAirWaybill"`
+	HouseAirWaybill          string               `json:"houseAirWaybill"`
+	Category                 string               `json:"category"`
+	ConsigneeTax             string               `json:"consigneeTax"`
+	ConsigneeBranch          string               `json:"consigneeBranch"`
+	ConsigneeName            string               `json:"consigneeName"`
+	ConsigneeAddress         string               `json:"consigneeAddress"`
+	ConsigneeDistrict        string               `json:"consigneeDistrict"`
+	ConsigneeSubprovince     string               `json:"consigneeSubprovince"`
+	ConsigneeProvince        string               `json:"consigneeProvince"`
+	ConsigneePostcode        string               `json:"consigneePostcode"`
+	ConsigneeCountryCode     string               `json:"consigneeCountryCode"`
+	ConsigneeEmail           string               `json:"consigneeEmail"`
+	ConsigneePhoneNumber     string               `json:"consigneePhoneNumber"`
+	ShipperName              string               `json:"shipperName"`
+	ShipperAddress           string               `json:"shipperAddress"`
+	ShipperDistrict          string               `json:"shipperDistrict"`
+	ShipperSubprovince       string               `json:"shipperSubprovince"`
+	ShipperProvince          string               `json:"shipperProvince"`
+	ShipperPostcode          string               `json:"shipperPostcode"`
+	ShipperCountryCode       string               `json:"shipperCountryCode"`
+	ShipperEmail             string               `json:"shipperEmail"`
+	ShipperPhoneNumber       string               `json:"shipperPhoneNumber"`
+	TariffCode               string               `json:"tariffCode"`
+	TariffSequence           string               `json:"tariffSequence"`
+	StatisticalCode          string               `json:"statisticalCode"`
+	EnglishDescriptionOfGood string               `json:"englishDescriptionOfGood"`
+	ThaiDescriptionOfGood    string               `json:"thaiDescriptionOfGood"`
+	Quantity                 int64                `json:"quantity"`
+	QuantityUnitCode         string               `json:"quantityUnitCode"`
+	NetWeight                float64              `json:"netWeight"`
+	NetWeightUnitCode        string               `json:"netWeightUnitCode"`
+	GrossWeight              float64              `json:"grossWeight"`
+	GrossWeightUnitCode      string               `json:"grossWeightUnitCode"`
+	Package                  string               `json:"package"`
+	PackageUnitCode          string               `json:"packageUnitCode"`
+	CifValueForeign          float64              `json:"cifValueForeign"`
+	FobValueForeign          float64              `json:"fobValueForeign"`
+	ExchangeRate             float64              `json:"exchangeRate"`
+	CurrencyCode             string               `json:"currencyCode"`
+	ShippingMark             string               `json:"shippingMark"`
+	ConsignmentCountry       string               `json:"consignmentCountry"`
+	FreightValueForeign      float64              `json:"freightValueForeign"`
+	FreightCurrencyCode      string               `json:"freightCurrencyCode"`
+	InsuranceValueForeign    float64              `json:"insuranceValueForeign"`
+	InsuranceCurrencyCode    string               `json:"insuranceCurrencyCode"`
+	OtherChargeValueForeign  string               `json:"otherChargeValueForeign"`
+	OtherChargeCurrencyCode  string               `json:"otherChargeCurrencyCode"`
+	InvoiceNo                string               `json:"invoiceNo"`
+	InvoiceDate              string               `json:"invoiceDate"`
+	CreatedAt                string               `json:"createdAt"`
+	UpdatedAt                string               `json:"updatedAt"`
+	DutyRate                 float64              `json:"dutyRate"`
+	Vat                      float64              `json:"vat"`
+	Duty                     float64              `json:"duty"`
+	ControlFlags             []*utils.ControlFlag `json:"controlFlags"`
+	IsGoodsMatched           bool                 `json:"isGoodsMatched"`
 }
 
 type UpdatePreImportManifestDetailModel struct {
@@ -249,11 +258,81 @@ func validateHeadersUploadUpdateRawPreImport(headers []string) error {
 	return nil
 }
 
+// GetSummaryModel is one HAWB of a MAWB summary. Category, Vat and Duty are filled in by the tax engine from Cif and DutyRate.
 type GetSummaryModel struct {
-	Hawb     string
-	Category string
-	Vat      float64
-	Duty     float64
+	Hawb                  string
+	Cif                   float64
+	ExchangeRate          float64
+	FreightCurrencyCode   string
+	InsuranceCurrencyCode string
+	DutyRate              float64
+	Category              string
+	Vat                   float64
+	Duty                  float64
+	ControlFlags          []*utils.ControlFlag
+}
+
+type GetDutyRateModel struct {
+	TariffCode      string
+	TariffSequence  string
+	StatisticalCode string
+	DutyRate        float64
+}
+
+type GetHsCodeControlModel struct {
+	TariffCode                        string
+	TariffSequence                    string
+	StatisticalCode                   string
+	FobPriceControl                   float64
+	FobPriceControlOriginCurrencyCode string
+	FobPriceControlOriginCountryCode  string
+	WeightControl                     float64
+	WeightControlUnitCode             string
+	CifControl                        float64
+	CifControlDestinationCurrencyCode string
+	CifControlDestinationCountryCode  string
+}
+
+// UploadPreviewModel is what an upload would insert, returned by a dry run.
+type UploadPreviewModel struct {
+	Details []*PreviewDetailModel `json:"details"`
+	Summary *UploadSummaryModel   `json:"summary"`
+}
+
+type PreviewDetailModel struct {
+	MasterAirWaybill      string                   `json:"masterAirWaybill"`
+	HouseAirWaybill       string                   `json:"houseAirWaybill"`
+	Category              string                   `json:"category"`
+	EnglishDescription    string                   `json:"englishDescription"`
+	TariffCode            string                   `json:"tariffCode"`
+	TariffSequence        string                   `json:"tariffSequence"`
+	StatisticalCode       string                   `json:"statisticalCode"`
+	Quantity              int64                    `json:"quantity"`
+	NetWeight             float64                  `json:"netWeight"`
+	CurrencyCode          string                   `json:"currencyCode"`
+	ExchangeRate          float64                  `json:"exchangeRate"`
+	FobValueForeign       float64                  `json:"fobValueForeign"`
+	FreightValueForeign   float64                  `json:"freightValueForeign"`
+	InsuranceValueForeign float64                  `json:"insuranceValueForeign"`
+	CifValueForeign       float64                  `json:"cifValueForeign"`
+	DutyRate              float64                  `json:"dutyRate"`
+	Vat                   float64                  `json:"vat"`
+	Duty                  float64                  `json:"duty"`
+	ControlFlags          []*utils.ControlFlag     `json:"controlFlags"`
+	HsCodeCandidates      []*utils.HsCodeCandidate `json:"hsCodeCandidates,omitempty"`
+}
+
+// UploadResultModel is returned by an upload: how many lines were inserted and the lines whose
+// goods matched no HS code with enough confidence, each with its ranked candidates.
+type UploadResultModel struct {
+	Amount        int                  `json:"amount"`
+	HsCodeReviews []*HsCodeReviewModel `json:"hsCodeReviews"`
+}
+
+type HsCodeReviewModel struct {
+	HouseAirWaybill    string                   `json:"houseAirWaybill"`
+	EnglishDescription string                   `json:"englishDescription"`
+	Candidates         []*utils.HsCodeCandidate `json:"candidates"`
 }
 
 type UploadSummaryModel struct {
@@ -265,10 +344,18 @@ type UploadSummaryModel struct {
 	Catogory2          *CatogorySummaryModel    `json:"catogory2"`
 	Catogory3          *CatogorySummaryModel    `json:"catogory3"`
 	OtherCatogory      *CatogorySummaryModel    `json:"otherCatogory"`
+	ControlFlags       *ControlFlagSummaryModel `json:"controlFlags"`
 	TotalTax           float64                  `json:"totalTax"`
 	TotalHawb          int64                    `json:"totalHawb"`
 }
 
+// ControlFlagSummaryModel counts the HAWBs breaking HS code control limits.
+type ControlFlagSummaryModel struct {
+	TotalFlaggedHawb int64            `json:"totalFlaggedHawb"`
+	ByCode           map[string]int64 `json:"byCode"`
+	FlaggedHawbs     []string         `json:"flaggedHawbs"`
+}
+
 type CatogorySummaryModel struct {
 	Category           string          `json:"category"`
 	Total              int64           `json:"total"`
@@ -1,191 +1,76 @@
 package inbound
 
-import "github.com/shopspring/decimal"
+import (
+	"github.com/shopspring/decimal"
 
-func calcCustomsFee(totalHawb int) *CustomFeeModel {
-	const maxHawbPerDeclaration = 40
-	const feePerDeclaration = 200
+	"hpc-express-service/setting"
+)
 
+func calcCustomsFee(schedule *setting.FeeSchedule, totalHawb int) *CustomFeeModel {
 	result := &CustomFeeModel{}
-
-	// number of declarations
-	result.TotalDeclaration = (totalHawb + maxHawbPerDeclaration - 1) / maxHawbPerDeclaration
-
-	// total customs fee
-	result.TotalFee = decimal.NewFromInt(int64(feePerDeclaration)).Mul(decimal.NewFromInt(int64(result.TotalDeclaration)))
-
-	// exact fee per HAWB
-	exactPerHawb := result.TotalFee.Div(decimal.NewFromInt(int64(totalHawb)))
-
-	// floor (2 decimals) for everyone first
-	result.FloorPerHawb = exactPerHawb.RoundFloor(2)
-
-	result.PerHawbFees = make([]decimal.Decimal, totalHawb)
-	for i := range result.PerHawbFees {
-		result.PerHawbFees[i] = result.FloorPerHawb
-	}
-
-	// how much leftover to distribute
-	sum := result.FloorPerHawb.Mul(decimal.NewFromInt(int64(totalHawb)))
-	remainder := result.TotalFee.Sub(sum)
-
-	// distribute remainder in +0.01 steps
-	i := 0
-	oneCent := decimal.NewFromFloat(0.01)
-	for remainder.GreaterThan(decimal.Zero) {
-		result.PerHawbFees[i] = result.PerHawbFees[i].Add(oneCent)
-		remainder = remainder.Sub(oneCent)
-		i++
-	}
-
+	result.TotalDeclaration, result.TotalFee = schedule.Calculate(totalHawb)
+	result.FloorPerHawb, result.PerHawbFees = distributeFee(result.TotalFee, totalHawb)
 	return result
 }
 
-func calcOTCustomsFee(totalHawb int) *OTCustomFeeModel {
-	const maxHawbPerDeclaration = 40
-	const feePerDeclaration = 200
-
+func calcOTCustomsFee(schedule *setting.FeeSchedule, totalHawb int) *OTCustomFeeModel {
 	result := &OTCustomFeeModel{}
-
-	// number of declarations
-	result.TotalDeclaration = (totalHawb + maxHawbPerDeclaration - 1) / maxHawbPerDeclaration
-
-	// total customs fee
-	result.TotalFee = decimal.NewFromInt(int64(feePerDeclaration)).Mul(decimal.NewFromInt(int64(result.TotalDeclaration)))
-
-	// exact fee per HAWB
-	exactPerHawb := result.TotalFee.Div(decimal.NewFromInt(int64(totalHawb)))
-
-	// floor (2 decimals) for everyone first
-	result.FloorPerHawb = exactPerHawb.RoundFloor(2)
-
-	result.PerHawbFees = make([]decimal.Decimal, totalHawb)
-	for i := range result.PerHawbFees {
-		result.PerHawbFees[i] = result.FloorPerHawb
-	}
-
-	// how much leftover to distribute
-	sum := result.FloorPerHawb.Mul(decimal.NewFromInt(int64(totalHawb)))
-	remainder := result.TotalFee.Sub(sum)
-
-	// distribute remainder in +0.01 steps
-	i := 0
-	oneCent := decimal.NewFromFloat(0.01)
-	for remainder.GreaterThan(decimal.Zero) {
-		result.PerHawbFees[i] = result.PerHawbFees[i].Add(oneCent)
-		remainder = remainder.Sub(oneCent)
-		i++
-	}
-
+	result.TotalDeclaration, result.TotalFee = schedule.Calculate(totalHawb)
+	result.FloorPerHawb, result.PerHawbFees = distributeFee(result.TotalFee, totalHawb)
 	return result
 }
 
-func calcBankFee(totalHawb int) *BankFeeFeeModel {
-	const maxHawbPerDeclaration = 40
-	const feePerDeclaration = 70
-
+func calcBankFee(schedule *setting.FeeSchedule, totalHawb int) *BankFeeFeeModel {
 	result := &BankFeeFeeModel{}
-
-	// number of declarations
-	result.TotalDeclaration = (totalHawb + maxHawbPerDeclaration - 1) / maxHawbPerDeclaration
-
-	// total customs fee
-	result.TotalFee = decimal.NewFromInt(int64(feePerDeclaration)).Mul(decimal.NewFromInt(int64(result.TotalDeclaration)))
-
-	// exact fee per HAWB
-	exactPerHawb := result.TotalFee.Div(decimal.NewFromInt(int64(totalHawb)))
-
-	// floor (2 decimals) for everyone first
-	result.FloorPerHawb = exactPerHawb.RoundFloor(2)
-
-	result.PerHawbFees = make([]decimal.Decimal, totalHawb)
-	for i := range result.PerHawbFees {
-		result.PerHawbFees[i] = result.FloorPerHawb
-	}
-
-	// how much leftover to distribute
-	sum := result.FloorPerHawb.Mul(decimal.NewFromInt(int64(totalHawb)))
-	remainder := result.TotalFee.Sub(sum)
-
-	// distribute remainder in +0.01 steps
-	i := 0
-	oneCent := decimal.NewFromFloat(0.01)
-	for remainder.GreaterThan(decimal.Zero) {
-		result.PerHawbFees[i] = result.PerHawbFees[i].Add(oneCent)
-		remainder = remainder.Sub(oneCent)
-		i++
-	}
-
+	result.TotalDeclaration, result.TotalFee = schedule.Calculate(totalHawb)
+	result.FloorPerHawb, result.PerHawbFees = distributeFee(result.TotalFee, totalHawb)
 	return result
 }
 
-func calcCargoPermitFee(totalHawb int) *CargoPermitFeeModel {
-	const maxHawbPerDeclaration = 40
-	const feePerDeclaration = 150
-
+func calcCargoPermitFee(schedule *setting.FeeSchedule, totalHawb int) *CargoPermitFeeModel {
 	result := &CargoPermitFeeModel{}
-
-	// number of declarations
-	result.TotalDeclaration = (totalHawb + maxHawbPerDeclaration - 1) / maxHawbPerDeclaration
-
-	// total customs fee
-	result.TotalFee = decimal.NewFromInt(int64(feePerDeclaration)).Mul(decimal.NewFromInt(int64(result.TotalDeclaration)))
-
-	// exact fee per HAWB
-	exactPerHawb := result.TotalFee.Div(decimal.NewFromInt(int64(totalHawb)))
-
-	// floor (2 decimals) for everyone first
-	result.FloorPerHawb = exactPerHawb.RoundFloor(2)
-
-	result.PerHawbFees = make([]decimal.Decimal, totalHawb)
-	for i := range result.PerHawbFees {
-		result.PerHawbFees[i] = result.FloorPerHawb
-	}
-
-	// how much leftover to distribute
-	sum := result.FloorPerHawb.Mul(decimal.NewFromInt(int64(totalHawb)))
-	remainder := result.TotalFee.Sub(sum)
-
-	// distribute remainder in +0.01 steps
-	i := 0
-	oneCent := decimal.NewFromFloat(0.01)
-	for remainder.GreaterThan(decimal.Zero) {
-		result.PerHawbFees[i] = result.PerHawbFees[i].Add(oneCent)
-		remainder = remainder.Sub(oneCent)
-		i++
-	}
-
+	result.TotalDeclaration, result.TotalFee = schedule.Calculate(totalHawb)
+	result.FloorPerHawb, result.PerHawbFees = distributeFee(result.TotalFee, totalHawb)
 	return result
 }
 
-func calcExpressDeliveryFee(totalHawb int) *ExpressDeliveryFeeModel {
-	feePerMasterAirwayBill := decimal.NewFromInt(380)
-
+func calcExpressDeliveryFee(schedule *setting.FeeSchedule, totalHawb int) *ExpressDeliveryFeeModel {
 	result := &ExpressDeliveryFeeModel{}
+	_, totalFee := schedule.Calculate(totalHawb)
+	result.FloorPerHawb, result.PerHawbFees = distributeFee(totalFee, totalHawb)
+	return result
+}
 
-	// exact per HAWB
-	exact := feePerMasterAirwayBill.Div(decimal.NewFromInt(int64(totalHawb)))
+// distributeFee splits totalFee over totalHawb HAWBs. Everyone gets the fee floored to
+// 2 decimals and the leftover cents go one by one to the first HAWBs, so the parts
+// always add back up to the rounded total.
+func distributeFee(totalFee decimal.Decimal, totalHawb int) (decimal.Decimal, []decimal.Decimal) {
+	if totalHawb <= 0 {
+		return decimal.Zero, []decimal.Decimal{}
+	}
 
-	// floor to 2 decimals
-	result.FloorPerHawb = exact.RoundFloor(2)
+	totalFee = totalFee.Round(2)
 
-	result.PerHawbFees = make([]decimal.Decimal, totalHawb)
-	for i := 0; i < totalHawb; i++ {
-		result.PerHawbFees[i] = result.FloorPerHawb
+	// floor (2 decimals) for everyone first
+	floorPerHawb := totalFee.Div(decimal.NewFromInt(int64(totalHawb))).RoundFloor(2)
+
+	perHawbFees := make([]decimal.Decimal, totalHawb)
+	for i := range perHawbFees {
+		perHawbFees[i] = floorPerHawb
 	}
 
 	// how much leftover to distribute
-	sum := result.FloorPerHawb.Mul(decimal.NewFromInt(int64(totalHawb)))
-	remainder := feePerMasterAirwayBill.Sub(sum)
+	sum := floorPerHawb.Mul(decimal.NewFromInt(int64(totalHawb)))
+	remainder := totalFee.Sub(sum)
 
 	// distribute remainder in +0.01 steps
 	i := 0
 	oneCent := decimal.NewFromFloat(0.01)
 	for remainder.GreaterThan(decimal.Zero) {
-		result.PerHawbFees[i] = result.PerHawbFees[i].Add(oneCent)
+		perHawbFees[i%totalHawb] = perHawbFees[i%totalHawb].Add(oneCent)
 		remainder = remainder.Sub(oneCent)
 		i++
 	}
 
-	return result
+	return floorPerHawb, perHawbFees
 }
@@ -52,7 +52,7 @@ func (s *loggingService) UpdatePreImportManifestHeader(ctx context.Context, data
 	return s.next.UpdatePreImportManifestHeader(ctx, data)
 }
 
-func (s *loggingService) UploadManifestDetails(ctx context.Context, userUUID, headerUUID, originName, templateCode string, fileBytes []byte) (err error) {
+func (s *loggingService) UploadManifestDetails(ctx context.Context, userUUID, headerUUID, originName, templateCode string, fileBytes []byte) (result *UploadResultModel, err error) {
 	defer func(begin time.Time) {
 		s.logger.Log(
 			"method", "upload_manifest",
@@ -66,6 +66,19 @@ func (s *loggingService) UploadManifestDetails(ctx context.Context, userUUID, he
 	return s.next.UploadManifestDetails(ctx, userUUID, headerUUID, originName, templateCode, fileBytes)
 }
 
+func (s *loggingService) PreviewManifestDetails(ctx context.Context, headerUUID, templateCode string, fileBytes []byte) (result *UploadPreviewModel, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "preview_manifest",
+			"header_uuid", headerUUID,
+			"template_code", templateCode,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.PreviewManifestDetails(ctx, headerUUID, templateCode, fileBytes)
+}
+
 func (s *loggingService) DownloadPreImport(ctx context.Context, uploadLoggingUUID string) (fileName string, result *bytes.Buffer, err error) {
 	defer func(begin time.Time) {
 		s.logger.Log(
@@ -78,6 +91,18 @@ func (s *loggingService) DownloadPreImport(ctx context.Context, uploadLoggingUUI
 	return s.next.DownloadPreImport(ctx, uploadLoggingUUID)
 }
 
+func (s *loggingService) DownloadImportDeclaration(ctx context.Context, headerUUID string) (fileName string, result *bytes.Buffer, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "download_import_declaration",
+			"header_uuid", headerUUID,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.DownloadImportDeclaration(ctx, headerUUID)
+}
+
 func (s *loggingService) DownloadRawPreImport(ctx context.Context, uploadLoggingUUID string) (filename string, excelBuf *bytes.Buffer, err error) {
 	defer func(begin time.Time) {
 		s.logger.Log(
@@ -127,3 +152,16 @@ func (s *loggingService) GetSummaryByHeaderUUID(ctx context.Context, headerUUID
 	}(time.Now())
 	return s.next.GetSummaryByHeaderUUID(ctx, headerUUID)
 }
+
+func (s *loggingService) ImportPreAlert(ctx context.Context, userUUID, originName string, fileBytes []byte) (result []*PreAlertResultModel, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "import_prealert",
+			"userUUID", userUUID,
+			"originName", originName,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.ImportPreAlert(ctx, userUUID, originName, fileBytes)
+}
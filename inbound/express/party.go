@@ -0,0 +1,68 @@
+package inbound
+
+import (
+	"context"
+	"fmt"
+
+	"hpc-express-service/setting"
+	"hpc-express-service/utils"
+)
+
+// applyParties fills the shipper and consignee of the lines that reference the address book,
+// by party UUID or account number in the ShipperPartyID and ConsigneePartyID columns. The
+// party's values replace those of the file; its city goes to the province.
+func (s *service) applyParties(ctx context.Context, details []*utils.InsertPreImportDetailManifestModel) error {
+	parties := map[string]*setting.Party{}
+	find := func(ref, role string) (*setting.Party, error) {
+		key := role + "|" + ref
+		if party, ok := parties[key]; ok {
+			return party, nil
+		}
+		party, err := s.partySvc.FindPartyByRef(ctx, ref, role)
+		if err != nil {
+			return nil, err
+		}
+		if party == nil {
+			return nil, fmt.Errorf("party '%s' is not in the address book", ref)
+		}
+		parties[key] = party
+		return party, nil
+	}
+
+	for _, d := range details {
+		if d.ShipperPartyID != "" {
+			p, err := find(d.ShipperPartyID, setting.PartyRoleShipper)
+			if err != nil {
+				return fmt.Errorf("HAWB %s: %w", d.HouseAirWaybill, err)
+			}
+			setIfNotEmpty(&d.ShipperName, p.Name)
+			setIfNotEmpty(&d.ShipperAddress, p.Address())
+			setIfNotEmpty(&d.ShipperProvince, p.City)
+			setIfNotEmpty(&d.ShipperPostcode, p.Postcode)
+			setIfNotEmpty(&d.ShipperCountryCode, p.CountryCode)
+			setIfNotEmpty(&d.ShipperEmail, p.Email)
+			setIfNotEmpty(&d.ShipperPhoneNumber, p.Phone)
+		}
+		if d.ConsigneePartyID != "" {
+			p, err := find(d.ConsigneePartyID, setting.PartyRoleConsignee)
+			if err != nil {
+				return fmt.Errorf("HAWB %s: %w", d.HouseAirWaybill, err)
+			}
+			setIfNotEmpty(&d.ConsigneeName, p.Name)
+			setIfNotEmpty(&d.ConsigneeAddress, p.Address())
+			setIfNotEmpty(&d.ConsigneeProvince, p.City)
+			setIfNotEmpty(&d.ConsigneePostcode, p.Postcode)
+			setIfNotEmpty(&d.ConsigneeCountryCode, p.CountryCode)
+			setIfNotEmpty(&d.ConsigneeTax, p.TaxID)
+			setIfNotEmpty(&d.ConsigneeEmail, p.Email)
+			setIfNotEmpty(&d.ConsigneePhoneNumber, p.Phone)
+		}
+	}
+	return nil
+}
+
+func setIfNotEmpty(field *string, value string) {
+	if value != "" {
+		*field = value
+	}
+}
@@ -0,0 +1,137 @@
+package inbound
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"hpc-express-service/cargoimp"
+)
+
+// PreAlertModel is what an airline FFM or FWB announced for one MAWB. The totals are kept
+// to be compared against the uploaded manifest.
+type PreAlertModel struct {
+	HeaderUUID        string  `json:"headerUUID"`
+	Mawb              string  `json:"mawb"`
+	MessageType       string  `json:"messageType"`
+	FlightNo          string  `json:"flightNo"`
+	ArrivalDate       string  `json:"arrivalDate"`
+	OriginPort        string  `json:"originPort"`
+	DischargePort     string  `json:"dischargePort"`
+	Pieces            int64   `json:"pieces"`
+	TotalPieces       int64   `json:"totalPieces"`
+	GrossWeight       float64 `json:"grossWeight"`
+	WeightUnitCode    string  `json:"weightUnitCode"`
+	UploadLoggingUUID string  `json:"uploadLoggingUUID"`
+	CreatedAt         string  `json:"createdAt"`
+	UpdatedAt         string  `json:"updatedAt"`
+}
+
+// PreAlertResultModel reports the header created or updated for each MAWB of a message.
+type PreAlertResultModel struct {
+	HeaderUUID string         `json:"headerUUID"`
+	Created    bool           `json:"created"`
+	PreAlert   *PreAlertModel `json:"preAlert"`
+}
+
+// dischargePortCodes maps the airports we clear at onto their customs port codes.
+var dischargePortCodes = map[string]string{
+	"BKK": "1190",
+}
+
+// buildPreAlerts reads an FFM or FWB message into one pre-alert per MAWB, in the order the
+// MAWBs appear. Only FFM consignments unloaded at an airport we clear at are kept, and the
+// parts of a split shipment on the flight are added up. The arrival date is only set when
+// the message gives it for the point of unloading.
+func buildPreAlerts(message string, now time.Time) (string, []*PreAlertModel, error) {
+	switch {
+	case strings.HasPrefix(strings.ToUpper(strings.TrimSpace(message)), "FFM/"):
+		m, err := cargoimp.ParseFFM(message, now)
+		if err != nil {
+			return "", nil, err
+		}
+
+		alerts := []*PreAlertModel{}
+		byMawb := map[string]*PreAlertModel{}
+		for _, c := range m.Consignments {
+			if _, ok := dischargePortCodes[c.UnloadingPoint]; !ok {
+				continue
+			}
+
+			alert, ok := byMawb[c.Number()]
+			if !ok {
+				alert = &PreAlertModel{
+					Mawb:           c.Number(),
+					MessageType:    "FFM",
+					FlightNo:       m.Flight(),
+					OriginPort:     c.Origin,
+					DischargePort:  c.UnloadingPoint,
+					TotalPieces:    c.TotalPieces,
+					WeightUnitCode: weightUnitCode(c.WeightUnit),
+				}
+				if !c.ArrivalDate.IsZero() {
+					alert.ArrivalDate = c.ArrivalDate.Format("2006-01-02")
+				}
+				byMawb[c.Number()] = alert
+				alerts = append(alerts, alert)
+			}
+			alert.Pieces += c.Pieces
+			alert.GrossWeight += c.Weight
+		}
+		if len(alerts) == 0 {
+			return "", nil, errors.New("no consignment of the flight is unloaded at an airport we clear at")
+		}
+		return "FFM", alerts, nil
+
+	case strings.HasPrefix(strings.ToUpper(strings.TrimSpace(message)), "FWB/"):
+		w, err := cargoimp.ParseFWB(message)
+		if err != nil {
+			return "", nil, err
+		}
+		if _, ok := dischargePortCodes[w.Destination]; !ok {
+			return "", nil, fmt.Errorf("destination %s is not an airport we clear at", w.Destination)
+		}
+
+		alert := &PreAlertModel{
+			Mawb:           w.Number(),
+			MessageType:    "FWB",
+			OriginPort:     w.Origin,
+			DischargePort:  w.Destination,
+			Pieces:         w.Pieces,
+			TotalPieces:    w.Pieces,
+			GrossWeight:    w.Weight,
+			WeightUnitCode: weightUnitCode(w.WeightUnit),
+		}
+		// The last booked flight is the one arriving at destination. Its day is the departure
+		// day, so the arrival date is left for the FFM or the operator.
+		if len(w.Flights) > 0 {
+			f := w.Flights[len(w.Flights)-1]
+			alert.FlightNo = f.Carrier + f.Number
+		}
+		return "FWB", []*PreAlertModel{alert}, nil
+	}
+
+	return "", nil, errors.New("only FFM and FWB messages are supported")
+}
+
+func weightUnitCode(unit string) string {
+	if unit == "L" {
+		return "LBR"
+	}
+	return "KGM"
+}
+
+// applyPreAlert copies the flight, arrival and customs port of a pre-alert onto a header.
+// Fields the message does not carry are left empty, so the header keeps its own.
+func applyPreAlert(header *UpdatePreImportHeaderManifestModel, alert *PreAlertModel) error {
+	port, ok := dischargePortCodes[alert.DischargePort]
+	if !ok {
+		return fmt.Errorf("airport %s has no customs port code", alert.DischargePort)
+	}
+	header.DischargePort = port
+	header.FlightNo = alert.FlightNo
+	header.VasselName = alert.FlightNo
+	header.ArrivalDate = alert.ArrivalDate
+	return nil
+}
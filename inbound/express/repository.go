@@ -2,7 +2,9 @@ package inbound
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"hpc-express-service/common"
 	"hpc-express-service/utils"
 	"time"
 
@@ -14,10 +16,18 @@ type InboundExpressRepository interface {
 	GetAllMawb(ctx context.Context) ([]*GetPreImportManifestModel, error)
 	InsertPreImportManifestHeader(ctx context.Context, data *InsertPreImportHeaderManifestModel) (string, error)
 	UpdatePreImportManifestHeader(ctx context.Context, data *UpdatePreImportHeaderManifestModel) error
+	UpdatePreImportHeaderArrival(ctx context.Context, data *UpdatePreImportHeaderManifestModel) error
 	InsertPreImportManifestDetails(ctx context.Context, headerUUID string, details []*utils.InsertPreImportDetailManifestModel, chunkSize int) error
 	GetOneMawb(ctx context.Context, headerUUID string) (*GetPreImportManifestModel, error)
 	UpdatePreImportManifestDetail(ctx context.Context, headerUUID string, data []*UpdatePreImportManifestDetailModel) error
 	GetSummaryByHeaderUUID(ctx context.Context, headerUUID string) ([]*GetSummaryModel, error)
+	GetCurrencyCodes(ctx context.Context) ([]string, error)
+	GetDutyRates(ctx context.Context, tariffCodes []string) ([]*GetDutyRateModel, error)
+	GetHsCodeControls(ctx context.Context, tariffCodes []string) ([]*GetHsCodeControlModel, error)
+	GetCountryCodes(ctx context.Context) ([]string, error)
+	GetHeaderUUIDByMawb(ctx context.Context, mawb string) (string, error)
+	UpsertPreAlert(ctx context.Context, data *PreAlertModel) error
+	GetPreAlert(ctx context.Context, headerUUID string) (*PreAlertModel, error)
 }
 
 type repository struct {
@@ -90,6 +100,7 @@ func (r repository) GetAllMawb(ctx context.Context) ([]*GetPreImportManifestMode
 				mh.discharge_port,
 				mh.vassel_name,
 				mh.arrival_date,
+				COALESCE(mh.customer_uuid::text, '') AS customer_uuid,
 				mh.customer_name,
 				mh.flight_no,
 				mh.origin_country_code,
@@ -113,63 +124,38 @@ func (r repository) GetAllMawb(ctx context.Context) ([]*GetPreImportManifestMode
 }
 
 func (r repository) InsertPreImportManifestHeader(ctx context.Context, data *InsertPreImportHeaderManifestModel) (string, error) {
-	db := ctx.Value("postgreSQLConn").(*pg.DB)
-	ctx, _ = context.WithTimeout(context.Background(), 30*time.Second)
-
-	tx, err := db.Begin()
+	db, err := common.GetQer(ctx)
 	if err != nil {
 		return "", err
 	}
-	defer tx.Rollback()
 
-	// Insert HeaderManifest
 	var headerUUID string
-	{
-		sqlStr :=
-			`
-			INSERT INTO public.tbl_pre_import_manifest_headers
-				(
-					mawb, discharge_port, vassel_name, arrival_date, customer_name, flight_no,  origin_country_code, origin_currency_code, is_enable_customs_ot
-				)
-			VALUES
-				(
-					?, ?, ?, ?, ?, ?, ?, ?, ?
-				)
-			RETURNING uuid
-		`
-
-		// Prepare statement
-		stmt, err := tx.Prepare(utils.PrepareSQL(sqlStr))
-		if err != nil {
-			tx.Rollback()
-			return "", err
-		}
-		defer stmt.Close()
-
-		values := []interface{}{}
-		values = append(
-			values,
-			data.Mawb,
-			data.DischargePort,
-			data.VasselName,
-			data.ArrivalDate,
-			data.CustomerName,
-			data.FlightNo,
-			data.OriginCountryCode,
-			data.OriginCurrencyCode,
-			data.IsEnableCustomsOT,
-		)
-
-		_, err = stmt.QueryOneContext(ctx, &headerUUID, values...)
-
-		if err != nil {
-			tx.Rollback()
-			return "", err
-		}
+	_, err = db.QueryOne(pg.Scan(&headerUUID), `
+		INSERT INTO public.tbl_pre_import_manifest_headers
+			(
+				mawb, discharge_port, vassel_name, arrival_date, customer_uuid, customer_name, flight_no,  origin_country_code, origin_currency_code, is_enable_customs_ot
+			)
+		VALUES
+			(
+				?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+			)
+		RETURNING uuid
+	`,
+		data.Mawb,
+		data.DischargePort,
+		data.VasselName,
+		data.ArrivalDate,
+		utils.NewNullString(data.CustomerUUID),
+		data.CustomerName,
+		data.FlightNo,
+		data.OriginCountryCode,
+		data.OriginCurrencyCode,
+		data.IsEnableCustomsOT,
+	)
+	if err != nil {
+		return "", err
 	}
 
-	tx.Commit()
-
 	return headerUUID, nil
 }
 
@@ -190,6 +176,7 @@ func (r repository) UpdatePreImportManifestHeader(ctx context.Context, data *Upd
 					origin_country_code=?7,
 					origin_currency_code=?8,
 					is_enable_customs_ot=?9,
+					customer_uuid=?10,
 					updated_at = NOW()
 			WHERE "uuid" = ?0;
 		`,
@@ -203,6 +190,7 @@ func (r repository) UpdatePreImportManifestHeader(ctx context.Context, data *Upd
 		utils.NewNullString(data.OriginCountryCode),
 		utils.NewNullString(data.OriginCurrencyCode),
 		data.IsEnableCustomsOT,
+		utils.NewNullString(data.CustomerUUID),
 	)
 
 	if err != nil {
@@ -215,6 +203,40 @@ func (r repository) UpdatePreImportManifestHeader(ctx context.Context, data *Upd
 	return nil
 }
 
+// UpdatePreImportHeaderArrival sets the flight, arrival date and discharge port of a header,
+// keeping the stored value of every field left empty.
+func (r repository) UpdatePreImportHeaderArrival(ctx context.Context, data *UpdatePreImportHeaderManifestModel) error {
+	db, err := common.GetQer(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.ExecOne(`
+		UPDATE public.tbl_pre_import_manifest_headers
+			SET
+				discharge_port = COALESCE(NULLIF(?1, ''), discharge_port),
+				vassel_name = COALESCE(NULLIF(?2, ''), vassel_name),
+				arrival_date = COALESCE(NULLIF(?3, ''), arrival_date),
+				flight_no = COALESCE(NULLIF(?4, ''), flight_no),
+				updated_at = NOW()
+		WHERE "uuid" = ?0
+	`,
+		data.UUID,
+		data.DischargePort,
+		data.VasselName,
+		data.ArrivalDate,
+		data.FlightNo,
+	)
+	if err != nil {
+		if err == pg.ErrNoRows {
+			return errors.New("not found")
+		}
+		return err
+	}
+
+	return nil
+}
+
 func (r repository) InsertPreImportManifestDetails(ctx context.Context, headerUUID string, details []*utils.InsertPreImportDetailManifestModel, chunkSize int) error {
 	db := ctx.Value("postgreSQLConn").(*pg.DB)
 	ctx, _ = context.WithTimeout(context.Background(), 30*time.Second)
@@ -235,7 +257,7 @@ func (r repository) InsertPreImportManifestDetails(ctx context.Context, headerUU
 				`
 				INSERT INTO public.tbl_pre_import_manifest_details 
 					(
-						header_uuid, master_air_waybill, house_air_waybill, category, consignee_tax, consignee_branch, consignee_name, consignee_address, consignee_district, consignee_subprovince, consignee_province, consignee_postcode, consignee_country_code, consignee_email, consignee_phone_number, shipper_name, shipper_address, shipper_district, shipper_subprovince, shipper_province, shipper_postcode, shipper_country_code, shipper_email, shipper_phone_number, tariff_code, tariff_sequence, statistical_code, english_description_of_good, thai_description_of_good, quantity, quantity_unit_code, net_weight, net_weight_unit_code, gross_weight, gross_weight_unit_code, package, package_unit_code, cif_value_foreign, fob_value_foreign, exchange_rate, currency_code, shipping_mark, consignment_country, freight_value_foreign, freight_currency_code, insurance_value_foreign, insurance_currency_code, other_charge_value_foreign, other_charge_currency_code, invoice_no, invoice_date
+						header_uuid, master_air_waybill, house_air_waybill, category, consignee_tax, consignee_branch, consignee_name, consignee_address, consignee_district, consignee_subprovince, consignee_province, consignee_postcode, consignee_country_code, consignee_email, consignee_phone_number, shipper_name, shipper_address, shipper_district, shipper_subprovince, shipper_province, shipper_postcode, shipper_country_code, shipper_email, shipper_phone_number, tariff_code, tariff_sequence, statistical_code, english_description_of_good, thai_description_of_good, quantity, quantity_unit_code, net_weight, net_weight_unit_code, gross_weight, gross_weight_unit_code, package, package_unit_code, cif_value_foreign, fob_value_foreign, exchange_rate, currency_code, shipping_mark, consignment_country, freight_value_foreign, freight_currency_code, insurance_value_foreign, insurance_currency_code, other_charge_value_foreign, other_charge_currency_code, invoice_no, invoice_date, control_flags
 					) 
 					VALUES 
 			`
@@ -243,7 +265,12 @@ func (r repository) InsertPreImportManifestDetails(ctx context.Context, headerUU
 			for _, row := range chunkedRows {
 				row.HeaderUUID = headerUUID
 
-				sqlStr += "(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?),"
+				controlFlags, err := marshalControlFlags(row.ControlFlags)
+				if err != nil {
+					return err
+				}
+
+				sqlStr += "(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?),"
 				vals = append(vals,
 					utils.NewNullString(row.HeaderUUID),
 					utils.NewNullString(row.MasterAirWaybill),
@@ -296,6 +323,7 @@ func (r repository) InsertPreImportManifestDetails(ctx context.Context, headerUU
 					utils.NewNullString(row.OtherChargeCurrencyCode),
 					utils.NewNullString(row.InvoiceNo),
 					utils.NewNullString(row.InvoiceDate),
+					controlFlags,
 				)
 			}
 
@@ -339,10 +367,12 @@ func (r repository) GetOneMawb(ctx context.Context, headerUUID string) (*GetPreI
 		&result.DischargePort,
 		&result.VasselName,
 		&result.ArrivalDate,
+		&result.CustomerUUID,
 		&result.CustomerName,
 		&result.FlightNo,
 		&result.OriginCountryCode,
 		&result.OriginCurrencyCode,
+		&result.IsEnableCustomsOT,
 		&result.CreatedAt,
 		&result.UpdatedAt,
 	), `
@@ -353,10 +383,12 @@ func (r repository) GetOneMawb(ctx context.Context, headerUUID string) (*GetPreI
 				mh.discharge_port,
 				mh.vassel_name,
 				mh.arrival_date,
+				COALESCE(mh.customer_uuid::text, '') AS customer_uuid,
 				mh.customer_name,
 				mh.flight_no,
 				mh.origin_country_code,
 				mh.origin_currency_code,
+				mh.is_enable_customs_ot,
 				mh.created_at,
 				mh.updated_at
 			FROM public.tbl_pre_import_manifest_headers mh
@@ -422,8 +454,8 @@ func (r repository) GetOneMawb(ctx context.Context, headerUUID string) (*GetPreI
 			tpimd.invoice_date,
 			tpimd.created_at,
 			tpimd.updated_at,
-				tpimd.cif_value_foreign*0.07 as vat,
-				tpimd.cif_value_foreign*(mhcv.duty_rate/100) as duty,
+				COALESCE(mhcv.duty_rate, 0) as duty_rate,
+				COALESCE(tpimd.control_flags, '[]'::jsonb) as control_flags,
 				CASE
 						WHEN mhcv.duty_rate is NULL THEN FALSE
 						ELSE TRUE
@@ -432,7 +464,11 @@ func (r repository) GetOneMawb(ctx context.Context, headerUUID string) (*GetPreI
 		LEFT JOIN LATERAL (
 		    SELECT duty_rate
 		    FROM master_hs_code_v2
-		    WHERE TRIM(UPPER(goods_en)) = TRIM(UPPER(tpimd.english_description_of_good))
+		    WHERE TRIM(hs_code) = TRIM(tpimd.tariff_code)
+		    AND deleted_at IS NULL
+		    ORDER BY
+		        COALESCE(TRIM(stat), '') = COALESCE(TRIM(tpimd.statistical_code), '') DESC,
+		        COALESCE(TRIM(tariff), '') = COALESCE(TRIM(tpimd.tariff_sequence), '') DESC
 		    LIMIT 1
 		) mhcv ON true
 		WHERE tpimd.header_uuid = $1
@@ -612,18 +648,26 @@ func (r repository) GetSummaryByHeaderUUID(ctx context.Context, headerUUID strin
 		`
 		SELECT distinct
 			tpimd.house_air_waybill as hawb,
-			tpimd.category,
-			tpimd.cif_value_foreign*0.07 as vat,
-			tpimd.cif_value_foreign*(mhcv.duty_rate/100) as duty
+			tpimd.cif_value_foreign as cif,
+			COALESCE(tpimd.exchange_rate, 0) as exchange_rate,
+			COALESCE(tpimd.freight_currency_code, '') as freight_currency_code,
+			COALESCE(tpimd.insurance_currency_code, '') as insurance_currency_code,
+			COALESCE(mhcv.duty_rate, 0) as duty_rate,
+			COALESCE(tpimd.control_flags, '[]'::jsonb) as control_flags
 		FROM public.tbl_pre_import_manifest_headers mh
 		left join tbl_pre_import_manifest_details tpimd on tpimd.header_uuid = mh."uuid"
 		LEFT JOIN LATERAL (
 		    SELECT duty_rate
 		    FROM master_hs_code_v2
-		    WHERE TRIM(UPPER(goods_en)) = TRIM(UPPER(tpimd.english_description_of_good))
+		    WHERE TRIM(hs_code) = TRIM(tpimd.tariff_code)
+		    AND deleted_at IS NULL
+		    ORDER BY
+		        COALESCE(TRIM(stat), '') = COALESCE(TRIM(tpimd.statistical_code), '') DESC,
+		        COALESCE(TRIM(tariff), '') = COALESCE(TRIM(tpimd.tariff_sequence), '') DESC
 		    LIMIT 1
 		) mhcv ON true
 		where mh.uuid = ?0
+		and tpimd.uuid is not null
 	`, headerUUID)
 
 	if err != nil {
@@ -632,3 +676,248 @@ func (r repository) GetSummaryByHeaderUUID(ctx context.Context, headerUUID strin
 
 	return list, nil
 }
+
+// GetDutyRates returns the duty rate of every HS master row with one of tariffCodes.
+func (r repository) GetDutyRates(ctx context.Context, tariffCodes []string) ([]*GetDutyRateModel, error) {
+	db := ctx.Value("postgreSQLConn").(*pg.DB)
+	ctx, _ = context.WithTimeout(context.Background(), 5*time.Second)
+
+	var list []*GetDutyRateModel
+	if len(tariffCodes) == 0 {
+		return list, nil
+	}
+
+	_, err := db.QueryContext(ctx, &list, `
+		SELECT
+			TRIM(hs_code) AS tariff_code,
+			COALESCE(TRIM(tariff), '') AS tariff_sequence,
+			COALESCE(TRIM(stat), '') AS statistical_code,
+			duty_rate
+		FROM master_hs_code_v2
+		WHERE TRIM(hs_code) IN (?)
+		AND deleted_at IS NULL
+		ORDER BY id ASC
+	`, pg.In(tariffCodes))
+
+	if err != nil {
+		return list, err
+	}
+
+	return list, nil
+}
+
+// GetHsCodeControls returns the control limits of every HS master row with one of tariffCodes.
+func (r repository) GetHsCodeControls(ctx context.Context, tariffCodes []string) ([]*GetHsCodeControlModel, error) {
+	db := ctx.Value("postgreSQLConn").(*pg.DB)
+	ctx, _ = context.WithTimeout(context.Background(), 5*time.Second)
+
+	var list []*GetHsCodeControlModel
+	if len(tariffCodes) == 0 {
+		return list, nil
+	}
+
+	_, err := db.QueryContext(ctx, &list, `
+		SELECT
+			TRIM(hs_code) AS tariff_code,
+			COALESCE(TRIM(tariff), '') AS tariff_sequence,
+			COALESCE(TRIM(stat), '') AS statistical_code,
+			COALESCE(fob_price_control, 0) AS fob_price_control,
+			COALESCE(fob_price_control_origin_currency_code, '') AS fob_price_control_origin_currency_code,
+			COALESCE(fob_price_control_origin_country_code, '') AS fob_price_control_origin_country_code,
+			COALESCE(weight_control, 0) AS weight_control,
+			COALESCE(weight_control_unit_code, '') AS weight_control_unit_code,
+			COALESCE(cif_control, 0) AS cif_control,
+			COALESCE(cif_control_destination_currency_code, '') AS cif_control_destination_currency_code,
+			COALESCE(cif_control_destination_country_code, '') AS cif_control_destination_country_code
+		FROM master_hs_code_v2
+		WHERE TRIM(hs_code) IN (?)
+		AND deleted_at IS NULL
+		ORDER BY id ASC
+	`, pg.In(tariffCodes))
+
+	if err != nil {
+		return list, err
+	}
+
+	return list, nil
+}
+
+// marshalControlFlags stores no flags as NULL so clean lines stay clean in the table.
+func marshalControlFlags(flags []*utils.ControlFlag) (interface{}, error) {
+	if len(flags) == 0 {
+		return nil, nil
+	}
+
+	b, err := json.Marshal(flags)
+	if err != nil {
+		return nil, err
+	}
+
+	return string(b), nil
+}
+
+func (r repository) GetCurrencyCodes(ctx context.Context) ([]string, error) {
+	db := ctx.Value("postgreSQLConn").(*pg.DB)
+	ctx, _ = context.WithTimeout(context.Background(), 5*time.Second)
+
+	var list []string
+	_, err := db.QueryContext(ctx, pg.Array(&list), `
+		SELECT ARRAY(
+			SELECT DISTINCT UPPER(cer.currency_code)
+			FROM ship2cu.customs_exchange_rate cer
+			WHERE cer.is_enabled = true
+		)
+	`)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+func (r repository) GetCountryCodes(ctx context.Context) ([]string, error) {
+	db := ctx.Value("postgreSQLConn").(*pg.DB)
+	ctx, _ = context.WithTimeout(context.Background(), 5*time.Second)
+
+	var list []string
+	_, err := db.QueryContext(ctx, pg.Array(&list), `
+		SELECT ARRAY(
+			SELECT UPPER(cer.country_code) FROM ship2cu.customs_exchange_rate cer
+			UNION
+			SELECT UPPER(fz.country_code) FROM public.master_inbound_express_freight_zones fz
+		)
+	`)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// GetHeaderUUIDByMawb finds the latest header of a MAWB however its number was typed.
+// An empty uuid means there is none yet.
+func (r repository) GetHeaderUUIDByMawb(ctx context.Context, mawb string) (string, error) {
+	db, err := common.GetQer(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	var headerUUID string
+	_, err = db.QueryOne(pg.Scan(&headerUUID), `
+		SELECT mh."uuid"
+		FROM public.tbl_pre_import_manifest_headers mh
+		WHERE regexp_replace(mh.mawb, '[^0-9]', '', 'g') = regexp_replace(?, '[^0-9]', '', 'g')
+			AND mh.deleted_at IS NULL
+		ORDER BY mh.created_at DESC
+		LIMIT 1
+	`, mawb)
+
+	if err != nil {
+		if err == pg.ErrNoRows {
+			return "", nil
+		}
+		return "", err
+	}
+
+	return headerUUID, nil
+}
+
+// UpsertPreAlert keeps one pre-alert per header, message type and flight, so the parts of a
+// split shipment arriving on different flights are all kept and a message received again
+// replaces its own row.
+func (r repository) UpsertPreAlert(ctx context.Context, data *PreAlertModel) error {
+	db, err := common.GetQer(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
+		INSERT INTO public.tbl_pre_import_prealerts
+			(
+				header_uuid, mawb, message_type, flight_no, arrival_date, origin_port, discharge_port, pieces, total_pieces, gross_weight, weight_unit_code, upload_logging_uuid
+			)
+		VALUES
+			(
+				?0, ?1, ?2, ?3, ?4, ?5, ?6, ?7, ?8, ?9, ?10, ?11
+			)
+		ON CONFLICT (header_uuid, message_type, flight_no) DO UPDATE
+			SET
+				mawb = EXCLUDED.mawb,
+				arrival_date = EXCLUDED.arrival_date,
+				origin_port = EXCLUDED.origin_port,
+				discharge_port = EXCLUDED.discharge_port,
+				pieces = EXCLUDED.pieces,
+				total_pieces = EXCLUDED.total_pieces,
+				gross_weight = EXCLUDED.gross_weight,
+				weight_unit_code = EXCLUDED.weight_unit_code,
+				upload_logging_uuid = EXCLUDED.upload_logging_uuid,
+				updated_at = NOW()
+	`,
+		data.HeaderUUID,
+		data.Mawb,
+		data.MessageType,
+		data.FlightNo,
+		utils.NewNullString(data.ArrivalDate),
+		utils.NewNullString(data.OriginPort),
+		utils.NewNullString(data.DischargePort),
+		data.Pieces,
+		data.TotalPieces,
+		data.GrossWeight,
+		data.WeightUnitCode,
+		utils.NewNullString(data.UploadLoggingUUID),
+	)
+
+	return err
+}
+
+// GetPreAlert returns what was announced for a header, or nil when nothing was. The flight,
+// arrival and ports are those of the last message. When that is an FFM, pieces and weight add
+// up every flight manifested for the MAWB, as a split shipment arrives in parts; an FWB
+// already announces the whole shipment.
+func (r repository) GetPreAlert(ctx context.Context, headerUUID string) (*PreAlertModel, error) {
+	db, err := common.GetQer(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &PreAlertModel{}
+	_, err = db.QueryOne(result, `
+		WITH latest AS (
+			SELECT pa.*
+			FROM public.tbl_pre_import_prealerts pa
+			WHERE pa.header_uuid = ?
+			ORDER BY COALESCE(pa.updated_at, pa.created_at) DESC
+			LIMIT 1
+		)
+		SELECT
+			l.header_uuid,
+			l.mawb,
+			l.message_type,
+			l.flight_no,
+			COALESCE(l.arrival_date, '') AS arrival_date,
+			COALESCE(l.origin_port, '') AS origin_port,
+			COALESCE(l.discharge_port, '') AS discharge_port,
+			CASE WHEN l.message_type = 'FFM' THEN SUM(pa.pieces) ELSE l.pieces END AS pieces,
+			MAX(pa.total_pieces) AS total_pieces,
+			CASE WHEN l.message_type = 'FFM' THEN SUM(pa.gross_weight) ELSE l.gross_weight END AS gross_weight,
+			l.weight_unit_code,
+			COALESCE(l.upload_logging_uuid::text, '') AS upload_logging_uuid,
+			MIN(pa.created_at) AS created_at,
+			MAX(COALESCE(pa.updated_at, pa.created_at)) AS updated_at
+		FROM latest l
+		JOIN public.tbl_pre_import_prealerts pa ON pa.header_uuid = l.header_uuid AND pa.message_type = l.message_type
+		GROUP BY l.header_uuid, l.mawb, l.message_type, l.flight_no, l.arrival_date, l.origin_port,
+			l.discharge_port, l.pieces, l.gross_weight, l.weight_unit_code, l.upload_logging_uuid
+	`, headerUUID)
+
+	if err != nil {
+		if err == pg.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return result, nil
+}
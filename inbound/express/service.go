@@ -7,12 +7,19 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"log"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/xuri/excelize/v2"
 
-	"hpc-express-service/ship2cu"
+	"hpc-express-service/common"
+	"hpc-express-service/customer"
+	"hpc-express-service/ecustoms"
+	"hpc-express-service/hsmatch"
+	"hpc-express-service/setting"
+	"hpc-express-service/tax"
 	"hpc-express-service/uploadlog"
 	"hpc-express-service/utils"
 )
@@ -22,35 +29,61 @@ type InboundExpressService interface {
 	// GetMawb(ctx context.Context, uuid string)
 	InsertPreImportManifestHeader(ctx context.Context, data *InsertPreImportHeaderManifestModel) (string, error)
 	UpdatePreImportManifestHeader(ctx context.Context, data *UpdatePreImportHeaderManifestModel) error
-	UploadManifestDetails(ctx context.Context, userUUID, headerUUID, originName, templateCode string, fileBytes []byte) error
+	UploadManifestDetails(ctx context.Context, userUUID, headerUUID, originName, templateCode string, fileBytes []byte) (*UploadResultModel, error)
+	PreviewManifestDetails(ctx context.Context, headerUUID, templateCode string, fileBytes []byte) (*UploadPreviewModel, error)
 	DownloadPreImport(ctx context.Context, headerUUID string) (string, *bytes.Buffer, error)
 	DownloadRawPreImport(ctx context.Context, headerUUID string) (string, *bytes.Buffer, error)
+	DownloadImportDeclaration(ctx context.Context, headerUUID string) (string, *bytes.Buffer, error)
 	UploadUpdateRawPreImport(ctx context.Context, userUUID, headerUUID, originName string, fileBytes []byte) error
 	GetOneByHeaderUUID(ctx context.Context, headerUUID string) (*GetPreImportManifestModel, error)
 	GetSummaryByHeaderUUID(ctx context.Context, headerUUID string) (*UploadSummaryModel, error)
+	ImportPreAlert(ctx context.Context, userUUID, originName string, fileBytes []byte) ([]*PreAlertResultModel, error)
 }
 
 type service struct {
 	selfRepo       InboundExpressRepository
 	contextTimeout time.Duration
-	ship2cuSvc     ship2cu.Service
+	converters     *ConverterRegistry
+	templateSvc    setting.ConvertTemplateService
+	feeScheduleSvc setting.FeeScheduleService
 	uploadlogSvc   uploadlog.Service
-	ship2cuRepo    ship2cu.Repository
+	commonSvc      common.Service
+	customerSvc    customer.Service
+	aliasSvc       setting.HsCodeAliasService
+	airlineSvc     setting.AirlineService
+	partySvc       setting.PartyService
+	declarations   *ecustoms.Marshaller
+	taxEngine      *tax.Engine
 }
 
 func NewInboundExpressService(
 	selfRepo InboundExpressRepository,
 	timeout time.Duration,
-	ship2cuSvc ship2cu.Service,
+	converters *ConverterRegistry,
+	templateSvc setting.ConvertTemplateService,
+	feeScheduleSvc setting.FeeScheduleService,
 	uploadlogSvc uploadlog.Service,
-	ship2cuRepo ship2cu.Repository,
+	commonSvc common.Service,
+	customerSvc customer.Service,
+	aliasSvc setting.HsCodeAliasService,
+	airlineSvc setting.AirlineService,
+	partySvc setting.PartyService,
+	declarations *ecustoms.Marshaller,
 ) InboundExpressService {
 	return &service{
 		selfRepo:       selfRepo,
 		contextTimeout: timeout,
-		ship2cuSvc:     ship2cuSvc,
+		converters:     converters,
+		templateSvc:    templateSvc,
+		feeScheduleSvc: feeScheduleSvc,
 		uploadlogSvc:   uploadlogSvc,
-		ship2cuRepo:    ship2cuRepo,
+		commonSvc:      commonSvc,
+		customerSvc:    customerSvc,
+		aliasSvc:       aliasSvc,
+		airlineSvc:     airlineSvc,
+		partySvc:       partySvc,
+		declarations:   declarations,
+		taxEngine:      tax.NewEngine(),
 	}
 }
 
@@ -70,6 +103,15 @@ func (s *service) InsertPreImportManifestHeader(ctx context.Context, data *Inser
 	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
 	defer cancel()
 
+	mawb, _, err := s.airlineSvc.ResolveMawb(ctx, data.Mawb)
+	if err != nil {
+		return "", err
+	}
+	data.Mawb = mawb
+	if err := s.resolveCustomer(ctx, &data.CustomerUUID, &data.CustomerName); err != nil {
+		return "", err
+	}
+
 	uuid, err := s.selfRepo.InsertPreImportManifestHeader(ctx, data)
 	if err != nil {
 		return "", err
@@ -82,7 +124,16 @@ func (s *service) UpdatePreImportManifestHeader(ctx context.Context, data *Updat
 	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
 	defer cancel()
 
-	err := s.selfRepo.UpdatePreImportManifestHeader(ctx, data)
+	mawb, _, err := s.airlineSvc.ResolveMawb(ctx, data.Mawb)
+	if err != nil {
+		return err
+	}
+	data.Mawb = mawb
+	if err := s.resolveCustomer(ctx, &data.CustomerUUID, &data.CustomerName); err != nil {
+		return err
+	}
+
+	err = s.selfRepo.UpdatePreImportManifestHeader(ctx, data)
 	if err != nil {
 		return err
 	}
@@ -90,57 +141,272 @@ func (s *service) UpdatePreImportManifestHeader(ctx context.Context, data *Updat
 	return nil
 }
 
-func (s *service) UploadManifestDetails(ctx context.Context, userUUID, headerUUID, originName, templateCode string, fileBytes []byte) error {
+// resolveCustomer keys the header on the customer picked by UUID, or on the only customer with
+// the name typed. Customer fee overrides follow the UUID, so renaming the customer keeps them.
+func (s *service) resolveCustomer(ctx context.Context, customerUUID, customerName *string) error {
+	c, err := s.customerSvc.ResolveCustomer(ctx, *customerUUID, *customerName)
+	if err != nil {
+		return err
+	}
+	if c == nil {
+		*customerUUID = ""
+		return nil
+	}
+	*customerUUID, *customerName = c.UUID, c.Name
+	return nil
+}
+
+func (s *service) UploadManifestDetails(ctx context.Context, userUUID, headerUUID, originName, templateCode string, fileBytes []byte) (*UploadResultModel, error) {
 	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
 	defer cancel()
 
-	if templateCode == "SHIP2CU" {
-		// Logging and Upload to GCS
-		uploadLogUUID, err := s.uploadlogSvc.UploadLogFile(ctx, &uploadlog.UploadFileModel{
-			// Mawb:         "", // TODO:
-			UserUUID:     userUUID,
-			FileName:     originName,
-			TemplateCode: templateCode,
-			Category:     "inbound",
-			SubCategory:  "upload_manifest",
-			FileBytes:    fileBytes,
-			// Amount:       0,
+	mawbInfo, err := s.selfRepo.GetOneMawb(ctx, headerUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	converter, err := s.getConverter(ctx, templateCode)
+	if err != nil {
+		return nil, err
+	}
+
+	arrivalDate, err := parseArrivalDate(mawbInfo.ArrivalDate)
+	if err != nil {
+		return nil, err
+	}
+
+	// Logging and Upload to GCS
+	uploadLogUUID, err := s.uploadlogSvc.UploadLogFile(ctx, &uploadlog.UploadFileModel{
+		// Mawb:         "", // TODO:
+		UserUUID:     userUUID,
+		FileName:     originName,
+		TemplateCode: templateCode,
+		Category:     "inbound",
+		SubCategory:  "upload_manifest",
+		FileBytes:    fileBytes,
+		// Amount:       0,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate every row before anything is inserted
+	report, err := s.validateManifest(ctx, converter, fileBytes)
+	if err != nil {
+		s.uploadlogSvc.Update(ctx, &uploadlog.UpdateModel{
+			UUID:   uploadLogUUID,
+			Status: "failed",
+			Remark: err.Error(),
 		})
-		if err != nil {
-			return err
+		return nil, err
+	}
+	if report.HasIssues() {
+		report.UploadLogUUID = uploadLogUUID
+		s.saveValidationReport(ctx, uploadLogUUID, originName, templateCode, report, fileBytes)
+		s.uploadlogSvc.Update(ctx, &uploadlog.UpdateModel{
+			UUID:   uploadLogUUID,
+			Status: "failed",
+			Remark: report.Error(),
+		})
+		return nil, report
+	}
+
+	// Insert Manifest
+	details, err := converter.ConvertToPreImportDetails(ctx, uploadLogUUID, arrivalDate, fileBytes)
+	if err != nil {
+		s.uploadlogSvc.Update(ctx, &uploadlog.UpdateModel{
+			UUID:   uploadLogUUID,
+			Mawb:   "",
+			Amount: 0,
+			Status: "failed",
+			Remark: err.Error(),
+		})
+		return nil, err
+	}
+	if err := s.applyParties(ctx, details); err != nil {
+		s.uploadlogSvc.Update(ctx, &uploadlog.UpdateModel{
+			UUID:   uploadLogUUID,
+			Status: "failed",
+			Remark: err.Error(),
+		})
+		return nil, err
+	}
+
+	// Category follows our own tax assessment, never the value in the file
+	for _, d := range details {
+		d.Category = s.taxEngine.Category(cifBaht(d.CifValueForeign, d.ExchangeRate, d.FreightCurrencyCode, d.InsuranceCurrencyCode))
+	}
+
+	if err := s.checkControls(ctx, details, arrivalDate); err != nil {
+		s.uploadlogSvc.Update(ctx, &uploadlog.UpdateModel{
+			UUID:   uploadLogUUID,
+			Status: "failed",
+			Remark: err.Error(),
+		})
+		return nil, err
+	}
+
+	err = s.selfRepo.InsertPreImportManifestDetails(ctx, headerUUID, details, 200)
+	if err != nil {
+		return nil, err
+	}
+
+	s.uploadlogSvc.Update(ctx, &uploadlog.UpdateModel{
+		UUID: uploadLogUUID,
+		// Mawb:   resultUpload.Mawb,
+		Amount: int64(len(details)),
+		Status: "success",
+	})
+
+	result := &UploadResultModel{
+		Amount:        len(details),
+		HsCodeReviews: []*HsCodeReviewModel{},
+	}
+	for _, d := range details {
+		if len(d.HsCodeCandidates) == 0 {
+			continue
 		}
+		result.HsCodeReviews = append(result.HsCodeReviews, &HsCodeReviewModel{
+			HouseAirWaybill:    d.HouseAirWaybill,
+			EnglishDescription: d.EnglishDescriptionOfGood,
+			Candidates:         d.HsCodeCandidates,
+		})
+	}
 
-		// Insert Manifest
-		details, err := s.ship2cuSvc.ConvertToPreImportDetails(ctx, uploadLogUUID, fileBytes)
-		if err != nil {
-			s.uploadlogSvc.Update(ctx, &uploadlog.UpdateModel{
-				UUID:   uploadLogUUID,
-				Mawb:   "",
-				Amount: 0,
-				Status: "failed",
-				Remark: err.Error(),
-			})
-			return err
-		} else {
+	return result, nil
+}
 
-			err := s.selfRepo.InsertPreImportManifestDetails(ctx, headerUUID, details, 200)
-			if err != nil {
-				return err
-			}
+// PreviewManifestDetails runs the same validation, conversion and fee calculation as
+// UploadManifestDetails without inserting details or creating an upload log.
+func (s *service) PreviewManifestDetails(ctx context.Context, headerUUID, templateCode string, fileBytes []byte) (*UploadPreviewModel, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
 
-			s.uploadlogSvc.Update(ctx, &uploadlog.UpdateModel{
-				UUID: uploadLogUUID,
-				// Mawb:   resultUpload.Mawb,
-				Amount: int64(len(details)),
-				Status: "success",
-			})
+	mawbInfo, err := s.selfRepo.GetOneMawb(ctx, headerUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	converter, err := s.getConverter(ctx, templateCode)
+	if err != nil {
+		return nil, err
+	}
+
+	arrivalDate, err := parseArrivalDate(mawbInfo.ArrivalDate)
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := s.validateManifest(ctx, converter, fileBytes)
+	if err != nil {
+		return nil, err
+	}
+	if report.HasIssues() {
+		return nil, report
+	}
+
+	details, err := converter.ConvertToPreImportDetails(ctx, "", arrivalDate, fileBytes)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applyParties(ctx, details); err != nil {
+		return nil, err
+	}
+
+	dutyRates, err := s.getDutyRates(ctx, details)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkControls(ctx, details, arrivalDate); err != nil {
+		return nil, err
+	}
+
+	result := &UploadPreviewModel{
+		Details: make([]*PreviewDetailModel, 0, len(details)),
+	}
+	summaryList := make([]*GetSummaryModel, 0, len(details))
+	for _, d := range details {
+		var dutyRate float64
+		if v, ok := dutyRates.Get(d.TariffCode, d.StatisticalCode, d.TariffSequence); ok {
+			dutyRate = v.DutyRate
+		}
+		breakdown := s.taxEngine.Assess(cifBaht(d.CifValueForeign, d.ExchangeRate, d.FreightCurrencyCode, d.InsuranceCurrencyCode), dutyRate)
+		row := &PreviewDetailModel{
+			MasterAirWaybill:      d.MasterAirWaybill,
+			HouseAirWaybill:       d.HouseAirWaybill,
+			Category:              breakdown.Category,
+			EnglishDescription:    d.EnglishDescriptionOfGood,
+			TariffCode:            d.TariffCode,
+			TariffSequence:        d.TariffSequence,
+			StatisticalCode:       d.StatisticalCode,
+			Quantity:              d.Quantity,
+			NetWeight:             d.NetWeight,
+			CurrencyCode:          d.CurrencyCode,
+			ExchangeRate:          d.ExchangeRate,
+			FobValueForeign:       d.FobValueForeign,
+			FreightValueForeign:   d.FreightValueForeign,
+			InsuranceValueForeign: d.InsuranceValueForeign,
+			CifValueForeign:       d.CifValueForeign,
+			DutyRate:              breakdown.DutyRate,
+			Vat:                   breakdown.Vat,
+			Duty:                  breakdown.Duty,
+			ControlFlags:          d.ControlFlags,
+			HsCodeCandidates:      d.HsCodeCandidates,
 		}
-		// }
-	} else {
-		return errors.New("not found template")
+		result.Details = append(result.Details, row)
+		summaryList = append(summaryList, &GetSummaryModel{
+			Hawb:         row.HouseAirWaybill,
+			Cif:          row.CifValueForeign,
+			DutyRate:     row.DutyRate,
+			Category:     row.Category,
+			Vat:          row.Vat,
+			Duty:         row.Duty,
+			ControlFlags: row.ControlFlags,
+		})
 	}
+	schedules, err := s.feeScheduleSvc.GetEffectiveFeeSchedules(ctx, mawbInfo.CustomerUUID, arrivalDate)
+	if err != nil {
+		return nil, err
+	}
+	result.Summary = buildUploadSummary(summaryList, mawbInfo.IsEnableCustomsOT, schedules)
 
-	return nil
+	return result, nil
+}
+
+// saveValidationReport keeps the report and an annotated copy of the upload on the upload log.
+// Failing to store it must not hide the validation result from the uploader.
+func (s *service) saveValidationReport(ctx context.Context, uploadLogUUID, originName, templateCode string, report *utils.ValidationReport, fileBytes []byte) {
+	annotated, err := report.AnnotateExcel(fileBytes)
+	if err != nil {
+		log.Println("AnnotateExcel: ", err.Error())
+		annotated = &bytes.Buffer{}
+	}
+
+	err = s.uploadlogSvc.SaveValidationReport(ctx, &uploadlog.SaveValidationReportModel{
+		UUID:         uploadLogUUID,
+		FileName:     originName,
+		TemplateCode: templateCode,
+		Category:     "inbound",
+		Report:       report,
+		FileBytes:    annotated.Bytes(),
+	})
+	if err != nil {
+		log.Println("SaveValidationReport: ", err.Error())
+	}
+}
+
+// getConverter prefers a registered converter and falls back to the column mapping stored on the template.
+func (s *service) getConverter(ctx context.Context, templateCode string) (PreImportConverter, error) {
+	if converter, err := s.converters.Get(templateCode); err == nil {
+		return converter, nil
+	}
+
+	template, err := s.templateSvc.GetConvertTemplateByCode(ctx, templateCode)
+	if err != nil || template.Mapping == nil || template.Type != "inbound" {
+		return nil, errors.New("not found template")
+	}
+
+	return s.converters.Mapped(template.Code, template.Mapping), nil
 }
 
 func (s *service) DownloadPreImport(ctx context.Context, headerUUID string) (string, *bytes.Buffer, error) {
@@ -167,6 +433,11 @@ func (s *service) DownloadPreImport(ctx context.Context, headerUUID string) (str
 	// 	mawbInfo = &utils.GetMawb{}
 	// }
 
+	// Re-value with the rate in force on the arrival date so a re-download matches the original declaration
+	if err := s.revalueDetails(ctx, preImportData); err != nil {
+		return "", nil, err
+	}
+
 	// Create in-memory ZIP buffer
 	var zipBuf bytes.Buffer
 	zipWriter := zip.NewWriter(&zipBuf)
@@ -400,6 +671,59 @@ func (s *service) DownloadRawPreImport(ctx context.Context, headerUUID string) (
 	return fileName, &excelBuf, nil
 }
 
+// DownloadImportDeclaration builds the e-Import declaration XML of every HAWB of a header and zips them.
+// Every declaration is checked against the import schema first; the errors of all HAWBs are returned together.
+func (s *service) DownloadImportDeclaration(ctx context.Context, headerUUID string) (string, *bytes.Buffer, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	preImportData, err := s.selfRepo.GetOneMawb(ctx, headerUUID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if err := s.revalueDetails(ctx, preImportData); err != nil {
+		return "", nil, err
+	}
+
+	declarations, err := buildImportDeclarations(preImportData, time.Now())
+	if err != nil {
+		return "", nil, err
+	}
+
+	var zipBuf bytes.Buffer
+	zipWriter := zip.NewWriter(&zipBuf)
+	problems := []string{}
+	for _, d := range declarations {
+		xmlBytes, err := s.declarations.MarshalImportDeclaration(d)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("HAWB %s: %v", d.Header.HouseAirWaybill, err))
+			continue
+		}
+
+		header := &zip.FileHeader{
+			Name:   fmt.Sprintf("import_%v_%v.xml", preImportData.Mawb, d.Header.HouseAirWaybill),
+			Method: zip.Deflate,
+		}
+		zipFileWriter, err := zipWriter.CreateHeader(header)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to create ZIP entry: %w", err)
+		}
+		if _, err := zipFileWriter.Write(xmlBytes); err != nil {
+			return "", nil, err
+		}
+	}
+	if len(problems) > 0 {
+		return "", nil, errors.New(strings.Join(problems, " | "))
+	}
+
+	if err := zipWriter.Close(); err != nil {
+		return "", nil, err
+	}
+
+	return fmt.Sprintf("import_declaration_%v", preImportData.Mawb), &zipBuf, nil
+}
+
 func (s *service) UploadUpdateRawPreImport(ctx context.Context, userUUID, headerUUID, originName string, fileBytes []byte) error {
 	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
 	defer cancel()
@@ -543,10 +867,20 @@ func (s *service) UploadUpdateRawPreImport(ctx context.Context, userUUID, header
 		listUpdateData = append(listUpdateData, &data)
 	}
 
+	// Keep the details as converted so HS code corrections can be learned as aliases
+	current, err := s.selfRepo.GetOneMawb(ctx, headerUUID)
+	if err != nil {
+		return err
+	}
+
 	if err := s.selfRepo.UpdatePreImportManifestDetail(ctx, headerUUID, listUpdateData); err != nil {
 		return err
 	}
 
+	if err := s.recordHsCodeCorrections(ctx, userUUID, current.Details, listUpdateData); err != nil {
+		log.Println("recordHsCodeCorrections: ", err.Error())
+	}
+
 	err = s.uploadlogSvc.Update(ctx, &uploadlog.UpdateModel{
 		UUID: uploadLogUUID,
 		// Mawb:   v.Mawb,
@@ -560,6 +894,42 @@ func (s *service) UploadUpdateRawPreImport(ctx context.Context, userUUID, header
 	return nil
 }
 
+// recordHsCodeCorrections learns an alias for every detail whose HS code was changed in the
+// uploaded raw pre-import, keyed on the goods text, shipper and origin the detail was converted from.
+func (s *service) recordHsCodeCorrections(ctx context.Context, userUUID string, before []*GetPreImportManifestDetilModel, after []*UpdatePreImportManifestDetailModel) error {
+	beforeByUUID := map[string]*GetPreImportManifestDetilModel{}
+	for _, v := range before {
+		beforeByUUID[v.UUID] = v
+	}
+
+	for _, v := range after {
+		old, ok := beforeByUUID[strings.TrimSpace(v.UUID)]
+		if !ok || strings.TrimSpace(v.TariffCode) == "" || hsmatch.Normalize(old.EnglishDescriptionOfGood) == "" {
+			continue
+		}
+		if strings.TrimSpace(v.TariffCode) == old.TariffCode &&
+			strings.TrimSpace(v.TariffSequence) == old.TariffSequence &&
+			strings.TrimSpace(v.StatisticalCode) == old.StatisticalCode {
+			continue
+		}
+
+		_, err := s.aliasSvc.RecordHsCodeCorrection(ctx, &setting.HsCodeAlias{
+			GoodsText:         old.EnglishDescriptionOfGood,
+			ShipperName:       old.ShipperName,
+			OriginCountryCode: old.ShipperCountryCode,
+			TariffCode:        v.TariffCode,
+			TariffSequence:    v.TariffSequence,
+			StatisticalCode:   v.StatisticalCode,
+			CreatedBy:         userUUID,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (s *service) GetOneByHeaderUUID(ctx context.Context, headerUUID string) (*GetPreImportManifestModel, error) {
 	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
 	defer cancel()
@@ -569,9 +939,121 @@ func (s *service) GetOneByHeaderUUID(ctx context.Context, headerUUID string) (*G
 		return nil, err
 	}
 
+	for _, v := range result.Details {
+		breakdown := s.taxEngine.Assess(cifBaht(v.CifValueForeign, v.ExchangeRate, v.FreightCurrencyCode, v.InsuranceCurrencyCode), v.DutyRate)
+		v.Category = breakdown.Category
+		v.Vat = breakdown.Vat
+		v.Duty = breakdown.Duty
+	}
+
+	result.PreAlert, err = s.selfRepo.GetPreAlert(ctx, headerUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	airline, err := s.airlineSvc.GetAirlineByMawb(ctx, result.Mawb)
+	if err != nil {
+		return nil, err
+	}
+	if airline != nil {
+		result.AirlineName = airline.Name
+		result.AirlineLogo = airline.LogoURL
+	}
+
 	return result, nil
 }
 
+// ImportPreAlert reads an airline FFM or FWB and creates or updates the header of every MAWB
+// in it with the flight, arrival date and discharge port. The announced pieces and weight
+// are kept with the header. All headers are written in one transaction, so a failing MAWB
+// leaves none of the message applied.
+func (s *service) ImportPreAlert(ctx context.Context, userUUID, originName string, fileBytes []byte) ([]*PreAlertResultModel, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	messageType, alerts, err := buildPreAlerts(string(fileBytes), time.Now())
+	if err != nil {
+		return nil, err
+	}
+	headers := make([]*UpdatePreImportHeaderManifestModel, len(alerts))
+	for i, alert := range alerts {
+		if alert.Mawb, _, err = s.airlineSvc.ResolveMawb(ctx, alert.Mawb); err != nil {
+			return nil, err
+		}
+		headers[i] = &UpdatePreImportHeaderManifestModel{Mawb: alert.Mawb}
+		if err := applyPreAlert(headers[i], alert); err != nil {
+			return nil, fmt.Errorf("MAWB %s: %v", alert.Mawb, err)
+		}
+	}
+
+	mawb := ""
+	if len(alerts) == 1 {
+		mawb = alerts[0].Mawb
+	}
+	uploadLogUUID, err := s.uploadlogSvc.UploadLogFile(ctx, &uploadlog.UploadFileModel{
+		Mawb:         mawb,
+		UserUUID:     userUUID,
+		FileName:     originName,
+		TemplateCode: messageType,
+		Category:     "inbound",
+		SubCategory:  "upload_prealert",
+		FileBytes:    fileBytes,
+		Amount:       int64(len(alerts)),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tx, txCtx, err := common.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	results := []*PreAlertResultModel{}
+	for i, alert := range alerts {
+		header := headers[i]
+		header.UUID, err = s.selfRepo.GetHeaderUUIDByMawb(txCtx, alert.Mawb)
+		if err != nil {
+			return nil, err
+		}
+
+		result := &PreAlertResultModel{PreAlert: alert}
+		if header.UUID != "" {
+			if err := s.selfRepo.UpdatePreImportHeaderArrival(txCtx, header); err != nil {
+				return nil, err
+			}
+		} else {
+			header.UUID, err = s.selfRepo.InsertPreImportManifestHeader(txCtx, &InsertPreImportHeaderManifestModel{
+				Mawb:          header.Mawb,
+				DischargePort: header.DischargePort,
+				VasselName:    header.VasselName,
+				ArrivalDate:   header.ArrivalDate,
+				FlightNo:      header.FlightNo,
+			})
+			if err != nil {
+				return nil, err
+			}
+			result.Created = true
+		}
+
+		alert.HeaderUUID = header.UUID
+		alert.UploadLoggingUUID = uploadLogUUID
+		if err := s.selfRepo.UpsertPreAlert(txCtx, alert); err != nil {
+			return nil, err
+		}
+
+		result.HeaderUUID = header.UUID
+		results = append(results, result)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
 func (s *service) GetSummaryByHeaderUUID(ctx context.Context, headerUUID string) (*UploadSummaryModel, error) {
 	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
 	defer cancel()
@@ -585,16 +1067,40 @@ func (s *service) GetSummaryByHeaderUUID(ctx context.Context, headerUUID string)
 	if err != nil {
 		return nil, err
 	}
+	for _, v := range list {
+		breakdown := s.taxEngine.Assess(cifBaht(v.Cif, v.ExchangeRate, v.FreightCurrencyCode, v.InsuranceCurrencyCode), v.DutyRate)
+		v.Category = breakdown.Category
+		v.Vat = breakdown.Vat
+		v.Duty = breakdown.Duty
+	}
+
+	arrivalDate, err := parseArrivalDate(mawbInfo.ArrivalDate)
+	if err != nil {
+		return nil, err
+	}
+	schedules, err := s.feeScheduleSvc.GetEffectiveFeeSchedules(ctx, mawbInfo.CustomerUUID, arrivalDate)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildUploadSummary(list, mawbInfo.IsEnableCustomsOT, schedules), nil
+}
+
+// buildUploadSummary spreads the per-MAWB fees over the HAWBs and totals duty and VAT per category.
+func buildUploadSummary(list []*GetSummaryModel, isEnableCustomsOT bool, schedules map[string]*setting.FeeSchedule) *UploadSummaryModel {
+	if len(list) == 0 {
+		return &UploadSummaryModel{}
+	}
 
 	totalHawb := len(list)
-	customFee := calcCustomsFee(int(totalHawb))
+	customFee := calcCustomsFee(schedules[setting.FeeCodeCustoms], int(totalHawb))
 	otCustomsFee := &OTCustomFeeModel{}
-	if mawbInfo.IsEnableCustomsOT {
-		otCustomsFee = calcOTCustomsFee(int(totalHawb))
+	if isEnableCustomsOT {
+		otCustomsFee = calcOTCustomsFee(schedules[setting.FeeCodeOTCustoms], int(totalHawb))
 	}
-	bankFee := calcBankFee(int(totalHawb))
-	cargoPermitFee := calcCargoPermitFee(int(totalHawb))
-	expressDelvieryFee := calcExpressDeliveryFee(int(totalHawb))
+	bankFee := calcBankFee(schedules[setting.FeeCodeBank], int(totalHawb))
+	cargoPermitFee := calcCargoPermitFee(schedules[setting.FeeCodeCargoPermit], int(totalHawb))
+	expressDelvieryFee := calcExpressDeliveryFee(schedules[setting.FeeCodeExpressDelivery], int(totalHawb))
 
 	result := &UploadSummaryModel{}
 	cat2 := &CatogorySummaryModel{Category: "2"}
@@ -606,7 +1112,7 @@ func (s *service) GetSummaryByHeaderUUID(ctx context.Context, headerUUID string)
 			cat2.Total++
 			cat2.Vat += v.Vat
 			cat2.CustomFee = cat2.CustomFee.Add(customFee.PerHawbFees[i])
-			if mawbInfo.IsEnableCustomsOT {
+			if isEnableCustomsOT {
 				cat2.OTCustomsFee = cat2.OTCustomsFee.Add(otCustomsFee.PerHawbFees[i])
 			}
 			cat2.BankFee = cat2.BankFee.Add(bankFee.PerHawbFees[i])
@@ -618,7 +1124,7 @@ func (s *service) GetSummaryByHeaderUUID(ctx context.Context, headerUUID string)
 			cat3.Duty += v.Duty
 			cat3.DutyAndVat += (v.Duty + v.Vat)
 			cat3.CustomFee = cat3.CustomFee.Add(customFee.PerHawbFees[i])
-			if mawbInfo.IsEnableCustomsOT {
+			if isEnableCustomsOT {
 				cat3.OTCustomsFee = cat3.OTCustomsFee.Add(otCustomsFee.PerHawbFees[i])
 			}
 			cat3.BankFee = cat3.BankFee.Add(bankFee.PerHawbFees[i])
@@ -630,7 +1136,7 @@ func (s *service) GetSummaryByHeaderUUID(ctx context.Context, headerUUID string)
 			otherCatogory.Duty += v.Duty
 			otherCatogory.DutyAndVat += (v.Duty + v.Vat)
 			otherCatogory.CustomFee = otherCatogory.CustomFee.Add(customFee.PerHawbFees[i])
-			if mawbInfo.IsEnableCustomsOT {
+			if isEnableCustomsOT {
 				otherCatogory.OTCustomsFee = otherCatogory.OTCustomsFee.Add(otCustomsFee.PerHawbFees[i])
 			}
 			otherCatogory.BankFee = otherCatogory.BankFee.Add(bankFee.PerHawbFees[i])
@@ -647,8 +1153,145 @@ func (s *service) GetSummaryByHeaderUUID(ctx context.Context, headerUUID string)
 	result.Catogory2 = cat2
 	result.Catogory3 = cat3
 	result.OtherCatogory = otherCatogory
+	result.ControlFlags = summarizeControlFlags(list)
 	result.TotalTax = cat2.Vat + cat3.DutyAndVat
 	result.TotalHawb = cat2.Total + cat3.Total + otherCatogory.Total
 
-	return result, nil
+	return result
+}
+
+// getDutyRates returns the HS master duty rates of the details' tariff codes.
+func (s *service) getDutyRates(ctx context.Context, details []*utils.InsertPreImportDetailManifestModel) (*hsCodeIndex[*GetDutyRateModel], error) {
+	list, err := s.selfRepo.GetDutyRates(ctx, tariffCodes(details))
+	if err != nil {
+		return nil, err
+	}
+
+	return newHsCodeIndex(list, func(v *GetDutyRateModel) (string, string, string) {
+		return v.TariffCode, v.StatisticalCode, v.TariffSequence
+	}), nil
+}
+
+func tariffCodes(details []*utils.InsertPreImportDetailManifestModel) []string {
+	codes := []string{}
+	for _, d := range details {
+		if code := strings.TrimSpace(d.TariffCode); code != "" {
+			codes = append(codes, code)
+		}
+	}
+	return codes
+}
+
+// hsCodeIndex finds the HS master row of a line by the tariff code stored on it. A row with the
+// same statistical code and tariff sequence wins, then one with the same statistical code, then
+// any row of the tariff code, the same order the detail queries use.
+type hsCodeIndex[T any] struct {
+	rows map[string]T
+}
+
+func newHsCodeIndex[T any](list []T, codes func(T) (tariffCode, statisticalCode, tariffSequence string)) *hsCodeIndex[T] {
+	x := &hsCodeIndex[T]{rows: map[string]T{}}
+	for _, v := range list {
+		tariffCode, statisticalCode, tariffSequence := codes(v)
+		for _, key := range hsCodeKeys(tariffCode, statisticalCode, tariffSequence) {
+			if _, exists := x.rows[key]; !exists {
+				x.rows[key] = v
+			}
+		}
+	}
+	return x
+}
+
+func (x *hsCodeIndex[T]) Get(tariffCode, statisticalCode, tariffSequence string) (T, bool) {
+	for _, key := range hsCodeKeys(tariffCode, statisticalCode, tariffSequence) {
+		if v, ok := x.rows[key]; ok {
+			return v, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+// hsCodeKeys lists the lookup keys of a line from most to least specific.
+func hsCodeKeys(tariffCode, statisticalCode, tariffSequence string) []string {
+	tariffCode = strings.TrimSpace(tariffCode)
+	statisticalCode = strings.TrimSpace(statisticalCode)
+	return []string{
+		tariffCode + "|" + statisticalCode + "|" + strings.TrimSpace(tariffSequence),
+		tariffCode + "|" + statisticalCode,
+		tariffCode,
+	}
+}
+
+// isValuedInTHB reports whether a line's CIF is already in THB. Converters that value a line
+// themselves (SHIP2CU) convert FOB at the line rate and carry freight and insurance in THB;
+// other lines hold CIF in the line currency.
+func isValuedInTHB(freightCurrencyCode, insuranceCurrencyCode string) bool {
+	return freightCurrencyCode == "THB" && insuranceCurrencyCode == "THB"
+}
+
+// cifBaht returns a line's CIF in THB, converting it at the line exchange rate when the line
+// holds CIF in its own currency. Category, duty and VAT are always assessed on this value.
+func cifBaht(cif, exchangeRate float64, freightCurrencyCode, insuranceCurrencyCode string) float64 {
+	if isValuedInTHB(freightCurrencyCode, insuranceCurrencyCode) {
+		return cif
+	}
+	return cif * exchangeRate
+}
+
+// revalueDetails sets each detail's exchange rate to the customs rate on the header arrival date.
+// Details valued in THB (freight and insurance in THB, as the converters produce them) get their
+// insurance and CIF recomputed from that rate; other details only carry the stored figures.
+// Category, duty and VAT are then reassessed from the resulting CIF.
+func (s *service) revalueDetails(ctx context.Context, data *GetPreImportManifestModel) error {
+	arrivalDate, err := parseArrivalDate(data.ArrivalDate)
+	if err != nil {
+		return err
+	}
+	rates := map[string]float64{"THB": 1, "": 1}
+	for _, v := range data.Details {
+		rate, ok := rates[v.CurrencyCode]
+		if !ok {
+			x, err := s.commonSvc.RateOn(ctx, v.CurrencyCode, arrivalDate)
+			if err != nil {
+				return err
+			}
+			rate = x.ImportExchangeRate
+			rates[v.CurrencyCode] = rate
+		}
+
+		if rate == v.ExchangeRate {
+			continue
+		}
+		v.ExchangeRate = rate
+		if isValuedInTHB(v.FreightCurrencyCode, v.InsuranceCurrencyCode) {
+			fob := v.FobValueForeign * rate
+			v.InsuranceValueForeign = fob * 0.01
+			v.CifValueForeign = fob + v.FreightValueForeign + v.InsuranceValueForeign
+		}
+	}
+
+	for _, v := range data.Details {
+		breakdown := s.taxEngine.Assess(cifBaht(v.CifValueForeign, v.ExchangeRate, v.FreightCurrencyCode, v.InsuranceCurrencyCode), v.DutyRate)
+		v.Category = breakdown.Category
+		v.Vat = breakdown.Vat
+		v.Duty = breakdown.Duty
+	}
+
+	return nil
+}
+
+// parseArrivalDate reads the header arrival date. The date picks the exchange rate and fee
+// schedule, so a missing or unreadable date is an error rather than today.
+func parseArrivalDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, errors.New("arrival date is required")
+	}
+	for _, layout := range []string{"2006-01-02", time.RFC3339, "2006-01-02 15:04:05", "02/01/2006"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid arrival date %q", value)
 }
@@ -0,0 +1,84 @@
+package inbound
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"hpc-express-service/utils"
+)
+
+// validateManifest reads the upload through the converter's column mapping and collects
+// every problem in the file, so nothing is inserted until the whole sheet is clean.
+func (s *service) validateManifest(ctx context.Context, converter PreImportConverter, fileBytes []byte) (*utils.ValidationReport, error) {
+	mapping, err := converter.ColumnMapping(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, report, err := utils.ReadMappedSheet[utils.InsertPreImportDetailManifestModel](fileBytes, mapping)
+	if err != nil {
+		return nil, err
+	}
+	if report.HasIssues() && len(rows) == 0 {
+		return report, nil
+	}
+
+	currencies, err := s.selfRepo.GetCurrencyCodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	countries, err := s.selfRepo.GetCountryCodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	validatePreImportRows(rows, report, mapping, toCodeSet(currencies), toCodeSet(countries))
+
+	return report, nil
+}
+
+func validatePreImportRows(rows []*utils.MappedRow[utils.InsertPreImportDetailManifestModel], report *utils.ValidationReport, mapping *utils.ColumnMappingTemplate, currencies, countries map[string]bool) {
+	sources := map[string]string{}
+	for _, c := range mapping.Columns {
+		sources[c.Target] = c.Source
+	}
+
+	addIssue := func(row *utils.MappedRow[utils.InsertPreImportDetailManifestModel], target, message string) {
+		cell := row.Cell(target)
+		if report.HasIssueAt(cell) {
+			return
+		}
+		report.Add(row.RowNumber, sources[target], cell, row.Value(target), message)
+	}
+
+	seenHawb := map[string]int{}
+	for _, row := range rows {
+		d := row.Data
+
+		if strings.TrimSpace(d.HouseAirWaybill) == "" {
+			addIssue(row, "HouseAirWaybill", "HAWB is required")
+		} else if first, ok := seenHawb[d.HouseAirWaybill]; ok {
+			addIssue(row, "HouseAirWaybill", fmt.Sprintf("duplicate HAWB, first seen in row %d", first))
+		} else {
+			seenHawb[d.HouseAirWaybill] = row.RowNumber
+		}
+
+		if _, ok := sources["CurrencyCode"]; ok && !currencies[strings.ToUpper(d.CurrencyCode)] {
+			addIssue(row, "CurrencyCode", fmt.Sprintf("unknown currency '%s'", d.CurrencyCode))
+		}
+
+		if _, ok := sources["ShipperCountryCode"]; ok && !countries[strings.ToUpper(d.ShipperCountryCode)] {
+			addIssue(row, "ShipperCountryCode", fmt.Sprintf("unknown origin country '%s'", d.ShipperCountryCode))
+		}
+	}
+}
+
+func toCodeSet(codes []string) map[string]bool {
+	set := make(map[string]bool, len(codes))
+	for _, code := range codes {
+		set[strings.ToUpper(strings.TrimSpace(code))] = true
+	}
+	return set
+}
@@ -0,0 +1,241 @@
+package inbound
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+
+	"hpc-express-service/common"
+	"hpc-express-service/setting"
+	"hpc-express-service/tax"
+	"hpc-express-service/uploadlog"
+	"hpc-express-service/utils"
+)
+
+// A USD line with its CIF held in dollars: 100 USD at 35 THB is 3,500 THB, above de minimis,
+// so it is category 3 with 10% duty. Read as THB it would be category 2 without duty.
+const (
+	valuationTariffCode = "39269099"
+	valuationRate       = 35
+	valuationDutyRate   = 10
+	wantCategory        = tax.CategoryDutiable
+	wantDuty            = 350
+)
+
+func valuationDetail() *GetPreImportManifestDetilModel {
+	return &GetPreImportManifestDetilModel{
+		MasterAirWaybill:         "217-12345675",
+		HouseAirWaybill:          "H001",
+		TariffCode:               valuationTariffCode,
+		EnglishDescriptionOfGood: "MOBILE PHONE CASE",
+		Quantity:                 1,
+		NetWeight:                1,
+		GrossWeight:              1.2,
+		CurrencyCode:             "USD",
+		ExchangeRate:             valuationRate,
+		FobValueForeign:          90,
+		FreightValueForeign:      8,
+		FreightCurrencyCode:      "USD",
+		InsuranceValueForeign:    2,
+		InsuranceCurrencyCode:    "USD",
+		CifValueForeign:          100,
+		DutyRate:                 valuationDutyRate,
+	}
+}
+
+type valuationRepo struct {
+	InboundExpressRepository
+	inserted []*utils.InsertPreImportDetailManifestModel
+}
+
+func (r *valuationRepo) GetOneMawb(ctx context.Context, headerUUID string) (*GetPreImportManifestModel, error) {
+	return &GetPreImportManifestModel{
+		UUID:        headerUUID,
+		Mawb:        "217-12345675",
+		ArrivalDate: "2026-10-16",
+		Details:     []*GetPreImportManifestDetilModel{valuationDetail()},
+	}, nil
+}
+
+func (r *valuationRepo) GetPreAlert(ctx context.Context, headerUUID string) (*PreAlertModel, error) {
+	return nil, nil
+}
+
+func (r *valuationRepo) GetSummaryByHeaderUUID(ctx context.Context, headerUUID string) ([]*GetSummaryModel, error) {
+	d := valuationDetail()
+	return []*GetSummaryModel{{
+		Hawb:                  d.HouseAirWaybill,
+		Cif:                   d.CifValueForeign,
+		ExchangeRate:          d.ExchangeRate,
+		FreightCurrencyCode:   d.FreightCurrencyCode,
+		InsuranceCurrencyCode: d.InsuranceCurrencyCode,
+		DutyRate:              d.DutyRate,
+	}}, nil
+}
+
+func (r *valuationRepo) GetDutyRates(ctx context.Context, tariffCodes []string) ([]*GetDutyRateModel, error) {
+	return []*GetDutyRateModel{{TariffCode: valuationTariffCode, DutyRate: valuationDutyRate}}, nil
+}
+
+func (r *valuationRepo) GetHsCodeControls(ctx context.Context, tariffCodes []string) ([]*GetHsCodeControlModel, error) {
+	return nil, nil
+}
+
+func (r *valuationRepo) GetCurrencyCodes(ctx context.Context) ([]string, error) {
+	return []string{"USD"}, nil
+}
+
+func (r *valuationRepo) GetCountryCodes(ctx context.Context) ([]string, error) {
+	return []string{"CN"}, nil
+}
+
+func (r *valuationRepo) InsertPreImportManifestDetails(ctx context.Context, headerUUID string, details []*utils.InsertPreImportDetailManifestModel, chunkSize int) error {
+	r.inserted = details
+	return nil
+}
+
+type valuationConverter struct{}
+
+func (valuationConverter) TemplateCode() string {
+	return "TEST"
+}
+
+func (valuationConverter) ColumnMapping(ctx context.Context) (*utils.ColumnMappingTemplate, error) {
+	return &utils.ColumnMappingTemplate{
+		SheetName:      "Sheet1",
+		HeaderRowIndex: 1,
+		Columns:        []*utils.ColumnMapping{{Source: "HAWB", Target: "HouseAirWaybill"}},
+	}, nil
+}
+
+func (valuationConverter) ConvertToPreImportDetails(ctx context.Context, uploadLogUUID string, arrivalDate time.Time, fileBytes []byte) ([]*utils.InsertPreImportDetailManifestModel, error) {
+	d := valuationDetail()
+	return []*utils.InsertPreImportDetailManifestModel{{
+		MasterAirWaybill:         d.MasterAirWaybill,
+		HouseAirWaybill:          d.HouseAirWaybill,
+		Category:                 tax.CategoryLowValue,
+		TariffCode:               d.TariffCode,
+		EnglishDescriptionOfGood: d.EnglishDescriptionOfGood,
+		Quantity:                 d.Quantity,
+		NetWeight:                d.NetWeight,
+		GrossWeight:              d.GrossWeight,
+		CurrencyCode:             d.CurrencyCode,
+		ExchangeRate:             d.ExchangeRate,
+		FobValueForeign:          d.FobValueForeign,
+		FreightValueForeign:      d.FreightValueForeign,
+		FreightCurrencyCode:      d.FreightCurrencyCode,
+		InsuranceValueForeign:    d.InsuranceValueForeign,
+		InsuranceCurrencyCode:    d.InsuranceCurrencyCode,
+		CifValueForeign:          d.CifValueForeign,
+	}}, nil
+}
+
+type valuationRates struct{ common.Service }
+
+func (valuationRates) RateOn(ctx context.Context, currencyCode string, on time.Time) (*common.ExchangeRateOnModel, error) {
+	return &common.ExchangeRateOnModel{CurrencyCode: currencyCode, ImportExchangeRate: valuationRate}, nil
+}
+
+type valuationFees struct{ setting.FeeScheduleService }
+
+func (valuationFees) GetEffectiveFeeSchedules(ctx context.Context, customerUUID string, on time.Time) (map[string]*setting.FeeSchedule, error) {
+	return setting.DefaultFeeSchedules(), nil
+}
+
+type valuationAirlines struct{ setting.AirlineService }
+
+func (valuationAirlines) GetAirlineByMawb(ctx context.Context, mawb string) (*setting.Airline, error) {
+	return nil, nil
+}
+
+type valuationUploadLog struct{ uploadlog.Service }
+
+func (valuationUploadLog) UploadLogFile(ctx context.Context, data *uploadlog.UploadFileModel) (string, error) {
+	return "upload-log", nil
+}
+
+func (valuationUploadLog) Update(ctx context.Context, data *uploadlog.UpdateModel) error {
+	return nil
+}
+
+func newValuationService() (*service, *valuationRepo) {
+	repo := &valuationRepo{}
+	return &service{
+		selfRepo:       repo,
+		contextTimeout: time.Minute,
+		converters:     NewConverterRegistry(nil, valuationConverter{}),
+		feeScheduleSvc: valuationFees{},
+		uploadlogSvc:   valuationUploadLog{},
+		commonSvc:      valuationRates{},
+		airlineSvc:     valuationAirlines{},
+		taxEngine:      tax.NewEngine(),
+	}, repo
+}
+
+func valuationManifest(t *testing.T) []byte {
+	t.Helper()
+	f := excelize.NewFile()
+	f.SetCellValue("Sheet1", "A1", "HAWB")
+	f.SetCellValue("Sheet1", "A2", "H001")
+	buf, err := f.WriteToBuffer()
+	if err != nil {
+		t.Fatalf("WriteToBuffer: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// TestForeignCurrencyLineAssessedAlike checks that every path assessing a line priced in a
+// foreign currency works from its CIF in THB, so they agree on category and duty.
+func TestForeignCurrencyLineAssessedAlike(t *testing.T) {
+	ctx := context.Background()
+	s, repo := newValuationService()
+
+	if _, err := s.UploadManifestDetails(ctx, "user", "header", "manifest.xlsx", "TEST", valuationManifest(t)); err != nil {
+		t.Fatalf("UploadManifestDetails: %v", err)
+	}
+	if len(repo.inserted) != 1 || repo.inserted[0].Category != wantCategory {
+		t.Errorf("upload stored %+v, want one line of category %s", repo.inserted, wantCategory)
+	}
+
+	preview, err := s.PreviewManifestDetails(ctx, "header", "TEST", valuationManifest(t))
+	if err != nil {
+		t.Fatalf("PreviewManifestDetails: %v", err)
+	}
+	if d := preview.Details[0]; d.Category != wantCategory || d.Duty != wantDuty {
+		t.Errorf("preview = category %s, duty %v; want %s, %v", d.Category, d.Duty, wantCategory, wantDuty)
+	}
+
+	header, err := s.GetOneByHeaderUUID(ctx, "header")
+	if err != nil {
+		t.Fatalf("GetOneByHeaderUUID: %v", err)
+	}
+	if d := header.Details[0]; d.Category != wantCategory || d.Duty != wantDuty {
+		t.Errorf("get = category %s, duty %v; want %s, %v", d.Category, d.Duty, wantCategory, wantDuty)
+	}
+
+	summary, err := s.GetSummaryByHeaderUUID(ctx, "header")
+	if err != nil {
+		t.Fatalf("GetSummaryByHeaderUUID: %v", err)
+	}
+	if c := summary.Catogory3; c.Total != 1 || c.Duty != wantDuty {
+		t.Errorf("summary category 3 = %d HAWBs, duty %v; want 1, %v", c.Total, c.Duty, wantDuty)
+	}
+
+	revalued, _ := repo.GetOneMawb(ctx, "header")
+	if err := s.revalueDetails(ctx, revalued); err != nil {
+		t.Fatalf("revalueDetails: %v", err)
+	}
+	if d := revalued.Details[0]; d.Category != wantCategory || d.Duty != wantDuty {
+		t.Errorf("download = category %s, duty %v; want %s, %v", d.Category, d.Duty, wantCategory, wantDuty)
+	}
+
+	declarations, err := buildImportDeclarations(revalued, time.Now())
+	if err != nil {
+		t.Fatalf("buildImportDeclarations: %v", err)
+	}
+	if got := declarations[0].Items[0].Valuation.CifBaht; got != "3500.00" {
+		t.Errorf("declared CifBaht = %s, want 3500.00", got)
+	}
+}
@@ -0,0 +1,115 @@
+package lazada
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"hpc-express-service/preexport"
+	"hpc-express-service/setting"
+	"hpc-express-service/utils"
+)
+
+// requiredHeaders are the columns of the Lazada order export the converter reads. The export
+// has one row per order item; "Package Weight(kg)", "Seller Name" and "HS Code" are optional.
+var requiredHeaders = []string{
+	"Order Number", "Tracking Code", "Seller SKU", "Item Name", "Paid Price", "Currency",
+	"Shipping Name", "Shipping Address", "Shipping City", "Shipping Postcode", "Shipping Country", "Shipping Phone",
+}
+
+// OrderModel is one parcel of a Lazada export: the order items sharing a tracking code.
+type OrderModel struct {
+	OrderNumber string
+	SellerName  string
+	Parcel      *preexport.Parcel
+}
+
+// parseOrders groups the item rows of a Lazada export by tracking code. Rows of the same SKU
+// in a parcel are merged into one item; every row is one unit at its paid price.
+func parseOrders(rows [][]string) ([]*OrderModel, error) {
+	if len(rows) == 0 {
+		return nil, errors.New("Excel file is empty!")
+	}
+
+	columns, err := preexport.NewColumns(rows[0], requiredHeaders)
+	if err != nil {
+		return nil, fmt.Errorf("Header validation failed: %v", err)
+	}
+
+	orders := []*OrderModel{}
+	byTracking := map[string]*OrderModel{}
+	for i := 1; i < len(rows); i++ {
+		row := rows[i]
+		tracking := columns.Cell(row, "Tracking Code")
+		if tracking == "" {
+			if columns.Cell(row, "Order Number") == "" {
+				continue
+			}
+			return nil, fmt.Errorf("row %d: missing tracking code", i+1)
+		}
+
+		if currency := columns.Cell(row, "Currency"); currency != "" && currency != "THB" {
+			return nil, fmt.Errorf("row %d: currency '%s' is not supported, amounts must be in THB", i+1, currency)
+		}
+		price, err := preexport.ParseAmount(columns.Cell(row, "Paid Price"))
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %v", i+1, err)
+		}
+
+		order, ok := byTracking[tracking]
+		if !ok {
+			order = &OrderModel{
+				OrderNumber: columns.Cell(row, "Order Number"),
+				SellerName:  columns.Cell(row, "Seller Name"),
+				Parcel: &preexport.Parcel{
+					Hawb:                   tracking,
+					ReceiverName:           columns.Cell(row, "Shipping Name"),
+					ReceiverAddress:        columns.Cell(row, "Shipping Address") + " " + columns.Cell(row, "Shipping Phone"),
+					ReceiverDistrict:       columns.Cell(row, "Shipping City"),
+					ReceiverProvince:       columns.Cell(row, "Shipping Region"),
+					ReceiverPostcode:       columns.Cell(row, "Shipping Postcode"),
+					DestinationCountryCode: preexport.CountryCode(columns.Cell(row, "Shipping Country")),
+					PurchaseCountryCode:    "TH",
+					Packages:               1,
+				},
+			}
+			byTracking[tracking] = order
+			orders = append(orders, order)
+		}
+
+		// the package weight is repeated on every item row of the parcel
+		if weight := columns.Cell(row, "Package Weight(kg)"); weight != "" && order.Parcel.Weight == 0 {
+			order.Parcel.Weight, err = strconv.ParseFloat(weight, 64)
+			if err != nil {
+				return nil, fmt.Errorf("row %d: '%s' is not a weight", i+1, weight)
+			}
+		}
+
+		order.Parcel.Add(&preexport.Item{
+			SKU:         columns.Cell(row, "Seller SKU"),
+			Description: columns.Cell(row, "Item Name"),
+			HSCode:      columns.Cell(row, "HS Code"),
+			Quantity:    1,
+			Value:       price,
+		})
+	}
+
+	if len(orders) == 0 {
+		return nil, errors.New("Excel file is empty!")
+	}
+
+	return orders, nil
+}
+
+// convertOrders builds the pre-export lines of every parcel, declaring the profile resolved from its seller.
+func convertOrders(orders []*OrderModel, profiles *setting.ExporterProfileSet) ([]*utils.InsertPreExportDetailManifestModel, error) {
+	details := []*utils.InsertPreExportDetailManifestModel{}
+	for _, v := range orders {
+		profile, err := profiles.Resolve(v.SellerName)
+		if err != nil {
+			return nil, fmt.Errorf("tracking %s: %v", v.Parcel.Hawb, err)
+		}
+		details = append(details, preexport.BuildLines(v.Parcel, profile)...)
+	}
+	return details, nil
+}
@@ -0,0 +1,146 @@
+package lazada
+
+import (
+	"strings"
+	"testing"
+
+	"hpc-express-service/setting"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func readFixture(t *testing.T, name string) [][]string {
+	t.Helper()
+
+	f, err := excelize.OpenFile("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	rows, err := f.GetRows(f.GetSheetName(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rows
+}
+
+func TestParseOrders(t *testing.T) {
+	orders, err := parseOrders(readFixture(t, "orders.xlsx"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(orders) != 2 {
+		t.Fatalf("got %d parcels, want 2", len(orders))
+	}
+
+	first := orders[0]
+	if first.Parcel.Hawb != "LZMY0001" || first.OrderNumber != "500001" || first.SellerName != "Siam Shirts" {
+		t.Errorf("unexpected parcel %+v", first)
+	}
+	if first.Parcel.DestinationCountryCode != "MY" {
+		t.Errorf("country = %q, want MY", first.Parcel.DestinationCountryCode)
+	}
+	if first.Parcel.Weight != 0.8 {
+		t.Errorf("weight = %v, want 0.8", first.Parcel.Weight)
+	}
+	if len(first.Parcel.Items) != 2 {
+		t.Fatalf("got %d items, want 2 (identical SKUs merged)", len(first.Parcel.Items))
+	}
+	if item := first.Parcel.Items[0]; item.SKU != "TSHIRT-M" || item.Quantity != 2 || item.Value != 500 {
+		t.Errorf("unexpected merged item %+v", item)
+	}
+	if item := first.Parcel.Items[1]; item.SKU != "CAP-01" || item.Value != 1200 {
+		t.Errorf("unexpected item %+v", item)
+	}
+
+	if second := orders[1]; second.Parcel.Hawb != "LZSG0002" || second.Parcel.DestinationCountryCode != "SG" {
+		t.Errorf("unexpected parcel %+v", second.Parcel)
+	}
+}
+
+func TestParseOrdersMissingHeader(t *testing.T) {
+	rows := readFixture(t, "orders.xlsx")
+	for i, header := range rows[0] {
+		if header == "Tracking Code" {
+			rows[0][i] = "Tracking No"
+		}
+	}
+
+	_, err := parseOrders(rows)
+	if err == nil || !strings.Contains(err.Error(), "missing header 'Tracking Code'") {
+		t.Fatalf("got %v, want missing header error", err)
+	}
+}
+
+func TestParseOrdersRejectsForeignCurrency(t *testing.T) {
+	rows := readFixture(t, "orders.xlsx")
+	for i, header := range rows[0] {
+		if header == "Currency" {
+			rows[2][i] = "MYR"
+		}
+	}
+
+	_, err := parseOrders(rows)
+	if err == nil || !strings.Contains(err.Error(), "row 3") {
+		t.Fatalf("got %v, want currency error on row 3", err)
+	}
+}
+
+func TestConvertOrders(t *testing.T) {
+	orders, err := parseOrders(readFixture(t, "orders.xlsx"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	profiles := setting.NewExporterProfileSet(TemplateCode, []setting.ExporterProfile{
+		{Name: "HPC DEFAULT", TaxNumber: "0105500000001", DefaultTariffCode: "000099999999", FreightRule: setting.ChargeRuleFixed, FreightValue: 100},
+		{Name: "SIAM SHIRTS", SenderName: "Siam Shirts", TaxNumber: "0105500000002", DefaultTariffCode: "000065050000", FreightRule: setting.ChargeRuleFixed, FreightValue: 170},
+	})
+
+	details, err := convertOrders(orders, profiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(details) != 3 {
+		t.Fatalf("got %d lines, want 3", len(details))
+	}
+
+	shirt, hat, mug := details[0], details[1], details[2]
+	if shirt.HouseAirWaybill != "LZMY0001" || shirt.ItemNo != 1 || hat.ItemNo != 2 {
+		t.Errorf("unexpected line numbering %s/%d, %d", shirt.HouseAirWaybill, shirt.ItemNo, hat.ItemNo)
+	}
+	if shirt.Category != 3 || hat.Category != 3 {
+		t.Errorf("parcel of 1700 THB should be category 3, got %d/%d", shirt.Category, hat.Category)
+	}
+	if shirt.ConsignorName != "SIAM SHIRTS" || mug.ConsignorName != "HPC DEFAULT" {
+		t.Errorf("unexpected consignors %q/%q", shirt.ConsignorName, mug.ConsignorName)
+	}
+	if shirt.TariffCode != "000061091000" || hat.TariffCode != "000065050000" {
+		t.Errorf("unexpected tariff codes %q/%q", shirt.TariffCode, hat.TariffCode)
+	}
+	if shirt.Quantity != 2 || shirt.FobValueBaht != 500 {
+		t.Errorf("unexpected merged line %d x %v", shirt.Quantity, shirt.FobValueBaht)
+	}
+	if shirt.FreightAmount+hat.FreightAmount != 170 {
+		t.Errorf("freight split %d+%d, want 170", shirt.FreightAmount, hat.FreightAmount)
+	}
+	if shirt.PackageAmount != 1 || hat.PackageAmount != 0 {
+		t.Errorf("packages %d/%d, want 1/0", shirt.PackageAmount, hat.PackageAmount)
+	}
+	if mug.Category != 2 || mug.ConsigneeCountryCode != "SG" {
+		t.Errorf("unexpected line %+v", mug)
+	}
+}
+
+func TestConvertOrdersWithoutProfile(t *testing.T) {
+	orders, err := parseOrders(readFixture(t, "orders.xlsx"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = convertOrders(orders, setting.NewExporterProfileSet(TemplateCode, nil))
+	if err == nil || !strings.Contains(err.Error(), "LZMY0001") {
+		t.Fatalf("got %v, want missing profile error", err)
+	}
+}
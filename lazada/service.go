@@ -0,0 +1,83 @@
+package lazada
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"hpc-express-service/setting"
+	"hpc-express-service/utils"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// TemplateCode is the convert template code handled by this converter.
+const TemplateCode = "LAZADA"
+
+// TemplateName is the name the convert template is listed under.
+const TemplateName = "Lazada"
+
+type Service interface {
+	TemplateCode() string
+	TemplateName() string
+	ConvertToPreExportDetails(ctx context.Context, customerUUID string, fileBytes []byte) ([]*utils.InsertPreExportDetailManifestModel, error)
+}
+
+type service struct {
+	contextTimeout time.Duration
+	profileSvc     setting.ExporterProfileService
+}
+
+func NewService(
+	timeout time.Duration,
+	profileSvc setting.ExporterProfileService,
+) Service {
+	return &service{
+		contextTimeout: timeout,
+		profileSvc:     profileSvc,
+	}
+}
+
+func (s *service) TemplateCode() string {
+	return TemplateCode
+}
+
+func (s *service) TemplateName() string {
+	return TemplateName
+}
+
+// ConvertToPreExportDetails converts a Lazada order export into pre-export lines. The consignor of each
+// parcel comes from the LAZADA exporter profile of customerUUID (optional) and the parcel's seller.
+func (s *service) ConvertToPreExportDetails(ctx context.Context, customerUUID string, fileBytes []byte) ([]*utils.InsertPreExportDetailManifestModel, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	if len(fileBytes) == 0 {
+		return nil, errors.New("empty")
+	}
+
+	f, err := excelize.OpenReader(bytes.NewReader(fileBytes))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	rows, err := f.GetRows(f.GetSheetName(0))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read rows: %v", err)
+	}
+
+	orders, err := parseOrders(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	profiles, err := s.profileSvc.GetExporterProfileSet(ctx, TemplateCode, customerUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	return convertOrders(orders, profiles)
+}
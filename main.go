@@ -15,6 +15,7 @@ import (
 
 	"hpc-express-service/config"
 	"hpc-express-service/database"
+	"hpc-express-service/ecustoms"
 	"hpc-express-service/factory"
 	"hpc-express-service/gcs"
 	"hpc-express-service/server"
@@ -65,16 +66,29 @@ func main() {
 	*/
 	repoFactory := factory.NewRepositoryFactory()
 
+	// e-Customs declaration schemas
+	declarations, err := ecustoms.NewMarshaller()
+	if err != nil {
+		logger.Log("ecustoms", "NewMarshaller", err)
+		os.Exit(1)
+	}
+
 	/*
 		Services Factory
 	*/
-	svcFactory := factory.NewServiceFactory(repoFactory, _gcs, config)
+	svcFactory := factory.NewServiceFactory(repoFactory, _gcs, config, declarations)
 
 	/*
 		Logging Factory
 	*/
 	factory.InitialLoggingFactory(logger, svcFactory)
 
+	// List the built-in pre-export converters as convert templates
+	dbCtx := context.WithValue(ctx, "postgreSQLConn", postgreSQLConn)
+	if err := svcFactory.OutboundExpressServiceSvc.RegisterConvertTemplates(dbCtx); err != nil {
+		logger.Log("RegisterConvertTemplates", err)
+	}
+
 	// Initial Server
 	srv := server.New(
 		svcFactory,
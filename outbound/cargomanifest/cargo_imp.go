@@ -0,0 +1,30 @@
+package outbound
+
+import (
+	"strconv"
+	"strings"
+
+	"hpc-express-service/cargoimp"
+)
+
+// weightReplacer strips the thousands separators and unit typed into gross weights such as "1,250.5 KG".
+var weightReplacer = strings.NewReplacer(",", "", "KG", "", " ", "")
+
+// ToHouses maps the manifest items onto the house waybills listed in FHL and XFZB messages.
+func (c *CargoManifest) ToHouses() []cargoimp.House {
+	houses := make([]cargoimp.House, 0, len(c.Items))
+	for _, item := range c.Items {
+		pieces, _ := strconv.ParseInt(strings.TrimSpace(item.Pkgs), 10, 64)
+		weight, _ := strconv.ParseFloat(weightReplacer.Replace(strings.ToUpper(item.GrossWeight)), 64)
+		houses = append(houses, cargoimp.House{
+			HAWB:        strings.TrimSpace(item.HAWBNo),
+			Destination: cargoimp.AirportCode(item.Destination),
+			Pieces:      pieces,
+			Weight:      weight,
+			Description: item.Commodity,
+			Shipper:     cargoimp.SplitNameAndAddress(item.ShipperNameAndAddress),
+			Consignee:   cargoimp.SplitNameAndAddress(item.ConsigneeNameAndAddress),
+		})
+	}
+	return houses
+}
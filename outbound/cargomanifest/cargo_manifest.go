@@ -9,23 +9,29 @@ type PrintOptions struct {
 	CargoManifest bool `json:"cargoManifest"`
 }
 type CargoManifest struct {
-	tableName       struct{}            `pg:"public.cargo_manifest"`
-	UUID            string              `json:"uuid" pg:"uuid"`
-	MAWBInfoUUID    string              `json:"mawbInfoUuid" pg:"mawb_info_uuid"`
-	MAWBNumber      string              `json:"mawbNumber" pg:"mawb_number"`
-	PortOfDischarge string              `json:"portOfDischarge" pg:"port_of_discharge"`
-	FlightNo        string              `json:"flightNo" pg:"flight_no"`
-	FreightDate     string              `json:"freightDate" pg:"freight_date"`
-	Shipper         string              `json:"shipper" pg:"shipper"`
-	Consignee       string              `json:"consignee" pg:"consignee"`
-	TotalCtn        string              `json:"totalCtn" pg:"total_ctn"`
-	Transshipment   string              `json:"transshipment" pg:"transshipment"`
-	StatusUUID      string              `json:"statusUuid" pg:"status_uuid"`
-	Status          string              `json:"status" pg:"-"`
-	PrintOptions    PrintOptions        `json:"printOptions" pg:"-"`
-	Items           []CargoManifestItem `json:"items"`
-	CreatedAt       time.Time           `json:"createdAt" pg:"created_at"`
-	UpdatedAt       time.Time           `json:"updatedAt" pg:"updated_at"`
+	tableName          struct{}            `pg:"public.cargo_manifest"`
+	UUID               string              `json:"uuid" pg:"uuid"`
+	MAWBInfoUUID       string              `json:"mawbInfoUuid" pg:"mawb_info_uuid"`
+	MAWBNumber         string              `json:"mawbNumber" pg:"mawb_number"`
+	AirlineName        string              `json:"airlineName,omitempty" pg:"-"`
+	AirlineLogo        string              `json:"airlineLogo,omitempty" pg:"-"`
+	PortOfDischarge    string              `json:"portOfDischarge" pg:"port_of_discharge"`
+	FlightNo           string              `json:"flightNo" pg:"flight_no"`
+	FreightDate        string              `json:"freightDate" pg:"freight_date"`
+	Shipper            string              `json:"shipper" pg:"shipper"`
+	ShipperPartyUUID   string              `json:"shipperPartyUuid" pg:"shipper_party_uuid"`
+	Consignee          string              `json:"consignee" pg:"consignee"`
+	ConsigneePartyUUID string              `json:"consigneePartyUuid" pg:"consignee_party_uuid"`
+	Notify             string              `json:"notify" pg:"notify"`
+	NotifyPartyUUID    string              `json:"notifyPartyUuid" pg:"notify_party_uuid"`
+	TotalCtn           string              `json:"totalCtn" pg:"total_ctn"`
+	Transshipment      string              `json:"transshipment" pg:"transshipment"`
+	StatusUUID         string              `json:"statusUuid" pg:"status_uuid"`
+	Status             string              `json:"status" pg:"-"`
+	PrintOptions       PrintOptions        `json:"printOptions" pg:"-"`
+	Items              []CargoManifestItem `json:"items"`
+	CreatedAt          time.Time           `json:"createdAt" pg:"created_at"`
+	UpdatedAt          time.Time           `json:"updatedAt" pg:"updated_at"`
 }
 
 func (c *CargoManifest) Bind(r *http.Request) error {
@@ -42,5 +48,7 @@ type CargoManifestItem struct {
 	Destination             string   `json:"destination" db:"destination"`
 	Commodity               string   `json:"commodity" db:"commodity"`
 	ShipperNameAndAddress   string   `json:"shipperNameAndAddress" db:"shipper_name_address"`
+	ShipperPartyUUID        string   `json:"shipperPartyUuid" db:"shipper_party_uuid"`
 	ConsigneeNameAndAddress string   `json:"consigneeNameAndAddress" db:"consignee_name_address"`
+	ConsigneePartyUUID      string   `json:"consigneePartyUuid" db:"consignee_party_uuid"`
 }
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"hpc-express-service/common"
 	"hpc-express-service/setting"
+	"strings"
 )
 
 type CargoManifestService interface {
@@ -17,21 +18,95 @@ type CargoManifestService interface {
 }
 
 type cargoManifestService struct {
-	repo      CargoManifestRepository
-	statusSvc setting.MasterStatusService
+	repo       CargoManifestRepository
+	statusSvc  setting.MasterStatusService
+	airlineSvc setting.AirlineService
+	partySvc   setting.PartyService
 }
 
-func NewCargoManifestService(repo CargoManifestRepository, statusSvc setting.MasterStatusService) CargoManifestService {
-	return &cargoManifestService{repo: repo, statusSvc: statusSvc}
+func NewCargoManifestService(repo CargoManifestRepository, statusSvc setting.MasterStatusService, airlineSvc setting.AirlineService, partySvc setting.PartyService) CargoManifestService {
+	return &cargoManifestService{repo: repo, statusSvc: statusSvc, airlineSvc: airlineSvc, partySvc: partySvc}
 }
 
 func (s *cargoManifestService) GetCargoManifestByMAWBUUID(ctx context.Context, mawbUUID string) (*CargoManifest, error) {
 	// Add any business logic here if needed, e.g., permission checks.
-	return s.repo.GetByMAWBUUID(ctx, mawbUUID)
+	manifest, err := s.repo.GetByMAWBUUID(ctx, mawbUUID)
+	if err != nil || manifest == nil {
+		return manifest, err
+	}
+	return manifest, s.setAirline(ctx, manifest)
 }
 
 func (s *cargoManifestService) GetCargoManifestByUUID(ctx context.Context, uuid string) (*CargoManifest, error) {
-	return s.repo.GetByUUID(ctx, uuid)
+	manifest, err := s.repo.GetByUUID(ctx, uuid)
+	if err != nil || manifest == nil {
+		return manifest, err
+	}
+	return manifest, s.setAirline(ctx, manifest)
+}
+
+// setAirline shows the airline the MAWB prefix belongs to on a manifest read back.
+func (s *cargoManifestService) setAirline(ctx context.Context, manifest *CargoManifest) error {
+	airline, err := s.airlineSvc.GetAirlineByMawb(ctx, manifest.MAWBNumber)
+	if err != nil || airline == nil {
+		return err
+	}
+	manifest.AirlineName = airline.Name
+	manifest.AirlineLogo = airline.LogoURL
+	return nil
+}
+
+// resolveMAWBNumber checks the MAWB number typed on the manifest and sets the airline its
+// prefix belongs to.
+func (s *cargoManifestService) resolveMAWBNumber(ctx context.Context, manifest *CargoManifest) error {
+	if strings.TrimSpace(manifest.MAWBNumber) == "" {
+		return nil
+	}
+	mawb, airline, err := s.airlineSvc.ResolveMawb(ctx, manifest.MAWBNumber)
+	if err != nil {
+		return err
+	}
+	manifest.MAWBNumber = mawb
+	manifest.AirlineName = airline.Name
+	manifest.AirlineLogo = airline.LogoURL
+	return nil
+}
+
+// resolveParties writes the shipper and consignee picked from the address book, on the
+// manifest and on its HAWB lines, and the notify party on the manifest. Those without a party
+// keep the text typed in.
+func (s *cargoManifestService) resolveParties(ctx context.Context, manifest *CargoManifest) error {
+	resolve := func(partyUUID, role string, text *string) error {
+		if partyUUID == "" {
+			return nil
+		}
+		party, err := s.partySvc.ResolveParty(ctx, partyUUID, role)
+		if err != nil {
+			return err
+		}
+		*text = party.TextBlock()
+		return nil
+	}
+
+	if err := resolve(manifest.ShipperPartyUUID, setting.PartyRoleShipper, &manifest.Shipper); err != nil {
+		return err
+	}
+	if err := resolve(manifest.ConsigneePartyUUID, setting.PartyRoleConsignee, &manifest.Consignee); err != nil {
+		return err
+	}
+	if err := resolve(manifest.NotifyPartyUUID, setting.PartyRoleNotify, &manifest.Notify); err != nil {
+		return err
+	}
+	for i := range manifest.Items {
+		item := &manifest.Items[i]
+		if err := resolve(item.ShipperPartyUUID, setting.PartyRoleShipper, &item.ShipperNameAndAddress); err != nil {
+			return fmt.Errorf("HAWB %s: %w", item.HAWBNo, err)
+		}
+		if err := resolve(item.ConsigneePartyUUID, setting.PartyRoleConsignee, &item.ConsigneeNameAndAddress); err != nil {
+			return fmt.Errorf("HAWB %s: %w", item.HAWBNo, err)
+		}
+	}
+	return nil
 }
 
 func (s *cargoManifestService) GetAllCargoManifest(ctx context.Context, startDate, endDate string) ([]CargoManifest, error) {
@@ -52,6 +127,13 @@ func (s *cargoManifestService) setDefaultStatus(ctx context.Context, manifest *C
 }
 
 func (s *cargoManifestService) CreateCargoManifest(ctx context.Context, manifest *CargoManifest) (*CargoManifest, error) {
+	if err := s.resolveMAWBNumber(ctx, manifest); err != nil {
+		return nil, err
+	}
+	if err := s.resolveParties(ctx, manifest); err != nil {
+		return nil, err
+	}
+
 	tx, txCtx, err := common.BeginTx(ctx)
 	if err != nil {
 		return nil, err
@@ -83,6 +165,13 @@ func (s *cargoManifestService) CreateCargoManifest(ctx context.Context, manifest
 }
 
 func (s *cargoManifestService) UpdateCargoManifest(ctx context.Context, manifest *CargoManifest) (*CargoManifest, error) {
+	if err := s.resolveMAWBNumber(ctx, manifest); err != nil {
+		return nil, err
+	}
+	if err := s.resolveParties(ctx, manifest); err != nil {
+		return nil, err
+	}
+
 	tx, txCtx, err := common.BeginTx(ctx)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,203 @@
+package outbound
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"hpc-express-service/cargoimp"
+)
+
+// ToWaybill maps the draft onto the e-AWB model used for FWB and XFWB messages.
+func (d *DraftMAWBWithRelations) ToWaybill() (*cargoimp.Waybill, error) {
+	prefix, serial, err := cargoimp.ParseAWBNumber(d.MAWB)
+	if err != nil {
+		return nil, err
+	}
+
+	wtValPrepaid, otherPrepaid := d.paymentIndicators()
+	w := &cargoimp.Waybill{
+		Prefix:           prefix,
+		Serial:           serial,
+		Origin:           cargoimp.AirportCode(d.AirportOfDeparture),
+		Destination:      cargoimp.AirportCode(d.AirportOfDestination),
+		Shipper:          cargoimp.SplitNameAndAddress(d.ShipperNameAndAddress),
+		Consignee:        cargoimp.SplitNameAndAddress(d.ConsigneeNameAndAddress),
+		HandlingInfo:     d.HandlingInfomation,
+		AccountingInfo:   d.AccountingInfomation,
+		Currency:         strings.ToUpper(strings.TrimSpace(d.Currency)),
+		ChargeCode:       strings.ToUpper(strings.TrimSpace(d.ChgsCode)),
+		WtValPrepaid:     wtValPrepaid,
+		OtherPrepaid:     otherPrepaid,
+		DeclaredCarriage: d.DeclaredValCarriage,
+		DeclaredCustoms:  d.DeclaredValCustoms,
+		Insurance:        d.AmountOfInsurance,
+		ShipperSignature: d.Signature1,
+		IssueDate:        parseIssueDate(d.Signature2Date),
+		IssuePlace:       d.Signature2Place,
+		IssuedBy:         d.Signature2Issuing,
+	}
+
+	agent := cargoimp.SplitNameAndAddress(d.IssuingCarrierAgentName)
+	w.Agent.Name = agent.Name
+	if len(agent.Address) > 0 {
+		w.Agent.Place = agent.Address[len(agent.Address)-1]
+	}
+	if code := digitsOnly(d.AgentsIATACode); len(code) >= 7 {
+		w.Agent.IATACode = code[:7]
+		w.Agent.CASSCode = code[7:]
+	}
+	w.Agent.AccountNo = d.AccountNo
+
+	for _, leg := range [][2]string{{d.RoutingTo, d.RoutingBy}, {d.DestinationTo1, d.DestinationBy1}, {d.DestinationTo2, d.DestinationBy2}} {
+		if to := cargoimp.AirportCode(leg[0]); to != "" {
+			w.Routing = append(w.Routing, cargoimp.Route{To: to, By: strings.ToUpper(strings.TrimSpace(leg[1]))})
+		}
+	}
+	for _, v := range []string{d.RequestedFlightDate1, d.RequestedFlightDate2} {
+		if f, ok := parseFlight(v); ok {
+			w.Flights = append(w.Flights, f)
+		}
+	}
+
+	for _, item := range d.Items {
+		line := cargoimp.RateLine{
+			Pieces:           leadingInt(item.PiecesRCP),
+			Weight:           parseFloat(item.GrossWeight),
+			WeightUnit:       item.KgLb,
+			RateClass:        strings.ToUpper(strings.TrimSpace(item.RateClass)),
+			ChargeableWeight: item.ChargeableWeight,
+			Rate:             item.RateCharge,
+			Total:            item.Total,
+			Description:      item.NatureAndQuantity,
+			Volume:           item.TotalVolume,
+		}
+		for _, dim := range item.Dims {
+			line.Dims = append(line.Dims, cargoimp.Dim{
+				Length: leadingInt(dim.Length),
+				Width:  leadingInt(dim.Width),
+				Height: leadingInt(dim.Height),
+				Count:  leadingInt(dim.Count),
+			})
+		}
+		w.Rates = append(w.Rates, line)
+
+		w.Pieces += line.Pieces
+		w.Weight += line.Weight
+		w.Volume += line.Volume
+		w.Summary.WeightCharge += line.Total
+		if w.WeightUnit == "" {
+			w.WeightUnit = item.KgLb
+		}
+	}
+
+	for _, c := range d.Charges {
+		code, entitlement := otherChargeCode(c.Key)
+		w.OtherCharges = append(w.OtherCharges, cargoimp.OtherCharge{Code: code, Entitlement: entitlement, Amount: c.Value})
+	}
+
+	w.Summary.ValuationCharge = d.ValuationCharge
+	w.Summary.Tax = d.Tax
+	w.Summary.DueAgent = d.TotalOtherChargesDueAgent
+	w.Summary.DueCarrier = d.TotalOtherChargesDueCarrier
+
+	return w, nil
+}
+
+// paymentIndicators reads whether weight/valuation and other charges are prepaid, from the
+// CHGS code when it is one of PP, CC, PC or CP and otherwise from the boxes ticked on the form.
+func (d *DraftMAWB) paymentIndicators() (wtValPrepaid, otherPrepaid bool) {
+	code := strings.ToUpper(strings.TrimSpace(d.ChgsCode))
+	if len(code) == 2 && strings.Trim(code, "PC") == "" {
+		return code[0] == 'P', code[1] == 'P'
+	}
+	wtValPrepaid = strings.TrimSpace(d.WtValColl) == "" || strings.TrimSpace(d.WtValPpd) != ""
+	otherPrepaid = strings.TrimSpace(d.OtherColl) == "" || strings.TrimSpace(d.OtherPpd) != ""
+	return wtValPrepaid, otherPrepaid
+}
+
+var otherChargeKeyPattern = regexp.MustCompile(`^([A-Z]{2})([AC]?)$`)
+
+// otherChargeNames maps the charge names operators type to IATA other charge codes.
+var otherChargeNames = []struct {
+	keyword string
+	code    string
+}{
+	{"AWB", "AW"},
+	{"FUEL", "MY"},
+	{"SECURITY", "SC"},
+	{"SCREENING", "SC"},
+	{"CUSTOMS", "CH"},
+	{"DANGEROUS", "RA"},
+	{"INSURANCE", "IN"},
+}
+
+// otherChargeCode returns the IATA code and entitlement of a charge row. Keys already typed as
+// codes such as MYC or AWA are kept; names are looked up and default to due carrier.
+func otherChargeCode(key string) (code, entitlement string) {
+	key = strings.ToUpper(strings.TrimSpace(key))
+	if m := otherChargeKeyPattern.FindStringSubmatch(key); m != nil {
+		if m[2] == "" {
+			return m[1], "C"
+		}
+		return m[1], m[2]
+	}
+
+	entitlement = "C"
+	if strings.Contains(key, "AGENT") {
+		entitlement = "A"
+	}
+	for _, v := range otherChargeNames {
+		if strings.Contains(key, v.keyword) {
+			return v.code, entitlement
+		}
+	}
+	return "MZ", entitlement
+}
+
+var flightPattern = regexp.MustCompile(`^([A-Z0-9]{2})\s*(\d{1,4}[A-Z]?)(?:\s*/\s*(\d{1,2}))?`)
+
+// parseFlight reads a requested flight such as TG920/15 or TG 920.
+func parseFlight(value string) (cargoimp.Flight, bool) {
+	m := flightPattern.FindStringSubmatch(strings.ToUpper(strings.TrimSpace(value)))
+	if m == nil {
+		return cargoimp.Flight{}, false
+	}
+	day, _ := strconv.Atoi(m[3])
+	return cargoimp.Flight{Carrier: m[1], Number: m[2], Day: day}, true
+}
+
+func parseIssueDate(value string) time.Time {
+	value = strings.TrimSpace(value)
+	for _, layout := range []string{"2006-01-02", time.RFC3339, "02/01/2006", "02-Jan-2006", "02Jan06", "02 Jan 2006"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func leadingInt(value string) int64 {
+	value = strings.TrimSpace(value)
+	end := 0
+	for end < len(value) && value[end] >= '0' && value[end] <= '9' {
+		end++
+	}
+	n, _ := strconv.ParseInt(value[:end], 10, 64)
+	return n
+}
+
+func parseFloat(value string) float64 {
+	v, _ := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(value), ",", ""), 64)
+	return v
+}
+
+func digitsOnly(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, s)
+}
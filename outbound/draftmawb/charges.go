@@ -0,0 +1,74 @@
+package outbound
+
+import "strings"
+
+// computeCharges fills the charges summary of the form. The weight charge is the sum of the
+// item totals and the other charges are split into due agent and due carrier by their
+// entitlement. Valuation charge and tax stay as typed. The WT/VAL and Other boxes are ticked
+// from the CHGS code, and each group goes to the prepaid or collect total accordingly.
+func (d *DraftMAWB) computeCharges(items []DraftMAWBItemInput, charges []DraftMAWBChargeInput) {
+	wtValPrepaid, otherPrepaid := d.paymentIndicators()
+	d.WtValPpd, d.WtValColl = indicator(wtValPrepaid), indicator(!wtValPrepaid)
+	d.OtherPpd, d.OtherColl = indicator(otherPrepaid), indicator(!otherPrepaid)
+
+	var weightCharge, dueAgent, dueCarrier float64
+	for _, item := range items {
+		weightCharge += item.Total
+	}
+	for _, c := range charges {
+		if _, entitlement := otherChargeCode(c.Key); entitlement == "A" {
+			dueAgent += c.Value
+		} else {
+			dueCarrier += c.Value
+		}
+	}
+	d.Prepaid = roundTo(weightCharge, 2)
+	d.TotalOtherChargesDueAgent = roundTo(dueAgent, 2)
+	d.TotalOtherChargesDueCarrier = roundTo(dueCarrier, 2)
+
+	wtVal := d.Prepaid + d.ValuationCharge + d.Tax
+	other := d.TotalOtherChargesDueAgent + d.TotalOtherChargesDueCarrier
+	d.TotalPrepaid, d.TotalCollect = 0, 0
+	if wtValPrepaid {
+		d.TotalPrepaid += wtVal
+	} else {
+		d.TotalCollect += wtVal
+	}
+	if otherPrepaid {
+		d.TotalPrepaid += other
+	} else {
+		d.TotalCollect += other
+	}
+	d.TotalPrepaid = roundTo(d.TotalPrepaid, 2)
+	d.TotalCollect = roundTo(d.TotalCollect, 2)
+}
+
+// ComputeCharges fills the charges summary of a form that is not saved, such as a preview.
+func (d *DraftMAWBInput) ComputeCharges() {
+	draft := d.ToDraftMAWB()
+	draft.computeCharges(d.Items, d.Charges)
+	d.WtValPpd, d.WtValColl = draft.WtValPpd, draft.WtValColl
+	d.OtherPpd, d.OtherColl = draft.OtherPpd, draft.OtherColl
+	d.Prepaid = draft.Prepaid
+	d.TotalOtherChargesDueAgent = draft.TotalOtherChargesDueAgent
+	d.TotalOtherChargesDueCarrier = draft.TotalOtherChargesDueCarrier
+	d.TotalPrepaid = draft.TotalPrepaid
+	d.TotalCollect = draft.TotalCollect
+}
+
+// WtValPrepaid reports whether weight charge, valuation charge and tax are prepaid.
+func (d *DraftMAWBInput) WtValPrepaid() bool {
+	return strings.TrimSpace(d.WtValColl) == "" || strings.TrimSpace(d.WtValPpd) != ""
+}
+
+// OtherPrepaid reports whether the other charges are prepaid.
+func (d *DraftMAWBInput) OtherPrepaid() bool {
+	return strings.TrimSpace(d.OtherColl) == "" || strings.TrimSpace(d.OtherPpd) != ""
+}
+
+func indicator(ticked bool) string {
+	if ticked {
+		return "X"
+	}
+	return ""
+}
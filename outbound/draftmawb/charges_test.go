@@ -0,0 +1,63 @@
+package outbound
+
+import "testing"
+
+func TestComputeChargesPrepaidCollectSplit(t *testing.T) {
+	items := []DraftMAWBItemInput{{Total: 1000}, {Total: 500}}
+	charges := []DraftMAWBChargeInput{
+		{Key: "AWC", Value: 200},
+		{Key: "Fuel surcharge", Value: 300},
+		{Key: "MYA", Value: 50},
+		{Key: "Agent handling", Value: 100},
+	}
+
+	tests := []struct {
+		name                  string
+		draft                 DraftMAWB
+		wantWtVal, wantOther  string
+		wantPrepaid, wantColl float64
+	}{
+		{"all prepaid", DraftMAWB{ChgsCode: "PP"}, "P", "P", 2165, 0},
+		{"all collect", DraftMAWB{ChgsCode: "CC"}, "C", "C", 0, 2165},
+		{"weight prepaid, other collect", DraftMAWB{ChgsCode: "PC"}, "P", "C", 1515, 650},
+		{"weight collect, other prepaid", DraftMAWB{ChgsCode: "CP"}, "C", "P", 650, 1515},
+		{"boxes ticked without a code", DraftMAWB{WtValColl: "X"}, "C", "P", 650, 1515},
+		{"nothing ticked is prepaid", DraftMAWB{}, "P", "P", 2165, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := tt.draft
+			d.ValuationCharge = 5
+			d.Tax = 10
+			d.computeCharges(items, charges)
+
+			if d.Prepaid != 1500 {
+				t.Errorf("Prepaid (weight charge) = %v, want 1500", d.Prepaid)
+			}
+			if d.TotalOtherChargesDueAgent != 150 || d.TotalOtherChargesDueCarrier != 500 {
+				t.Errorf("due agent %v, due carrier %v; want 150, 500", d.TotalOtherChargesDueAgent, d.TotalOtherChargesDueCarrier)
+			}
+			if got := boxes(d.WtValPpd, d.WtValColl); got != tt.wantWtVal {
+				t.Errorf("WT/VAL = %s, want %s", got, tt.wantWtVal)
+			}
+			if got := boxes(d.OtherPpd, d.OtherColl); got != tt.wantOther {
+				t.Errorf("Other = %s, want %s", got, tt.wantOther)
+			}
+			if d.TotalPrepaid != tt.wantPrepaid || d.TotalCollect != tt.wantColl {
+				t.Errorf("total prepaid %v, collect %v; want %v, %v", d.TotalPrepaid, d.TotalCollect, tt.wantPrepaid, tt.wantColl)
+			}
+		})
+	}
+}
+
+// boxes reads a prepaid/collect pair of boxes as P or C, or "?" when not exactly one is ticked.
+func boxes(prepaid, collect string) string {
+	switch {
+	case prepaid == "X" && collect == "":
+		return "P"
+	case prepaid == "" && collect == "X":
+		return "C"
+	}
+	return "?"
+}
@@ -0,0 +1,143 @@
+package outbound
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// DraftMAWBCloneResult tells what carried over from the source draft into the new one.
+// CopiedFields are the JSON names of the fields copied, so the operator can review them.
+type DraftMAWBCloneResult struct {
+	UUID          string   `json:"uuid"`
+	SourceUUID    string   `json:"sourceUuid"`
+	MAWBInfoUUID  string   `json:"mawbInfoUuid"`
+	MAWB          string   `json:"mawb"`
+	CopiedFields  []string `json:"copiedFields"`
+	ItemsCopied   int      `json:"itemsCopied"`
+	ChargesCopied int      `json:"chargesCopied"`
+}
+
+// CloneDraftMAWB creates the draft of a MAWB info from another draft: its parties, routing,
+// handling and accounting information, charges and, when includeItems is set, its items. The
+// number, references, flight dates, issue date, status and totals are not copied; the new
+// draft gets mawb, the number of its own MAWB info, and the default status.
+func (s *draftMAWBService) CloneDraftMAWB(ctx context.Context, mawbInfoUUID, mawb, sourceUUID string, includeItems bool) (*DraftMAWBCloneResult, error) {
+	source, err := s.repo.GetWithRelations(ctx, sourceUUID)
+	if err != nil {
+		return nil, err
+	}
+	if source == nil {
+		return nil, errors.New("source draft MAWB not found")
+	}
+	if source.MAWBInfoUUID == mawbInfoUUID {
+		return nil, errors.New("source draft MAWB already belongs to this MAWB")
+	}
+	input := source.ToDraftMAWBInput()
+
+	draft := &DraftMAWB{MAWBInfoUUID: mawbInfoUUID, MAWB: strings.TrimSpace(mawb)}
+	result := &DraftMAWBCloneResult{SourceUUID: source.UUID, MAWBInfoUUID: mawbInfoUUID, CopiedFields: []string{}}
+
+	fields := []struct {
+		name     string
+		src, dst *string
+	}{
+		{"customerUuid", &source.CustomerUUID, &draft.CustomerUUID},
+		{"shipperPartyUuid", &source.ShipperPartyUUID, &draft.ShipperPartyUUID},
+		{"shipperNameAndAddress", &source.ShipperNameAndAddress, &draft.ShipperNameAndAddress},
+		{"consigneePartyUuid", &source.ConsigneePartyUUID, &draft.ConsigneePartyUUID},
+		{"consigneeNameAndAddress", &source.ConsigneeNameAndAddress, &draft.ConsigneeNameAndAddress},
+		{"notifyPartyUuid", &source.NotifyPartyUUID, &draft.NotifyPartyUUID},
+		{"notifyNameAndAddress", &source.NotifyNameAndAddress, &draft.NotifyNameAndAddress},
+		{"awbIssuedBy", &source.AWBIssuedBy, &draft.AWBIssuedBy},
+		{"issuingCarrierAgentName", &source.IssuingCarrierAgentName, &draft.IssuingCarrierAgentName},
+		{"agentsIATACode", &source.AgentsIATACode, &draft.AgentsIATACode},
+		{"accountNo", &source.AccountNo, &draft.AccountNo},
+		{"accountingInfomation", &source.AccountingInfomation, &draft.AccountingInfomation},
+		{"airportOfDeparture", &source.AirportOfDeparture, &draft.AirportOfDeparture},
+		{"routingTo", &source.RoutingTo, &draft.RoutingTo},
+		{"routingBy", &source.RoutingBy, &draft.RoutingBy},
+		{"destinationTo1", &source.DestinationTo1, &draft.DestinationTo1},
+		{"destinationBy1", &source.DestinationBy1, &draft.DestinationBy1},
+		{"destinationTo2", &source.DestinationTo2, &draft.DestinationTo2},
+		{"destinationBy2", &source.DestinationBy2, &draft.DestinationBy2},
+		{"airportOfDestination", &source.AirportOfDestination, &draft.AirportOfDestination},
+		{"optionalShippingInfo1", &source.OptionalShippingInfo1, &draft.OptionalShippingInfo1},
+		{"optionalShippingInfo2", &source.OptionalShippingInfo2, &draft.OptionalShippingInfo2},
+		{"currency", &source.Currency, &draft.Currency},
+		{"chgsCode", &source.ChgsCode, &draft.ChgsCode},
+		{"wtValPpd", &source.WtValPpd, &draft.WtValPpd},
+		{"wtValColl", &source.WtValColl, &draft.WtValColl},
+		{"otherPpd", &source.OtherPpd, &draft.OtherPpd},
+		{"otherColl", &source.OtherColl, &draft.OtherColl},
+		{"declaredValCarriage", &source.DeclaredValCarriage, &draft.DeclaredValCarriage},
+		{"declaredValCustoms", &source.DeclaredValCustoms, &draft.DeclaredValCustoms},
+		{"amountOfInsurance", &source.AmountOfInsurance, &draft.AmountOfInsurance},
+		{"handlingInfomation", &source.HandlingInfomation, &draft.HandlingInfomation},
+		{"sci", &source.SCI, &draft.SCI},
+		{"signature1", &source.Signature1, &draft.Signature1},
+		{"signature2Place", &source.Signature2Place, &draft.Signature2Place},
+		{"signature2Issuing", &source.Signature2Issuing, &draft.Signature2Issuing},
+	}
+	for _, f := range fields {
+		if strings.TrimSpace(*f.src) == "" {
+			continue
+		}
+		*f.dst = *f.src
+		result.CopiedFields = append(result.CopiedFields, f.name)
+	}
+	// Without a number of its own the draft stays with the source's airline.
+	if draft.MAWB == "" && source.AirlineUUID != "" {
+		draft.AirlineUUID = source.AirlineUUID
+		draft.AirlineName = source.AirlineName
+		draft.AirlineLogo = source.AirlineLogo
+		result.CopiedFields = append(result.CopiedFields, "airlineUuid")
+	}
+
+	var items []DraftMAWBItemInput
+	if includeItems {
+		for _, item := range input.Items {
+			items = append(items, cloneItem(item))
+		}
+		if len(items) > 0 {
+			result.CopiedFields = append(result.CopiedFields, "items")
+		}
+	}
+	var charges []DraftMAWBChargeInput
+	for _, c := range input.Charges {
+		charges = append(charges, DraftMAWBChargeInput{Key: c.Key, Value: c.Value})
+	}
+	if len(charges) > 0 {
+		result.CopiedFields = append(result.CopiedFields, "charges")
+	}
+
+	created, err := s.CreateDraftMAWB(ctx, draft, items, charges)
+	if err != nil {
+		return nil, err
+	}
+	result.UUID = created.UUID
+	result.MAWB = created.MAWB
+	result.ItemsCopied = len(items)
+	result.ChargesCopied = len(charges)
+	return result, nil
+}
+
+// cloneItem copies an item without its IDs. The computed figures are cleared so they are
+// worked out again; a total kept as typed, without a rate, is copied as is.
+func cloneItem(item DraftMAWBItemInput) DraftMAWBItemInput {
+	item.ID = 0
+	item.ChargeableWeight = 0
+	if item.RateCharge != 0 {
+		item.Total = 0
+	}
+	if len(item.Dims) > 0 {
+		item.TotalVolume = 0
+	}
+	dims := make([]DraftMAWBItemDimInput, len(item.Dims))
+	for i, d := range item.Dims {
+		d.ID = 0
+		dims[i] = d
+	}
+	item.Dims = dims
+	return item
+}
@@ -3,6 +3,7 @@ package outbound
 import (
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -16,8 +17,12 @@ type DraftMAWB struct {
 	MAWB                        string    `json:"mawb" pg:"mawb"`
 	HAWB                        string    `json:"hawb" pg:"hawb"`
 	ShipperNameAndAddress       string    `json:"shipperNameAndAddress" pg:"shipper_name_and_address"`
+	ShipperPartyUUID            string    `json:"shipperPartyUuid" pg:"shipper_party_uuid"`
 	AWBIssuedBy                 string    `json:"awbIssuedBy" pg:"awb_issued_by"`
 	ConsigneeNameAndAddress     string    `json:"consigneeNameAndAddress" pg:"consignee_name_and_address"`
+	ConsigneePartyUUID          string    `json:"consigneePartyUuid" pg:"consignee_party_uuid"`
+	NotifyNameAndAddress        string    `json:"notifyNameAndAddress" pg:"notify_name_and_address"`
+	NotifyPartyUUID             string    `json:"notifyPartyUuid" pg:"notify_party_uuid"`
 	IssuingCarrierAgentName     string    `json:"issuingCarrierAgentName" pg:"issuing_carrier_agent_name"`
 	AccountingInfomation        string    `json:"accountingInfomation" pg:"accounting_infomation"`
 	AgentsIATACode              string    `json:"agentsIATACode" pg:"agents_iata_code"`
@@ -52,6 +57,7 @@ type DraftMAWB struct {
 	TotalOtherChargesDueAgent   float64   `json:"totalOtherChargesDueAgent" pg:"total_other_charges_due_agent"`
 	TotalOtherChargesDueCarrier float64   `json:"totalOtherChargesDueCarrier" pg:"total_other_charges_due_carrier"`
 	TotalPrepaid                float64   `json:"totalPrepaid" pg:"total_prepaid"`
+	TotalCollect                float64   `json:"totalCollect" pg:"total_collect"`
 	CurrencyConversionRates     string    `json:"currencyConversionRates" pg:"currency_conversion_rates"`
 	Signature1                  string    `json:"signature1" pg:"signature1"`
 	Signature2Date              string    `json:"signature2Date" pg:"signature2_date"`
@@ -89,8 +95,12 @@ type DraftMAWBResponse struct {
 	MAWB                        string    `json:"mawb"`
 	HAWB                        string    `json:"hawb"`
 	ShipperNameAndAddress       string    `json:"shipperNameAndAddress"`
+	ShipperPartyUUID            string    `json:"shipperPartyUuid"`
 	AWBIssuedBy                 string    `json:"awbIssuedBy"`
 	ConsigneeNameAndAddress     string    `json:"consigneeNameAndAddress"`
+	ConsigneePartyUUID          string    `json:"consigneePartyUuid"`
+	NotifyNameAndAddress        string    `json:"notifyNameAndAddress"`
+	NotifyPartyUUID             string    `json:"notifyPartyUuid"`
 	IssuingCarrierAgentName     string    `json:"issuingCarrierAgentName"`
 	AccountingInfomation        string    `json:"accountingInfomation"`
 	AgentsIATACode              string    `json:"agentsIATACode"`
@@ -125,6 +135,7 @@ type DraftMAWBResponse struct {
 	TotalOtherChargesDueAgent   float64   `json:"totalOtherChargesDueAgent"`
 	TotalOtherChargesDueCarrier float64   `json:"totalOtherChargesDueCarrier"`
 	TotalPrepaid                float64   `json:"totalPrepaid"`
+	TotalCollect                float64   `json:"totalCollect"`
 	CurrencyConversionRates     string    `json:"currencyConversionRates"`
 	Signature1                  string    `json:"signature1"`
 	Signature2Date              string    `json:"signature2Date"`
@@ -153,8 +164,12 @@ func (d *DraftMAWB) ToDraftMAWBResponse() *DraftMAWBResponse {
 		MAWB:                        d.MAWB,
 		HAWB:                        d.HAWB,
 		ShipperNameAndAddress:       d.ShipperNameAndAddress,
+		ShipperPartyUUID:            d.ShipperPartyUUID,
 		AWBIssuedBy:                 d.AWBIssuedBy,
 		ConsigneeNameAndAddress:     d.ConsigneeNameAndAddress,
+		ConsigneePartyUUID:          d.ConsigneePartyUUID,
+		NotifyNameAndAddress:        d.NotifyNameAndAddress,
+		NotifyPartyUUID:             d.NotifyPartyUUID,
 		IssuingCarrierAgentName:     d.IssuingCarrierAgentName,
 		AccountingInfomation:        d.AccountingInfomation,
 		AgentsIATACode:              d.AgentsIATACode,
@@ -189,6 +204,7 @@ func (d *DraftMAWB) ToDraftMAWBResponse() *DraftMAWBResponse {
 		TotalOtherChargesDueAgent:   d.TotalOtherChargesDueAgent,
 		TotalOtherChargesDueCarrier: d.TotalOtherChargesDueCarrier,
 		TotalPrepaid:                d.TotalPrepaid,
+		TotalCollect:                d.TotalCollect,
 		CurrencyConversionRates:     d.CurrencyConversionRates,
 		Signature1:                  d.Signature1,
 		Signature2Date:              d.Signature2Date,
@@ -224,6 +240,7 @@ type DraftMAWBItem struct {
 	RateCharge        float64            `json:"rateCharge" pg:"rate_charge"`
 	Total             float64            `json:"total" pg:"total"`
 	NatureAndQuantity string             `json:"natureAndQuantity" pg:"nature_and_quantity"`
+	DimUnit           string             `json:"dimUnit" pg:"dim_unit"`
 	Dims              []DraftMAWBItemDim `json:"dims,omitempty"`
 }
 
@@ -270,8 +287,12 @@ type DraftMAWBInput struct {
 	MAWB                        string                 `json:"mawb"`
 	HAWB                        string                 `json:"hawb"`
 	ShipperNameAndAddress       string                 `json:"shipperNameAndAddress"`
+	ShipperPartyUUID            string                 `json:"shipperPartyUuid"`
 	AWBIssuedBy                 string                 `json:"awbIssuedBy"`
 	ConsigneeNameAndAddress     string                 `json:"consigneeNameAndAddress"`
+	ConsigneePartyUUID          string                 `json:"consigneePartyUuid"`
+	NotifyNameAndAddress        string                 `json:"notifyNameAndAddress"`
+	NotifyPartyUUID             string                 `json:"notifyPartyUuid"`
 	IssuingCarrierAgentName     string                 `json:"issuingCarrierAgentName"`
 	AccountingInfomation        string                 `json:"accountingInfomation"`
 	AgentsIATACode              string                 `json:"agentsIATACode"`
@@ -306,6 +327,7 @@ type DraftMAWBInput struct {
 	TotalOtherChargesDueAgent   float64                `json:"totalOtherChargesDueAgent"`
 	TotalOtherChargesDueCarrier float64                `json:"totalOtherChargesDueCarrier"`
 	TotalPrepaid                float64                `json:"totalPrepaid"`
+	TotalCollect                float64                `json:"totalCollect"`
 	CurrencyConversionRates     string                 `json:"currencyConversionRates"`
 	Signature1                  string                 `json:"signature1"`
 	Signature2Date              string                 `json:"signature2Date"`
@@ -328,6 +350,7 @@ type DraftMAWBItemInput struct {
 	RateCharge        float64                 `json:"rateCharge"`
 	Total             float64                 `json:"total"`
 	NatureAndQuantity string                  `json:"natureAndQuantity"`
+	DimUnit           string                  `json:"dimUnit"` // CM (default) or IN
 	Dims              []DraftMAWBItemDimInput `json:"dims,omitempty"`
 }
 
@@ -349,6 +372,25 @@ func (d *DraftMAWBInput) Bind(r *http.Request) error {
 	return nil
 }
 
+// HandlingText is the Handling Information box as printed. The notify party goes first, its
+// name and address block on one line, then the handling information typed on the form.
+func (d *DraftMAWBInput) HandlingText() string {
+	lines := []string{}
+	for _, line := range strings.Split(strings.ReplaceAll(d.NotifyNameAndAddress, "\\n", "\n"), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) == 0 {
+		return d.HandlingInfomation
+	}
+	notify := "NOTIFY: " + strings.Join(lines, ", ")
+	if strings.TrimSpace(d.HandlingInfomation) == "" {
+		return notify
+	}
+	return notify + "\n" + d.HandlingInfomation
+}
+
 // ToDraftMAWBInput converts DraftMAWBWithRelations to DraftMAWBInput
 func (d *DraftMAWBWithRelations) ToDraftMAWBInput() *DraftMAWBInput {
 	// Convert items
@@ -413,6 +455,7 @@ func (d *DraftMAWBWithRelations) ToDraftMAWBInput() *DraftMAWBInput {
 			RateCharge:        item.RateCharge,
 			Total:             item.Total,
 			NatureAndQuantity: item.NatureAndQuantity,
+			DimUnit:           item.DimUnit,
 			Dims:              dims,
 		}
 	}
@@ -436,8 +479,12 @@ func (d *DraftMAWBWithRelations) ToDraftMAWBInput() *DraftMAWBInput {
 		MAWB:                        d.MAWB,
 		HAWB:                        d.HAWB,
 		ShipperNameAndAddress:       d.ShipperNameAndAddress,
+		ShipperPartyUUID:            d.ShipperPartyUUID,
 		AWBIssuedBy:                 d.AWBIssuedBy,
 		ConsigneeNameAndAddress:     d.ConsigneeNameAndAddress,
+		ConsigneePartyUUID:          d.ConsigneePartyUUID,
+		NotifyNameAndAddress:        d.NotifyNameAndAddress,
+		NotifyPartyUUID:             d.NotifyPartyUUID,
 		IssuingCarrierAgentName:     d.IssuingCarrierAgentName,
 		AccountingInfomation:        d.AccountingInfomation,
 		AgentsIATACode:              d.AgentsIATACode,
@@ -472,6 +519,7 @@ func (d *DraftMAWBWithRelations) ToDraftMAWBInput() *DraftMAWBInput {
 		TotalOtherChargesDueAgent:   d.TotalOtherChargesDueAgent,
 		TotalOtherChargesDueCarrier: d.TotalOtherChargesDueCarrier,
 		TotalPrepaid:                d.TotalPrepaid,
+		TotalCollect:                d.TotalCollect,
 		CurrencyConversionRates:     d.CurrencyConversionRates,
 		Signature1:                  d.Signature1,
 		Signature2Date:              d.Signature2Date,
@@ -496,8 +544,12 @@ func (d *DraftMAWBInput) ToDraftMAWB() *DraftMAWB {
 		MAWB:                        d.MAWB,
 		HAWB:                        d.HAWB,
 		ShipperNameAndAddress:       d.ShipperNameAndAddress,
+		ShipperPartyUUID:            d.ShipperPartyUUID,
 		AWBIssuedBy:                 d.AWBIssuedBy,
 		ConsigneeNameAndAddress:     d.ConsigneeNameAndAddress,
+		ConsigneePartyUUID:          d.ConsigneePartyUUID,
+		NotifyNameAndAddress:        d.NotifyNameAndAddress,
+		NotifyPartyUUID:             d.NotifyPartyUUID,
 		IssuingCarrierAgentName:     d.IssuingCarrierAgentName,
 		AccountingInfomation:        d.AccountingInfomation,
 		AgentsIATACode:              d.AgentsIATACode,
@@ -532,6 +584,7 @@ func (d *DraftMAWBInput) ToDraftMAWB() *DraftMAWB {
 		TotalOtherChargesDueAgent:   d.TotalOtherChargesDueAgent,
 		TotalOtherChargesDueCarrier: d.TotalOtherChargesDueCarrier,
 		TotalPrepaid:                d.TotalPrepaid,
+		TotalCollect:                d.TotalCollect,
 		CurrencyConversionRates:     d.CurrencyConversionRates,
 		Signature1:                  d.Signature1,
 		Signature2Date:              d.Signature2Date,
@@ -0,0 +1,67 @@
+package outbound
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"hpc-express-service/setting"
+)
+
+type fakeParties struct {
+	setting.PartyService
+	parties map[string]*setting.Party
+}
+
+func (f *fakeParties) ResolveParty(ctx context.Context, partyUUID, role string) (*setting.Party, error) {
+	party, ok := f.parties[partyUUID]
+	if !ok {
+		return nil, fmt.Errorf("party '%s' not found", partyUUID)
+	}
+	if !party.HasRole(role) {
+		return nil, fmt.Errorf("party '%s' is not a %s", party.Name, role)
+	}
+	return party, nil
+}
+
+func TestResolvePartiesNotify(t *testing.T) {
+	s := &draftMAWBService{partySvc: &fakeParties{parties: map[string]*setting.Party{
+		"agent":   {Name: "SG AGENT PTE LTD", AddressLine1: "1 Changi North Way", CountryCode: "SG", Roles: []string{setting.PartyRoleNotify}},
+		"shipper": {Name: "HPC EXPRESS", CountryCode: "TH", Roles: []string{setting.PartyRoleShipper}},
+	}}}
+
+	draft := &DraftMAWB{NotifyPartyUUID: "agent", NotifyNameAndAddress: "typed"}
+	if err := s.resolveParties(context.Background(), draft); err != nil {
+		t.Fatalf("resolveParties: %v", err)
+	}
+	if want := "SG AGENT PTE LTD\n1 Changi North Way\nSG"; draft.NotifyNameAndAddress != want {
+		t.Errorf("NotifyNameAndAddress = %q, want %q", draft.NotifyNameAndAddress, want)
+	}
+
+	draft = &DraftMAWB{NotifyPartyUUID: "shipper"}
+	if err := s.resolveParties(context.Background(), draft); err == nil {
+		t.Error("a party without the notify role was accepted as notify party")
+	}
+}
+
+func TestHandlingText(t *testing.T) {
+	tests := []struct {
+		name     string
+		notify   string
+		handling string
+		want     string
+	}{
+		{"handling only", "", "KEEP DRY", "KEEP DRY"},
+		{"notify only", "SG AGENT PTE LTD\n1 Changi North Way\nSG", "", "NOTIFY: SG AGENT PTE LTD, 1 Changi North Way, SG"},
+		{"notify first", "SG AGENT\\nSG", "KEEP DRY", "NOTIFY: SG AGENT, SG\nKEEP DRY"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &DraftMAWBInput{NotifyNameAndAddress: tt.notify, HandlingInfomation: tt.handling}
+			if got := d.HandlingText(); got != tt.want {
+				t.Errorf("HandlingText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package outbound
+
+import (
+	"context"
+	"math"
+	"strings"
+	"time"
+
+	"hpc-express-service/cargoimp"
+
+	"github.com/shopspring/decimal"
+)
+
+// applyRateCard prices a new draft from the airline's rate card. Items without a rate get the
+// rate of their chargeable weight on the lane, or of a cheaper higher break, or the minimum
+// charge, and a draft without other charges gets the charges the airline bills from the
+// origin. Nothing is filled when the airline, the lane or a card in the draft's currency is
+// unknown.
+func (s *draftMAWBService) applyRateCard(ctx context.Context, draftMAWB *DraftMAWB, items []DraftMAWBItemInput, charges []DraftMAWBChargeInput) ([]DraftMAWBChargeInput, error) {
+	if draftMAWB.AirlineUUID == "" {
+		return charges, nil
+	}
+	origin := cargoimp.AirportCode(draftMAWB.AirportOfDeparture)
+	destination := cargoimp.AirportCode(draftMAWB.AirportOfDestination)
+	now := time.Now()
+	breakWeight := s.breakWeight(ctx, draftMAWB)
+
+	var totalKilos float64
+	for i := range items {
+		item := &items[i]
+		pounds := inPounds(item.KgLb)
+		kilos := item.ChargeableWeight
+		if pounds {
+			kilos /= poundsPerKilogram
+		}
+		totalKilos += kilos
+
+		if item.RateCharge != 0 || origin == "" || destination == "" {
+			continue
+		}
+		card, err := s.rateCardSvc.FindRateCard(ctx, draftMAWB.AirlineUUID, origin, destination, cardRateClass(item.RateClass), now)
+		if err != nil {
+			return nil, err
+		}
+		if card == nil || (draftMAWB.Currency != "" && !strings.EqualFold(card.Currency, strings.TrimSpace(draftMAWB.Currency))) {
+			continue
+		}
+		if draftMAWB.Currency == "" {
+			draftMAWB.Currency = card.Currency
+		}
+
+		rateClass, rate, _, _ := card.Calculate(decimal.NewFromFloat(kilos))
+		item.RateClass = rateClass
+		item.RateCharge = cardRate(rate, rateClass, pounds)
+		// Weighed again, as a cheaper higher break is charged at its own weight.
+		item.ChargeableWeight, item.Total = 0, 0
+		if err := computeItem(item, s.volumetricDivisor, breakWeight); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(charges) > 0 {
+		return charges, nil
+	}
+	otherCharges, err := s.rateCardSvc.GetAirlineOtherCharges(ctx, draftMAWB.AirlineUUID, origin)
+	if err != nil {
+		return nil, err
+	}
+	weight := decimal.NewFromFloat(totalKilos)
+	for _, c := range otherCharges {
+		amount := c.Calculate(weight)
+		if amount.IsZero() {
+			continue
+		}
+		charges = append(charges, DraftMAWBChargeInput{Key: c.Key(), Value: amount.InexactFloat64()})
+	}
+	return charges, nil
+}
+
+// breakWeight returns the weight of a cheaper higher break of the airline's card for the lane
+// when an item is rated at that break, and the item's own weight otherwise. Only the card
+// decides: a client cannot charge an item on a higher weight the card does not price lower.
+func (s *draftMAWBService) breakWeight(ctx context.Context, draftMAWB *DraftMAWB) breakWeightFunc {
+	origin := cargoimp.AirportCode(draftMAWB.AirportOfDeparture)
+	destination := cargoimp.AirportCode(draftMAWB.AirportOfDestination)
+	now := time.Now()
+
+	return func(item *DraftMAWBItemInput, weight float64) (float64, error) {
+		if item.RateCharge == 0 || draftMAWB.AirlineUUID == "" || origin == "" || destination == "" {
+			return weight, nil
+		}
+		card, err := s.rateCardSvc.FindRateCard(ctx, draftMAWB.AirlineUUID, origin, destination, cardRateClass(item.RateClass), now)
+		if err != nil {
+			return 0, err
+		}
+		if card == nil || (draftMAWB.Currency != "" && !strings.EqualFold(card.Currency, strings.TrimSpace(draftMAWB.Currency))) {
+			return weight, nil
+		}
+
+		pounds := inPounds(item.KgLb)
+		kilos := weight
+		if pounds {
+			kilos /= poundsPerKilogram
+		}
+		rateClass, rate, chargedWeight, _ := card.Calculate(decimal.NewFromFloat(kilos))
+		chargedKilos := chargedWeight.InexactFloat64()
+		if chargedKilos <= kilos || math.Abs(item.RateCharge-cardRate(rate, rateClass, pounds)) > 0.0001 {
+			return weight, nil
+		}
+		if pounds {
+			return math.Ceil(chargedKilos*poundsPerKilogram - 1e-9), nil
+		}
+		return chargedKilos, nil
+	}
+}
+
+// cardRate returns a card rate in the item's unit. Cards are per kilo; a pound rate comes to
+// the same charge. A minimum (M) rate is the charge itself.
+func cardRate(rate decimal.Decimal, rateClass string, pounds bool) float64 {
+	if pounds && rateClass != "M" {
+		return roundTo(rate.InexactFloat64()/poundsPerKilogram, 4)
+	}
+	return rate.InexactFloat64()
+}
+
+// applyMinimumCharges raises an item whose total is under the minimum charge of the airline's
+// card for the lane to that minimum, rated M. Items without a rate, and drafts in another
+// currency than the card, are left as they are.
+func (s *draftMAWBService) applyMinimumCharges(ctx context.Context, draftMAWB *DraftMAWB, items []DraftMAWBItemInput) error {
+	origin := cargoimp.AirportCode(draftMAWB.AirportOfDeparture)
+	destination := cargoimp.AirportCode(draftMAWB.AirportOfDestination)
+	if draftMAWB.AirlineUUID == "" || origin == "" || destination == "" {
+		return nil
+	}
+	now := time.Now()
+
+	for i := range items {
+		item := &items[i]
+		if item.RateCharge == 0 {
+			continue
+		}
+		card, err := s.rateCardSvc.FindRateCard(ctx, draftMAWB.AirlineUUID, origin, destination, cardRateClass(item.RateClass), now)
+		if err != nil {
+			return err
+		}
+		if card == nil || (draftMAWB.Currency != "" && !strings.EqualFold(card.Currency, strings.TrimSpace(draftMAWB.Currency))) {
+			continue
+		}
+		minimum := card.MinimumCharge.InexactFloat64()
+		if item.Total >= minimum {
+			continue
+		}
+		item.RateClass = "M"
+		item.RateCharge = minimum
+		item.Total = roundTo(minimum, 2)
+	}
+	return nil
+}
+
+// cardRateClass returns the class of the card pricing an item. Normal, quantity and minimum
+// rates all come from the general cargo card.
+func cardRateClass(rateClass string) string {
+	switch rateClass = strings.ToUpper(strings.TrimSpace(rateClass)); rateClass {
+	case "", "N", "Q", "M":
+		return "N"
+	}
+	return rateClass
+}
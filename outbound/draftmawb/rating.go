@@ -0,0 +1,115 @@
+package outbound
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// DefaultVolumetricDivisor is the IATA 6000 cubic centimetres per kilogram, used when no
+// divisor is configured.
+const DefaultVolumetricDivisor = 6000
+
+const (
+	cubicCentimetresPerInch = 16.387064
+	poundsPerKilogram       = 2.20462
+)
+
+// breakWeightFunc returns the weight an item of the given chargeable weight is charged on,
+// which is higher when its rate is that of a cheaper higher break.
+type breakWeightFunc func(item *DraftMAWBItemInput, weight float64) (float64, error)
+
+// computeItems works out the volume, chargeable weight and total of every item from its
+// dims, gross weight and rate, and stores them on the item. A value the client sent that
+// disagrees with the computed one is rejected; zero means not sent. breakWeight may be nil
+// when no rate card applies.
+func computeItems(items []DraftMAWBItemInput, divisor float64, breakWeight breakWeightFunc) error {
+	for i := range items {
+		if err := computeItem(&items[i], divisor, breakWeight); err != nil {
+			return fmt.Errorf("item %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+func computeItem(item *DraftMAWBItemInput, divisor float64, breakWeight breakWeightFunc) error {
+	if divisor <= 0 {
+		divisor = DefaultVolumetricDivisor
+	}
+
+	// Without dims the volume typed on the item is used as is.
+	cubicCentimetres := item.TotalVolume * 1e6
+	if len(item.Dims) > 0 {
+		inches := strings.HasPrefix(strings.ToUpper(strings.TrimSpace(item.DimUnit)), "IN")
+		cubicCentimetres = 0
+		for _, d := range item.Dims {
+			if d.Length < 0 || d.Width < 0 || d.Height < 0 || d.Count < 0 {
+				return fmt.Errorf("dims must not be negative")
+			}
+			v := float64(d.Length) * float64(d.Width) * float64(d.Height) * float64(d.Count)
+			if inches {
+				v *= cubicCentimetresPerInch
+			}
+			cubicCentimetres += v
+		}
+		volume := roundTo(cubicCentimetres/1e6, 3)
+		if err := agree("totalVolume", item.TotalVolume, volume, 0.01); err != nil {
+			return err
+		}
+		item.TotalVolume = volume
+	}
+
+	// Kilos are charged by the next half kilo and pounds by the next whole pound.
+	volumetricWeight := cubicCentimetres / divisor
+	chargeableWeight := math.Max(item.GrossWeight, volumetricWeight)
+	if inPounds(item.KgLb) {
+		chargeableWeight = math.Max(item.GrossWeight, volumetricWeight*poundsPerKilogram)
+		chargeableWeight = math.Ceil(chargeableWeight - 1e-9)
+	} else {
+		chargeableWeight = math.Ceil(chargeableWeight*2-1e-9) / 2
+	}
+	if breakWeight != nil {
+		weight, err := breakWeight(item, chargeableWeight)
+		if err != nil {
+			return err
+		}
+		chargeableWeight = math.Max(chargeableWeight, weight)
+	}
+	if err := agree("chargeableWeight", item.ChargeableWeight, chargeableWeight, 0.001); err != nil {
+		return err
+	}
+	item.ChargeableWeight = chargeableWeight
+
+	// A minimum (M) rate is the charge itself. Without a rate the total is kept as typed,
+	// as for charges shown "as agreed".
+	if item.RateCharge == 0 {
+		return nil
+	}
+	total := roundTo(item.RateCharge*chargeableWeight, 2)
+	if strings.EqualFold(strings.TrimSpace(item.RateClass), "M") {
+		total = roundTo(item.RateCharge, 2)
+	}
+	if err := agree("total", item.Total, total, 0.01); err != nil {
+		return err
+	}
+	item.Total = total
+	return nil
+}
+
+// inPounds reports whether an item's weights are in pounds rather than kilos.
+func inPounds(kgLb string) bool {
+	return strings.HasPrefix(strings.ToUpper(strings.TrimSpace(kgLb)), "L")
+}
+
+// agree reports a sent value that differs from the computed one by more than tolerance.
+func agree(field string, sent, computed, tolerance float64) error {
+	if sent != 0 && math.Abs(sent-computed) > tolerance {
+		return fmt.Errorf("%s %v does not match the computed %v", field, sent, computed)
+	}
+	return nil
+}
+
+func roundTo(v float64, places int) float64 {
+	p := math.Pow(10, float64(places))
+	return math.Round(v*p) / p
+}
@@ -0,0 +1,236 @@
+package outbound
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"hpc-express-service/setting"
+)
+
+func TestComputeItems(t *testing.T) {
+	tests := []struct {
+		name      string
+		item      DraftMAWBItemInput
+		want      DraftMAWBItemInput
+		wantError string
+	}{
+		{
+			name: "volumetric weight over gross weight",
+			item: DraftMAWBItemInput{GrossWeight: 10, RateCharge: 10, Dims: []DraftMAWBItemDimInput{{Length: 50, Width: 40, Height: 30, Count: 2}}},
+			want: DraftMAWBItemInput{TotalVolume: 0.12, ChargeableWeight: 20, Total: 200},
+		},
+		{
+			name: "dims in inches",
+			item: DraftMAWBItemInput{GrossWeight: 10, DimUnit: "IN", Dims: []DraftMAWBItemDimInput{{Length: 20, Width: 20, Height: 20, Count: 1}}},
+			want: DraftMAWBItemInput{TotalVolume: 0.131, ChargeableWeight: 22},
+		},
+		{
+			name: "kilos rounded up to the next half kilo",
+			item: DraftMAWBItemInput{GrossWeight: 10.2, RateCharge: 10},
+			want: DraftMAWBItemInput{ChargeableWeight: 10.5, Total: 105},
+		},
+		{
+			name: "a half kilo stays",
+			item: DraftMAWBItemInput{GrossWeight: 10.5},
+			want: DraftMAWBItemInput{ChargeableWeight: 10.5},
+		},
+		{
+			name: "pounds rounded up to the next whole pound",
+			item: DraftMAWBItemInput{GrossWeight: 22.1, KgLb: "L", RateCharge: 2},
+			want: DraftMAWBItemInput{ChargeableWeight: 23, Total: 46},
+		},
+		{
+			name: "volumetric weight in pounds",
+			item: DraftMAWBItemInput{GrossWeight: 10, KgLb: "LB", DimUnit: "IN", Dims: []DraftMAWBItemDimInput{{Length: 20, Width: 20, Height: 20, Count: 1}}},
+			want: DraftMAWBItemInput{TotalVolume: 0.131, ChargeableWeight: 49},
+		},
+		{
+			name: "minimum rate is the charge",
+			item: DraftMAWBItemInput{GrossWeight: 3, RateClass: "M", RateCharge: 75},
+			want: DraftMAWBItemInput{ChargeableWeight: 3, Total: 75},
+		},
+		{
+			name: "matching client values are kept",
+			item: DraftMAWBItemInput{GrossWeight: 10, ChargeableWeight: 10, RateCharge: 10, Total: 100},
+			want: DraftMAWBItemInput{ChargeableWeight: 10, Total: 100},
+		},
+		{
+			name: "total without a rate stays as typed",
+			item: DraftMAWBItemInput{GrossWeight: 10, Total: 123},
+			want: DraftMAWBItemInput{ChargeableWeight: 10, Total: 123},
+		},
+		{
+			name:      "higher client weight is rejected",
+			item:      DraftMAWBItemInput{GrossWeight: 10, ChargeableWeight: 45, RateCharge: 10},
+			wantError: "chargeableWeight 45 does not match the computed 10",
+		},
+		{
+			name:      "wrong client volume is rejected",
+			item:      DraftMAWBItemInput{GrossWeight: 10, TotalVolume: 0.5, Dims: []DraftMAWBItemDimInput{{Length: 50, Width: 40, Height: 30, Count: 2}}},
+			wantError: "totalVolume 0.5 does not match the computed 0.12",
+		},
+		{
+			name:      "wrong client total is rejected",
+			item:      DraftMAWBItemInput{GrossWeight: 10, RateCharge: 10, Total: 90},
+			wantError: "total 90 does not match the computed 100",
+		},
+		{
+			name:      "negative dims are rejected",
+			item:      DraftMAWBItemInput{GrossWeight: 10, Dims: []DraftMAWBItemDimInput{{Length: -1, Width: 1, Height: 1, Count: 1}}},
+			wantError: "dims must not be negative",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items := []DraftMAWBItemInput{tt.item}
+			err := computeItems(items, 0, nil)
+			if tt.wantError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantError) {
+					t.Fatalf("err = %v, want %q", err, tt.wantError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("computeItems: %v", err)
+			}
+			got := items[0]
+			if got.TotalVolume != tt.want.TotalVolume || got.ChargeableWeight != tt.want.ChargeableWeight || got.Total != tt.want.Total {
+				t.Errorf("got volume %v, weight %v, total %v; want %v, %v, %v",
+					got.TotalVolume, got.ChargeableWeight, got.Total, tt.want.TotalVolume, tt.want.ChargeableWeight, tt.want.Total)
+			}
+		})
+	}
+}
+
+type fakeRateCards struct {
+	setting.RateCardService
+	card *setting.AirlineRateCard
+}
+
+func (f *fakeRateCards) FindRateCard(ctx context.Context, airlineUUID, origin, destination, rateClass string, on time.Time) (*setting.AirlineRateCard, error) {
+	if rateClass != f.card.RateClass {
+		return nil, nil
+	}
+	return f.card, nil
+}
+
+func (f *fakeRateCards) GetAirlineOtherCharges(ctx context.Context, airlineUUID, origin string) ([]setting.AirlineOtherCharge, error) {
+	return nil, nil
+}
+
+func newRatedService() *draftMAWBService {
+	return &draftMAWBService{rateCardSvc: &fakeRateCards{card: &setting.AirlineRateCard{
+		RateClass:     "N",
+		Currency:      "THB",
+		MinimumCharge: decimal.NewFromInt(1000),
+		Breaks: []*setting.RateBreak{
+			{FromWeight: decimal.NewFromInt(0), Rate: decimal.NewFromInt(100)},
+			{FromWeight: decimal.NewFromInt(45), Rate: decimal.NewFromInt(80)},
+		},
+	}}}
+}
+
+func ratedDraft() *DraftMAWB {
+	return &DraftMAWB{AirlineUUID: "airline", AirportOfDeparture: "BKK", AirportOfDestination: "HKG", Currency: "THB"}
+}
+
+func TestComputeItemsCheaperHigherBreak(t *testing.T) {
+	tests := []struct {
+		name       string
+		item       DraftMAWBItemInput
+		wantWeight float64
+		wantTotal  float64
+		wantError  string
+	}{
+		{
+			name:       "rated at the higher break",
+			item:       DraftMAWBItemInput{GrossWeight: 40, RateClass: "Q", RateCharge: 80},
+			wantWeight: 45, wantTotal: 3600,
+		},
+		{
+			name:       "client sends the break weight",
+			item:       DraftMAWBItemInput{GrossWeight: 40, RateClass: "Q", RateCharge: 80, ChargeableWeight: 45, Total: 3600},
+			wantWeight: 45, wantTotal: 3600,
+		},
+		{
+			name:       "pound rate of the higher break",
+			item:       DraftMAWBItemInput{GrossWeight: 88, KgLb: "L", RateClass: "Q", RateCharge: 36.2874},
+			wantWeight: 100, wantTotal: 3628.74,
+		},
+		{
+			name:       "rated at its own break",
+			item:       DraftMAWBItemInput{GrossWeight: 40, RateClass: "N", RateCharge: 100},
+			wantWeight: 40, wantTotal: 4000,
+		},
+		{
+			name:      "break weight without the break rate is rejected",
+			item:      DraftMAWBItemInput{GrossWeight: 40, RateClass: "N", RateCharge: 100, ChargeableWeight: 45},
+			wantError: "chargeableWeight 45 does not match the computed 40",
+		},
+		{
+			name:      "weight above the break is rejected",
+			item:      DraftMAWBItemInput{GrossWeight: 40, RateClass: "Q", RateCharge: 80, ChargeableWeight: 50},
+			wantError: "chargeableWeight 50 does not match the computed 45",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newRatedService()
+			items := []DraftMAWBItemInput{tt.item}
+			err := computeItems(items, 0, s.breakWeight(context.Background(), ratedDraft()))
+			if tt.wantError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantError) {
+					t.Fatalf("err = %v, want %q", err, tt.wantError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("computeItems: %v", err)
+			}
+			if items[0].ChargeableWeight != tt.wantWeight || items[0].Total != tt.wantTotal {
+				t.Errorf("got weight %v, total %v; want %v, %v", items[0].ChargeableWeight, items[0].Total, tt.wantWeight, tt.wantTotal)
+			}
+		})
+	}
+}
+
+func TestApplyRateCard(t *testing.T) {
+	tests := []struct {
+		name       string
+		gross      float64
+		wantClass  string
+		wantRate   float64
+		wantWeight float64
+		wantTotal  float64
+	}{
+		{"under the minimum", 5, "M", 1000, 5, 1000},
+		{"normal rate", 20, "N", 100, 20, 2000},
+		{"cheaper higher break", 40, "Q", 80, 45, 3600},
+		{"quantity rate", 50, "Q", 80, 50, 4000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newRatedService()
+			draft := ratedDraft()
+			items := []DraftMAWBItemInput{{GrossWeight: tt.gross}}
+			if err := computeItems(items, 0, s.breakWeight(context.Background(), draft)); err != nil {
+				t.Fatalf("computeItems: %v", err)
+			}
+			if _, err := s.applyRateCard(context.Background(), draft, items, nil); err != nil {
+				t.Fatalf("applyRateCard: %v", err)
+			}
+			got := items[0]
+			if got.RateClass != tt.wantClass || got.RateCharge != tt.wantRate || got.ChargeableWeight != tt.wantWeight || got.Total != tt.wantTotal {
+				t.Errorf("got %s %v x %v = %v; want %s %v x %v = %v",
+					got.RateClass, got.RateCharge, got.ChargeableWeight, got.Total, tt.wantClass, tt.wantRate, tt.wantWeight, tt.wantTotal)
+			}
+		})
+	}
+}
@@ -196,6 +196,7 @@ func (r *draftMAWBRepository) CreateWithRelations(ctx context.Context, draftMAWB
 			RateCharge:        itemInput.RateCharge,
 			Total:             itemInput.Total,
 			NatureAndQuantity: itemInput.NatureAndQuantity,
+			DimUnit:           itemInput.DimUnit,
 		}
 		_, err = db.Model(item).Insert()
 		if err != nil {
@@ -282,6 +283,7 @@ func (r *draftMAWBRepository) UpdateWithRelations(ctx context.Context, draftMAWB
 			RateCharge:        itemInput.RateCharge,
 			Total:             itemInput.Total,
 			NatureAndQuantity: itemInput.NatureAndQuantity,
+			DimUnit:           itemInput.DimUnit,
 		}
 		_, err = db.Model(item).Insert()
 		if err != nil {
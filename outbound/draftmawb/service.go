@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"hpc-express-service/common"
 	"hpc-express-service/setting"
+	"strings"
 )
 
 type DraftMAWBService interface {
@@ -12,6 +13,8 @@ type DraftMAWBService interface {
 	GetDraftMAWBByUUID(ctx context.Context, uuid string) (*DraftMAWB, error)
 	CreateDraftMAWB(ctx context.Context, draftMAWB *DraftMAWB, items []DraftMAWBItemInput, charges []DraftMAWBChargeInput) (*DraftMAWB, error)
 	UpdateDraftMAWB(ctx context.Context, draftMAWB *DraftMAWB, items []DraftMAWBItemInput, charges []DraftMAWBChargeInput) (*DraftMAWB, error)
+	PreviewDraftMAWB(ctx context.Context, input *DraftMAWBInput) error
+	CloneDraftMAWB(ctx context.Context, mawbInfoUUID, mawb, sourceUUID string, includeItems bool) (*DraftMAWBCloneResult, error)
 	UpdateDraftMAWBStatus(ctx context.Context, mawbUUID, statusUUID string) error
 	GetAllDraftMAWB(ctx context.Context, startDate, endDate string) ([]DraftMAWBListItem, error)
 	CancelDraftMAWB(ctx context.Context, mawbUUID string) error
@@ -21,12 +24,18 @@ type DraftMAWBService interface {
 }
 
 type draftMAWBService struct {
-	repo      DraftMAWBRepository
-	statusSvc setting.MasterStatusService
+	repo              DraftMAWBRepository
+	statusSvc         setting.MasterStatusService
+	airlineSvc        setting.AirlineService
+	rateCardSvc       setting.RateCardService
+	partySvc          setting.PartyService
+	volumetricDivisor float64
 }
 
-func NewDraftMAWBService(repo DraftMAWBRepository, statusSvc setting.MasterStatusService) DraftMAWBService {
-	return &draftMAWBService{repo: repo, statusSvc: statusSvc}
+// NewDraftMAWBService returns the draft MAWB service. volumetricDivisor is the cubic
+// centimetres per kilogram of volumetric weight (DefaultVolumetricDivisor when zero).
+func NewDraftMAWBService(repo DraftMAWBRepository, statusSvc setting.MasterStatusService, airlineSvc setting.AirlineService, rateCardSvc setting.RateCardService, partySvc setting.PartyService, volumetricDivisor float64) DraftMAWBService {
+	return &draftMAWBService{repo: repo, statusSvc: statusSvc, airlineSvc: airlineSvc, rateCardSvc: rateCardSvc, partySvc: partySvc, volumetricDivisor: volumetricDivisor}
 }
 
 func (s *draftMAWBService) GetDraftMAWBByMAWBUUID(ctx context.Context, mawbUUID string) (*DraftMAWB, error) {
@@ -50,7 +59,69 @@ func (s *draftMAWBService) setDefaultStatus(ctx context.Context, draftMAWB *Draf
 	return nil
 }
 
+// resolveAirline checks the MAWB number and sets the airline its prefix belongs to. A draft
+// saved before the number is known keeps the airline chosen on the form.
+func (s *draftMAWBService) resolveAirline(ctx context.Context, draftMAWB *DraftMAWB) error {
+	if strings.TrimSpace(draftMAWB.MAWB) == "" {
+		return nil
+	}
+	mawb, airline, err := s.airlineSvc.ResolveMawb(ctx, draftMAWB.MAWB)
+	if err != nil {
+		return err
+	}
+	draftMAWB.MAWB = mawb
+	draftMAWB.AirlineUUID = airline.UUID
+	draftMAWB.AirlineName = airline.Name
+	draftMAWB.AirlineLogo = airline.LogoURL
+	return nil
+}
+
+// resolveParties writes the name and address block of the shipper, consignee and notify party
+// picked from the address book. Boxes without a party keep the text typed on the form.
+func (s *draftMAWBService) resolveParties(ctx context.Context, draftMAWB *DraftMAWB) error {
+	if draftMAWB.ShipperPartyUUID != "" {
+		party, err := s.partySvc.ResolveParty(ctx, draftMAWB.ShipperPartyUUID, setting.PartyRoleShipper)
+		if err != nil {
+			return err
+		}
+		draftMAWB.ShipperNameAndAddress = party.TextBlock()
+	}
+	if draftMAWB.ConsigneePartyUUID != "" {
+		party, err := s.partySvc.ResolveParty(ctx, draftMAWB.ConsigneePartyUUID, setting.PartyRoleConsignee)
+		if err != nil {
+			return err
+		}
+		draftMAWB.ConsigneeNameAndAddress = party.TextBlock()
+	}
+	if draftMAWB.NotifyPartyUUID != "" {
+		party, err := s.partySvc.ResolveParty(ctx, draftMAWB.NotifyPartyUUID, setting.PartyRoleNotify)
+		if err != nil {
+			return err
+		}
+		draftMAWB.NotifyNameAndAddress = party.TextBlock()
+	}
+	return nil
+}
+
 func (s *draftMAWBService) CreateDraftMAWB(ctx context.Context, draftMAWB *DraftMAWB, items []DraftMAWBItemInput, charges []DraftMAWBChargeInput) (*DraftMAWB, error) {
+	if err := s.resolveAirline(ctx, draftMAWB); err != nil {
+		return nil, err
+	}
+	if err := s.resolveParties(ctx, draftMAWB); err != nil {
+		return nil, err
+	}
+	if err := computeItems(items, s.volumetricDivisor, s.breakWeight(ctx, draftMAWB)); err != nil {
+		return nil, err
+	}
+	charges, err := s.applyRateCard(ctx, draftMAWB, items, charges)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applyMinimumCharges(ctx, draftMAWB, items); err != nil {
+		return nil, err
+	}
+	draftMAWB.computeCharges(items, charges)
+
 	tx, txCtx, err := common.BeginTx(ctx)
 	if err != nil {
 		return nil, err
@@ -74,6 +145,20 @@ func (s *draftMAWBService) CreateDraftMAWB(ctx context.Context, draftMAWB *Draft
 }
 
 func (s *draftMAWBService) UpdateDraftMAWB(ctx context.Context, draftMAWB *DraftMAWB, items []DraftMAWBItemInput, charges []DraftMAWBChargeInput) (*DraftMAWB, error) {
+	if err := s.resolveAirline(ctx, draftMAWB); err != nil {
+		return nil, err
+	}
+	if err := s.resolveParties(ctx, draftMAWB); err != nil {
+		return nil, err
+	}
+	if err := computeItems(items, s.volumetricDivisor, s.breakWeight(ctx, draftMAWB)); err != nil {
+		return nil, err
+	}
+	if err := s.applyMinimumCharges(ctx, draftMAWB, items); err != nil {
+		return nil, err
+	}
+	draftMAWB.computeCharges(items, charges)
+
 	tx, txCtx, err := common.BeginTx(ctx)
 	if err != nil {
 		return nil, err
@@ -95,6 +180,24 @@ func (s *draftMAWBService) UpdateDraftMAWB(ctx context.Context, draftMAWB *Draft
 
 	return result, nil
 }
+
+// PreviewDraftMAWB rates the items and works out the charges summary of a draft as saving it
+// would, without saving it.
+func (s *draftMAWBService) PreviewDraftMAWB(ctx context.Context, input *DraftMAWBInput) error {
+	draftMAWB := input.ToDraftMAWB()
+	if err := s.resolveAirline(ctx, draftMAWB); err != nil {
+		return err
+	}
+	if err := computeItems(input.Items, s.volumetricDivisor, s.breakWeight(ctx, draftMAWB)); err != nil {
+		return err
+	}
+	if err := s.applyMinimumCharges(ctx, draftMAWB, input.Items); err != nil {
+		return err
+	}
+	input.ComputeCharges()
+	return nil
+}
+
 func (s *draftMAWBService) UpdateDraftMAWBStatus(ctx context.Context, mawbUUID, statusUUID string) error {
 	tx, txCtx, err := common.BeginTx(ctx)
 	if err != nil {
@@ -0,0 +1,68 @@
+package outbound
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"hpc-express-service/utils"
+)
+
+// PreExportConverter converts an uploaded marketplace export into pre-export detail rows.
+// customerUUID, which may be empty, selects the customer's exporter profiles.
+type PreExportConverter interface {
+	TemplateCode() string
+	TemplateName() string
+	ConvertToPreExportDetails(ctx context.Context, customerUUID string, fileBytes []byte) ([]*utils.InsertPreExportDetailManifestModel, error)
+}
+
+// ConverterRegistry resolves the converter for a template code.
+type ConverterRegistry struct {
+	converters map[string]PreExportConverter
+}
+
+func NewConverterRegistry(converters ...PreExportConverter) *ConverterRegistry {
+	r := &ConverterRegistry{
+		converters: make(map[string]PreExportConverter),
+	}
+
+	for _, c := range converters {
+		r.Register(c)
+	}
+
+	return r
+}
+
+// Register adds a converter, replacing any converter already registered under the same template code.
+func (r *ConverterRegistry) Register(c PreExportConverter) {
+	r.converters[c.TemplateCode()] = c
+}
+
+func (r *ConverterRegistry) Get(templateCode string) (PreExportConverter, error) {
+	c, ok := r.converters[templateCode]
+	if !ok {
+		return nil, errors.New("not found template")
+	}
+
+	return c, nil
+}
+
+// Converters returns the registered converters ordered by template code.
+func (r *ConverterRegistry) Converters() []PreExportConverter {
+	list := make([]PreExportConverter, 0, len(r.converters))
+	for _, code := range r.TemplateCodes() {
+		list = append(list, r.converters[code])
+	}
+
+	return list
+}
+
+func (r *ConverterRegistry) TemplateCodes() []string {
+	codes := make([]string, 0, len(r.converters))
+	for code := range r.converters {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	return codes
+}
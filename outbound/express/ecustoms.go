@@ -0,0 +1,186 @@
+package outbound
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"hpc-express-service/ecustoms"
+	"hpc-express-service/utils"
+)
+
+// buildExportDeclarations maps pre-export lines onto e-Export declarations, one per HAWB in the
+// order the HAWBs first appear. mawb, when set, replaces the MAWB stamped on the lines.
+func buildExportDeclarations(manifest *utils.GetHeaderManifestPreExport, mawb string, now time.Time) ([]*ecustoms.ExportDeclaration, error) {
+	departureDate, ok := parseDepartureDate(manifest.DepartureDate)
+	if !ok {
+		return nil, errors.New("departure date is required to declare the shipment")
+	}
+
+	declarations := []*ecustoms.ExportDeclaration{}
+	byHawb := map[string]*ecustoms.ExportDeclaration{}
+	netWeights := map[string]float64{}
+	grossWeights := map[string]float64{}
+	for _, v := range manifest.Details {
+		d, ok := byHawb[v.HouseAirWaybill]
+		if !ok {
+			d = newExportDeclaration(manifest, v, mawb, departureDate, now)
+			byHawb[v.HouseAirWaybill] = d
+			declarations = append(declarations, d)
+		}
+
+		d.Header.TotalPackage += v.PackageAmount
+		netWeights[v.HouseAirWaybill] += v.NetWeight
+		grossWeights[v.HouseAirWaybill] += v.GrossWeight
+
+		valuation := &ecustoms.ExportValuation{
+			FobBaht: ecustoms.FormatAmount(v.FobValueBaht),
+		}
+		if v.CurrencyCode != "" && v.CurrencyCode != "THB" {
+			valuation.FobForeign = ecustoms.FormatAmount(v.FobValueForeign)
+			valuation.CurrencyCode = v.CurrencyCode
+			valuation.ExchangeRate = ecustoms.FormatRate(float64(v.ExchangeRate))
+		}
+		if v.FreightAmount > 0 {
+			valuation.Freight = &ecustoms.ChargeModel{Amount: ecustoms.FormatAmount(float64(v.FreightAmount)), CurrencyCode: v.FreightAmountCurrencyCode}
+		}
+		if v.InsuranceAmount > 0 {
+			valuation.Insurance = &ecustoms.ChargeModel{Amount: ecustoms.FormatAmount(float64(v.InsuranceAmount)), CurrencyCode: v.InsuranceAmountCurrencyCode}
+		}
+
+		d.Items = append(d.Items, &ecustoms.ExportDeclarationItem{
+			ItemNumber: int64(len(d.Items) + 1),
+			Tariff: &ecustoms.TariffModel{
+				TariffCode:      strings.TrimSpace(v.TariffCode),
+				TariffSequence:  strings.TrimSpace(v.TariffSequence),
+				StatisticalCode: strings.TrimSpace(v.StatCode),
+			},
+			DescriptionEnglish:  strings.TrimSpace(v.EnglishDescriptionOfGoods),
+			DescriptionThai:     strings.TrimSpace(v.ThaiDescriptionOfGoods),
+			Quantity:            ecustoms.FormatWeight(float64(v.Quantity)),
+			QuantityUnitCode:    v.QuantityUnitCode,
+			NetWeight:           ecustoms.FormatWeight(v.NetWeight),
+			NetWeightUnitCode:   v.NetWeightUnitCode,
+			GrossWeight:         ecustoms.FormatWeight(v.GrossWeight),
+			GrossWeightUnitCode: v.GrossWeightUnitCode,
+			PackageAmount:       v.PackageAmount,
+			PackageUnitCode:     v.PackageUnitCode,
+			Remark:              v.Remark,
+			Valuation:           valuation,
+		})
+	}
+
+	if len(declarations) == 0 {
+		return nil, errors.New("no details to declare")
+	}
+	for _, d := range declarations {
+		d.Header.TotalNetWeight = ecustoms.FormatWeight(netWeights[d.Header.HouseAirWaybill])
+		d.Header.TotalGrossWeight = ecustoms.FormatWeight(grossWeights[d.Header.HouseAirWaybill])
+	}
+
+	return declarations, nil
+}
+
+func newExportDeclaration(manifest *utils.GetHeaderManifestPreExport, v *utils.GetDetailManifestPreExport, mawb string, departureDate, now time.Time) *ecustoms.ExportDeclaration {
+	if mawb == "" {
+		mawb = v.MasterAirWaybill
+	}
+
+	return &ecustoms.ExportDeclaration{
+		DocumentControl: &ecustoms.ExportDocumentControl{
+			ReferenceNumber: v.HouseAirWaybill,
+			DocumentType:    "EXPRESS_EXPORT",
+			MessageDate:     ecustoms.FormatMessageDate(now),
+		},
+		Header: &ecustoms.ExportHeader{
+			MasterAirWaybill:       mawb,
+			HouseAirWaybill:        v.HouseAirWaybill,
+			Category:               strconv.FormatInt(v.Category, 10),
+			VesselName:             manifest.VasselName,
+			DepartureDate:          departureDate.Format("2006-01-02"),
+			ReleasePort:            strconv.FormatInt(manifest.ReleasePort, 10),
+			LoadingPort:            strconv.FormatInt(manifest.LoadingPort, 10),
+			PurchaseCountryCode:    v.PurchaseCountryCode,
+			DestinationCountryCode: v.DestinationCountryCode,
+			PackageUnitCode:        manifest.TotalPackageUnitCode,
+			WeightUnitCode:         "KGM",
+		},
+		Exporter: &ecustoms.ExportExporter{
+			TaxNumber:        v.ConsignorCompanyTaxNumber,
+			Branch:           v.ConsignorCompanyBranch,
+			Name:             v.ConsignorName,
+			StreetAndAddress: v.ConsignorStreetAndAddress,
+			District:         v.ConsignorDistrict,
+			SubProvince:      v.ConsignorSubProvince,
+			Province:         v.ConsignorProvince,
+			Postcode:         v.ConsignorPostcode,
+			Email:            v.ConsignorEmail,
+		},
+		Consignee: &ecustoms.ExportConsignee{
+			Name:             v.ConsigneeName,
+			StreetAndAddress: v.ConsigneeStreetAndAddress,
+			District:         v.ConsigneeDistrict,
+			SubProvince:      v.ConsigneeSubProvince,
+			Province:         v.ConsigneeProvince,
+			Postcode:         v.ConsigneePostcode,
+			CountryCode:      v.ConsigneeCountryCode,
+			Email:            v.ConsigneeEmail,
+		},
+	}
+}
+
+// writeExportDeclarationZip renders the declarations of a manifest and zips them under baseName.
+// Every declaration is checked against the export schema first; the errors of all HAWBs are returned together.
+func writeExportDeclarationZip(declarationMarshaller *ecustoms.Marshaller, baseName string, manifest *utils.GetHeaderManifestPreExport, mawb string) (string, *bytes.Buffer, error) {
+	declarations, err := buildExportDeclarations(manifest, mawb, time.Now())
+	if err != nil {
+		return "", nil, err
+	}
+
+	var zipBuf bytes.Buffer
+	zipWriter := zip.NewWriter(&zipBuf)
+	problems := []string{}
+	for _, d := range declarations {
+		xmlBytes, err := declarationMarshaller.MarshalExportDeclaration(d)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("HAWB %s: %v", d.Header.HouseAirWaybill, err))
+			continue
+		}
+
+		header := &zip.FileHeader{
+			Name:   fmt.Sprintf("export_%v.xml", d.Header.HouseAirWaybill),
+			Method: zip.Deflate,
+		}
+		zipFileWriter, err := zipWriter.CreateHeader(header)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to create ZIP entry: %w", err)
+		}
+		if _, err := zipFileWriter.Write(xmlBytes); err != nil {
+			return "", nil, err
+		}
+	}
+	if len(problems) > 0 {
+		return "", nil, errors.New(strings.Join(problems, " | "))
+	}
+
+	if err := zipWriter.Close(); err != nil {
+		return "", nil, err
+	}
+
+	return baseName, &zipBuf, nil
+}
+
+// parseDepartureDate reads the departure date of a header, which is typed in by hand.
+func parseDepartureDate(value string) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	for _, layout := range []string{"2006-01-02", time.RFC3339, "2006-01-02 15:04:05", "2/1/2006", "20060102"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,159 @@
+package outbound
+
+import (
+	"testing"
+	"time"
+
+	"hpc-express-service/ecustoms"
+	"hpc-express-service/utils"
+)
+
+func sampleExportLine(hawb string, fobBaht, netWeight, grossWeight float64, packages int64) *utils.GetDetailManifestPreExport {
+	return &utils.GetDetailManifestPreExport{
+		MasterAirWaybill:          "217-12345675",
+		HouseAirWaybill:           hawb,
+		Category:                  2,
+		ConsignorCompanyTaxNumber: "0105551234567",
+		ConsignorCompanyBranch:    "0",
+		ConsignorName:             "HPC Express Co., Ltd.",
+		ConsignorStreetAndAddress: "99/9 Moo 4 Bang Na-Trad Road",
+		ConsignorProvince:         "Samut Prakan",
+		ConsignorPostcode:         "10540",
+		ConsigneeName:             "Tan Ah Kow",
+		ConsigneeStreetAndAddress: "1 Orchard Road",
+		ConsigneeCountryCode:      "SG",
+		PurchaseCountryCode:       "SG",
+		DestinationCountryCode:    "SG",
+		EnglishDescriptionOfGoods: " DRIED MANGO ",
+		Quantity:                  2,
+		QuantityUnitCode:          "C62",
+		NetWeight:                 netWeight,
+		NetWeightUnitCode:         "KGM",
+		GrossWeight:               grossWeight,
+		GrossWeightUnitCode:       "KGM",
+		PackageAmount:             packages,
+		PackageUnitCode:           "PK",
+		FobValueBaht:              fobBaht,
+		CurrencyCode:              "THB",
+		TariffCode:                "08045020",
+		StatCode:                  "000",
+	}
+}
+
+func sampleExportManifest() *utils.GetHeaderManifestPreExport {
+	return &utils.GetHeaderManifestPreExport{
+		VasselName:           "TG413",
+		DepartureDate:        "2026-10-17",
+		ReleasePort:          2801,
+		LoadingPort:          2801,
+		TotalPackageUnitCode: "PK",
+		Details: []*utils.GetDetailManifestPreExport{
+			sampleExportLine("H001", 500, 1, 1.2, 1),
+			sampleExportLine("H002", 300, 0.5, 0.6, 1),
+			sampleExportLine("H001", 250, 0.25, 0.3, 2),
+		},
+	}
+}
+
+var exportNow = time.Date(2026, 10, 17, 9, 30, 0, 0, time.UTC)
+
+func TestBuildExportDeclarationsGroupsLinesByHawb(t *testing.T) {
+	declarations, err := buildExportDeclarations(sampleExportManifest(), "", exportNow)
+	if err != nil {
+		t.Fatalf("buildExportDeclarations: %v", err)
+	}
+	if len(declarations) != 2 {
+		t.Fatalf("got %d declarations, want 2", len(declarations))
+	}
+
+	d := declarations[0]
+	if d.Header.HouseAirWaybill != "H001" || declarations[1].Header.HouseAirWaybill != "H002" {
+		t.Errorf("HAWBs = %s, %s; want H001, H002 in the order they first appear", d.Header.HouseAirWaybill, declarations[1].Header.HouseAirWaybill)
+	}
+	if len(d.Items) != 2 || d.Items[1].ItemNumber != 2 {
+		t.Fatalf("H001 items = %d, want 2 numbered from 1", len(d.Items))
+	}
+	if d.Header.TotalPackage != 3 {
+		t.Errorf("TotalPackage = %d, want 3", d.Header.TotalPackage)
+	}
+	if d.Header.TotalNetWeight != "1.250" || d.Header.TotalGrossWeight != "1.500" {
+		t.Errorf("weights = %s net, %s gross; want 1.250 net, 1.500 gross", d.Header.TotalNetWeight, d.Header.TotalGrossWeight)
+	}
+	if d.Header.MasterAirWaybill != "217-12345675" {
+		t.Errorf("MasterAirWaybill = %s, want the MAWB of the lines", d.Header.MasterAirWaybill)
+	}
+	if d.Header.DepartureDate != "2026-10-17" || d.DocumentControl.MessageDate != "2026-10-17T09:30:00" {
+		t.Errorf("dates = %s departure, %s message", d.Header.DepartureDate, d.DocumentControl.MessageDate)
+	}
+	if d.Items[0].DescriptionEnglish != "DRIED MANGO" {
+		t.Errorf("DescriptionEnglish = %q, want it trimmed", d.Items[0].DescriptionEnglish)
+	}
+	if v := d.Items[0].Valuation; v.FobBaht != "500.00" || v.CurrencyCode != "" || v.FobForeign != "" {
+		t.Errorf("THB valuation = %+v, want FOB in baht only", v)
+	}
+}
+
+func TestBuildExportDeclarationsForeignCurrencyAndMawb(t *testing.T) {
+	manifest := sampleExportManifest()
+	line := manifest.Details[1]
+	line.CurrencyCode = "USD"
+	line.FobValueForeign = 8.5
+	line.ExchangeRate = 35
+	line.FreightAmount = 120
+	line.FreightAmountCurrencyCode = "THB"
+
+	declarations, err := buildExportDeclarations(manifest, "217-99999995", exportNow)
+	if err != nil {
+		t.Fatalf("buildExportDeclarations: %v", err)
+	}
+
+	for _, d := range declarations {
+		if d.Header.MasterAirWaybill != "217-99999995" {
+			t.Errorf("%s MasterAirWaybill = %s, want the linked MAWB", d.Header.HouseAirWaybill, d.Header.MasterAirWaybill)
+		}
+	}
+	v := declarations[1].Items[0].Valuation
+	if v.CurrencyCode != "USD" || v.FobForeign != "8.50" || v.ExchangeRate != "35.000000" {
+		t.Errorf("foreign valuation = %+v", v)
+	}
+	if v.Freight == nil || v.Freight.Amount != "120.00" || v.Freight.CurrencyCode != "THB" {
+		t.Errorf("freight = %+v, want 120.00 THB", v.Freight)
+	}
+}
+
+func TestBuildExportDeclarationsRequiresDepartureDate(t *testing.T) {
+	for _, value := range []string{"", "  ", "next tuesday"} {
+		manifest := sampleExportManifest()
+		manifest.DepartureDate = value
+
+		if _, err := buildExportDeclarations(manifest, "", exportNow); err == nil {
+			t.Errorf("departure date %q: want an error", value)
+		}
+	}
+}
+
+func TestBuildExportDeclarationsRequiresDetails(t *testing.T) {
+	manifest := sampleExportManifest()
+	manifest.Details = nil
+
+	if _, err := buildExportDeclarations(manifest, "", exportNow); err == nil {
+		t.Error("want an error for a manifest without details")
+	}
+}
+
+func TestBuildExportDeclarationsPassSchema(t *testing.T) {
+	m, err := ecustoms.NewMarshaller()
+	if err != nil {
+		t.Fatalf("NewMarshaller: %v", err)
+	}
+	declarations, err := buildExportDeclarations(sampleExportManifest(), "", exportNow)
+	if err != nil {
+		t.Fatalf("buildExportDeclarations: %v", err)
+	}
+
+	for _, d := range declarations {
+		if _, err := m.MarshalExportDeclaration(d); err != nil {
+			t.Errorf("%s: %v", d.Header.HouseAirWaybill, err)
+		}
+	}
+}
@@ -1,5 +1,11 @@
 package outbound
 
+import (
+	"net/http"
+
+	"hpc-express-service/utils"
+)
+
 // import "hpc-express-service/shopee"
 
 // type PreExportManifestModel struct {
@@ -8,3 +14,61 @@ package outbound
 // func (d *shopee.UploadManifestModel) ConvertToManifest() *PreExportManifestModel {
 // 	return &PreExportManifestModel{}
 // }
+
+type InsertPreExportHeaderManifestModel struct {
+	MawbInfoUUID  string `json:"mawbInfoUUID"`
+	CustomerUUID  string `json:"customerUUID"`
+	VasselName    string `json:"vasselName" validate:"required"`
+	DepartureDate string `json:"departureDate" validate:"required"`
+	ReleasePort   int64  `json:"releasePort" validate:"required"`
+	LoadingPort   int64  `json:"loadingPort" validate:"required"`
+}
+
+func (o *InsertPreExportHeaderManifestModel) Bind(r *http.Request) error {
+	return nil
+}
+
+func (o *InsertPreExportHeaderManifestModel) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
+
+type UpdatePreExportHeaderManifestModel struct {
+	UUID          string `json:"uuid" validate:"required"`
+	MawbInfoUUID  string `json:"mawbInfoUUID"`
+	CustomerUUID  string `json:"customerUUID"`
+	VasselName    string `json:"vasselName" validate:"required"`
+	DepartureDate string `json:"departureDate" validate:"required"`
+	ReleasePort   int64  `json:"releasePort" validate:"required"`
+	LoadingPort   int64  `json:"loadingPort" validate:"required"`
+}
+
+func (o *UpdatePreExportHeaderManifestModel) Bind(r *http.Request) error {
+	return nil
+}
+
+func (o *UpdatePreExportHeaderManifestModel) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
+
+// GetPreExportManifestModel is a pre-export header. Mawb comes from the linked mawbinfo record;
+// the totals are recalculated from the details after every upload.
+type GetPreExportManifestModel struct {
+	UUID                     string                              `json:"uuid"`
+	UploadLoggingUUID        string                              `json:"uploadLoggingUUID"`
+	MawbInfoUUID             string                              `json:"mawbInfoUUID"`
+	Mawb                     string                              `json:"mawb"`
+	CustomerUUID             string                              `json:"customerUUID"`
+	VasselName               string                              `json:"vasselName"`
+	DepartureDate            string                              `json:"departureDate"`
+	ReleasePort              int64                               `json:"releasePort"`
+	LoadingPort              int64                               `json:"loadingPort"`
+	TotalPackage             int64                               `json:"totalPackage"`
+	TotalPackageUnitCode     string                              `json:"totalPackageUnitCode"`
+	TotalNetWeight           int64                               `json:"totalNetWeight"`
+	TotalNetWeightUnitCode   string                              `json:"totalNetWeightUnitCode"`
+	TotalGrossWeight         float64                             `json:"totalGrossWeight"`
+	TotalGrossWeightUnitCode string                              `json:"totalGrossWeightUnitCode"`
+	CreatedAt                string                              `json:"createdAt"`
+	UpdatedAt                string                              `json:"updatedAt"`
+	Details                  []*utils.GetDetailManifestPreExport `json:"details,omitempty"`
+}
@@ -2,8 +2,10 @@ package outbound
 
 import (
 	"context"
+	"errors"
 	"hpc-express-service/utils"
 	"log"
+	"math"
 	"time"
 
 	"github.com/go-pg/pg/v9"
@@ -12,6 +14,15 @@ import (
 type OutboundExpressRepository interface {
 	// GetMawbByTimstamp(ctx context.Context, timestamp string) (*GetMawb, error)
 	GetAllManifestToPreExport(ctx context.Context, uploadLoggingUUID string) (*utils.GetHeaderManifestPreExport, error)
+	GetManifestToPreExportByHeader(ctx context.Context, headerUUID string) (*utils.GetHeaderManifestPreExport, error)
+	InsertPreExportManifestHeader(ctx context.Context, data *InsertPreExportHeaderManifestModel) (string, error)
+	UpdatePreExportManifestHeader(ctx context.Context, data *UpdatePreExportHeaderManifestModel) error
+	GetAllPreExportHeaders(ctx context.Context) ([]*GetPreExportManifestModel, error)
+	GetPreExportHeader(ctx context.Context, headerUUID string) (*GetPreExportManifestModel, error)
+	GetMawbInfoMawb(ctx context.Context, mawbInfoUUID string) (string, error)
+	RecalculatePreExportHeader(ctx context.Context, headerUUID string) error
+	InsertPreExportManifest(ctx context.Context, manifest *utils.InsertPreExportHeaderManifestModel, chunkSize int) (string, error)
+	InsertPreExportManifestDetails(ctx context.Context, headerUUID, uploadLoggingUUID string, details []*utils.InsertPreExportDetailManifestModel, chunkSize int) error
 }
 
 type repository struct {
@@ -26,6 +37,8 @@ func NewOutboundExpressRepository(
 	}
 }
 
+// GetAllManifestToPreExport loads the header an upload was added to, with only that upload's details.
+// Header totals are summed from those details.
 func (r repository) GetAllManifestToPreExport(ctx context.Context, uploadLoggingUUID string) (*utils.GetHeaderManifestPreExport, error) {
 	db := ctx.Value("postgreSQLConn").(*pg.DB)
 	ctx, _ = context.WithTimeout(context.Background(), 5*time.Second)
@@ -51,7 +64,7 @@ func (r repository) GetAllManifestToPreExport(ctx context.Context, uploadLogging
 	), `
 			SELECT 
 				"uuid", 
-				upload_logging_uuid, 
+				COALESCE(upload_logging_uuid::text, ''), 
 				vassel_name, 
 				departure_date, 
 				release_port, 
@@ -66,7 +79,16 @@ func (r repository) GetAllManifestToPreExport(ctx context.Context, uploadLogging
 				updated_at, 
 				deleted_at
 			FROM public.tbl_pre_export_manifest_headers
-			WHERE upload_logging_uuid = ?
+			WHERE deleted_at IS NULL
+			AND (
+				upload_logging_uuid::text = ?0
+				OR "uuid" IN (
+					SELECT header_uuid
+					FROM public.tbl_pre_export_manifest_details
+					WHERE upload_logging_uuid::text = ?0
+				)
+			)
+			LIMIT 1
 	 `, uploadLoggingUUID)
 
 	if err != nil {
@@ -74,12 +96,98 @@ func (r repository) GetAllManifestToPreExport(ctx context.Context, uploadLogging
 		return nil, err
 	}
 
+	if err := r.getPreExportDetails(ctx, db, result, uploadLoggingUUID); err != nil {
+		return nil, err
+	}
+	sumPreExportDetails(result)
+	result.UploadLoggingUUID = uploadLoggingUUID
+
+	return result, nil
+}
+
+// sumPreExportDetails sets the header totals from the loaded details, counted the same way
+// as RecalculatePreExportHeader counts a whole header.
+func sumPreExportDetails(result *utils.GetHeaderManifestPreExport) {
+	hawbs := map[string]bool{}
+	var netWeight, grossWeight float64
+	for _, v := range result.Details {
+		hawbs[v.HouseAirWaybill] = true
+		netWeight += v.NetWeight
+		grossWeight += v.GrossWeight
+	}
+
+	result.TotalPackage = int64(len(hawbs))
+	result.TotalNetWeight = int64(math.Round(netWeight))
+	result.TotalGrossWeight = grossWeight
+}
+
+// GetManifestToPreExportByHeader loads a pre-export header and its details for the download.
+func (r repository) GetManifestToPreExportByHeader(ctx context.Context, headerUUID string) (*utils.GetHeaderManifestPreExport, error) {
+	db := ctx.Value("postgreSQLConn").(*pg.DB)
+	ctx, _ = context.WithTimeout(context.Background(), 5*time.Second)
+
+	result := &utils.GetHeaderManifestPreExport{}
+
+	_, err := db.QueryOneContext(ctx, pg.Scan(
+		&result.UUID,
+		&result.UploadLoggingUUID,
+		&result.VasselName,
+		&result.DepartureDate,
+		&result.ReleasePort,
+		&result.LoadingPort,
+		&result.TotalPackage,
+		&result.TotalPackageUnitCode,
+		&result.TotalNetWeight,
+		&result.TotalNetWeightUnitCode,
+		&result.TotalGrossWeight,
+		&result.TotalGrossWeightUnitCode,
+		&result.CreatedAt,
+		&result.UpdatedAt,
+		&result.DeletedAt,
+	), `
+			SELECT 
+				"uuid", 
+				COALESCE(upload_logging_uuid::text, ''), 
+				vassel_name, 
+				departure_date, 
+				release_port, 
+				loading_port, 
+				total_package, 
+				total_package_unit_code, 
+				total_net_weight, 
+				total_net_weight_unit_code, 
+				total_gross_weight, 
+				total_gross_weight_unit_code, 
+				created_at, 
+				updated_at, 
+				deleted_at
+			FROM public.tbl_pre_export_manifest_headers
+			WHERE "uuid" = ? AND deleted_at IS NULL
+	 `, headerUUID)
+	if err != nil {
+		if err == pg.ErrNoRows {
+			return nil, errors.New("not found")
+		}
+		return nil, err
+	}
+
+	if err := r.getPreExportDetails(ctx, db, result, ""); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// getPreExportDetails loads the details of result's header; a non-empty uploadLoggingUUID keeps only
+// the lines of that upload. Lines stored before uploads were recorded per line belong to the header's upload.
+func (r repository) getPreExportDetails(ctx context.Context, db *pg.DB, result *utils.GetHeaderManifestPreExport, uploadLoggingUUID string) error {
 	stmt, err := db.Prepare(`
 	SELECT 
 			"uuid", 
 			header_uuid, 
 			master_air_waybill, 
 			house_air_waybill, 
+			item_no, 
 			category, 
 			consignor_company_tax_number, 
 			consignor_company_branch, 
@@ -127,17 +235,396 @@ func (r repository) GetAllManifestToPreExport(ctx context.Context, uploadLogging
 			deleted_at
 		FROM public.tbl_pre_export_manifest_details
 		WHERE header_uuid = $1
+		AND (
+			$2 = ''
+			OR COALESCE(upload_logging_uuid::text, (
+				SELECT upload_logging_uuid::text
+				FROM public.tbl_pre_export_manifest_headers
+				WHERE "uuid" = $1
+			)) = $2
+		)
+		ORDER BY house_air_waybill, item_no
 	`)
 	if err != nil {
 		log.Println("xxx")
-		return nil, err
+		return err
 	}
 	defer stmt.Close()
 
-	_, err = stmt.QueryContext(ctx, &result.Details, result.UUID)
+	_, err = stmt.QueryContext(ctx, &result.Details, result.UUID, uploadLoggingUUID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r repository) InsertPreExportManifestHeader(ctx context.Context, data *InsertPreExportHeaderManifestModel) (string, error) {
+	db := ctx.Value("postgreSQLConn").(*pg.DB)
+	ctx, _ = context.WithTimeout(context.Background(), 5*time.Second)
+
+	var headerUUID string
+	_, err := db.QueryOneContext(ctx, pg.Scan(&headerUUID),
+		`
+			INSERT INTO public.tbl_pre_export_manifest_headers
+				(
+					mawb_info_uuid, customer_uuid, vassel_name, departure_date, release_port, loading_port, total_package, total_package_unit_code, total_net_weight, total_net_weight_unit_code, total_gross_weight, total_gross_weight_unit_code
+				)
+			VALUES
+				(
+					?, ?, ?, ?, ?, ?, 0, 'PK', 0, 'KGM', 0, 'KGM'
+				)
+			RETURNING uuid
+		`,
+		utils.NewNullString(data.MawbInfoUUID),
+		utils.NewNullString(data.CustomerUUID),
+		data.VasselName,
+		data.DepartureDate,
+		data.ReleasePort,
+		data.LoadingPort,
+	)
+	if err != nil {
+		return "", err
+	}
+
+	return headerUUID, nil
+}
+
+func (r repository) UpdatePreExportManifestHeader(ctx context.Context, data *UpdatePreExportHeaderManifestModel) error {
+	db := ctx.Value("postgreSQLConn").(*pg.DB)
+	ctx, _ = context.WithTimeout(context.Background(), 5*time.Second)
+
+	_, err := db.ExecOneContext(ctx,
+		`
+			UPDATE public.tbl_pre_export_manifest_headers
+				SET
+					mawb_info_uuid=?1,
+					customer_uuid=?2,
+					vassel_name=?3,
+					departure_date=?4,
+					release_port=?5,
+					loading_port=?6,
+					updated_at = NOW()
+			WHERE "uuid" = ?0 AND deleted_at IS NULL;
+		`,
+		data.UUID,
+		utils.NewNullString(data.MawbInfoUUID),
+		utils.NewNullString(data.CustomerUUID),
+		data.VasselName,
+		data.DepartureDate,
+		data.ReleasePort,
+		data.LoadingPort,
+	)
+
 	if err != nil {
+		if err == pg.ErrNoRows {
+			return errors.New("not found")
+		}
+		return err
+	}
+
+	return nil
+}
+
+const selectPreExportHeaderSQL = `
+			SELECT
+				h."uuid",
+				COALESCE(h.upload_logging_uuid::text, '') AS upload_logging_uuid,
+				COALESCE(h.mawb_info_uuid::text, '') AS mawb_info_uuid,
+				COALESCE(mi.mawb, '') AS mawb,
+				COALESCE(h.customer_uuid::text, '') AS customer_uuid,
+				h.vassel_name,
+				h.departure_date,
+				h.release_port,
+				h.loading_port,
+				h.total_package,
+				h.total_package_unit_code,
+				h.total_net_weight,
+				h.total_net_weight_unit_code,
+				h.total_gross_weight,
+				h.total_gross_weight_unit_code,
+				h.created_at,
+				h.updated_at
+			FROM public.tbl_pre_export_manifest_headers h
+			LEFT JOIN tbl_mawb_info mi ON mi.uuid = h.mawb_info_uuid
+			WHERE h.deleted_at IS NULL
+`
+
+func (r repository) GetAllPreExportHeaders(ctx context.Context) ([]*GetPreExportManifestModel, error) {
+	db := ctx.Value("postgreSQLConn").(*pg.DB)
+	ctx, _ = context.WithTimeout(context.Background(), 5*time.Second)
+
+	var list []*GetPreExportManifestModel
+	_, err := db.QueryContext(ctx, &list, selectPreExportHeaderSQL+` ORDER BY h.created_at DESC`)
+	if err != nil {
+		return list, err
+	}
+
+	return list, nil
+}
+
+func (r repository) GetPreExportHeader(ctx context.Context, headerUUID string) (*GetPreExportManifestModel, error) {
+	db := ctx.Value("postgreSQLConn").(*pg.DB)
+	ctx, _ = context.WithTimeout(context.Background(), 5*time.Second)
+
+	result := &GetPreExportManifestModel{}
+	_, err := db.QueryOneContext(ctx, result, selectPreExportHeaderSQL+` AND h."uuid" = ?`, headerUUID)
+	if err != nil {
+		if err == pg.ErrNoRows {
+			return nil, errors.New("not found")
+		}
 		return nil, err
 	}
 
 	return result, nil
 }
+
+// GetMawbInfoMawb returns the MAWB number of a mawbinfo record.
+func (r repository) GetMawbInfoMawb(ctx context.Context, mawbInfoUUID string) (string, error) {
+	db := ctx.Value("postgreSQLConn").(*pg.DB)
+	ctx, _ = context.WithTimeout(context.Background(), 5*time.Second)
+
+	var mawb string
+	_, err := db.QueryOneContext(ctx, pg.Scan(&mawb), `SELECT mawb FROM tbl_mawb_info WHERE uuid = ?`, mawbInfoUUID)
+	if err != nil {
+		if err == pg.ErrNoRows {
+			return "", errors.New("mawb info not found")
+		}
+		return "", err
+	}
+
+	return mawb, nil
+}
+
+// RecalculatePreExportHeader sets the header totals from its details (packages are distinct HAWBs)
+// and stamps the MAWB of the linked mawbinfo record on every detail, replacing the MAWB of a
+// previously linked record or of the uploaded file.
+func (r repository) RecalculatePreExportHeader(ctx context.Context, headerUUID string) error {
+	db := ctx.Value("postgreSQLConn").(*pg.DB)
+	ctx, _ = context.WithTimeout(context.Background(), 10*time.Second)
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE public.tbl_pre_export_manifest_details d
+			SET master_air_waybill = mi.mawb
+		FROM public.tbl_pre_export_manifest_headers h
+		JOIN tbl_mawb_info mi ON mi.uuid = h.mawb_info_uuid
+		WHERE h."uuid" = ?0
+			AND d.header_uuid = h."uuid"
+			AND d.master_air_waybill IS DISTINCT FROM mi.mawb
+	`, headerUUID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE public.tbl_pre_export_manifest_headers h
+			SET
+				total_package = t.total_package,
+				total_net_weight = t.total_net_weight,
+				total_gross_weight = t.total_gross_weight,
+				updated_at = NOW()
+		FROM (
+			SELECT
+				COUNT(DISTINCT d.house_air_waybill) AS total_package,
+				ROUND(COALESCE(SUM(d.net_weight), 0)) AS total_net_weight,
+				COALESCE(SUM(d.gross_weight), 0) AS total_gross_weight
+			FROM public.tbl_pre_export_manifest_details d
+			WHERE d.header_uuid = ?0 AND d.deleted_at IS NULL
+		) t
+		WHERE h."uuid" = ?0
+	`, headerUUID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r repository) InsertPreExportManifest(ctx context.Context, manifest *utils.InsertPreExportHeaderManifestModel, chunkSize int) (string, error) {
+	db := ctx.Value("postgreSQLConn").(*pg.DB)
+	ctx, _ = context.WithTimeout(context.Background(), 30*time.Second)
+
+	tx, err := db.Begin()
+	if err != nil {
+		return "", err
+	}
+
+	defer tx.Rollback()
+
+	// Insert HeaderManifest
+	var headerUUID string
+	{
+		sqlStr :=
+			`
+			INSERT INTO public.tbl_pre_export_manifest_headers
+				(
+					upload_logging_uuid, vassel_name, departure_date, release_port, loading_port, total_package, total_package_unit_code, total_net_weight, total_net_weight_unit_code, total_gross_weight, total_gross_weight_unit_code
+				)
+			VALUES
+				(
+					?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+				)
+			RETURNING uuid
+		`
+
+		// Prepare statement
+		stmt, err := tx.Prepare(utils.PrepareSQL(sqlStr))
+		if err != nil {
+			tx.Rollback()
+			return "", err
+		}
+		defer stmt.Close()
+
+		values := []interface{}{}
+		values = append(
+			values,
+			manifest.UploadLoggingUUID,
+			manifest.VasselName,
+			manifest.DepartureDate,
+			manifest.ReleasePort,
+			manifest.LoadingPort,
+			manifest.TotalPackage,
+			manifest.TotalPackageUnitCode,
+			manifest.TotalNetWeight,
+			manifest.TotalNetWeightUnitCode,
+			manifest.TotalGrossWeight,
+			manifest.TotalGrossWeightUnitCode,
+		)
+
+		_, err = stmt.QueryOneContext(ctx, &headerUUID, values...)
+
+		if err != nil {
+			tx.Rollback()
+			return "", err
+		}
+	}
+
+	if err := insertPreExportDetails(ctx, tx, headerUUID, manifest.UploadLoggingUUID, manifest.Details, chunkSize); err != nil {
+		return "", err
+	}
+
+	tx.Commit()
+
+	return headerUUID, nil
+}
+
+// InsertPreExportManifestDetails adds the details of upload uploadLoggingUUID to an existing pre-export header.
+func (r repository) InsertPreExportManifestDetails(ctx context.Context, headerUUID, uploadLoggingUUID string, details []*utils.InsertPreExportDetailManifestModel, chunkSize int) error {
+	db := ctx.Value("postgreSQLConn").(*pg.DB)
+	ctx, _ = context.WithTimeout(context.Background(), 30*time.Second)
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	if err := insertPreExportDetails(ctx, tx, headerUUID, uploadLoggingUUID, details, chunkSize); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func insertPreExportDetails(ctx context.Context, tx *pg.Tx, headerUUID, uploadLoggingUUID string, details []*utils.InsertPreExportDetailManifestModel, chunkSize int) error {
+	// Insert Manifest
+	// Chunk slice
+	chunked := utils.ChunkSlice(details, chunkSize)
+	{
+
+		for _, chunkedRows := range chunked {
+			sqlStr := `
+				INSERT INTO public.tbl_pre_export_manifest_details 
+					(
+						header_uuid, upload_logging_uuid, master_air_waybill, house_air_waybill, item_no, category, consignor_company_tax_number, consignor_company_branch, consignor_name, consignor_street_and_address, consignor_district, consignor_sub_province, consignor_province, consignor_postcode, consignor_email, consignee_name, consignee_street_and_address, consignee_district, consignee_sub_province, consignee_province, consignee_postcode, consignee_country_code, consignee_email, purchase_country_code, destination_country_code, thai_description_of_goods, english_description_of_goods, quantity, quantity_unit_code, net_weight, net_weight_unit_code, gross_weight, gross_weight_unit_code, package_amount, package_unit_code, remark, fob_value_baht, fob_value_foreign, currency_code, exchange_rate, freight_amount, freight_amount_currency_code, insurance_amount, insurance_amount_currency_code, tariff_code, stat_code, tariff_sequence
+					) 
+					VALUES 
+			`
+			vals := []interface{}{}
+			for _, row := range chunkedRows {
+				row.HeaderUUID = headerUUID
+
+				sqlStr += "(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?),"
+				vals = append(vals,
+					utils.NewNullString(row.HeaderUUID),
+					utils.NewNullString(uploadLoggingUUID),
+					utils.NewNullString(row.MasterAirWaybill),
+					utils.NewNullString(row.HouseAirWaybill),
+					utils.NewNullInt(row.ItemNo),
+					utils.NewNullInt(row.Category),
+					utils.NewNullString(row.ConsignorCompanyTaxNumber),
+					utils.NewNullString(row.ConsignorCompanyBranch),
+					utils.NewNullString(row.ConsignorName),
+					utils.NewNullString(row.ConsignorStreetAndAddress),
+					utils.NewNullString(row.ConsignorDistrict),
+					utils.NewNullString(row.ConsignorSubProvince),
+					utils.NewNullString(row.ConsignorProvince),
+					utils.NewNullString(row.ConsignorPostcode),
+					utils.NewNullString(row.ConsignorEmail),
+					utils.NewNullString(row.ConsigneeName),
+					utils.NewNullString(row.ConsigneeStreetAndAddress),
+					utils.NewNullString(row.ConsigneeDistrict),
+					utils.NewNullString(row.ConsigneeSubProvince),
+					utils.NewNullString(row.ConsigneeProvince),
+					utils.NewNullString(row.ConsigneePostcode),
+					utils.NewNullString(row.ConsigneeCountryCode),
+					utils.NewNullString(row.ConsigneeEmail),
+					utils.NewNullString(row.PurchaseCountryCode),
+					utils.NewNullString(row.DestinationCountryCode),
+					utils.NewNullString(row.ThaiDescriptionOfGoods),
+					utils.NewNullString(row.EnglishDescriptionOfGoods),
+					utils.NewNullInt(row.Quantity),
+					utils.NewNullString(row.QuantityUnitCode),
+					row.NetWeight,
+					utils.NewNullString(row.NetWeightUnitCode),
+					row.GrossWeight,
+					utils.NewNullString(row.GrossWeightUnitCode),
+					utils.NewNullInt(row.PackageAmount),
+					utils.NewNullString(row.PackageUnitCode),
+					utils.NewNullString(row.Remark),
+					row.FobValueBaht,
+					row.FobValueForeign,
+					utils.NewNullString(row.CurrencyCode),
+					utils.NewNullInt(row.ExchangeRate),
+					utils.NewNullInt(row.FreightAmount),
+					utils.NewNullString(row.FreightAmountCurrencyCode),
+					utils.NewNullInt(row.InsuranceAmount),
+					utils.NewNullString(row.InsuranceAmountCurrencyCode),
+					utils.NewNullString(row.TariffCode),
+					utils.NewNullString(row.StatCode),
+					utils.NewNullString(row.TariffSequence),
+				)
+			}
+
+			// remove last comma,
+			sqlStr = sqlStr[0 : len(sqlStr)-1]
+
+			// Convert symbol ? to $
+			sqlStr = utils.ReplaceSQL(sqlStr, "?")
+			// sqlStr += " ON CONFLICT (local_no) DO NOTHING returning uuid, local_no;"
+
+			// Prepare statement
+			stmt, err := tx.Prepare(sqlStr)
+			if err != nil {
+				tx.Rollback()
+				return err
+			}
+			defer stmt.Close()
+
+			_, err = stmt.ExecContext(ctx, vals...)
+			if err != nil {
+				tx.Rollback()
+				return err
+			}
+		}
+	}
+
+	return nil
+}
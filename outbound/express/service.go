@@ -9,9 +9,11 @@ import (
 	"io"
 	"log"
 	"strconv"
+	"strings"
 	"time"
 
-	"hpc-express-service/shopee"
+	"hpc-express-service/ecustoms"
+	"hpc-express-service/setting"
 	"hpc-express-service/uploadlog"
 	"hpc-express-service/utils"
 
@@ -19,77 +21,210 @@ import (
 )
 
 type OutboundExpressService interface {
-	UploadManifest(ctx context.Context, userUUID, originName, templateCode string, fileBytes []byte) error
+	RegisterConvertTemplates(ctx context.Context) error
+	UploadManifest(ctx context.Context, userUUID, headerUUID, customerUUID, vasselName, departureDate, originName, templateCode string, fileBytes []byte) error
 	DownloadPreExport(ctx context.Context, uploadLoggingUUID string) (string, *bytes.Buffer, error)
+	DownloadPreExportByHeader(ctx context.Context, headerUUID string) (string, *bytes.Buffer, error)
+	DownloadExportDeclaration(ctx context.Context, uploadLoggingUUID string) (string, *bytes.Buffer, error)
+	DownloadExportDeclarationByHeader(ctx context.Context, headerUUID string) (string, *bytes.Buffer, error)
+	InsertPreExportManifestHeader(ctx context.Context, data *InsertPreExportHeaderManifestModel) (string, error)
+	UpdatePreExportManifestHeader(ctx context.Context, data *UpdatePreExportHeaderManifestModel) error
+	GetAllPreExportHeaders(ctx context.Context) ([]*GetPreExportManifestModel, error)
+	GetPreExportHeader(ctx context.Context, headerUUID string) (*GetPreExportManifestModel, error)
 }
 
 type service struct {
 	selfRepo       OutboundExpressRepository
 	contextTimeout time.Duration
-	shopeeSvc      shopee.Service
+	converters     *ConverterRegistry
+	templateSvc    setting.ConvertTemplateService
 	uploadlogSvc   uploadlog.Service
+	declarations   *ecustoms.Marshaller
 }
 
 func NewOutboundExpressService(
 	selfRepo OutboundExpressRepository,
 	timeout time.Duration,
-	shopeeSvc shopee.Service,
+	converters *ConverterRegistry,
+	templateSvc setting.ConvertTemplateService,
 	uploadlogSvc uploadlog.Service,
+	declarations *ecustoms.Marshaller,
 ) OutboundExpressService {
 	return &service{
 		selfRepo:       selfRepo,
 		contextTimeout: timeout,
-		shopeeSvc:      shopeeSvc,
+		converters:     converters,
+		templateSvc:    templateSvc,
 		uploadlogSvc:   uploadlogSvc,
+		declarations:   declarations,
 	}
 }
 
-func (s *service) UploadManifest(ctx context.Context, userUUID, originName, templateCode string, fileBytes []byte) error {
-	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
-	defer cancel()
-
-	if templateCode == "SHOPEE" {
-		// Logging and Upload to GCS
-		uploadLogUUID, err := s.uploadlogSvc.UploadLogFile(ctx, &uploadlog.UploadFileModel{
-			// Mawb:         "", // TODO:
-			UserUUID:     userUUID,
-			FileName:     originName,
-			TemplateCode: templateCode,
-			Category:     "outbound",
-			SubCategory:  "upload_manifest",
-			FileBytes:    fileBytes,
-			// Amount:       0,
+// RegisterConvertTemplates adds every registered converter to master_convert_templates as an
+// outbound template, so /convert_templates offers it for upload.
+func (s *service) RegisterConvertTemplates(ctx context.Context) error {
+	for _, c := range s.converters.Converters() {
+		err := s.templateSvc.EnsureConvertTemplate(ctx, &setting.ConvertTemplate{
+			Code: c.TemplateCode(),
+			Name: c.TemplateName(),
+			Type: "outbound",
 		})
 		if err != nil {
 			return err
 		}
+	}
 
-		// Insert Manifest
-		resultUpload, err := s.shopeeSvc.UploadPreImportManifests(ctx, uploadLogUUID, fileBytes)
+	return nil
+}
+
+// UploadManifest converts a manifest file and adds its lines to the header headerUUID. Without a
+// header a new one is created from the file, departing on vasselName at departureDate, which the
+// export declaration needs. customerUUID defaults to the header's customer.
+func (s *service) UploadManifest(ctx context.Context, userUUID, headerUUID, customerUUID, vasselName, departureDate, originName, templateCode string, fileBytes []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	var mawb string
+	if headerUUID == "" {
+		if strings.TrimSpace(vasselName) == "" {
+			return errors.New("vasselName is required to create a header")
+		}
+		if _, ok := parseDepartureDate(departureDate); !ok {
+			return fmt.Errorf("invalid departureDate %q", departureDate)
+		}
+	} else {
+		header, err := s.selfRepo.GetPreExportHeader(ctx, headerUUID)
 		if err != nil {
 			return err
 		}
+		if customerUUID == "" {
+			customerUUID = header.CustomerUUID
+		}
+		mawb = header.Mawb
+	}
 
-		log.Println(resultUpload)
+	converter, err := s.converters.Get(templateCode)
+	if err != nil {
+		return err
+	}
 
-		// Update Logging
-		for _, v := range resultUpload {
-			err := s.uploadlogSvc.Update(ctx, &uploadlog.UpdateModel{
-				UUID:   uploadLogUUID,
-				Mawb:   v.Mawb,
-				Amount: v.Amount,
-				Status: "success",
-			})
-			if err != nil {
-				log.Println(err)
-			}
-		}
+	// Logging and Upload to GCS
+	uploadLogUUID, err := s.uploadlogSvc.UploadLogFile(ctx, &uploadlog.UploadFileModel{
+		Mawb:         mawb,
+		UserUUID:     userUUID,
+		FileName:     originName,
+		TemplateCode: templateCode,
+		Category:     "outbound",
+		SubCategory:  "upload_manifest",
+		FileBytes:    fileBytes,
+		// Amount:       0,
+	})
+	if err != nil {
+		return err
+	}
+
+	details, err := converter.ConvertToPreExportDetails(ctx, customerUUID, fileBytes)
+	if err != nil {
+		return err
+	}
+
+	// Insert Manifest
+	if headerUUID != "" {
+		err = s.selfRepo.InsertPreExportManifestDetails(ctx, headerUUID, uploadLogUUID, details, 200)
 	} else {
-		return errors.New("not found template")
+		headerUUID, err = s.selfRepo.InsertPreExportManifest(ctx, &utils.InsertPreExportHeaderManifestModel{
+			UploadLoggingUUID:        uploadLogUUID,
+			VasselName:               strings.TrimSpace(vasselName),
+			DepartureDate:            strings.TrimSpace(departureDate),
+			ReleasePort:              1193,
+			LoadingPort:              1190,
+			TotalPackageUnitCode:     "PK",
+			TotalNetWeightUnitCode:   "KGM",
+			TotalGrossWeightUnitCode: "KGM",
+			Details:                  details,
+		}, 200)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Update Header and Logging
+	if err := s.selfRepo.RecalculatePreExportHeader(ctx, headerUUID); err != nil {
+		return err
+	}
+
+	hawbs := map[string]bool{}
+	for _, v := range details {
+		hawbs[v.HouseAirWaybill] = true
+	}
+
+	err = s.uploadlogSvc.Update(ctx, &uploadlog.UpdateModel{
+		UUID:   uploadLogUUID,
+		Mawb:   mawb,
+		Amount: int64(len(hawbs)),
+		Status: "success",
+	})
+	if err != nil {
+		log.Println(err)
 	}
 
 	return nil
+}
+
+func (s *service) InsertPreExportManifestHeader(ctx context.Context, data *InsertPreExportHeaderManifestModel) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	if data.MawbInfoUUID != "" {
+		if _, err := s.selfRepo.GetMawbInfoMawb(ctx, data.MawbInfoUUID); err != nil {
+			return "", err
+		}
+	}
+
+	return s.selfRepo.InsertPreExportManifestHeader(ctx, data)
+}
+
+// UpdatePreExportManifestHeader saves the header and re-stamps the linked MAWB on its details.
+func (s *service) UpdatePreExportManifestHeader(ctx context.Context, data *UpdatePreExportHeaderManifestModel) error {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	if data.MawbInfoUUID != "" {
+		if _, err := s.selfRepo.GetMawbInfoMawb(ctx, data.MawbInfoUUID); err != nil {
+			return err
+		}
+	}
+
+	if err := s.selfRepo.UpdatePreExportManifestHeader(ctx, data); err != nil {
+		return err
+	}
+
+	return s.selfRepo.RecalculatePreExportHeader(ctx, data.UUID)
+}
+
+func (s *service) GetAllPreExportHeaders(ctx context.Context) ([]*GetPreExportManifestModel, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	return s.selfRepo.GetAllPreExportHeaders(ctx)
+}
+
+func (s *service) GetPreExportHeader(ctx context.Context, headerUUID string) (*GetPreExportManifestModel, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	result, err := s.selfRepo.GetPreExportHeader(ctx, headerUUID)
+	if err != nil {
+		return nil, err
+	}
 
+	manifest, err := s.selfRepo.GetManifestToPreExportByHeader(ctx, headerUUID)
+	if err != nil {
+		return nil, err
+	}
+	result.Details = manifest.Details
+
+	return result, nil
 }
 
 func (s *service) DownloadPreExport(ctx context.Context, uploadLoggingUUID string) (string, *bytes.Buffer, error) {
@@ -105,21 +240,93 @@ func (s *service) DownloadPreExport(ctx context.Context, uploadLoggingUUID strin
 	/*
 		Prepare Data
 	*/
-	manifest := &utils.GetHeaderManifestPreExport{}
-	if uploadLogData.TemplateCode == "SHOPEE" {
-		manifest, err = s.selfRepo.GetAllManifestToPreExport(ctx, uploadLogData.UUID)
-		if err != nil {
-			return "", nil, err
-		}
-	} else {
+	if _, err := s.converters.Get(uploadLogData.TemplateCode); err != nil {
 		return "", nil, errors.New("invalid template")
 	}
 
+	manifest, err := s.selfRepo.GetAllManifestToPreExport(ctx, uploadLogData.UUID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return writePreExportZip(fmt.Sprintf("pre_export_%v_%v", uploadLogData.TemplateCode, uploadLogData.Mawb), manifest)
+}
+
+// DownloadPreExportByHeader builds the pre-export sheets of every upload attached to a header.
+func (s *service) DownloadPreExportByHeader(ctx context.Context, headerUUID string) (string, *bytes.Buffer, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	header, err := s.selfRepo.GetPreExportHeader(ctx, headerUUID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	manifest, err := s.selfRepo.GetManifestToPreExportByHeader(ctx, headerUUID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	name := header.Mawb
+	if name == "" {
+		name = header.UUID
+	}
+
+	return writePreExportZip(fmt.Sprintf("pre_export_%v", name), manifest)
+}
+
+// DownloadExportDeclaration builds the e-Export declaration XML of every HAWB of an upload.
+func (s *service) DownloadExportDeclaration(ctx context.Context, uploadLoggingUUID string) (string, *bytes.Buffer, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	uploadLogData, err := s.uploadlogSvc.Get(ctx, uploadLoggingUUID)
+	if err != nil {
+		return "", nil, err
+	}
+	if _, err := s.converters.Get(uploadLogData.TemplateCode); err != nil {
+		return "", nil, errors.New("invalid template")
+	}
+
+	manifest, err := s.selfRepo.GetAllManifestToPreExport(ctx, uploadLogData.UUID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return writeExportDeclarationZip(s.declarations, fmt.Sprintf("export_declaration_%v_%v", uploadLogData.TemplateCode, uploadLogData.Mawb), manifest, "")
+}
+
+// DownloadExportDeclarationByHeader builds the e-Export declaration XML of every HAWB of a header,
+// declaring them under the header's MAWB.
+func (s *service) DownloadExportDeclarationByHeader(ctx context.Context, headerUUID string) (string, *bytes.Buffer, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	header, err := s.selfRepo.GetPreExportHeader(ctx, headerUUID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	manifest, err := s.selfRepo.GetManifestToPreExportByHeader(ctx, headerUUID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	name := header.Mawb
+	if name == "" {
+		name = header.UUID
+	}
+
+	return writeExportDeclarationZip(s.declarations, fmt.Sprintf("export_declaration_%v", name), manifest, header.Mawb)
+}
+
+// writePreExportZip writes the manifest as pre-export sheets named after baseName, zipped.
+func writePreExportZip(baseName string, manifest *utils.GetHeaderManifestPreExport) (string, *bytes.Buffer, error) {
 	// Create in-memory ZIP buffer
 	var zipBuf bytes.Buffer
 	zipWriter := zip.NewWriter(&zipBuf)
-	// Split into chunks of 50
-	chunks := utils.ChunkSlice(manifest.Details, 10000)
+	// Split into files of about 10000 lines, keeping the item lines of a HAWB in one file
+	chunks := chunkByHawb(manifest.Details, 10000)
 	// Print the result
 	for i, chunk := range chunks {
 
@@ -205,7 +412,7 @@ func (s *service) DownloadPreExport(ctx context.Context, uploadLoggingUUID strin
 			f.SetCellValue(sheetName, fmt.Sprintf("%s%d", "AS", rowNum), v.TariffSequence)
 		}
 		// Save Excel to a buffer
-		fileName := fmt.Sprintf("pre_export_%v_%v_%v.xlsx", uploadLogData.TemplateCode, uploadLogData.Mawb, strconv.Itoa(i+1))
+		fileName := fmt.Sprintf("%v_%v.xlsx", baseName, strconv.Itoa(i+1))
 
 		var excelBuf bytes.Buffer
 		if err := f.Write(&excelBuf); err != nil {
@@ -237,5 +444,28 @@ func (s *service) DownloadPreExport(ctx context.Context, uploadLoggingUUID strin
 		return "", nil, err
 	}
 
-	return fmt.Sprintf("pre_export_%v_%v", uploadLogData.TemplateCode, uploadLogData.Mawb), &zipBuf, nil
+	return baseName, &zipBuf, nil
+}
+
+// chunkByHawb splits details, ordered by HAWB and item, into chunks of at most chunkSize lines
+// without splitting a HAWB. A HAWB with more than chunkSize lines gets a chunk of its own.
+func chunkByHawb(details []*utils.GetDetailManifestPreExport, chunkSize int) [][]*utils.GetDetailManifestPreExport {
+	var chunks [][]*utils.GetDetailManifestPreExport
+	start := 0
+	for start < len(details) {
+		end := start
+		for end < len(details) {
+			next := end + 1
+			for next < len(details) && details[next].HouseAirWaybill == details[end].HouseAirWaybill {
+				next++
+			}
+			if end > start && next-start > chunkSize {
+				break
+			}
+			end = next
+		}
+		chunks = append(chunks, details[start:end])
+		start = end
+	}
+	return chunks
 }
@@ -9,9 +9,11 @@ import (
 type CreateMawbInfoRequest struct {
 	ChargeableWeight string `json:"chargeableWeight" validate:"required"`
 	Date             string `json:"date" validate:"required"`
-	Mawb             string `json:"mawb" validate:"required"`
-	ServiceType      string `json:"serviceType" validate:"required"`
-	ShippingType     string `json:"shippingType" validate:"required"`
+	Mawb             string `json:"mawb"`
+	// AirlineUUID is the airline whose AWB stock the MAWB is taken from when Mawb is empty.
+	AirlineUUID  string `json:"airlineUuid"`
+	ServiceType  string `json:"serviceType" validate:"required"`
+	ShippingType string `json:"shippingType" validate:"required"`
 }
 
 // Bind implements the chi render.Binder interface for HTTP request binding
@@ -25,6 +27,9 @@ type MawbInfoResponse struct {
 	ChargeableWeight float64          `json:"chargeableWeight"`
 	Date             string           `json:"date"`
 	Mawb             string           `json:"mawb"`
+	AirlineUUID      string           `json:"airlineUuid,omitempty"`
+	AirlineName      string           `json:"airlineName,omitempty"`
+	AirlineLogo      string           `json:"airlineLogo,omitempty"`
 	ServiceType      string           `json:"serviceType"`
 	ShippingType     string           `json:"shippingType"`
 	CreatedAt        string           `json:"createdAt"`
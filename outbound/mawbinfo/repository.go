@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"hpc-express-service/common"
 	"hpc-express-service/utils"
 	"strings"
 	"time"
@@ -35,12 +36,13 @@ func NewRepository(
 }
 
 func (r repository) CreateMawbInfo(ctx context.Context, data *CreateMawbInfoRequest, chargeableWeight float64) (*MawbInfoResponse, error) {
-	db := ctx.Value("postgreSQLConn").(*pg.DB)
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	db, err := common.GetQer(ctx)
+	if err != nil {
+		return nil, err
+	}
 
 	// Create table if not exists
-	err := r.createTableIfNotExists(ctx, db)
+	err = r.createTableIfNotExists(db)
 	if err != nil {
 		return nil, utils.PostgresErrorTransform(err)
 	}
@@ -62,14 +64,7 @@ func (r repository) CreateMawbInfo(ctx context.Context, data *CreateMawbInfoRequ
 			to_char(created_at at time zone 'utc' at time zone 'Asia/Bangkok', 'YYYY-MM-DD"T"HH24:MI:SS"Z"') as created_at
 	`
 
-	sqlStr = utils.ReplaceSQL(sqlStr, "?")
-	stmt, err := db.Prepare(sqlStr)
-	if err != nil {
-		return nil, utils.PostgresErrorTransform(err)
-	}
-	defer stmt.Close()
-
-	_, err = stmt.QueryOneContext(ctx, pg.Scan(
+	_, err = db.QueryOne(pg.Scan(
 		&response.UUID,
 		&response.ChargeableWeight,
 		&response.Date,
@@ -78,6 +73,7 @@ func (r repository) CreateMawbInfo(ctx context.Context, data *CreateMawbInfoRequ
 		&response.ShippingType,
 		&response.CreatedAt,
 	),
+		sqlStr,
 		chargeableWeight,
 		data.Date,
 		data.Mawb,
@@ -92,7 +88,7 @@ func (r repository) CreateMawbInfo(ctx context.Context, data *CreateMawbInfoRequ
 	return &response, nil
 }
 
-func (r repository) createTableIfNotExists(ctx context.Context, db *pg.DB) error {
+func (r repository) createTableIfNotExists(db common.Qer) error {
 	// First create the table if it doesn't exist
 	sqlStr := `
 		CREATE TABLE IF NOT EXISTS tbl_mawb_info (
@@ -108,7 +104,7 @@ func (r repository) createTableIfNotExists(ctx context.Context, db *pg.DB) error
 		)
 	`
 
-	_, err := db.ExecContext(ctx, sqlStr)
+	_, err := db.Exec(sqlStr)
 	if err != nil {
 		return err
 	}
@@ -127,14 +123,14 @@ func (r repository) createTableIfNotExists(ctx context.Context, db *pg.DB) error
 		END $$;
 	`
 
-	_, err = db.ExecContext(ctx, alterSQL)
+	_, err = db.Exec(alterSQL)
 	return err
 }
 
 // Helper function to check if attachments column exists
-func (r repository) hasAttachmentsColumn(ctx context.Context, db *pg.DB) bool {
+func (r repository) hasAttachmentsColumn(db common.Qer) bool {
 	var count int
-	_, err := db.QueryOneContext(ctx, pg.Scan(&count), `
+	_, err := db.QueryOne(pg.Scan(&count), `
 		SELECT COUNT(*) 
 		FROM information_schema.columns 
 		WHERE table_name = 'tbl_mawb_info' 
@@ -143,12 +139,13 @@ func (r repository) hasAttachmentsColumn(ctx context.Context, db *pg.DB) bool {
 	return err == nil && count > 0
 }
 func (r repository) GetMawbInfo(ctx context.Context, uuid string) (*MawbInfoResponse, error) {
-	db := ctx.Value("postgreSQLConn").(*pg.DB)
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	db, err := common.GetQer(ctx)
+	if err != nil {
+		return nil, err
+	}
 
 	// First ensure the table has the attachments column
-	err := r.createTableIfNotExists(ctx, db)
+	err = r.createTableIfNotExists(db)
 	if err != nil {
 		return nil, utils.PostgresErrorTransform(err)
 	}
@@ -158,7 +155,7 @@ func (r repository) GetMawbInfo(ctx context.Context, uuid string) (*MawbInfoResp
 	var hasDraft, hasCargo bool
 	// Build query based on whether attachments column exists
 	var sqlStr string
-	if r.hasAttachmentsColumn(ctx, db) {
+	if r.hasAttachmentsColumn(db) {
 		sqlStr = `
 			    SELECT
                                uuid,
@@ -192,14 +189,7 @@ func (r repository) GetMawbInfo(ctx context.Context, uuid string) (*MawbInfoResp
                `
 	}
 
-	sqlStr = utils.ReplaceSQL(sqlStr, "?")
-	stmt, err := db.Prepare(sqlStr)
-	if err != nil {
-		return nil, utils.PostgresErrorTransform(err)
-	}
-	defer stmt.Close()
-
-	_, err = stmt.QueryOneContext(ctx, pg.Scan(
+	_, err = db.QueryOne(pg.Scan(
 		&response.UUID,
 		&response.ChargeableWeight,
 		&response.Date,
@@ -210,7 +200,7 @@ func (r repository) GetMawbInfo(ctx context.Context, uuid string) (*MawbInfoResp
 		&response.CreatedAt,
 		&hasDraft,
 		&hasCargo,
-	), uuid)
+	), sqlStr, uuid)
 
 	if err != nil {
 		return nil, utils.PostgresErrorTransform(err)
@@ -239,14 +229,14 @@ func (r repository) GetAllMawbInfo(ctx context.Context, startDate, endDate strin
 	var responses []*MawbInfoResponse
 
 	// First ensure the table has the attachments column
-	err := r.createTableIfNotExists(ctx, db)
+	err := r.createTableIfNotExists(db)
 	if err != nil {
 		return nil, utils.PostgresErrorTransform(err)
 	}
 
 	// Build base query based on whether attachments column exists
 	var sqlStr string
-	if r.hasAttachmentsColumn(ctx, db) {
+	if r.hasAttachmentsColumn(db) {
 		sqlStr = `
 			   SELECT
                                uuid,
@@ -345,19 +335,19 @@ func (r repository) GetAllMawbInfo(ctx context.Context, startDate, endDate strin
 	return responses, nil
 }
 func (r repository) UpdateMawbInfo(ctx context.Context, uuid string, data *UpdateMawbInfoRequest, chargeableWeight float64, attachments []AttachmentInfo) (*MawbInfoResponse, error) {
-	db := ctx.Value("postgreSQLConn").(*pg.DB)
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	db, err := common.GetQer(ctx)
+	if err != nil {
+		return nil, err
+	}
 
 	// Ensure table exists with attachments column
-	err := r.createTableIfNotExists(ctx, db)
+	err = r.createTableIfNotExists(db)
 	if err != nil {
 		return nil, utils.PostgresErrorTransform(err)
 	}
 
-	// Get existing attachments first with a fresh context
-	getCtx := context.WithValue(context.Background(), "postgreSQLConn", db)
-	existingRecord, err := r.GetMawbInfo(getCtx, uuid)
+	// Get existing attachments first, in the same transaction when there is one
+	existingRecord, err := r.GetMawbInfo(ctx, uuid)
 	if err != nil {
 		// If record not found, continue with empty attachments
 		existingRecord = &MawbInfoResponse{
@@ -418,14 +408,7 @@ func (r repository) UpdateMawbInfo(ctx context.Context, uuid string, data *Updat
 			to_char(created_at at time zone 'utc' at time zone 'Asia/Bangkok', 'YYYY-MM-DD"T"HH24:MI:SS"Z"') as created_at
 	`
 
-	sqlStr = utils.ReplaceSQL(sqlStr, "?")
-	stmt, err := db.Prepare(sqlStr)
-	if err != nil {
-		return nil, utils.PostgresErrorTransform(err)
-	}
-	defer stmt.Close()
-
-	_, err = stmt.QueryOneContext(ctx, pg.Scan(
+	_, err = db.QueryOne(pg.Scan(
 		&response.UUID,
 		&response.ChargeableWeight,
 		&response.Date,
@@ -435,6 +418,7 @@ func (r repository) UpdateMawbInfo(ctx context.Context, uuid string, data *Updat
 		&attachmentsStr,
 		&response.CreatedAt,
 	),
+		sqlStr,
 		chargeableWeight,
 		data.Date,
 		data.Mawb,
@@ -574,26 +558,16 @@ func (r repository) DeleteMawbInfoAttachment(ctx context.Context, uuid string, f
 }
 
 func (r repository) IsMawbExists(ctx context.Context, mawb string, uuid string) (bool, error) {
-	db := ctx.Value("postgreSQLConn").(*pg.DB)
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	var count int
-	query := "SELECT count(*) FROM tbl_mawb_info WHERE mawb = ?"
-	params := []interface{}{mawb}
-
-	if uuid != "" {
-		query += " AND uuid != ?"
-		params = append(params, uuid)
+	db, err := common.GetQer(ctx)
+	if err != nil {
+		return false, err
 	}
 
-	query = utils.ReplaceSQL(query, "?")
-
-	var err error
+	var count int
 	if uuid != "" {
-		_, err = db.QueryOneContext(ctx, pg.Scan(&count), "SELECT count(*) FROM tbl_mawb_info WHERE mawb = ? AND uuid != ?", mawb, uuid)
+		_, err = db.QueryOne(pg.Scan(&count), "SELECT count(*) FROM tbl_mawb_info WHERE regexp_replace(mawb, '[^0-9]', '', 'g') = regexp_replace(?, '[^0-9]', '', 'g') AND uuid != ?", mawb, uuid)
 	} else {
-		_, err = db.QueryOneContext(ctx, pg.Scan(&count), "SELECT count(*) FROM tbl_mawb_info WHERE mawb = ?", mawb)
+		_, err = db.QueryOne(pg.Scan(&count), "SELECT count(*) FROM tbl_mawb_info WHERE regexp_replace(mawb, '[^0-9]', '', 'g') = regexp_replace(?, '[^0-9]', '', 'g')", mawb)
 	}
 
 	if err != nil {
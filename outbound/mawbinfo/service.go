@@ -4,8 +4,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"hpc-express-service/common"
 	"hpc-express-service/config"
 	"hpc-express-service/gcs"
+	"hpc-express-service/setting"
 	"hpc-express-service/utils"
 	"math"
 	"net/http"
@@ -32,6 +34,8 @@ type service struct {
 	contextTimeout time.Duration
 	gcsClient      *gcs.Client
 	conf           *config.Config
+	airlineSvc     setting.AirlineService
+	awbStockSvc    setting.AwbStockService
 }
 
 func NewService(
@@ -39,12 +43,16 @@ func NewService(
 	timeout time.Duration,
 	gcsClient *gcs.Client,
 	conf *config.Config,
+	airlineSvc setting.AirlineService,
+	awbStockSvc setting.AwbStockService,
 ) Service {
 	return &service{
 		selfRepo:       selfRepo,
 		contextTimeout: timeout,
 		gcsClient:      gcsClient,
 		conf:           conf,
+		airlineSvc:     airlineSvc,
+		awbStockSvc:    awbStockSvc,
 	}
 }
 
@@ -57,30 +65,60 @@ func (s *service) CreateMawbInfo(ctx context.Context, data *CreateMawbInfoReques
 		return nil, err
 	}
 
-	exists, err := s.selfRepo.IsMawbExists(ctx, data.Mawb, "")
+	// Convert chargeableWeight string to float64 with 2 decimal places
+	chargeableWeight, err := s.convertChargeableWeight(data.ChargeableWeight)
 	if err != nil {
 		return nil, err
 	}
-	if exists {
-		return nil, errors.New("mawb already exists")
+
+	// Validate date format
+	if err := s.validateDateFormat(data.Date); err != nil {
+		return nil, err
 	}
 
-	// Convert chargeableWeight string to float64 with 2 decimal places
-	chargeableWeight, err := s.convertChargeableWeight(data.ChargeableWeight)
+	// The number is allocated, checked and recorded in one transaction, so a MAWB info that
+	// cannot be created leaves the number in the stock.
+	tx, txCtx, err := common.BeginTx(ctx)
 	if err != nil {
 		return nil, err
 	}
+	defer tx.Rollback()
 
-	// Validate date format
-	if err := s.validateDateFormat(data.Date); err != nil {
+	// A MAWB left empty is taken from the airline's AWB stock
+	if strings.TrimSpace(data.Mawb) == "" {
+		if data.Mawb, err = s.awbStockSvc.AllocateMawb(txCtx, data.AirlineUUID); err != nil {
+			return nil, err
+		}
+	}
+
+	mawb, airline, err := s.airlineSvc.ResolveMawb(txCtx, data.Mawb)
+	if err != nil {
 		return nil, err
 	}
+	data.Mawb = mawb
+
+	exists, err := s.selfRepo.IsMawbExists(txCtx, data.Mawb, "")
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, errors.New("mawb already exists")
+	}
 
 	// Call repository to create MAWB info
-	result, err := s.selfRepo.CreateMawbInfo(ctx, data, chargeableWeight)
+	result, err := s.selfRepo.CreateMawbInfo(txCtx, data, chargeableWeight)
 	if err != nil {
 		return nil, err
 	}
+	setAirline(result, airline)
+
+	if err := s.awbStockSvc.MarkAwbUsed(txCtx, result.Mawb, result.UUID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
 
 	return result, nil
 }
@@ -99,8 +137,8 @@ func (s *service) validateInput(data *CreateMawbInfoRequest) error {
 		return errors.New("date is required")
 	}
 
-	if strings.TrimSpace(data.Mawb) == "" {
-		return errors.New("mawb is required")
+	if strings.TrimSpace(data.Mawb) == "" && strings.TrimSpace(data.AirlineUUID) == "" {
+		return errors.New("mawb or airlineUuid is required")
 	}
 
 	if strings.TrimSpace(data.ServiceType) == "" {
@@ -176,6 +214,12 @@ func (s *service) GetMawbInfo(ctx context.Context, uuid string) (*MawbInfoRespon
 		return nil, err
 	}
 
+	airline, err := s.airlineSvc.GetAirlineByMawb(ctx, result.Mawb)
+	if err != nil {
+		return nil, err
+	}
+	setAirline(result, airline)
+
 	return result, nil
 }
 
@@ -201,8 +245,47 @@ func (s *service) GetAllMawbInfo(ctx context.Context, startDate, endDate string)
 		return nil, err
 	}
 
+	// Most MAWBs of a period share a few airlines, so each prefix is looked up once.
+	airlines := map[string]*setting.Airline{}
+	for _, info := range result {
+		prefix := info.Mawb
+		if len(prefix) > 3 {
+			prefix = prefix[:3]
+		}
+		airline, ok := airlines[prefix]
+		if !ok {
+			if airline, err = s.airlineSvc.GetAirlineByMawb(ctx, info.Mawb); err != nil {
+				return nil, err
+			}
+			airlines[prefix] = airline
+		}
+		setAirline(info, airline)
+	}
+
 	return result, nil
 }
+
+// setAirline shows the airline the MAWB prefix belongs to on a response.
+// sameAwbNumber compares two AWB numbers by their digits, as 217-12345675 and 21712345675 are
+// the same number.
+func sameAwbNumber(a, b string) bool {
+	digits := func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}
+	return strings.Map(digits, a) == strings.Map(digits, b)
+}
+
+func setAirline(result *MawbInfoResponse, airline *setting.Airline) {
+	if result == nil || airline == nil {
+		return
+	}
+	result.AirlineUUID = airline.UUID
+	result.AirlineName = airline.Name
+	result.AirlineLogo = airline.LogoURL
+}
 func (s *service) UpdateMawbInfo(ctx context.Context, uuid string, data *UpdateMawbInfoRequest) (*MawbInfoResponse, error) {
 	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
 	defer cancel()
@@ -216,6 +299,12 @@ func (s *service) UpdateMawbInfo(ctx context.Context, uuid string, data *UpdateM
 		return nil, err
 	}
 
+	mawb, airline, err := s.airlineSvc.ResolveMawb(ctx, data.Mawb)
+	if err != nil {
+		return nil, err
+	}
+	data.Mawb = mawb
+
 	exists, err := s.selfRepo.IsMawbExists(ctx, data.Mawb, uuid)
 	if err != nil {
 		return nil, err
@@ -294,13 +383,41 @@ func (s *service) UpdateMawbInfo(ctx context.Context, uuid string, data *UpdateM
 
 	fmt.Printf("DEBUG: Final attachmentInfos count: %d\n", len(attachmentInfos))
 
+	// The update and the stock bookkeeping run in one transaction, so a number that cannot be
+	// marked used leaves both the MAWB info and the stock as they were.
+	tx, txCtx, err := common.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	current, err := s.selfRepo.GetMawbInfo(txCtx, uuid)
+	if err != nil {
+		return nil, err
+	}
+
 	// Call repository to update MAWB info
-	result, err := s.selfRepo.UpdateMawbInfo(ctx, uuid, data, chargeableWeight, attachmentInfos)
+	result, err := s.selfRepo.UpdateMawbInfo(txCtx, uuid, data, chargeableWeight, attachmentInfos)
 	if err != nil {
 		return nil, err
 	}
 
 	fmt.Printf("DEBUG: Repository result attachments count: %d\n", len(result.Attachments))
+	setAirline(result, airline)
+
+	if err := s.awbStockSvc.MarkAwbUsed(txCtx, result.Mawb, uuid); err != nil {
+		return nil, err
+	}
+	// A changed MAWB gives its old number back to the stock
+	if !sameAwbNumber(current.Mawb, result.Mawb) {
+		if err := s.awbStockSvc.ReleaseAwb(txCtx, current.Mawb, uuid); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
 
 	return result, nil
 }
@@ -0,0 +1,188 @@
+package preexport
+
+import (
+	"math"
+	"strings"
+
+	"hpc-express-service/setting"
+	"hpc-express-service/utils"
+)
+
+// DeMinimis is the parcel FOB value in THB above which a pre-export line is category 3.
+const DeMinimis = 1500
+
+// Parcel is one marketplace parcel (HAWB) with the items declared in it.
+type Parcel struct {
+	Hawb                   string
+	ReceiverName           string
+	ReceiverAddress        string
+	ReceiverDistrict       string
+	ReceiverProvince       string
+	ReceiverPostcode       string
+	DestinationCountryCode string
+	PurchaseCountryCode    string
+	Weight                 float64 // KGM
+	Packages               int64
+	Items                  []*Item
+}
+
+// Item is one declared item of a parcel. Value is the FOB value of the whole quantity in THB.
+// SKU is only used to merge repeated rows of the same item.
+type Item struct {
+	SKU              string
+	Description      string
+	DescriptionLocal string
+	HSCode           string
+	Quantity         int64
+	Value            float64
+}
+
+// Value returns the FOB value of the parcel in THB.
+func (p *Parcel) Value() float64 {
+	var total float64
+	for _, v := range p.Items {
+		total += v.Value
+	}
+	return total
+}
+
+// BuildLines builds one pre-export line per declared item of a parcel, declaring profile as
+// the consignor. The category is decided on the parcel total; weight, freight and insurance are
+// split over the items by declared value. A parcel without declared items gets a single line.
+func BuildLines(p *Parcel, profile *setting.ExporterProfile) []*utils.InsertPreExportDetailManifestModel {
+	items := p.Items
+	if len(items) == 0 {
+		items = []*Item{{}}
+	}
+
+	fobValueBaht := p.Value()
+	values := make([]float64, len(items))
+	for i, v := range items {
+		values[i] = v.Value
+	}
+
+	var category int64
+	if fobValueBaht > DeMinimis {
+		category = 3
+	} else {
+		category = 2
+	}
+
+	// pre-export amounts are whole baht
+	freight := math.Round(profile.Freight(fobValueBaht, p.Weight))
+	insurance := math.Round(profile.Insurance(fobValueBaht, p.Weight))
+	weights := SplitByValue(p.Weight, values, 3)
+	freights := SplitByValue(freight, values, 0)
+	insurances := SplitByValue(insurance, values, 0)
+
+	lines := []*utils.InsertPreExportDetailManifestModel{}
+	for i, v := range items {
+		tariffCode := ToTariffCode(v.HSCode)
+		if tariffCode == "" {
+			tariffCode = profile.DefaultTariffCode
+		}
+
+		// the parcel is declared as its packages on the first item
+		var packageAmount int64
+		if i == 0 {
+			packageAmount = p.Packages
+		}
+
+		lines = append(lines, &utils.InsertPreExportDetailManifestModel{
+			HeaderUUID:                  "",
+			MasterAirWaybill:            "",
+			HouseAirWaybill:             p.Hawb,
+			ItemNo:                      int64(i + 1),
+			Category:                    category,
+			ConsignorCompanyTaxNumber:   profile.TaxNumber,
+			ConsignorCompanyBranch:      profile.Branch,
+			ConsignorName:               profile.Name,
+			ConsignorStreetAndAddress:   profile.StreetAndAddress,
+			ConsignorDistrict:           profile.District,
+			ConsignorSubProvince:        profile.SubProvince,
+			ConsignorProvince:           profile.Province,
+			ConsignorPostcode:           profile.Postcode,
+			ConsignorEmail:              profile.Email,
+			ConsigneeName:               p.ReceiverName,
+			ConsigneeStreetAndAddress:   p.ReceiverAddress,
+			ConsigneeDistrict:           p.ReceiverDistrict,
+			ConsigneeSubProvince:        "",
+			ConsigneeProvince:           p.ReceiverProvince,
+			ConsigneePostcode:           p.ReceiverPostcode,
+			ConsigneeCountryCode:        p.DestinationCountryCode,
+			ConsigneeEmail:              "",
+			PurchaseCountryCode:         p.PurchaseCountryCode,
+			DestinationCountryCode:      p.DestinationCountryCode,
+			ThaiDescriptionOfGoods:      v.DescriptionLocal,
+			EnglishDescriptionOfGoods:   v.Description,
+			Quantity:                    v.Quantity,
+			QuantityUnitCode:            "C62",
+			NetWeight:                   weights[i],
+			NetWeightUnitCode:           "KGM",
+			GrossWeight:                 weights[i],
+			GrossWeightUnitCode:         "KGM",
+			PackageAmount:               packageAmount,
+			PackageUnitCode:             "PK",
+			Remark:                      "REMARK",
+			FobValueBaht:                v.Value,
+			FobValueForeign:             0,
+			CurrencyCode:                "THB",
+			ExchangeRate:                1,
+			FreightAmount:               int64(freights[i]),
+			FreightAmountCurrencyCode:   "THB",
+			InsuranceAmount:             int64(insurances[i]),
+			InsuranceAmountCurrencyCode: "THB",
+			TariffCode:                  tariffCode,
+			StatCode:                    profile.DefaultStatCode,
+			TariffSequence:              profile.DefaultTariffSequence,
+		})
+	}
+
+	return lines
+}
+
+// SplitByValue splits total over the items in proportion to their values (evenly when all are zero),
+// rounded to places decimals; the last item takes the rounding difference so the parts add up to total.
+func SplitByValue(total float64, values []float64, places int) []float64 {
+	parts := make([]float64, len(values))
+	if len(values) == 0 {
+		return parts
+	}
+
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+
+	scale := math.Pow(10, float64(places))
+	var allocated float64
+	for i, v := range values[:len(values)-1] {
+		share := 1 / float64(len(values))
+		if sum > 0 {
+			share = v / sum
+		}
+		parts[i] = math.Round(total*share*scale) / scale
+		allocated += parts[i]
+	}
+	parts[len(values)-1] = math.Round((total-allocated)*scale) / scale
+
+	return parts
+}
+
+// ToTariffCode turns a declared HS code ("6109.10", "61091000") into the 12 digit tariff code of the
+// pre-export sheet: the 8 digit national code, zero filled, behind four leading zeros.
+func ToTariffCode(hsCode string) string {
+	digits := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, hsCode)
+	if digits == "" {
+		return ""
+	}
+	if len(digits) > 8 {
+		digits = digits[:8]
+	}
+	return "0000" + digits + strings.Repeat("0", 8-len(digits))
+}
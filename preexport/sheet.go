@@ -0,0 +1,99 @@
+package preexport
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// countryCodes maps the destination country names used by marketplace exports onto ISO codes.
+var countryCodes = map[string]string{
+	"thailand":    "TH",
+	"malaysia":    "MY",
+	"singapore":   "SG",
+	"vietnam":     "VN",
+	"viet nam":    "VN",
+	"philippines": "PH",
+	"indonesia":   "ID",
+	"taiwan":      "TW",
+	"brazil":      "BR",
+	"mexico":      "MX",
+}
+
+// Columns is the column index of every header of a marketplace sheet, looked up by header name.
+type Columns map[string]int
+
+// NewColumns indexes headers by name and fails on the first required header that is missing,
+// so a file exported with reordered or extra columns is still accepted.
+func NewColumns(headers []string, required []string) (Columns, error) {
+	c := Columns{}
+	for i, header := range headers {
+		header = strings.TrimSpace(header)
+		if _, exists := c[header]; !exists && header != "" {
+			c[header] = i
+		}
+	}
+
+	for _, header := range required {
+		if _, ok := c[header]; !ok {
+			return nil, fmt.Errorf("missing header '%s'", header)
+		}
+	}
+
+	return c, nil
+}
+
+// Cell returns the trimmed value of header in row, or "" when the column is absent or the row is short.
+func (c Columns) Cell(row []string, header string) string {
+	idx, ok := c[header]
+	if !ok || idx >= len(row) {
+		return ""
+	}
+	return strings.TrimSpace(row[idx])
+}
+
+// Add appends item to the parcel, merging it into an earlier item with the same SKU.
+func (p *Parcel) Add(item *Item) {
+	if item.SKU != "" {
+		for _, v := range p.Items {
+			if v.SKU == item.SKU {
+				v.Quantity += item.Quantity
+				v.Value += item.Value
+				return
+			}
+		}
+	}
+	p.Items = append(p.Items, item)
+}
+
+// ParseAmount reads a THB amount such as "1,299.00", "฿1,299" or "THB 1299". An amount in
+// any other currency is rejected because pre-export values are declared in THB.
+func ParseAmount(s string) (float64, error) {
+	s = strings.TrimSpace(strings.ReplaceAll(s, ",", ""))
+	s = strings.TrimSpace(strings.TrimPrefix(s, "฿"))
+	if len(s) > 3 && s[0] >= 'A' && s[0] <= 'Z' {
+		currency := strings.ToUpper(s[:3])
+		if currency != "THB" {
+			return 0, fmt.Errorf("currency '%s' is not supported, amounts must be in THB", currency)
+		}
+		s = strings.TrimSpace(s[3:])
+	}
+	if s == "" {
+		return 0, nil
+	}
+
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("'%s' is not an amount", s)
+	}
+	return f, nil
+}
+
+// CountryCode returns the ISO code of a destination country given either as a code or as its English name.
+func CountryCode(s string) string {
+	s = strings.TrimSpace(s)
+	if code, ok := countryCodes[strings.ToLower(s)]; ok {
+		return code
+	}
+	return strings.ToUpper(s)
+}
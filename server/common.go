@@ -2,8 +2,11 @@ package server
 
 import (
 	"context"
+	"errors"
 	"hpc-express-service/common"
+	"io/ioutil"
 	"net/http"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/render"
@@ -17,6 +20,9 @@ func (h *commonHandler) router() chi.Router {
 	r := chi.NewRouter()
 
 	r.Get("/exchange_rates", h.getAllExchangeRates)
+	r.Post("/exchange_rates/import", h.importExchangeRates)
+	r.Get("/exchange_rates/history", h.getExchangeRateHistories)
+	r.Get("/exchange_rates/rate-on", h.getExchangeRateOn)
 	r.Get("/convert_templates", h.getAllConvertTemplates)
 
 	return r
@@ -54,3 +60,98 @@ func (h *commonHandler) getAllConvertTemplates(w http.ResponseWriter, r *http.Re
 
 	render.Respond(w, r, SuccessResponse(result, "success"))
 }
+
+func (h *commonHandler) importExchangeRates(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	r.ParseMultipartForm(10 << uint32(20)) // 10 * 2^20
+	file, handler, err := r.FormFile("file")
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+	defer file.Close()
+
+	fileBytes, err := ioutil.ReadAll(file)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	effectiveFrom, err := time.Parse("2006-01-02", r.FormValue("effectiveFrom"))
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(errors.New("effectiveFrom must be YYYY-MM-DD")))
+		return
+	}
+
+	var effectiveTo time.Time
+	if v := r.FormValue("effectiveTo"); v != "" {
+		effectiveTo, err = time.Parse("2006-01-02", v)
+		if err != nil {
+			render.Render(w, r, ErrInvalidRequest(errors.New("effectiveTo must be YYYY-MM-DD")))
+			return
+		}
+	}
+
+	result, err := h.s.ImportExchangeRates(ctx, &common.ImportExchangeRateModel{
+		FileName:      handler.Filename,
+		FileBytes:     fileBytes,
+		EffectiveFrom: effectiveFrom,
+		EffectiveTo:   effectiveTo,
+	})
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	render.Respond(w, r, SuccessResponse(result, "success"))
+}
+
+func (h *commonHandler) getExchangeRateHistories(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	result, err := h.s.GetExchangeRateHistories(ctx, r.URL.Query().Get("currencyCode"))
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	render.Respond(w, r, SuccessResponse(result, "success"))
+}
+
+func (h *commonHandler) getExchangeRateOn(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	currencyCode := r.URL.Query().Get("currencyCode")
+	if currencyCode == "" {
+		render.Render(w, r, ErrInvalidRequest(errors.New("required currencyCode")))
+		return
+	}
+
+	on := time.Now()
+	if v := r.URL.Query().Get("date"); v != "" {
+		var err error
+		on, err = time.Parse("2006-01-02", v)
+		if err != nil {
+			render.Render(w, r, ErrInvalidRequest(errors.New("date must be YYYY-MM-DD")))
+			return
+		}
+	}
+
+	result, err := h.s.RateOn(ctx, currencyCode, on)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	render.Respond(w, r, SuccessResponse(result, "success"))
+}
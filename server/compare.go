@@ -6,6 +6,7 @@ import (
 	"hpc-express-service/tools/compare"
 	"io"
 	"net/http"
+	"strconv"
 
 	"github.com/go-chi/chi/v5"
 )
@@ -70,8 +71,25 @@ func (h *excelHandler) compareExcel(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Optional fuzzy matching options for goods columns
+	opts := compare.CompareOptions{}
+	if v := r.FormValue("topN"); v != "" {
+		opts.TopN, err = strconv.Atoi(v)
+		if err != nil {
+			http.Error(w, "topN must be a number", http.StatusBadRequest)
+			return
+		}
+	}
+	if v := r.FormValue("threshold"); v != "" {
+		opts.Threshold, err = strconv.ParseFloat(v, 64)
+		if err != nil || opts.Threshold > 1 {
+			http.Error(w, "threshold must be a number between 0 and 1", http.StatusBadRequest)
+			return
+		}
+	}
+
 	// Excel processing is now handled by the service
-	response, err := h.service.CompareExcelWithDB(r.Context(), excelFileBytes, columnName)
+	response, err := h.service.CompareExcelWithDB(r.Context(), excelFileBytes, columnName, opts)
 	if err != nil {
 
 		fmt.Printf("Service error during comparison for column '%s': %v\n", columnName, err)           // Log for debugging
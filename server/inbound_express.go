@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	inbound "hpc-express-service/inbound/express"
+	"hpc-express-service/utils"
 	"io/ioutil"
 	"log"
 	"net/http"
@@ -25,10 +26,12 @@ func (h *inboundExpressHandler) router() chi.Router {
 		r.Route("/download", func(r chi.Router) {
 			r.Get("/pre-import/{headerUUID}", h.downloadPreImport)
 			r.Get("/raw-pre-import/{headerUUID}", h.downloadRawPreImport)
+			r.Get("/import-declaration/{headerUUID}", h.downloadImportDeclaration)
 		})
 		r.Route("/upload", func(r chi.Router) {
 			r.Post("/", h.uploadManifestDetails)
 			r.Post("/update-raw-manifest", h.uploadUpdateRawPreImport)
+			r.Post("/pre-alert", h.uploadPreAlert)
 		})
 		r.Get("/", h.getAllMawb)
 		r.Post("/", h.createMawb)
@@ -154,14 +157,35 @@ func (h *inboundExpressHandler) uploadManifestDetails(w http.ResponseWriter, r *
 
 	log.Println("#1 ", templateCode)
 
+	if r.FormValue("dryRun") == "true" {
+		result, err := h.s.PreviewManifestDetails(ctx, headerUUID, templateCode, fileBytes)
+		if err != nil {
+			var report *utils.ValidationReport
+			if errors.As(err, &report) {
+				render.Render(w, r, ErrValidationFailed(err, report))
+				return
+			}
+			render.Render(w, r, ErrInvalidRequest(err))
+			return
+		}
+
+		render.Respond(w, r, SuccessResponse(result, "success"))
+		return
+	}
+
 	// result, err := h.s.UploadManifest(ctx, userUUID, handler.Filename, fileBytes)
-	err = h.s.UploadManifestDetails(ctx, userUUID, headerUUID, handler.Filename, templateCode, fileBytes)
+	result, err := h.s.UploadManifestDetails(ctx, userUUID, headerUUID, handler.Filename, templateCode, fileBytes)
 	if err != nil {
+		var report *utils.ValidationReport
+		if errors.As(err, &report) {
+			render.Render(w, r, ErrValidationFailed(err, report))
+			return
+		}
 		render.Render(w, r, ErrInvalidRequest(err))
 		return
 	}
 
-	render.Respond(w, r, SuccessResponse(nil, "success"))
+	render.Respond(w, r, SuccessResponse(result, "success"))
 }
 
 func (h *inboundExpressHandler) downloadPreImport(w http.ResponseWriter, r *http.Request) {
@@ -189,6 +213,31 @@ func (h *inboundExpressHandler) downloadPreImport(w http.ResponseWriter, r *http
 	w.Write(zipBuf.Bytes())
 }
 
+func (h *inboundExpressHandler) downloadImportDeclaration(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	headerUUID := chi.URLParam(r, "headerUUID")
+	if len(headerUUID) == 0 {
+		render.Render(w, r, ErrInvalidRequest(errors.New("required uuid")))
+		return
+	}
+
+	fileName, zipBuf, err := h.s.DownloadImportDeclaration(ctx, headerUUID)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	// Send ZIP file as response
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+fileName+`.zip"`)
+	w.WriteHeader(http.StatusOK)
+	w.Write(zipBuf.Bytes())
+}
+
 func (h *inboundExpressHandler) downloadRawPreImport(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	if ctx == nil {
@@ -282,3 +331,33 @@ func (h *inboundExpressHandler) getSummary(w http.ResponseWriter, r *http.Reques
 
 	render.Respond(w, r, SuccessResponse(result, "success"))
 }
+
+// uploadPreAlert creates or updates headers from an airline FFM or FWB text file.
+func (h *inboundExpressHandler) uploadPreAlert(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	r.ParseMultipartForm(10 << uint32(20)) // 10 * 2^20
+	file, handler, err := r.FormFile("file")
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+	defer file.Close()
+
+	fileBytes, err := ioutil.ReadAll(file)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	result, err := h.s.ImportPreAlert(ctx, GetUserUUIDFromContext(r), handler.Filename, fileBytes)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	render.Respond(w, r, SuccessResponse(result, "success"))
+}
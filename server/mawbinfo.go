@@ -11,6 +11,7 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
 
 	"github.com/go-chi/chi/v5"
@@ -67,12 +68,17 @@ func (h *mawbInfoHandler) router() chi.Router {
 		r.Get("/draft-mawb", h.getDraftMAWB)
 		r.Post("/draft-mawb", h.createDraftMAWB)
 		r.Put("/draft-mawb", h.updateDraftMAWB)
+		r.Post("/draft-mawb/clone-from/{sourceDraftUUID}", h.cloneDraftMAWB)
 		r.Post("/draft-mawb/send-customer", h.sendDraftMAWBToCustomer)
 		r.Post("/draft-mawb/customer-confirm", h.customerConfirmDraftMAWB)
 		r.Post("/draft-mawb/customer-reject", h.customerRejectDraftMAWB)
 		r.Post("/draft-mawb/confirm", h.confirmDraftMAWB)
 		r.Post("/draft-mawb/reject", h.rejectDraftMAWB)
 		r.Get("/draft-mawb/print", h.printDraftMAWB)
+		r.Get("/draft-mawb/fwb", h.downloadDraftMAWBFWB)
+		r.Get("/draft-mawb/fhl", h.downloadDraftMAWBFHL)
+		r.Get("/draft-mawb/xfwb", h.downloadDraftMAWBXFWB)
+		r.Get("/draft-mawb/xfzb", h.downloadDraftMAWBXFZB)
 		r.Post("/draft-mawb/preview", h.previewDraftMAWB)
 
 		// MAWB management routes
@@ -453,6 +459,43 @@ func (h *mawbInfoHandler) updateDraftMAWB(w http.ResponseWriter, r *http.Request
 
 	render.Respond(w, r, SuccessResponse(map[string]string{"uuid": result.UUID}, "Draft MAWB updated successfully"))
 }
+
+// cloneDraftMAWB creates the draft of this MAWB from another draft. Items are copied only with
+// ?includeItems=true.
+func (h *mawbInfoHandler) cloneDraftMAWB(w http.ResponseWriter, r *http.Request) {
+	mawbUUID := chi.URLParam(r, "uuid")
+	sourceUUID := chi.URLParam(r, "sourceDraftUUID")
+	if mawbUUID == "" || sourceUUID == "" {
+		render.Render(w, r, ErrInvalidRequest(fmt.Errorf("uuid and sourceDraftUUID parameters are required")))
+		return
+	}
+	includeItems, _ := strconv.ParseBool(r.URL.Query().Get("includeItems"))
+
+	mawbInfo, err := h.s.GetMawbInfo(r.Context(), mawbUUID)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+	if mawbInfo == nil {
+		render.Render(w, r, &ErrResponse{HTTPStatusCode: http.StatusNotFound, Message: "MAWB info not found"})
+		return
+	}
+
+	// Check if draft MAWB already exists for this MAWB UUID
+	existing, _ := h.draftMAWBSvc.GetDraftMAWBByMAWBUUID(r.Context(), mawbUUID)
+	if existing != nil {
+		render.Render(w, r, &ErrResponse{HTTPStatusCode: http.StatusConflict, Message: "Draft MAWB already exists for this MAWB"})
+		return
+	}
+
+	result, err := h.draftMAWBSvc.CloneDraftMAWB(r.Context(), mawbUUID, mawbInfo.Mawb, sourceUUID, includeItems)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	render.Respond(w, r, SuccessResponse(result, "Draft MAWB cloned successfully"))
+}
 func (h *mawbInfoHandler) sendDraftMAWBToCustomer(w http.ResponseWriter, r *http.Request) {
 	mawbUUID := chi.URLParam(r, "uuid")
 	if mawbUUID == "" {
@@ -885,6 +928,11 @@ func (h *mawbInfoHandler) previewDraftMAWB(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	if err := h.draftMAWBSvc.PreviewDraftMAWB(r.Context(), inputData); err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
 	// Generate PDF preview
 	pdfBuffer, err := h.generateDraftMAWBPDF(inputData, true)
 	if err != nil {
@@ -916,6 +964,7 @@ func convertItemInputsToItems(inputs []draftMawb.DraftMAWBItemInput) []draftMawb
 			RateCharge:        input.RateCharge,
 			Total:             input.Total,
 			NatureAndQuantity: input.NatureAndQuantity,
+			DimUnit:           input.DimUnit,
 			Dims:              convertDimInputsToDims(input.Dims),
 		}
 	}
@@ -1008,9 +1057,13 @@ func (h *mawbInfoHandler) generateCargoManifestPDF(manifest *cargoManifest.Cargo
 	// แปลง \n ที่มาจาก client ให้ขึ้นบรรทัดจริง
 	shipper := strings.ReplaceAll(manifest.Shipper, "\\n", "\n")
 	consignee := strings.ReplaceAll(manifest.Consignee, "\\n", "\n")
+	notify := strings.ReplaceAll(manifest.Notify, "\\n", "\n")
 
 	labelValueMulti("SHIPPER: ", shipper, labelW, lineH)
 	labelValueMulti("CONSIGNEE: ", consignee, labelW, lineH)
+	if strings.TrimSpace(notify) != "" {
+		labelValueMulti("NOTIFY: ", notify, labelW, lineH)
+	}
 
 	labelValue("TOTAL CTN: ", manifest.TotalCtn, labelW, lineH)
 
@@ -1259,9 +1312,15 @@ func (h *mawbInfoHandler) generateDraftMAWBPDF(data *draftMawb.DraftMAWBInput, i
 	pdf.SetXY(98, 111)
 	pdf.MultiCell(28, 7, data.AmountOfInsurance, "0", "C", false)
 
+	// A notify party takes a line of the box, so the text is printed on half height lines
+	handling := data.HandlingText()
+	handlingLineH := 12.0
+	if strings.TrimSpace(data.NotifyNameAndAddress) != "" && strings.TrimSpace(data.HandlingInfomation) != "" {
+		handlingLineH = 6
+	}
 	setFont("THSarabunNew Bold", "", 16)
 	pdf.SetXY(9, 119)
-	pdf.MultiCell(156, 12, data.HandlingInfomation, "0", "L", false)
+	pdf.MultiCell(156, handlingLineH, handling, "0", "L", false)
 
 	pdf.SetXY(165, 125)
 	pdf.MultiCell(30, 7, data.SCI, "0", "L", false)
@@ -1347,24 +1406,36 @@ func (h *mawbInfoHandler) generateDraftMAWBPDF(data *draftMawb.DraftMAWBInput, i
 		}
 	}
 
-	pdf.SetXY(6, 204)
+	// Charges go in the prepaid or the collect column as ticked on WT/VAL and Other
+	wtValX, otherX := 6.0, 6.0
+	if !data.WtValPrepaid() {
+		wtValX = 41
+	}
+	if !data.OtherPrepaid() {
+		otherX = 41
+	}
+
+	pdf.SetXY(wtValX, 204)
 	pdf.MultiCell(33, 6, fmt.Sprintf("%.2f", data.Prepaid), "0", "C", false)
 
-	pdf.SetXY(6, 212)
+	pdf.SetXY(wtValX, 212)
 	pdf.MultiCell(33, 6, fmt.Sprintf("%.2f", data.ValuationCharge), "0", "C", false)
 
-	pdf.SetXY(6, 221)
+	pdf.SetXY(wtValX, 221)
 	pdf.MultiCell(33, 6, fmt.Sprintf("%.2f", data.Tax), "0", "C", false)
 
-	pdf.SetXY(6, 230)
+	pdf.SetXY(otherX, 230)
 	pdf.MultiCell(33, 6, fmt.Sprintf("%.2f", data.TotalOtherChargesDueAgent), "0", "C", false)
 
-	pdf.SetXY(6, 239)
+	pdf.SetXY(otherX, 239)
 	pdf.MultiCell(33, 6, fmt.Sprintf("%.2f", data.TotalOtherChargesDueCarrier), "0", "C", false)
 
 	pdf.SetXY(6, 257)
 	pdf.MultiCell(33, 6, fmt.Sprintf("%.2f", data.TotalPrepaid), "0", "C", false)
 
+	pdf.SetXY(41, 257)
+	pdf.MultiCell(33, 6, fmt.Sprintf("%.2f", data.TotalCollect), "0", "C", false)
+
 	pdf.SetXY(6, 266)
 	pdf.MultiCell(33, 6, data.CurrencyConversionRates, "0", "C", false)
 
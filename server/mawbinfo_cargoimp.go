@@ -0,0 +1,158 @@
+package server
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"net/http"
+	"time"
+
+	"hpc-express-service/cargoimp"
+	cargoManifest "hpc-express-service/outbound/cargoManifest"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+// Cargo-IMP / Cargo-XML e-AWB Handlers
+
+func (h *mawbInfoHandler) downloadDraftMAWBFWB(w http.ResponseWriter, r *http.Request) {
+	waybill, ok := h.getDraftMAWBWaybill(w, r)
+	if !ok {
+		return
+	}
+
+	message, err := cargoimp.BuildFWB(waybill)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	writeCargoIMPAttachment(w, "text/plain; charset=utf-8", fmt.Sprintf("fwb_%s%s.txt", waybill.Prefix, waybill.Serial), []byte(message))
+}
+
+func (h *mawbInfoHandler) downloadDraftMAWBFHL(w http.ResponseWriter, r *http.Request) {
+	waybill, ok := h.getDraftMAWBWaybill(w, r)
+	if !ok {
+		return
+	}
+	manifest, ok := h.getCargoManifestForWaybill(w, r)
+	if !ok {
+		return
+	}
+
+	message, err := cargoimp.BuildFHL(waybill, manifest.ToHouses())
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	writeCargoIMPAttachment(w, "text/plain; charset=utf-8", fmt.Sprintf("fhl_%s%s.txt", waybill.Prefix, waybill.Serial), []byte(message))
+}
+
+func (h *mawbInfoHandler) downloadDraftMAWBXFWB(w http.ResponseWriter, r *http.Request) {
+	waybill, ok := h.getDraftMAWBWaybill(w, r)
+	if !ok {
+		return
+	}
+
+	message, err := cargoimp.BuildXFWB(waybill, time.Now())
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	writeCargoIMPAttachment(w, "application/xml", fmt.Sprintf("xfwb_%s%s.xml", waybill.Prefix, waybill.Serial), message)
+}
+
+// downloadDraftMAWBXFZB zips one XFZB message per house waybill of the cargo manifest.
+func (h *mawbInfoHandler) downloadDraftMAWBXFZB(w http.ResponseWriter, r *http.Request) {
+	waybill, ok := h.getDraftMAWBWaybill(w, r)
+	if !ok {
+		return
+	}
+	manifest, ok := h.getCargoManifestForWaybill(w, r)
+	if !ok {
+		return
+	}
+
+	houses := manifest.ToHouses()
+	if len(houses) == 0 {
+		render.Render(w, r, ErrInvalidRequest(fmt.Errorf("cargo manifest has no house waybills")))
+		return
+	}
+
+	now := time.Now()
+	var zipBuf bytes.Buffer
+	zipWriter := zip.NewWriter(&zipBuf)
+	for _, house := range houses {
+		message, err := cargoimp.BuildXFZB(waybill, house, now)
+		if err != nil {
+			render.Render(w, r, ErrInvalidRequest(err))
+			return
+		}
+		fileWriter, err := zipWriter.Create(fmt.Sprintf("xfzb_%s.xml", house.HAWB))
+		if err != nil {
+			render.Render(w, r, ErrInvalidRequest(err))
+			return
+		}
+		if _, err := fileWriter.Write(message); err != nil {
+			render.Render(w, r, ErrInvalidRequest(err))
+			return
+		}
+	}
+	if err := zipWriter.Close(); err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	writeCargoIMPAttachment(w, "application/zip", fmt.Sprintf("xfzb_%s%s.zip", waybill.Prefix, waybill.Serial), zipBuf.Bytes())
+}
+
+// getDraftMAWBWaybill loads the draft MAWB of the {uuid} MAWB info as an e-AWB waybill,
+// rendering the error response itself when it cannot.
+func (h *mawbInfoHandler) getDraftMAWBWaybill(w http.ResponseWriter, r *http.Request) (*cargoimp.Waybill, bool) {
+	mawbUUID := chi.URLParam(r, "uuid")
+	if mawbUUID == "" {
+		render.Render(w, r, ErrInvalidRequest(fmt.Errorf("uuid parameter is required")))
+		return nil, false
+	}
+
+	draft, err := h.draftMAWBSvc.GetDraftMAWBWithRelationsByMAWBUUID(r.Context(), mawbUUID)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return nil, false
+	}
+	if draft == nil {
+		render.Render(w, r, &ErrResponse{HTTPStatusCode: http.StatusNotFound, Message: "Draft MAWB not found for this MAWB"})
+		return nil, false
+	}
+
+	waybill, err := draft.ToWaybill()
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return nil, false
+	}
+	return waybill, true
+}
+
+func (h *mawbInfoHandler) getCargoManifestForWaybill(w http.ResponseWriter, r *http.Request) (*cargoManifest.CargoManifest, bool) {
+	manifest, err := h.cargoManifestSvc.GetCargoManifestByMAWBUUID(r.Context(), chi.URLParam(r, "uuid"))
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return nil, false
+	}
+	if manifest == nil {
+		render.Render(w, r, &ErrResponse{HTTPStatusCode: http.StatusNotFound, Message: "Cargo Manifest not found for this MAWB"})
+		return nil, false
+	}
+	return manifest, true
+}
+
+func writeCargoIMPAttachment(w http.ResponseWriter, contentType, fileName string, data []byte) {
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", `attachment; filename="`+fileName+`"`)
+	w.Header().Set("Content-Length", fmt.Sprintf("%d", len(data)))
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/render"
+	"github.com/go-playground/validator"
 )
 
 type outboundExpressHandler struct {
@@ -22,6 +23,16 @@ func (h *outboundExpressHandler) router() chi.Router {
 
 	r.Post("/upload", h.uploadManifest)
 	r.Get("/download/pre-export", h.downloadPreExport)
+	r.Get("/download/pre-export/{headerUUID}", h.downloadPreExportByHeader)
+	r.Get("/download/export-declaration", h.downloadExportDeclaration)
+	r.Get("/download/export-declaration/{headerUUID}", h.downloadExportDeclarationByHeader)
+
+	r.Route("/mawb", func(r chi.Router) {
+		r.Get("/", h.getAllHeaders)
+		r.Post("/", h.createHeader)
+		r.Put("/", h.updateHeader)
+		r.Get("/{headerUUID}", h.getHeader)
+	})
 
 	return r
 }
@@ -47,12 +58,16 @@ func (h *outboundExpressHandler) uploadManifest(w http.ResponseWriter, r *http.R
 	}
 
 	templateCode := r.FormValue("templateCode")
+	headerUUID := r.FormValue("headerUUID")
+	customerUUID := r.FormValue("customerUUID")
+	vasselName := r.FormValue("vasselName")
+	departureDate := r.FormValue("departureDate")
 	userUUID := GetUserUUIDFromContext(r)
 
 	log.Println("#1 ", templateCode)
 
 	// result, err := h.s.UploadManifest(ctx, userUUID, handler.Filename, fileBytes)
-	err = h.s.UploadManifest(ctx, userUUID, handler.Filename, templateCode, fileBytes)
+	err = h.s.UploadManifest(ctx, userUUID, headerUUID, customerUUID, vasselName, departureDate, handler.Filename, templateCode, fileBytes)
 	if err != nil {
 		render.Render(w, r, ErrInvalidRequest(err))
 		return
@@ -85,3 +100,125 @@ func (h *outboundExpressHandler) downloadPreExport(w http.ResponseWriter, r *htt
 	w.WriteHeader(http.StatusOK)
 	w.Write(zipBuf.Bytes())
 }
+
+func (h *outboundExpressHandler) downloadPreExportByHeader(w http.ResponseWriter, r *http.Request) {
+	headerUUID := chi.URLParam(r, "headerUUID")
+
+	fileName, zipBuf, err := h.s.DownloadPreExportByHeader(r.Context(), headerUUID)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	// Send ZIP file as response
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+fileName+`.zip"`)
+	w.WriteHeader(http.StatusOK)
+	w.Write(zipBuf.Bytes())
+}
+
+func (h *outboundExpressHandler) downloadExportDeclaration(w http.ResponseWriter, r *http.Request) {
+	uploadLoggingUUID := r.URL.Query().Get("uploadLoggingUUID")
+	if len(uploadLoggingUUID) == 0 {
+		render.Render(w, r, ErrInvalidRequest(errors.New("required uuid")))
+		return
+	}
+
+	fileName, zipBuf, err := h.s.DownloadExportDeclaration(r.Context(), uploadLoggingUUID)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	// Send ZIP file as response
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+fileName+`.zip"`)
+	w.WriteHeader(http.StatusOK)
+	w.Write(zipBuf.Bytes())
+}
+
+func (h *outboundExpressHandler) downloadExportDeclarationByHeader(w http.ResponseWriter, r *http.Request) {
+	headerUUID := chi.URLParam(r, "headerUUID")
+
+	fileName, zipBuf, err := h.s.DownloadExportDeclarationByHeader(r.Context(), headerUUID)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	// Send ZIP file as response
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+fileName+`.zip"`)
+	w.WriteHeader(http.StatusOK)
+	w.Write(zipBuf.Bytes())
+}
+
+func (h *outboundExpressHandler) getAllHeaders(w http.ResponseWriter, r *http.Request) {
+	result, err := h.s.GetAllPreExportHeaders(r.Context())
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	render.Respond(w, r, SuccessResponse(result, "success"))
+}
+
+func (h *outboundExpressHandler) getHeader(w http.ResponseWriter, r *http.Request) {
+	headerUUID := chi.URLParam(r, "headerUUID")
+
+	result, err := h.s.GetPreExportHeader(r.Context(), headerUUID)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	render.Respond(w, r, SuccessResponse(result, "success"))
+}
+
+func (h *outboundExpressHandler) createHeader(w http.ResponseWriter, r *http.Request) {
+	data := &outbound.InsertPreExportHeaderManifestModel{}
+	if err := render.Bind(r, data); err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	// Validate Data
+	validate := validator.New()
+	err := validate.Struct(data)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	uuid, err := h.s.InsertPreExportManifestHeader(r.Context(), data)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	render.Respond(w, r, SuccessResponse(uuid, "success"))
+}
+
+func (h *outboundExpressHandler) updateHeader(w http.ResponseWriter, r *http.Request) {
+	data := &outbound.UpdatePreExportHeaderManifestModel{}
+	if err := render.Bind(r, data); err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	// Validate Data
+	validate := validator.New()
+	err := validate.Struct(data)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	err = h.s.UpdatePreExportManifestHeader(r.Context(), data)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	render.Respond(w, r, SuccessResponse(nil, "success"))
+}
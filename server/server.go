@@ -135,8 +135,16 @@ func New(
 			r.Mount("/mawb", mawbSvc.router())
 
 			settingSvc := settingHandler{
-				s:         s.svcFactory.SettingSvc,
-				statusSvc: s.svcFactory.MasterStatusSvc,
+				s:           s.svcFactory.SettingSvc,
+				statusSvc:   s.svcFactory.MasterStatusSvc,
+				templateSvc: s.svcFactory.ConvertTemplateSvc,
+				feeSvc:      s.svcFactory.FeeScheduleSvc,
+				aliasSvc:    s.svcFactory.HsCodeAliasSvc,
+				exporterSvc: s.svcFactory.ExporterProfileSvc,
+				airlineSvc:  s.svcFactory.AirlineSvc,
+				awbStockSvc: s.svcFactory.AwbStockSvc,
+				rateCardSvc: s.svcFactory.RateCardSvc,
+				partySvc:    s.svcFactory.PartySvc,
 			}
 			r.Mount("/settings", settingSvc.router())
 
@@ -340,9 +348,10 @@ type ErrResponse struct {
 	Err            error `json:"-"` // low-level runtime error
 	HTTPStatusCode int   `json:"-"` // http response status code
 
-	StatusText string `json:"status,omitempty"`  // user-level status message
-	AppCode    int64  `json:"code,omitempty"`    // application-specific error code
-	Message    string `json:"message,omitempty"` // application-level error message, for debugging
+	StatusText string      `json:"status,omitempty"`  // user-level status message
+	AppCode    int64       `json:"code,omitempty"`    // application-specific error code
+	Message    string      `json:"message,omitempty"` // application-level error message, for debugging
+	Data       interface{} `json:"data,omitempty"`    // details the client needs to fix the request
 }
 
 func (e *ErrResponse) Render(w http.ResponseWriter, r *http.Request) error {
@@ -360,6 +369,17 @@ func ErrInvalidRequest(err error) render.Renderer {
 	}
 }
 
+// ErrValidationFailed is ErrInvalidRequest carrying the details of what failed validation.
+func ErrValidationFailed(err error, data interface{}) render.Renderer {
+	return &ErrResponse{
+		Err:            err,
+		HTTPStatusCode: http.StatusUnprocessableEntity,
+		AppCode:        constant.CodeError,
+		Message:        err.Error(),
+		Data:           data,
+	}
+}
+
 func ErrUnauthorized(err error) render.Renderer {
 	return &ErrResponse{
 		Err:            err,
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/go-chi/chi/v5"
@@ -14,8 +15,16 @@ import (
 )
 
 type settingHandler struct {
-	s         setting.Service
-	statusSvc setting.MasterStatusService
+	s           setting.Service
+	statusSvc   setting.MasterStatusService
+	templateSvc setting.ConvertTemplateService
+	feeSvc      setting.FeeScheduleService
+	aliasSvc    setting.HsCodeAliasService
+	exporterSvc setting.ExporterProfileService
+	airlineSvc  setting.AirlineService
+	awbStockSvc setting.AwbStockService
+	rateCardSvc setting.RateCardService
+	partySvc    setting.PartyService
 }
 
 func (h *settingHandler) router() chi.Router {
@@ -38,11 +47,623 @@ func (h *settingHandler) router() chi.Router {
 		r.Delete("/{uuid}", h.deleteMasterStatus)
 	})
 
+	r.Route("/convert-templates", func(r chi.Router) {
+		r.Post("/", h.createConvertTemplate)
+		r.Get("/", h.getAllConvertTemplates)
+		r.Get("/{code}", h.getOneConvertTemplate)
+		r.Put("/", h.updateConvertTemplate)
+		r.Delete("/{code}", h.deleteConvertTemplate)
+	})
+
+	r.Route("/fee-schedules", func(r chi.Router) {
+		r.Post("/", h.createFeeSchedule)
+		r.Get("/", h.getAllFeeSchedules)
+		r.Get("/{uuid}", h.getOneFeeSchedule)
+		r.Put("/", h.updateFeeSchedule)
+		r.Delete("/{uuid}", h.deleteFeeSchedule)
+	})
+
+	r.Route("/hs-code-aliases", func(r chi.Router) {
+		r.Post("/", h.createHsCodeAlias)
+		r.Get("/", h.getAllHsCodeAliases)
+		r.Get("/{uuid}", h.getOneHsCodeAlias)
+		r.Put("/", h.updateHsCodeAlias)
+		r.Delete("/{uuid}", h.deleteHsCodeAlias)
+	})
+
+	r.Route("/exporter-profiles", func(r chi.Router) {
+		r.Post("/", h.createExporterProfile)
+		r.Get("/", h.getAllExporterProfiles)
+		r.Get("/{uuid}", h.getOneExporterProfile)
+		r.Put("/", h.updateExporterProfile)
+		r.Delete("/{uuid}", h.deleteExporterProfile)
+	})
+
+	r.Route("/airlines", func(r chi.Router) {
+		r.Post("/", h.createAirline)
+		r.Get("/", h.getAllAirlines)
+		r.Get("/{uuid}", h.getOneAirline)
+		r.Put("/", h.updateAirline)
+		r.Delete("/{uuid}", h.deleteAirline)
+	})
+
+	r.Route("/awb-stocks", func(r chi.Router) {
+		r.Post("/", h.createAwbStock)
+		r.Get("/", h.getAllAwbStocks)
+		r.Get("/report", h.getAwbStockReport)
+		r.Post("/allocate", h.allocateAwb)
+		r.Patch("/numbers", h.setAwbNumberStatus)
+		r.Get("/{uuid}", h.getOneAwbStock)
+		r.Get("/{uuid}/numbers", h.getAwbStockNumbers)
+		r.Put("/", h.updateAwbStock)
+	})
+
+	r.Route("/airline-rate-cards", func(r chi.Router) {
+		r.Post("/", h.createRateCard)
+		r.Get("/", h.getAllRateCards)
+		r.Get("/{uuid}", h.getOneRateCard)
+		r.Put("/", h.updateRateCard)
+		r.Delete("/{uuid}", h.deleteRateCard)
+	})
+
+	r.Route("/airline-other-charges", func(r chi.Router) {
+		r.Post("/", h.createOtherCharge)
+		r.Get("/", h.getAllOtherCharges)
+		r.Get("/{uuid}", h.getOneOtherCharge)
+		r.Put("/", h.updateOtherCharge)
+		r.Delete("/{uuid}", h.deleteOtherCharge)
+	})
+
+	r.Route("/parties", func(r chi.Router) {
+		r.Post("/", h.createParty)
+		r.Get("/", h.searchParties)
+		r.Get("/autocomplete", h.autocompleteParties)
+		r.Get("/{uuid}", h.getOneParty)
+		r.Put("/", h.updateParty)
+		r.Delete("/{uuid}", h.deleteParty)
+	})
+
 	return r
 }
 
-func (h *settingHandler) createMasterStatus(w http.ResponseWriter, r *http.Request) {
-	data := &setting.MasterStatus{}
+func (h *settingHandler) createConvertTemplate(w http.ResponseWriter, r *http.Request) {
+	data := &setting.ConvertTemplate{}
+	if err := render.Bind(r, data); err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	// Validate Data
+	validate := validator.New()
+	err := validate.Struct(data)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	created, err := h.templateSvc.CreateConvertTemplate(r.Context(), data)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	render.Respond(w, r, SuccessResponse(created, "success"))
+}
+
+func (h *settingHandler) getAllConvertTemplates(w http.ResponseWriter, r *http.Request) {
+	templates, err := h.templateSvc.GetAllConvertTemplates(r.Context(), r.URL.Query().Get("type"))
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	render.Respond(w, r, SuccessResponse(templates, "success"))
+}
+
+func (h *settingHandler) getOneConvertTemplate(w http.ResponseWriter, r *http.Request) {
+	code := chi.URLParam(r, "code")
+	template, err := h.templateSvc.GetConvertTemplateByCode(r.Context(), code)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	render.Respond(w, r, SuccessResponse(template, "success"))
+}
+
+func (h *settingHandler) updateConvertTemplate(w http.ResponseWriter, r *http.Request) {
+	data := &setting.ConvertTemplate{}
+	if err := render.Bind(r, data); err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	// Validate Data
+	validate := validator.New()
+	err := validate.Struct(data)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	updated, err := h.templateSvc.UpdateConvertTemplate(r.Context(), data)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	render.Respond(w, r, SuccessResponse(updated, "success"))
+}
+
+func (h *settingHandler) deleteConvertTemplate(w http.ResponseWriter, r *http.Request) {
+	code := chi.URLParam(r, "code")
+	err := h.templateSvc.DeleteConvertTemplate(r.Context(), code)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	render.Respond(w, r, SuccessResponse(nil, "success"))
+}
+
+func (h *settingHandler) createMasterStatus(w http.ResponseWriter, r *http.Request) {
+	data := &setting.MasterStatus{}
+	if err := render.Bind(r, data); err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	// Validate Data
+	validate := validator.New()
+	err := validate.Struct(data)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	createdStatus, err := h.statusSvc.CreateMasterStatus(r.Context(), data)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	render.Respond(w, r, SuccessResponse(createdStatus, "success"))
+}
+
+func (h *settingHandler) getAllMasterStatuses(w http.ResponseWriter, r *http.Request) {
+	statuses, err := h.statusSvc.GetAllMasterStatuses(r.Context())
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	render.Respond(w, r, SuccessResponse(statuses, "success"))
+}
+
+func (h *settingHandler) getOneMasterStatus(w http.ResponseWriter, r *http.Request) {
+	uuid := chi.URLParam(r, "uuid")
+	status, err := h.statusSvc.GetMasterStatusByUUID(r.Context(), uuid)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	render.Respond(w, r, SuccessResponse(status, "success"))
+}
+
+func (h *settingHandler) updateMasterStatus(w http.ResponseWriter, r *http.Request) {
+	data := &setting.MasterStatus{}
+	if err := render.Bind(r, data); err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	// Validate Data
+	validate := validator.New()
+	err := validate.Struct(data)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	updatedStatus, err := h.statusSvc.UpdateMasterStatus(r.Context(), data)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	render.Respond(w, r, SuccessResponse(updatedStatus, "success"))
+}
+
+func (h *settingHandler) deleteMasterStatus(w http.ResponseWriter, r *http.Request) {
+	uuid := chi.URLParam(r, "uuid")
+	err := h.statusSvc.DeleteMasterStatus(r.Context(), uuid)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	render.Respond(w, r, SuccessResponse(nil, "success"))
+}
+
+func (h *settingHandler) createFeeSchedule(w http.ResponseWriter, r *http.Request) {
+	data := &setting.FeeSchedule{}
+	if err := render.Bind(r, data); err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	// Validate Data
+	validate := validator.New()
+	err := validate.Struct(data)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	created, err := h.feeSvc.CreateFeeSchedule(r.Context(), data)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	render.Respond(w, r, SuccessResponse(created, "success"))
+}
+
+func (h *settingHandler) getAllFeeSchedules(w http.ResponseWriter, r *http.Request) {
+	feeCode := r.URL.Query().Get("feeCode")
+	customerUUID := r.URL.Query().Get("customerUuid")
+
+	schedules, err := h.feeSvc.GetAllFeeSchedules(r.Context(), feeCode, customerUUID)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	render.Respond(w, r, SuccessResponse(schedules, "success"))
+}
+
+func (h *settingHandler) getOneFeeSchedule(w http.ResponseWriter, r *http.Request) {
+	uuid := chi.URLParam(r, "uuid")
+	schedule, err := h.feeSvc.GetFeeScheduleByUUID(r.Context(), uuid)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	render.Respond(w, r, SuccessResponse(schedule, "success"))
+}
+
+func (h *settingHandler) updateFeeSchedule(w http.ResponseWriter, r *http.Request) {
+	data := &setting.FeeSchedule{}
+	if err := render.Bind(r, data); err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	// Validate Data
+	validate := validator.New()
+	err := validate.Struct(data)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	updated, err := h.feeSvc.UpdateFeeSchedule(r.Context(), data)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	render.Respond(w, r, SuccessResponse(updated, "success"))
+}
+
+func (h *settingHandler) deleteFeeSchedule(w http.ResponseWriter, r *http.Request) {
+	uuid := chi.URLParam(r, "uuid")
+	err := h.feeSvc.DeleteFeeSchedule(r.Context(), uuid)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	render.Respond(w, r, SuccessResponse(nil, "success"))
+}
+
+func (h *settingHandler) createHsCodeAlias(w http.ResponseWriter, r *http.Request) {
+	data := &setting.HsCodeAlias{}
+	if err := render.Bind(r, data); err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	// Validate Data
+	validate := validator.New()
+	err := validate.Struct(data)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	data.CreatedBy = GetUserUUIDFromContext(r)
+
+	created, err := h.aliasSvc.CreateHsCodeAlias(r.Context(), data)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	render.Respond(w, r, SuccessResponse(created, "success"))
+}
+
+func (h *settingHandler) getAllHsCodeAliases(w http.ResponseWriter, r *http.Request) {
+	search := r.URL.Query().Get("search")
+
+	aliases, err := h.aliasSvc.GetAllHsCodeAliases(r.Context(), search)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	render.Respond(w, r, SuccessResponse(aliases, "success"))
+}
+
+func (h *settingHandler) getOneHsCodeAlias(w http.ResponseWriter, r *http.Request) {
+	uuid := chi.URLParam(r, "uuid")
+	alias, err := h.aliasSvc.GetHsCodeAliasByUUID(r.Context(), uuid)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	render.Respond(w, r, SuccessResponse(alias, "success"))
+}
+
+func (h *settingHandler) updateHsCodeAlias(w http.ResponseWriter, r *http.Request) {
+	data := &setting.HsCodeAlias{}
+	if err := render.Bind(r, data); err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	// Validate Data
+	validate := validator.New()
+	err := validate.Struct(data)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	updated, err := h.aliasSvc.UpdateHsCodeAlias(r.Context(), data)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	render.Respond(w, r, SuccessResponse(updated, "success"))
+}
+
+func (h *settingHandler) deleteHsCodeAlias(w http.ResponseWriter, r *http.Request) {
+	uuid := chi.URLParam(r, "uuid")
+	err := h.aliasSvc.DeleteHsCodeAlias(r.Context(), uuid)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	render.Respond(w, r, SuccessResponse(nil, "success"))
+}
+
+func (h *settingHandler) createExporterProfile(w http.ResponseWriter, r *http.Request) {
+	data := &setting.ExporterProfile{}
+	if err := render.Bind(r, data); err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	// Validate Data
+	validate := validator.New()
+	err := validate.Struct(data)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	created, err := h.exporterSvc.CreateExporterProfile(r.Context(), data)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	render.Respond(w, r, SuccessResponse(created, "success"))
+}
+
+func (h *settingHandler) getAllExporterProfiles(w http.ResponseWriter, r *http.Request) {
+	templateCode := r.URL.Query().Get("templateCode")
+	customerUUID := r.URL.Query().Get("customerUuid")
+
+	profiles, err := h.exporterSvc.GetAllExporterProfiles(r.Context(), templateCode, customerUUID)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	render.Respond(w, r, SuccessResponse(profiles, "success"))
+}
+
+func (h *settingHandler) getOneExporterProfile(w http.ResponseWriter, r *http.Request) {
+	uuid := chi.URLParam(r, "uuid")
+	profile, err := h.exporterSvc.GetExporterProfileByUUID(r.Context(), uuid)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	render.Respond(w, r, SuccessResponse(profile, "success"))
+}
+
+func (h *settingHandler) updateExporterProfile(w http.ResponseWriter, r *http.Request) {
+	data := &setting.ExporterProfile{}
+	if err := render.Bind(r, data); err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	// Validate Data
+	validate := validator.New()
+	err := validate.Struct(data)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	updated, err := h.exporterSvc.UpdateExporterProfile(r.Context(), data)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	render.Respond(w, r, SuccessResponse(updated, "success"))
+}
+
+func (h *settingHandler) deleteExporterProfile(w http.ResponseWriter, r *http.Request) {
+	uuid := chi.URLParam(r, "uuid")
+	err := h.exporterSvc.DeleteExporterProfile(r.Context(), uuid)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	render.Respond(w, r, SuccessResponse(nil, "success"))
+}
+
+func (h *settingHandler) createAirline(w http.ResponseWriter, r *http.Request) {
+	data := &setting.Airline{}
+	if err := render.Bind(r, data); err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	// Validate Data
+	validate := validator.New()
+	err := validate.Struct(data)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	created, err := h.airlineSvc.CreateAirline(r.Context(), data)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	render.Respond(w, r, SuccessResponse(created, "success"))
+}
+
+func (h *settingHandler) getAllAirlines(w http.ResponseWriter, r *http.Request) {
+	airlines, err := h.airlineSvc.GetAllAirlines(r.Context())
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	render.Respond(w, r, SuccessResponse(airlines, "success"))
+}
+
+func (h *settingHandler) getOneAirline(w http.ResponseWriter, r *http.Request) {
+	uuid := chi.URLParam(r, "uuid")
+	airline, err := h.airlineSvc.GetAirlineByUUID(r.Context(), uuid)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	render.Respond(w, r, SuccessResponse(airline, "success"))
+}
+
+func (h *settingHandler) updateAirline(w http.ResponseWriter, r *http.Request) {
+	data := &setting.Airline{}
+	if err := render.Bind(r, data); err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	// Validate Data
+	validate := validator.New()
+	err := validate.Struct(data)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	updated, err := h.airlineSvc.UpdateAirline(r.Context(), data)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	render.Respond(w, r, SuccessResponse(updated, "success"))
+}
+
+func (h *settingHandler) deleteAirline(w http.ResponseWriter, r *http.Request) {
+	uuid := chi.URLParam(r, "uuid")
+	err := h.airlineSvc.DeleteAirline(r.Context(), uuid)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	render.Respond(w, r, SuccessResponse(nil, "success"))
+}
+
+func (h *settingHandler) createAwbStock(w http.ResponseWriter, r *http.Request) {
+	data := &setting.AwbStock{}
+	if err := render.Bind(r, data); err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	// Validate Data
+	validate := validator.New()
+	err := validate.Struct(data)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	created, err := h.awbStockSvc.CreateAwbStock(r.Context(), data)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	render.Respond(w, r, SuccessResponse(created, "success"))
+}
+
+func (h *settingHandler) getAllAwbStocks(w http.ResponseWriter, r *http.Request) {
+	airlineUUID := r.URL.Query().Get("airlineUuid")
+
+	stocks, err := h.awbStockSvc.GetAllAwbStocks(r.Context(), airlineUUID)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	render.Respond(w, r, SuccessResponse(stocks, "success"))
+}
+
+func (h *settingHandler) getAwbStockReport(w http.ResponseWriter, r *http.Request) {
+	report, err := h.awbStockSvc.GetAwbStockReport(r.Context())
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	render.Respond(w, r, SuccessResponse(report, "success"))
+}
+
+func (h *settingHandler) allocateAwb(w http.ResponseWriter, r *http.Request) {
+	data := &setting.AllocateAwbRequest{}
 	if err := render.Bind(r, data); err != nil {
 		render.Render(w, r, ErrInvalidRequest(err))
 		return
@@ -56,69 +677,75 @@ func (h *settingHandler) createMasterStatus(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	createdStatus, err := h.statusSvc.CreateMasterStatus(r.Context(), data)
+	mawb, err := h.awbStockSvc.AllocateMawb(r.Context(), data.AirlineUUID)
 	if err != nil {
 		render.Render(w, r, ErrInvalidRequest(err))
 		return
 	}
 
-	render.Respond(w, r, SuccessResponse(createdStatus, "success"))
+	render.Respond(w, r, SuccessResponse(map[string]string{"mawb": mawb}, "success"))
 }
 
-func (h *settingHandler) getAllMasterStatuses(w http.ResponseWriter, r *http.Request) {
-	statuses, err := h.statusSvc.GetAllMasterStatuses(r.Context())
-	if err != nil {
+func (h *settingHandler) setAwbNumberStatus(w http.ResponseWriter, r *http.Request) {
+	data := &setting.AwbNumberStatusRequest{}
+	if err := render.Bind(r, data); err != nil {
 		render.Render(w, r, ErrInvalidRequest(err))
 		return
 	}
 
-	render.Respond(w, r, SuccessResponse(statuses, "success"))
-}
-
-func (h *settingHandler) getOneMasterStatus(w http.ResponseWriter, r *http.Request) {
-	uuid := chi.URLParam(r, "uuid")
-	status, err := h.statusSvc.GetMasterStatusByUUID(r.Context(), uuid)
+	// Validate Data
+	validate := validator.New()
+	err := validate.Struct(data)
 	if err != nil {
 		render.Render(w, r, ErrInvalidRequest(err))
 		return
 	}
 
-	render.Respond(w, r, SuccessResponse(status, "success"))
-}
-
-func (h *settingHandler) updateMasterStatus(w http.ResponseWriter, r *http.Request) {
-	data := &setting.MasterStatus{}
-	if err := render.Bind(r, data); err != nil {
+	number, err := h.awbStockSvc.SetAwbNumberStatus(r.Context(), data)
+	if err != nil {
 		render.Render(w, r, ErrInvalidRequest(err))
 		return
 	}
 
-	// Validate Data
-	validate := validator.New()
-	err := validate.Struct(data)
+	render.Respond(w, r, SuccessResponse(number, "success"))
+}
+
+func (h *settingHandler) getOneAwbStock(w http.ResponseWriter, r *http.Request) {
+	uuid := chi.URLParam(r, "uuid")
+	stock, err := h.awbStockSvc.GetAwbStockByUUID(r.Context(), uuid)
 	if err != nil {
 		render.Render(w, r, ErrInvalidRequest(err))
 		return
 	}
 
-	updatedStatus, err := h.statusSvc.UpdateMasterStatus(r.Context(), data)
+	render.Respond(w, r, SuccessResponse(stock, "success"))
+}
+
+func (h *settingHandler) getAwbStockNumbers(w http.ResponseWriter, r *http.Request) {
+	uuid := chi.URLParam(r, "uuid")
+	numbers, err := h.awbStockSvc.GetAwbStockNumbers(r.Context(), uuid)
 	if err != nil {
 		render.Render(w, r, ErrInvalidRequest(err))
 		return
 	}
 
-	render.Respond(w, r, SuccessResponse(updatedStatus, "success"))
+	render.Respond(w, r, SuccessResponse(numbers, "success"))
 }
 
-func (h *settingHandler) deleteMasterStatus(w http.ResponseWriter, r *http.Request) {
-	uuid := chi.URLParam(r, "uuid")
-	err := h.statusSvc.DeleteMasterStatus(r.Context(), uuid)
+func (h *settingHandler) updateAwbStock(w http.ResponseWriter, r *http.Request) {
+	data := &setting.AwbStock{}
+	if err := render.Bind(r, data); err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	updated, err := h.awbStockSvc.UpdateAwbStock(r.Context(), data)
 	if err != nil {
 		render.Render(w, r, ErrInvalidRequest(err))
 		return
 	}
 
-	render.Respond(w, r, SuccessResponse(nil, "success"))
+	render.Respond(w, r, SuccessResponse(updated, "success"))
 }
 
 func (h *settingHandler) createHsCode(w http.ResponseWriter, r *http.Request) {
@@ -261,3 +888,276 @@ func (h *settingHandler) exportHsCode(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	w.Write(excelBuf.Bytes())
 }
+
+func (h *settingHandler) createRateCard(w http.ResponseWriter, r *http.Request) {
+	data := &setting.AirlineRateCard{}
+	if err := render.Bind(r, data); err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	// Validate Data
+	validate := validator.New()
+	err := validate.Struct(data)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	created, err := h.rateCardSvc.CreateRateCard(r.Context(), data)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	render.Respond(w, r, SuccessResponse(created, "success"))
+}
+
+func (h *settingHandler) getAllRateCards(w http.ResponseWriter, r *http.Request) {
+	airlineUUID := r.URL.Query().Get("airlineUuid")
+
+	cards, err := h.rateCardSvc.GetAllRateCards(r.Context(), airlineUUID)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	render.Respond(w, r, SuccessResponse(cards, "success"))
+}
+
+func (h *settingHandler) getOneRateCard(w http.ResponseWriter, r *http.Request) {
+	uuid := chi.URLParam(r, "uuid")
+	card, err := h.rateCardSvc.GetRateCardByUUID(r.Context(), uuid)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	render.Respond(w, r, SuccessResponse(card, "success"))
+}
+
+func (h *settingHandler) updateRateCard(w http.ResponseWriter, r *http.Request) {
+	data := &setting.AirlineRateCard{}
+	if err := render.Bind(r, data); err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	// Validate Data
+	validate := validator.New()
+	err := validate.Struct(data)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	updated, err := h.rateCardSvc.UpdateRateCard(r.Context(), data)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	render.Respond(w, r, SuccessResponse(updated, "success"))
+}
+
+func (h *settingHandler) deleteRateCard(w http.ResponseWriter, r *http.Request) {
+	uuid := chi.URLParam(r, "uuid")
+	err := h.rateCardSvc.DeleteRateCard(r.Context(), uuid)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	render.Respond(w, r, SuccessResponse(nil, "success"))
+}
+
+func (h *settingHandler) createOtherCharge(w http.ResponseWriter, r *http.Request) {
+	data := &setting.AirlineOtherCharge{}
+	if err := render.Bind(r, data); err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	// Validate Data
+	validate := validator.New()
+	err := validate.Struct(data)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	created, err := h.rateCardSvc.CreateOtherCharge(r.Context(), data)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	render.Respond(w, r, SuccessResponse(created, "success"))
+}
+
+func (h *settingHandler) getAllOtherCharges(w http.ResponseWriter, r *http.Request) {
+	airlineUUID := r.URL.Query().Get("airlineUuid")
+
+	charges, err := h.rateCardSvc.GetAllOtherCharges(r.Context(), airlineUUID)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	render.Respond(w, r, SuccessResponse(charges, "success"))
+}
+
+func (h *settingHandler) getOneOtherCharge(w http.ResponseWriter, r *http.Request) {
+	uuid := chi.URLParam(r, "uuid")
+	charge, err := h.rateCardSvc.GetOtherChargeByUUID(r.Context(), uuid)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	render.Respond(w, r, SuccessResponse(charge, "success"))
+}
+
+func (h *settingHandler) updateOtherCharge(w http.ResponseWriter, r *http.Request) {
+	data := &setting.AirlineOtherCharge{}
+	if err := render.Bind(r, data); err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	// Validate Data
+	validate := validator.New()
+	err := validate.Struct(data)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	updated, err := h.rateCardSvc.UpdateOtherCharge(r.Context(), data)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	render.Respond(w, r, SuccessResponse(updated, "success"))
+}
+
+func (h *settingHandler) deleteOtherCharge(w http.ResponseWriter, r *http.Request) {
+	uuid := chi.URLParam(r, "uuid")
+	err := h.rateCardSvc.DeleteOtherCharge(r.Context(), uuid)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	render.Respond(w, r, SuccessResponse(nil, "success"))
+}
+
+func (h *settingHandler) createParty(w http.ResponseWriter, r *http.Request) {
+	data := &setting.Party{}
+	if err := render.Bind(r, data); err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	// Validate Data
+	validate := validator.New()
+	err := validate.Struct(data)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	created, err := h.partySvc.CreateParty(r.Context(), data)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	render.Respond(w, r, SuccessResponse(created, "success"))
+}
+
+func (h *settingHandler) searchParties(w http.ResponseWriter, r *http.Request) {
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	search := setting.PartySearch{
+		Query:       r.URL.Query().Get("search"),
+		Role:        r.URL.Query().Get("role"),
+		CountryCode: r.URL.Query().Get("countryCode"),
+		Limit:       limit,
+	}
+
+	parties, err := h.partySvc.SearchParties(r.Context(), search)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	render.Respond(w, r, SuccessResponse(parties, "success"))
+}
+
+func (h *settingHandler) autocompleteParties(w http.ResponseWriter, r *http.Request) {
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	search := setting.PartySearch{
+		Query: r.URL.Query().Get("q"),
+		Role:  r.URL.Query().Get("role"),
+		Limit: limit,
+	}
+
+	suggestions, err := h.partySvc.AutocompleteParties(r.Context(), search)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	render.Respond(w, r, SuccessResponse(suggestions, "success"))
+}
+
+func (h *settingHandler) getOneParty(w http.ResponseWriter, r *http.Request) {
+	uuid := chi.URLParam(r, "uuid")
+	party, err := h.partySvc.GetPartyByUUID(r.Context(), uuid)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+	if party == nil {
+		render.Render(w, r, &ErrResponse{HTTPStatusCode: http.StatusNotFound, Message: "Party not found"})
+		return
+	}
+
+	render.Respond(w, r, SuccessResponse(party, "success"))
+}
+
+func (h *settingHandler) updateParty(w http.ResponseWriter, r *http.Request) {
+	data := &setting.Party{}
+	if err := render.Bind(r, data); err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	// Validate Data
+	validate := validator.New()
+	err := validate.Struct(data)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	updated, err := h.partySvc.UpdateParty(r.Context(), data)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	render.Respond(w, r, SuccessResponse(updated, "success"))
+}
+
+func (h *settingHandler) deleteParty(w http.ResponseWriter, r *http.Request) {
+	uuid := chi.URLParam(r, "uuid")
+	err := h.partySvc.DeleteParty(r.Context(), uuid)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	render.Respond(w, r, SuccessResponse(nil, "success"))
+}
@@ -20,6 +20,8 @@ func (h *uploadLoggingHandler) router() chi.Router {
 	r := chi.NewRouter()
 
 	r.Get("/", h.getAllUploadLoggings)
+	r.Get("/{uuid}/validation-report", h.getValidationReport)
+	r.Get("/{uuid}/validation-report/download", h.downloadValidationReport)
 
 	return r
 }
@@ -51,3 +53,43 @@ func (h *uploadLoggingHandler) getAllUploadLoggings(w http.ResponseWriter, r *ht
 
 	render.Respond(w, r, SuccessResponse(result, "success"))
 }
+
+func (h *uploadLoggingHandler) getValidationReport(w http.ResponseWriter, r *http.Request) {
+	uuid := chi.URLParam(r, "uuid")
+	if len(uuid) == 0 {
+		render.Render(w, r, ErrInvalidRequest(errors.New("required uuid")))
+		return
+	}
+
+	result, err := h.s.GetValidationReport(r.Context(), uuid)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	render.Respond(w, r, SuccessResponse(result, "success"))
+}
+
+func (h *uploadLoggingHandler) downloadValidationReport(w http.ResponseWriter, r *http.Request) {
+	uuid := chi.URLParam(r, "uuid")
+	if len(uuid) == 0 {
+		render.Render(w, r, ErrInvalidRequest(errors.New("required uuid")))
+		return
+	}
+
+	fileName, buf, fileURL, err := h.s.DownloadValidationReport(r.Context(), uuid)
+	if err != nil {
+		render.Render(w, r, ErrInvalidRequest(err))
+		return
+	}
+
+	if fileURL != "" {
+		http.Redirect(w, r, fileURL, http.StatusFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+fileName+`"`)
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.Bytes())
+}
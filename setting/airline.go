@@ -0,0 +1,19 @@
+package setting
+
+import "net/http"
+
+// Airline is an entry of the airline master, the same rows the airline logo dropdown lists.
+// AwbPrefix is the three digit IATA prefix the airline's AWB numbers start with.
+type Airline struct {
+	tableName struct{} `pg:"airline_logos,alias:al"`
+	UUID      string   `json:"uuid" pg:"uuid,pk"`
+	Code      string   `json:"code" pg:"code" validate:"required"`
+	Name      string   `json:"name" pg:"name" validate:"required"`
+	LogoURL   string   `json:"logoUrl" pg:"logo_url"`
+	AwbPrefix string   `json:"awbPrefix" pg:"awb_prefix" validate:"required,len=3,numeric"`
+	IsActive  bool     `json:"isActive" pg:"is_active,use_zero"`
+}
+
+func (a *Airline) Bind(r *http.Request) error {
+	return nil
+}
@@ -0,0 +1,90 @@
+package setting
+
+import (
+	"context"
+	"hpc-express-service/common"
+
+	"github.com/go-pg/pg/v9"
+	"github.com/google/uuid"
+)
+
+type AirlineRepository interface {
+	CreateAirline(ctx context.Context, airline *Airline) (*Airline, error)
+	GetAllAirlines(ctx context.Context) ([]Airline, error)
+	GetAirlineByUUID(ctx context.Context, uuid string) (*Airline, error)
+	GetAirlineByPrefix(ctx context.Context, prefix string) (*Airline, error)
+	UpdateAirline(ctx context.Context, airline *Airline) (*Airline, error)
+	DeleteAirline(ctx context.Context, uuid string) error
+}
+
+type airlineRepository struct{}
+
+func NewAirlineRepository() AirlineRepository {
+	return &airlineRepository{}
+}
+
+func (r *airlineRepository) CreateAirline(ctx context.Context, airline *Airline) (*Airline, error) {
+	db, err := common.GetQer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	airline.UUID = uuid.New().String()
+	_, err = db.Model(airline).Insert()
+	return airline, err
+}
+
+func (r *airlineRepository) GetAllAirlines(ctx context.Context) ([]Airline, error) {
+	db, err := common.GetQer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var airlines []Airline
+	err = db.Model(&airlines).Order("name").Select()
+	return airlines, err
+}
+
+func (r *airlineRepository) GetAirlineByUUID(ctx context.Context, uuid string) (*Airline, error) {
+	db, err := common.GetQer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	airline := new(Airline)
+	err = db.Model(airline).Where("uuid = ?", uuid).Select()
+	return airline, err
+}
+
+// GetAirlineByPrefix returns the active airline with the AWB prefix, or nil when there is none.
+func (r *airlineRepository) GetAirlineByPrefix(ctx context.Context, prefix string) (*Airline, error) {
+	db, err := common.GetQer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	airline := new(Airline)
+	err = db.Model(airline).
+		Where("awb_prefix = ?", prefix).
+		Where("is_active = ?", true).
+		Limit(1).
+		Select()
+	if err == pg.ErrNoRows {
+		return nil, nil
+	}
+	return airline, err
+}
+
+func (r *airlineRepository) UpdateAirline(ctx context.Context, airline *Airline) (*Airline, error) {
+	db, err := common.GetQer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	_, err = db.Model(airline).WherePK().Update()
+	return airline, err
+}
+
+func (r *airlineRepository) DeleteAirline(ctx context.Context, uuid string) error {
+	db, err := common.GetQer(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = db.Model(&Airline{}).Where("uuid = ?", uuid).Delete()
+	return err
+}
@@ -0,0 +1,131 @@
+package setting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"hpc-express-service/cargoimp"
+)
+
+type AirlineService interface {
+	CreateAirline(ctx context.Context, airline *Airline) (*Airline, error)
+	GetAllAirlines(ctx context.Context) ([]Airline, error)
+	GetAirlineByUUID(ctx context.Context, uuid string) (*Airline, error)
+	UpdateAirline(ctx context.Context, airline *Airline) (*Airline, error)
+	DeleteAirline(ctx context.Context, uuid string) error
+	ResolveMawb(ctx context.Context, mawb string) (string, *Airline, error)
+	GetAirlineByMawb(ctx context.Context, mawb string) (*Airline, error)
+}
+
+type airlineService struct {
+	repo           AirlineRepository
+	contextTimeout time.Duration
+}
+
+func NewAirlineService(repo AirlineRepository, timeout time.Duration) AirlineService {
+	return &airlineService{
+		repo:           repo,
+		contextTimeout: timeout,
+	}
+}
+
+func (s *airlineService) CreateAirline(ctx context.Context, airline *Airline) (*Airline, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	if err := s.validateAirline(ctx, airline); err != nil {
+		return nil, err
+	}
+
+	return s.repo.CreateAirline(ctx, airline)
+}
+
+func (s *airlineService) GetAllAirlines(ctx context.Context) ([]Airline, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+	return s.repo.GetAllAirlines(ctx)
+}
+
+func (s *airlineService) GetAirlineByUUID(ctx context.Context, uuid string) (*Airline, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+	return s.repo.GetAirlineByUUID(ctx, uuid)
+}
+
+func (s *airlineService) UpdateAirline(ctx context.Context, airline *Airline) (*Airline, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	if airline.UUID == "" {
+		return nil, errors.New("uuid is required")
+	}
+	if err := s.validateAirline(ctx, airline); err != nil {
+		return nil, err
+	}
+
+	return s.repo.UpdateAirline(ctx, airline)
+}
+
+func (s *airlineService) DeleteAirline(ctx context.Context, uuid string) error {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+	return s.repo.DeleteAirline(ctx, uuid)
+}
+
+// ResolveMawb checks a MAWB number and returns it as 618-12345675 together with the airline
+// its prefix belongs to. Numbers failing the check digit or with a prefix not in the master
+// are rejected.
+func (s *airlineService) ResolveMawb(ctx context.Context, mawb string) (string, *Airline, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	number, err := cargoimp.NormalizeAWBNumber(mawb)
+	if err != nil {
+		return "", nil, err
+	}
+
+	prefix := number[:3]
+	airline, err := s.repo.GetAirlineByPrefix(ctx, prefix)
+	if err != nil {
+		return "", nil, err
+	}
+	if airline == nil {
+		return "", nil, fmt.Errorf("airline prefix '%s' of MAWB '%s' is not in the airline master", prefix, mawb)
+	}
+
+	return number, airline, nil
+}
+
+// GetAirlineByMawb looks up the airline of a stored MAWB number for display. It returns nil
+// when the number cannot be read or its prefix is unknown.
+func (s *airlineService) GetAirlineByMawb(ctx context.Context, mawb string) (*Airline, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	prefix, _, err := cargoimp.ParseAWBNumber(mawb)
+	if err != nil {
+		return nil, nil
+	}
+	return s.repo.GetAirlineByPrefix(ctx, prefix)
+}
+
+// validateAirline keeps one active airline per AWB prefix, so a MAWB resolves to one airline.
+func (s *airlineService) validateAirline(ctx context.Context, airline *Airline) error {
+	airline.Code = strings.ToUpper(strings.TrimSpace(airline.Code))
+	airline.AwbPrefix = strings.TrimSpace(airline.AwbPrefix)
+	if !airline.IsActive {
+		return nil
+	}
+
+	existing, err := s.repo.GetAirlineByPrefix(ctx, airline.AwbPrefix)
+	if err != nil {
+		return err
+	}
+	if existing != nil && existing.UUID != airline.UUID {
+		return fmt.Errorf("AWB prefix '%s' is already used by %s", airline.AwbPrefix, existing.Name)
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package setting
+
+import (
+	"net/http"
+	"time"
+
+	"hpc-express-service/cargoimp"
+)
+
+const (
+	AwbStatusAllocated = "allocated"
+	AwbStatusUsed      = "used"
+	AwbStatusVoided    = "voided"
+	AwbStatusReturned  = "returned"
+	AwbStatusAvailable = "available"
+)
+
+// AwbStock is a block of AWB numbers an airline handed to us, used in order. Serials are the
+// first seven digits of an AWB serial; the check digit is added when a number is allocated.
+// FirstMawb and LastMawb are how the block is typed in and shown.
+type AwbStock struct {
+	tableName    struct{}  `pg:"awb_stocks,alias:ast"`
+	UUID         string    `json:"uuid" pg:"uuid,pk"`
+	AirlineUUID  string    `json:"airlineUuid" pg:"airline_uuid"`
+	AwbPrefix    string    `json:"awbPrefix" pg:"awb_prefix"`
+	FirstMawb    string    `json:"firstMawb" pg:"-" validate:"required"`
+	LastMawb     string    `json:"lastMawb" pg:"-" validate:"required"`
+	FirstSerial  int64     `json:"firstSerial" pg:"first_serial"`
+	LastSerial   int64     `json:"lastSerial" pg:"last_serial"`
+	NextSerial   int64     `json:"nextSerial" pg:"next_serial,use_zero"`
+	ReceivedDate string    `json:"receivedDate" pg:"received_date"`
+	Remark       string    `json:"remark" pg:"remark"`
+	IsActive     bool      `json:"isActive" pg:"is_active,use_zero"`
+	CreatedAt    time.Time `json:"createdAt" pg:"created_at"`
+	UpdatedAt    time.Time `json:"updatedAt" pg:"updated_at"`
+}
+
+func (a *AwbStock) Bind(r *http.Request) error {
+	return nil
+}
+
+// setNumbers fills FirstMawb and LastMawb from the stored serials.
+func (a *AwbStock) setNumbers() {
+	a.FirstMawb = cargoimp.AWBNumber(a.AwbPrefix, a.FirstSerial)
+	a.LastMawb = cargoimp.AWBNumber(a.AwbPrefix, a.LastSerial)
+}
+
+// AwbStockNumber records what became of one number of a stock. Numbers without a record
+// below NextSerial do not exist; a record above it is a number typed in by hand, which
+// allocation skips. A number released from its MAWB info is available and handed out again
+// before the next serial.
+type AwbStockNumber struct {
+	tableName    struct{}  `pg:"awb_stock_numbers,alias:asn"`
+	UUID         string    `json:"uuid" pg:"uuid,pk"`
+	StockUUID    string    `json:"stockUuid" pg:"stock_uuid"`
+	Mawb         string    `json:"mawb" pg:"mawb"`
+	Serial       int64     `json:"serial" pg:"serial"`
+	Status       string    `json:"status" pg:"status"`
+	MawbInfoUUID string    `json:"mawbInfoUuid" pg:"mawb_info_uuid"`
+	Remark       string    `json:"remark" pg:"remark"`
+	CreatedAt    time.Time `json:"createdAt" pg:"created_at"`
+	UpdatedAt    time.Time `json:"updatedAt" pg:"updated_at"`
+}
+
+// AwbNumberStatusRequest marks a number as used, voided or returned to the airline.
+type AwbNumberStatusRequest struct {
+	Mawb         string `json:"mawb" validate:"required"`
+	Status       string `json:"status" validate:"required,oneof=used voided returned"`
+	MawbInfoUUID string `json:"mawbInfoUuid"`
+	Remark       string `json:"remark"`
+}
+
+func (a *AwbNumberStatusRequest) Bind(r *http.Request) error {
+	return nil
+}
+
+// AllocateAwbRequest asks for the next number of an airline's stock.
+type AllocateAwbRequest struct {
+	AirlineUUID string `json:"airlineUuid" validate:"required"`
+}
+
+func (a *AllocateAwbRequest) Bind(r *http.Request) error {
+	return nil
+}
+
+// AwbStockStatusCount is the number of recorded numbers of a stock in one status. Ahead counts
+// those at or above the stock's next serial.
+type AwbStockStatusCount struct {
+	StockUUID string `pg:"stock_uuid"`
+	Status    string `pg:"status"`
+	Count     int64  `pg:"count"`
+	Ahead     int64  `pg:"ahead"`
+}
+
+// AwbStockSummary is the stock left for one airline.
+type AwbStockSummary struct {
+	AirlineUUID string `json:"airlineUuid"`
+	AirlineName string `json:"airlineName"`
+	AwbPrefix   string `json:"awbPrefix"`
+	Ranges      int    `json:"ranges"`
+	Total       int64  `json:"total"`
+	Remaining   int64  `json:"remaining"`
+	Allocated   int64  `json:"allocated"`
+	Used        int64  `json:"used"`
+	Voided      int64  `json:"voided"`
+	Returned    int64  `json:"returned"`
+	Available   int64  `json:"available"`
+	NextMawb    string `json:"nextMawb"`
+}
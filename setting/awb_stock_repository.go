@@ -0,0 +1,249 @@
+package setting
+
+import (
+	"context"
+	"hpc-express-service/common"
+	"time"
+
+	"github.com/go-pg/pg/v9"
+	"github.com/google/uuid"
+)
+
+type AwbStockRepository interface {
+	CreateAwbStock(ctx context.Context, stock *AwbStock) (*AwbStock, error)
+	GetAllAwbStocks(ctx context.Context, airlineUUID string) ([]AwbStock, error)
+	GetAwbStockByUUID(ctx context.Context, uuid string) (*AwbStock, error)
+	UpdateAwbStock(ctx context.Context, stock *AwbStock) (*AwbStock, error)
+	GetOverlappingAwbStock(ctx context.Context, prefix string, firstSerial, lastSerial int64) (*AwbStock, error)
+	GetAwbStockForSerial(ctx context.Context, prefix string, serial int64) (*AwbStock, error)
+	GetNextAwbStockForUpdate(ctx context.Context, airlineUUID string) (*AwbStock, error)
+	GetAvailableAwbStockNumberForUpdate(ctx context.Context, airlineUUID string) (*AwbStockNumber, error)
+	UpdateAwbStockNextSerial(ctx context.Context, stock *AwbStock) error
+	IsAwbNumberTaken(ctx context.Context, mawb string) (bool, error)
+	GetAwbStockNumber(ctx context.Context, mawb string) (*AwbStockNumber, error)
+	GetAwbStockNumbers(ctx context.Context, stockUUID string) ([]AwbStockNumber, error)
+	SaveAwbStockNumber(ctx context.Context, number *AwbStockNumber) error
+	DeleteAwbStockNumber(ctx context.Context, number *AwbStockNumber) error
+	CountAwbStockNumbers(ctx context.Context) ([]AwbStockStatusCount, error)
+}
+
+type awbStockRepository struct{}
+
+func NewAwbStockRepository() AwbStockRepository {
+	return &awbStockRepository{}
+}
+
+func (r *awbStockRepository) CreateAwbStock(ctx context.Context, stock *AwbStock) (*AwbStock, error) {
+	db, err := common.GetQer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	stock.UUID = uuid.New().String()
+	stock.CreatedAt = time.Now()
+	stock.UpdatedAt = time.Now()
+	_, err = db.Model(stock).Insert()
+	return stock, err
+}
+
+func (r *awbStockRepository) GetAllAwbStocks(ctx context.Context, airlineUUID string) ([]AwbStock, error) {
+	db, err := common.GetQer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var stocks []AwbStock
+	q := db.Model(&stocks).Order("awb_prefix", "received_date", "first_serial")
+	if airlineUUID != "" {
+		q = q.Where("airline_uuid = ?", airlineUUID)
+	}
+	err = q.Select()
+	for i := range stocks {
+		stocks[i].setNumbers()
+	}
+	return stocks, err
+}
+
+func (r *awbStockRepository) GetAwbStockByUUID(ctx context.Context, uuid string) (*AwbStock, error) {
+	db, err := common.GetQer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	stock := new(AwbStock)
+	err = db.Model(stock).Where("uuid = ?", uuid).Select()
+	stock.setNumbers()
+	return stock, err
+}
+
+// UpdateAwbStock changes the remark, received date and whether the stock is used. The range
+// itself cannot change once numbers may have been allocated from it.
+func (r *awbStockRepository) UpdateAwbStock(ctx context.Context, stock *AwbStock) (*AwbStock, error) {
+	db, err := common.GetQer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	stock.UpdatedAt = time.Now()
+	_, err = db.Model(stock).Column("remark", "received_date", "is_active", "updated_at").WherePK().Update()
+	return stock, err
+}
+
+// GetOverlappingAwbStock returns a stock of the prefix sharing a number with the range, or nil.
+func (r *awbStockRepository) GetOverlappingAwbStock(ctx context.Context, prefix string, firstSerial, lastSerial int64) (*AwbStock, error) {
+	db, err := common.GetQer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	stock := new(AwbStock)
+	err = db.Model(stock).
+		Where("awb_prefix = ?", prefix).
+		Where("first_serial <= ?", lastSerial).
+		Where("last_serial >= ?", firstSerial).
+		Limit(1).
+		Select()
+	if err == pg.ErrNoRows {
+		return nil, nil
+	}
+	stock.setNumbers()
+	return stock, err
+}
+
+// GetAwbStockForSerial returns the stock the number belongs to, or nil.
+func (r *awbStockRepository) GetAwbStockForSerial(ctx context.Context, prefix string, serial int64) (*AwbStock, error) {
+	return r.GetOverlappingAwbStock(ctx, prefix, serial, serial)
+}
+
+// GetNextAwbStockForUpdate locks the oldest active stock of the airline with numbers left, or
+// returns nil. It must run in a transaction so concurrent allocations wait for each other.
+func (r *awbStockRepository) GetNextAwbStockForUpdate(ctx context.Context, airlineUUID string) (*AwbStock, error) {
+	db, err := common.GetQer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	stock := new(AwbStock)
+	err = db.Model(stock).
+		Where("airline_uuid = ?", airlineUUID).
+		Where("is_active = ?", true).
+		Where("next_serial <= last_serial").
+		Order("received_date", "first_serial").
+		Limit(1).
+		For("UPDATE").
+		Select()
+	if err == pg.ErrNoRows {
+		return nil, nil
+	}
+	stock.setNumbers()
+	return stock, err
+}
+
+// GetAvailableAwbStockNumberForUpdate locks the lowest released number of the airline's
+// active stocks, or returns nil. Numbers locked by another allocation are skipped.
+func (r *awbStockRepository) GetAvailableAwbStockNumberForUpdate(ctx context.Context, airlineUUID string) (*AwbStockNumber, error) {
+	db, err := common.GetQer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	number := new(AwbStockNumber)
+	_, err = db.QueryOne(number, `
+		SELECT n.*
+		FROM awb_stock_numbers n
+		JOIN awb_stocks s ON s.uuid = n.stock_uuid
+		WHERE s.airline_uuid = ?0
+			AND s.is_active
+			AND n.status = ?1
+		ORDER BY s.received_date, n.serial
+		LIMIT 1
+		FOR UPDATE OF n SKIP LOCKED`,
+		airlineUUID, AwbStatusAvailable)
+	if err == pg.ErrNoRows {
+		return nil, nil
+	}
+	return number, err
+}
+
+func (r *awbStockRepository) UpdateAwbStockNextSerial(ctx context.Context, stock *AwbStock) error {
+	db, err := common.GetQer(ctx)
+	if err != nil {
+		return err
+	}
+	stock.UpdatedAt = time.Now()
+	_, err = db.Model(stock).Column("next_serial", "updated_at").WherePK().Update()
+	return err
+}
+
+// IsAwbNumberTaken reports whether the number was already recorded against a stock or typed
+// on a MAWB info.
+func (r *awbStockRepository) IsAwbNumberTaken(ctx context.Context, mawb string) (bool, error) {
+	db, err := common.GetQer(ctx)
+	if err != nil {
+		return false, err
+	}
+	var taken bool
+	_, err = db.QueryOne(pg.Scan(&taken), `
+		SELECT EXISTS(SELECT 1 FROM awb_stock_numbers WHERE mawb = ?0)
+			OR EXISTS(SELECT 1 FROM public.tbl_mawb_info WHERE regexp_replace(mawb, '[^0-9]', '', 'g') = regexp_replace(?0, '[^0-9]', '', 'g'))`,
+		mawb)
+	return taken, err
+}
+
+// GetAwbStockNumber returns the record of a number, or nil when it has none.
+func (r *awbStockRepository) GetAwbStockNumber(ctx context.Context, mawb string) (*AwbStockNumber, error) {
+	db, err := common.GetQer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	number := new(AwbStockNumber)
+	err = db.Model(number).Where("mawb = ?", mawb).Select()
+	if err == pg.ErrNoRows {
+		return nil, nil
+	}
+	return number, err
+}
+
+func (r *awbStockRepository) GetAwbStockNumbers(ctx context.Context, stockUUID string) ([]AwbStockNumber, error) {
+	db, err := common.GetQer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var numbers []AwbStockNumber
+	err = db.Model(&numbers).Where("stock_uuid = ?", stockUUID).Order("serial").Select()
+	return numbers, err
+}
+
+// SaveAwbStockNumber inserts a number record without a UUID and updates one with it.
+func (r *awbStockRepository) SaveAwbStockNumber(ctx context.Context, number *AwbStockNumber) error {
+	db, err := common.GetQer(ctx)
+	if err != nil {
+		return err
+	}
+	number.UpdatedAt = time.Now()
+	if number.UUID == "" {
+		number.UUID = uuid.New().String()
+		number.CreatedAt = time.Now()
+		_, err = db.Model(number).Insert()
+		return err
+	}
+	_, err = db.Model(number).ExcludeColumn("created_at").WherePK().Update()
+	return err
+}
+
+func (r *awbStockRepository) DeleteAwbStockNumber(ctx context.Context, number *AwbStockNumber) error {
+	db, err := common.GetQer(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = db.Model(number).WherePK().Delete()
+	return err
+}
+
+func (r *awbStockRepository) CountAwbStockNumbers(ctx context.Context) ([]AwbStockStatusCount, error) {
+	db, err := common.GetQer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var counts []AwbStockStatusCount
+	_, err = db.Query(&counts, `
+		SELECT n.stock_uuid, n.status, count(*) AS count,
+			count(*) FILTER (WHERE n.serial >= s.next_serial) AS ahead
+		FROM awb_stock_numbers n
+		JOIN awb_stocks s ON s.uuid = n.stock_uuid
+		GROUP BY n.stock_uuid, n.status`)
+	return counts, err
+}
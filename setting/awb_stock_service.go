@@ -0,0 +1,390 @@
+package setting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"hpc-express-service/cargoimp"
+	"hpc-express-service/common"
+
+	"github.com/go-pg/pg/v9"
+)
+
+type AwbStockService interface {
+	CreateAwbStock(ctx context.Context, stock *AwbStock) (*AwbStock, error)
+	GetAllAwbStocks(ctx context.Context, airlineUUID string) ([]AwbStock, error)
+	GetAwbStockByUUID(ctx context.Context, uuid string) (*AwbStock, error)
+	UpdateAwbStock(ctx context.Context, stock *AwbStock) (*AwbStock, error)
+	GetAwbStockNumbers(ctx context.Context, stockUUID string) ([]AwbStockNumber, error)
+	AllocateMawb(ctx context.Context, airlineUUID string) (string, error)
+	SetAwbNumberStatus(ctx context.Context, req *AwbNumberStatusRequest) (*AwbStockNumber, error)
+	MarkAwbUsed(ctx context.Context, mawb, mawbInfoUUID string) error
+	ReleaseAwb(ctx context.Context, mawb, mawbInfoUUID string) error
+	GetAwbStockReport(ctx context.Context) ([]AwbStockSummary, error)
+}
+
+// errAwbNotInStock is returned for numbers outside every registered stock.
+var errAwbNotInStock = errors.New("AWB number is not in any registered stock")
+
+type awbStockService struct {
+	repo           AwbStockRepository
+	airlineRepo    AirlineRepository
+	contextTimeout time.Duration
+}
+
+func NewAwbStockService(repo AwbStockRepository, airlineRepo AirlineRepository, timeout time.Duration) AwbStockService {
+	return &awbStockService{
+		repo:           repo,
+		airlineRepo:    airlineRepo,
+		contextTimeout: timeout,
+	}
+}
+
+// CreateAwbStock registers the block FirstMawb to LastMawb for the airline owning its prefix.
+func (s *awbStockService) CreateAwbStock(ctx context.Context, stock *AwbStock) (*AwbStock, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	first, err := cargoimp.NormalizeAWBNumber(stock.FirstMawb)
+	if err != nil {
+		return nil, err
+	}
+	last, err := cargoimp.NormalizeAWBNumber(stock.LastMawb)
+	if err != nil {
+		return nil, err
+	}
+	if first[:3] != last[:3] {
+		return nil, errors.New("first and last AWB numbers must have the same airline prefix")
+	}
+	stock.AwbPrefix = first[:3]
+	stock.FirstSerial = serialOf(first)
+	stock.LastSerial = serialOf(last)
+	if stock.FirstSerial > stock.LastSerial {
+		return nil, errors.New("first AWB number must not be after the last")
+	}
+
+	airline, err := s.airlineRepo.GetAirlineByPrefix(ctx, stock.AwbPrefix)
+	if err != nil {
+		return nil, err
+	}
+	if airline == nil {
+		return nil, fmt.Errorf("airline prefix '%s' is not in the airline master", stock.AwbPrefix)
+	}
+	stock.AirlineUUID = airline.UUID
+
+	overlap, err := s.repo.GetOverlappingAwbStock(ctx, stock.AwbPrefix, stock.FirstSerial, stock.LastSerial)
+	if err != nil {
+		return nil, err
+	}
+	if overlap != nil {
+		return nil, fmt.Errorf("AWB numbers overlap the stock %s to %s", overlap.FirstMawb, overlap.LastMawb)
+	}
+
+	stock.NextSerial = stock.FirstSerial
+	stock.IsActive = true
+	stock.Remark = strings.TrimSpace(stock.Remark)
+	created, err := s.repo.CreateAwbStock(ctx, stock)
+	if err != nil {
+		return nil, err
+	}
+	created.setNumbers()
+	return created, nil
+}
+
+func (s *awbStockService) GetAllAwbStocks(ctx context.Context, airlineUUID string) ([]AwbStock, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+	return s.repo.GetAllAwbStocks(ctx, airlineUUID)
+}
+
+func (s *awbStockService) GetAwbStockByUUID(ctx context.Context, uuid string) (*AwbStock, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+	return s.repo.GetAwbStockByUUID(ctx, uuid)
+}
+
+func (s *awbStockService) UpdateAwbStock(ctx context.Context, stock *AwbStock) (*AwbStock, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	if stock.UUID == "" {
+		return nil, errors.New("uuid is required")
+	}
+	stock.Remark = strings.TrimSpace(stock.Remark)
+	if _, err := s.repo.UpdateAwbStock(ctx, stock); err != nil {
+		return nil, err
+	}
+	return s.repo.GetAwbStockByUUID(ctx, stock.UUID)
+}
+
+func (s *awbStockService) GetAwbStockNumbers(ctx context.Context, stockUUID string) ([]AwbStockNumber, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+	return s.repo.GetAwbStockNumbers(ctx, stockUUID)
+}
+
+// AllocateMawb takes a released number of the airline's stocks, or else the next number of
+// its oldest stock, and records it as allocated. The stock row stays locked until the
+// transaction commits, so two operators never get the same number. Numbers already typed in
+// by hand are skipped. When ctx carries a transaction the number is allocated in it, so it
+// goes back to the stock if the caller rolls back.
+func (s *awbStockService) AllocateMawb(ctx context.Context, airlineUUID string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	if _, ok := ctx.Value("postgreSQLConn").(*pg.Tx); ok {
+		return s.allocateMawb(ctx, airlineUUID)
+	}
+
+	tx, txCtx, err := common.BeginTx(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	mawb, err := s.allocateMawb(txCtx, airlineUUID)
+	if err != nil {
+		return "", err
+	}
+	if err := tx.Commit(); err != nil {
+		return "", err
+	}
+	return mawb, nil
+}
+
+func (s *awbStockService) allocateMawb(txCtx context.Context, airlineUUID string) (string, error) {
+	released, err := s.repo.GetAvailableAwbStockNumberForUpdate(txCtx, airlineUUID)
+	if err != nil {
+		return "", err
+	}
+	if released != nil {
+		released.Status = AwbStatusAllocated
+		released.MawbInfoUUID = ""
+		if err := s.repo.SaveAwbStockNumber(txCtx, released); err != nil {
+			return "", err
+		}
+		return released.Mawb, nil
+	}
+
+	for {
+		stock, err := s.repo.GetNextAwbStockForUpdate(txCtx, airlineUUID)
+		if err != nil {
+			return "", err
+		}
+		if stock == nil {
+			return "", errors.New("no AWB stock left for this airline")
+		}
+
+		serial := stock.NextSerial
+		stock.NextSerial++
+		if err := s.repo.UpdateAwbStockNextSerial(txCtx, stock); err != nil {
+			return "", err
+		}
+
+		mawb := cargoimp.AWBNumber(stock.AwbPrefix, serial)
+		taken, err := s.repo.IsAwbNumberTaken(txCtx, mawb)
+		if err != nil {
+			return "", err
+		}
+		if taken {
+			continue
+		}
+
+		if err := s.repo.SaveAwbStockNumber(txCtx, &AwbStockNumber{
+			StockUUID: stock.UUID,
+			Mawb:      mawb,
+			Serial:    serial,
+			Status:    AwbStatusAllocated,
+		}); err != nil {
+			return "", err
+		}
+		return mawb, nil
+	}
+}
+
+func (s *awbStockService) SetAwbNumberStatus(ctx context.Context, req *AwbNumberStatusRequest) (*AwbStockNumber, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+	return s.setStatus(ctx, req.Mawb, req.Status, req.MawbInfoUUID, req.Remark)
+}
+
+// MarkAwbUsed records a MAWB info's number as used. Numbers outside our stock are ignored,
+// as not every MAWB comes from a block we hold.
+func (s *awbStockService) MarkAwbUsed(ctx context.Context, mawb, mawbInfoUUID string) error {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	_, err := s.setStatus(ctx, mawb, AwbStatusUsed, mawbInfoUUID, "")
+	if errors.Is(err, errAwbNotInStock) {
+		return nil
+	}
+	return err
+}
+
+// ReleaseAwb gives back the number a MAWB info no longer uses. A number of the stock that was
+// handed out becomes available for the next allocation; one typed in by hand ahead of the
+// stock's next serial loses its record, as allocation will reach it in turn. Numbers outside
+// our stock, or no longer held by the MAWB info, are left alone.
+func (s *awbStockService) ReleaseAwb(ctx context.Context, mawb, mawbInfoUUID string) error {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	mawb, err := cargoimp.NormalizeAWBNumber(mawb)
+	if err != nil {
+		// typed before numbers were checked, so it cannot be one of our stock
+		return nil
+	}
+	number, err := s.repo.GetAwbStockNumber(ctx, mawb)
+	if err != nil {
+		return err
+	}
+	if number == nil || number.MawbInfoUUID != mawbInfoUUID ||
+		(number.Status != AwbStatusUsed && number.Status != AwbStatusAllocated) {
+		return nil
+	}
+
+	stock, err := s.repo.GetAwbStockByUUID(ctx, number.StockUUID)
+	if err != nil {
+		return err
+	}
+	if number.Serial >= stock.NextSerial {
+		return s.repo.DeleteAwbStockNumber(ctx, number)
+	}
+
+	number.Status = AwbStatusAvailable
+	number.MawbInfoUUID = ""
+	number.Remark = "released from MAWB info"
+	return s.repo.SaveAwbStockNumber(ctx, number)
+}
+
+// setStatus moves a number to used, voided or returned. Voided and returned numbers are
+// final, and a number that flew cannot be returned to the airline.
+func (s *awbStockService) setStatus(ctx context.Context, mawb, status, mawbInfoUUID, remark string) (*AwbStockNumber, error) {
+	mawb, err := cargoimp.NormalizeAWBNumber(mawb)
+	if err != nil {
+		return nil, err
+	}
+
+	number, err := s.repo.GetAwbStockNumber(ctx, mawb)
+	if err != nil {
+		return nil, err
+	}
+	if number == nil {
+		stock, err := s.repo.GetAwbStockForSerial(ctx, mawb[:3], serialOf(mawb))
+		if err != nil {
+			return nil, err
+		}
+		if stock == nil {
+			return nil, errAwbNotInStock
+		}
+		number = &AwbStockNumber{StockUUID: stock.UUID, Mawb: mawb, Serial: serialOf(mawb)}
+	}
+
+	switch {
+	case number.Status == AwbStatusVoided || number.Status == AwbStatusReturned:
+		return nil, fmt.Errorf("AWB %s is already %s", mawb, number.Status)
+	case status == AwbStatusReturned && number.Status == AwbStatusUsed:
+		return nil, fmt.Errorf("AWB %s is used and cannot be returned", mawb)
+	}
+
+	number.Status = status
+	if mawbInfoUUID != "" {
+		number.MawbInfoUUID = mawbInfoUUID
+	}
+	if remark = strings.TrimSpace(remark); remark != "" {
+		number.Remark = remark
+	}
+	if err := s.repo.SaveAwbStockNumber(ctx, number); err != nil {
+		return nil, err
+	}
+	return number, nil
+}
+
+// GetAwbStockReport sums the stocks of each airline. Remaining counts the numbers of active
+// stocks not yet allocated nor typed in by hand, and the released numbers waiting to be reused.
+func (s *awbStockService) GetAwbStockReport(ctx context.Context) ([]AwbStockSummary, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	stocks, err := s.repo.GetAllAwbStocks(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	counts, err := s.repo.CountAwbStockNumbers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	airlines, err := s.airlineRepo.GetAllAirlines(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	countsByStock := map[string][]AwbStockStatusCount{}
+	for _, c := range counts {
+		countsByStock[c.StockUUID] = append(countsByStock[c.StockUUID], c)
+	}
+	names := map[string]string{}
+	for _, a := range airlines {
+		names[a.UUID] = a.Name
+	}
+
+	summaries := []AwbStockSummary{}
+	byAirline := map[string]int{}
+	for _, stock := range stocks {
+		i, ok := byAirline[stock.AirlineUUID]
+		if !ok {
+			i = len(summaries)
+			byAirline[stock.AirlineUUID] = i
+			summaries = append(summaries, AwbStockSummary{
+				AirlineUUID: stock.AirlineUUID,
+				AirlineName: names[stock.AirlineUUID],
+				AwbPrefix:   stock.AwbPrefix,
+			})
+		}
+		summary := &summaries[i]
+		summary.Ranges++
+		summary.Total += stock.LastSerial - stock.FirstSerial + 1
+
+		var ahead int64
+		for _, c := range countsByStock[stock.UUID] {
+			ahead += c.Ahead
+			switch c.Status {
+			case AwbStatusAllocated:
+				summary.Allocated += c.Count
+			case AwbStatusUsed:
+				summary.Used += c.Count
+			case AwbStatusVoided:
+				summary.Voided += c.Count
+			case AwbStatusReturned:
+				summary.Returned += c.Count
+			case AwbStatusAvailable:
+				summary.Available += c.Count
+				if stock.IsActive {
+					summary.Remaining += c.Count
+				}
+			}
+		}
+		if stock.IsActive && stock.NextSerial <= stock.LastSerial {
+			summary.Remaining += stock.LastSerial - stock.NextSerial + 1 - ahead
+			if summary.NextMawb == "" {
+				summary.NextMawb = cargoimp.AWBNumber(stock.AwbPrefix, stock.NextSerial)
+			}
+		}
+	}
+
+	sort.SliceStable(summaries, func(i, j int) bool {
+		return summaries[i].AirlineName < summaries[j].AirlineName
+	})
+	return summaries, nil
+}
+
+// serialOf returns the seven digit serial of a normalised AWB number, without the check digit.
+func serialOf(mawb string) int64 {
+	serial, _ := strconv.ParseInt(mawb[4:11], 10, 64)
+	return serial
+}
@@ -0,0 +1,21 @@
+package setting
+
+import (
+	"net/http"
+	"time"
+
+	"hpc-express-service/utils"
+)
+
+type ConvertTemplate struct {
+	tableName struct{}                     `pg:"master_convert_templates,alias:mct"`
+	Code      string                       `json:"code" pg:"code,pk" validate:"required"`
+	Name      string                       `json:"name" pg:"name" validate:"required"`
+	Type      string                       `json:"type" pg:"type" validate:"required,oneof=inbound outbound"`
+	Mapping   *utils.ColumnMappingTemplate `json:"mapping" pg:"mapping"`
+	DeletedAt *time.Time                   `json:"-" pg:"deleted_at,soft_delete"`
+}
+
+func (ct *ConvertTemplate) Bind(r *http.Request) error {
+	return nil
+}
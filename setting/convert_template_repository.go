@@ -0,0 +1,82 @@
+package setting
+
+import (
+	"context"
+	"hpc-express-service/common"
+)
+
+type ConvertTemplateRepository interface {
+	CreateConvertTemplate(ctx context.Context, template *ConvertTemplate) (*ConvertTemplate, error)
+	GetAllConvertTemplates(ctx context.Context, templateType string) ([]ConvertTemplate, error)
+	GetConvertTemplateByCode(ctx context.Context, code string) (*ConvertTemplate, error)
+	UpdateConvertTemplate(ctx context.Context, template *ConvertTemplate) (*ConvertTemplate, error)
+	DeleteConvertTemplate(ctx context.Context, code string) error
+	EnsureConvertTemplate(ctx context.Context, template *ConvertTemplate) error
+}
+
+type convertTemplateRepository struct{}
+
+func NewConvertTemplateRepository() ConvertTemplateRepository {
+	return &convertTemplateRepository{}
+}
+
+func (r *convertTemplateRepository) CreateConvertTemplate(ctx context.Context, template *ConvertTemplate) (*ConvertTemplate, error) {
+	db, err := common.GetQer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	_, err = db.Model(template).Insert()
+	return template, err
+}
+
+func (r *convertTemplateRepository) GetAllConvertTemplates(ctx context.Context, templateType string) ([]ConvertTemplate, error) {
+	db, err := common.GetQer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var templates []ConvertTemplate
+	q := db.Model(&templates).Order("code")
+	if templateType != "" {
+		q = q.Where("type = ?", templateType)
+	}
+	err = q.Select()
+	return templates, err
+}
+
+func (r *convertTemplateRepository) GetConvertTemplateByCode(ctx context.Context, code string) (*ConvertTemplate, error) {
+	db, err := common.GetQer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	template := new(ConvertTemplate)
+	err = db.Model(template).Where("code = ?", code).Select()
+	return template, err
+}
+
+func (r *convertTemplateRepository) UpdateConvertTemplate(ctx context.Context, template *ConvertTemplate) (*ConvertTemplate, error) {
+	db, err := common.GetQer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	_, err = db.Model(template).Column("name", "type", "mapping").WherePK().Update()
+	return template, err
+}
+
+func (r *convertTemplateRepository) DeleteConvertTemplate(ctx context.Context, code string) error {
+	db, err := common.GetQer(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = db.Model(&ConvertTemplate{}).Where("code = ?", code).Delete()
+	return err
+}
+
+// EnsureConvertTemplate inserts template unless its code exists, including a deleted one.
+func (r *convertTemplateRepository) EnsureConvertTemplate(ctx context.Context, template *ConvertTemplate) error {
+	db, err := common.GetQer(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = db.Model(template).OnConflict("(code) DO NOTHING").Insert()
+	return err
+}
@@ -0,0 +1,90 @@
+package setting
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"hpc-express-service/utils"
+)
+
+type ConvertTemplateService interface {
+	CreateConvertTemplate(ctx context.Context, template *ConvertTemplate) (*ConvertTemplate, error)
+	GetAllConvertTemplates(ctx context.Context, templateType string) ([]ConvertTemplate, error)
+	GetConvertTemplateByCode(ctx context.Context, code string) (*ConvertTemplate, error)
+	UpdateConvertTemplate(ctx context.Context, template *ConvertTemplate) (*ConvertTemplate, error)
+	DeleteConvertTemplate(ctx context.Context, code string) error
+	EnsureConvertTemplate(ctx context.Context, template *ConvertTemplate) error
+}
+
+type convertTemplateService struct {
+	repo           ConvertTemplateRepository
+	contextTimeout time.Duration
+}
+
+func NewConvertTemplateService(repo ConvertTemplateRepository, timeout time.Duration) ConvertTemplateService {
+	return &convertTemplateService{
+		repo:           repo,
+		contextTimeout: timeout,
+	}
+}
+
+func (s *convertTemplateService) CreateConvertTemplate(ctx context.Context, template *ConvertTemplate) (*ConvertTemplate, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	if err := validateConvertTemplateMapping(template); err != nil {
+		return nil, err
+	}
+
+	return s.repo.CreateConvertTemplate(ctx, template)
+}
+
+func (s *convertTemplateService) GetAllConvertTemplates(ctx context.Context, templateType string) ([]ConvertTemplate, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+	return s.repo.GetAllConvertTemplates(ctx, templateType)
+}
+
+func (s *convertTemplateService) GetConvertTemplateByCode(ctx context.Context, code string) (*ConvertTemplate, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+	return s.repo.GetConvertTemplateByCode(ctx, code)
+}
+
+func (s *convertTemplateService) UpdateConvertTemplate(ctx context.Context, template *ConvertTemplate) (*ConvertTemplate, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	if err := validateConvertTemplateMapping(template); err != nil {
+		return nil, err
+	}
+
+	return s.repo.UpdateConvertTemplate(ctx, template)
+}
+
+func (s *convertTemplateService) DeleteConvertTemplate(ctx context.Context, code string) error {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+	return s.repo.DeleteConvertTemplate(ctx, code)
+}
+
+// EnsureConvertTemplate lists a converter built into the service as a convert template. A template
+// already stored under the code, or deleted by an admin, is left as it is.
+func (s *convertTemplateService) EnsureConvertTemplate(ctx context.Context, template *ConvertTemplate) error {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+	return s.repo.EnsureConvertTemplate(ctx, template)
+}
+
+// validateConvertTemplateMapping rejects mappings the inbound reader could not apply,
+// so a bad template fails on save rather than on the next upload.
+func validateConvertTemplateMapping(template *ConvertTemplate) error {
+	if template.Mapping == nil {
+		return nil
+	}
+	if template.Type != "inbound" {
+		return errors.New("column mapping is only supported for inbound templates")
+	}
+	return template.Mapping.Validate(&utils.InsertPreImportDetailManifestModel{})
+}
@@ -0,0 +1,109 @@
+package setting
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	ChargeRuleFixed      = "fixed"
+	ChargeRulePerKg      = "per_kg"
+	ChargeRulePercentFob = "percent_fob"
+)
+
+// ExporterProfile is the consignor declared on pre-export lines of one convert template.
+// A profile with CustomerUUID set is used for that customer's uploads and one with SenderName
+// set for sheet rows from that sender; a profile with neither is the template default.
+type ExporterProfile struct {
+	tableName             struct{}  `pg:"master_exporter_profiles,alias:mep"`
+	UUID                  string    `json:"uuid" pg:"uuid,pk"`
+	TemplateCode          string    `json:"templateCode" pg:"template_code" validate:"required"`
+	CustomerUUID          *string   `json:"customerUuid" pg:"customer_uuid"`
+	SenderName            string    `json:"senderName" pg:"sender_name,use_zero"`
+	TaxNumber             string    `json:"taxNumber" pg:"tax_number" validate:"required,len=13"`
+	Branch                string    `json:"branch" pg:"branch,use_zero"`
+	Name                  string    `json:"name" pg:"name" validate:"required"`
+	StreetAndAddress      string    `json:"streetAndAddress" pg:"street_and_address"`
+	District              string    `json:"district" pg:"district"`
+	SubProvince           string    `json:"subProvince" pg:"sub_province"`
+	Province              string    `json:"province" pg:"province"`
+	Postcode              string    `json:"postcode" pg:"postcode"`
+	Email                 string    `json:"email" pg:"email"`
+	DefaultTariffCode     string    `json:"defaultTariffCode" pg:"default_tariff_code" validate:"required"`
+	DefaultStatCode       string    `json:"defaultStatCode" pg:"default_stat_code"`
+	DefaultTariffSequence string    `json:"defaultTariffSequence" pg:"default_tariff_sequence"`
+	FreightRule           string    `json:"freightRule" pg:"freight_rule" validate:"required,oneof=fixed per_kg percent_fob"`
+	FreightValue          float64   `json:"freightValue" pg:"freight_value,use_zero"`
+	InsuranceRule         string    `json:"insuranceRule" pg:"insurance_rule" validate:"required,oneof=fixed per_kg percent_fob"`
+	InsuranceValue        float64   `json:"insuranceValue" pg:"insurance_value,use_zero"`
+	CreatedAt             time.Time `json:"createdAt" pg:"created_at"`
+	UpdatedAt             time.Time `json:"updatedAt" pg:"updated_at"`
+}
+
+func (p *ExporterProfile) Bind(r *http.Request) error {
+	return nil
+}
+
+// Freight returns the freight in THB of a HAWB with the given FOB (THB) and gross weight (KGM).
+func (p *ExporterProfile) Freight(fobBaht, weightKgm float64) float64 {
+	return charge(p.FreightRule, p.FreightValue, fobBaht, weightKgm)
+}
+
+// Insurance returns the insurance in THB of a HAWB with the given FOB (THB) and gross weight (KGM).
+func (p *ExporterProfile) Insurance(fobBaht, weightKgm float64) float64 {
+	return charge(p.InsuranceRule, p.InsuranceValue, fobBaht, weightKgm)
+}
+
+func charge(rule string, value, fobBaht, weightKgm float64) float64 {
+	var amount float64
+	switch rule {
+	case ChargeRuleFixed:
+		amount = value
+	case ChargeRulePerKg:
+		amount = value * weightKgm
+	case ChargeRulePercentFob:
+		amount = fobBaht * value / 100
+	}
+	return math.Round(amount*100) / 100
+}
+
+// ExporterProfileSet holds the exporter profiles that can apply to one upload.
+type ExporterProfileSet struct {
+	templateCode string
+	profiles     []ExporterProfile
+}
+
+func NewExporterProfileSet(templateCode string, profiles []ExporterProfile) *ExporterProfileSet {
+	return &ExporterProfileSet{templateCode: templateCode, profiles: profiles}
+}
+
+// Resolve returns the profile for a row from senderName: a customer profile for that sender
+// beats any customer profile, which beats a sender profile, which beats the template default.
+func (set *ExporterProfileSet) Resolve(senderName string) (*ExporterProfile, error) {
+	var best *ExporterProfile
+	bestRank := -1
+	for i := range set.profiles {
+		p := &set.profiles[i]
+		rank := 0
+		if p.SenderName != "" {
+			if !strings.EqualFold(p.SenderName, strings.TrimSpace(senderName)) {
+				continue
+			}
+			rank++
+		}
+		if p.CustomerUUID != nil {
+			rank += 2
+		}
+		if rank > bestRank {
+			best, bestRank = p, rank
+		}
+	}
+
+	if best == nil {
+		return nil, fmt.Errorf("no exporter profile for template '%s' and sender '%s'", set.templateCode, senderName)
+	}
+	return best, nil
+}
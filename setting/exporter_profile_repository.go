@@ -0,0 +1,99 @@
+package setting
+
+import (
+	"context"
+	"hpc-express-service/common"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ExporterProfileRepository interface {
+	CreateExporterProfile(ctx context.Context, profile *ExporterProfile) (*ExporterProfile, error)
+	GetAllExporterProfiles(ctx context.Context, templateCode, customerUUID string) ([]ExporterProfile, error)
+	GetExporterProfileByUUID(ctx context.Context, uuid string) (*ExporterProfile, error)
+	UpdateExporterProfile(ctx context.Context, profile *ExporterProfile) (*ExporterProfile, error)
+	DeleteExporterProfile(ctx context.Context, uuid string) error
+	GetTemplateExporterProfiles(ctx context.Context, templateCode, customerUUID string) ([]ExporterProfile, error)
+}
+
+type exporterProfileRepository struct{}
+
+func NewExporterProfileRepository() ExporterProfileRepository {
+	return &exporterProfileRepository{}
+}
+
+func (r *exporterProfileRepository) CreateExporterProfile(ctx context.Context, profile *ExporterProfile) (*ExporterProfile, error) {
+	db, err := common.GetQer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	profile.UUID = uuid.New().String()
+	profile.CreatedAt = time.Now()
+	profile.UpdatedAt = time.Now()
+	_, err = db.Model(profile).Insert()
+	return profile, err
+}
+
+func (r *exporterProfileRepository) GetAllExporterProfiles(ctx context.Context, templateCode, customerUUID string) ([]ExporterProfile, error) {
+	db, err := common.GetQer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var profiles []ExporterProfile
+	q := db.Model(&profiles).Order("template_code", "name")
+	if templateCode != "" {
+		q = q.Where("template_code = ?", templateCode)
+	}
+	if customerUUID != "" {
+		q = q.Where("customer_uuid = ?", customerUUID)
+	}
+	err = q.Select()
+	return profiles, err
+}
+
+func (r *exporterProfileRepository) GetExporterProfileByUUID(ctx context.Context, uuid string) (*ExporterProfile, error) {
+	db, err := common.GetQer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	profile := new(ExporterProfile)
+	err = db.Model(profile).Where("uuid = ?", uuid).Select()
+	return profile, err
+}
+
+func (r *exporterProfileRepository) UpdateExporterProfile(ctx context.Context, profile *ExporterProfile) (*ExporterProfile, error) {
+	db, err := common.GetQer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	profile.UpdatedAt = time.Now()
+	_, err = db.Model(profile).ExcludeColumn("created_at").WherePK().Update()
+	return profile, err
+}
+
+func (r *exporterProfileRepository) DeleteExporterProfile(ctx context.Context, uuid string) error {
+	db, err := common.GetQer(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = db.Model(&ExporterProfile{}).Where("uuid = ?", uuid).Delete()
+	return err
+}
+
+// GetTemplateExporterProfiles returns the template's default and sender profiles plus the customer's own profiles.
+func (r *exporterProfileRepository) GetTemplateExporterProfiles(ctx context.Context, templateCode, customerUUID string) ([]ExporterProfile, error) {
+	db, err := common.GetQer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var profiles []ExporterProfile
+	q := db.Model(&profiles).Where("template_code = ?", templateCode)
+	if customerUUID != "" {
+		q = q.Where("customer_uuid IS NULL OR customer_uuid = ?", customerUUID)
+	} else {
+		q = q.Where("customer_uuid IS NULL")
+	}
+	err = q.Select()
+	return profiles, err
+}
@@ -0,0 +1,98 @@
+package setting
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+type ExporterProfileService interface {
+	CreateExporterProfile(ctx context.Context, profile *ExporterProfile) (*ExporterProfile, error)
+	GetAllExporterProfiles(ctx context.Context, templateCode, customerUUID string) ([]ExporterProfile, error)
+	GetExporterProfileByUUID(ctx context.Context, uuid string) (*ExporterProfile, error)
+	UpdateExporterProfile(ctx context.Context, profile *ExporterProfile) (*ExporterProfile, error)
+	DeleteExporterProfile(ctx context.Context, uuid string) error
+	GetExporterProfileSet(ctx context.Context, templateCode, customerUUID string) (*ExporterProfileSet, error)
+}
+
+type exporterProfileService struct {
+	repo           ExporterProfileRepository
+	contextTimeout time.Duration
+}
+
+func NewExporterProfileService(repo ExporterProfileRepository, timeout time.Duration) ExporterProfileService {
+	return &exporterProfileService{
+		repo:           repo,
+		contextTimeout: timeout,
+	}
+}
+
+func (s *exporterProfileService) CreateExporterProfile(ctx context.Context, profile *ExporterProfile) (*ExporterProfile, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	if err := validateExporterProfile(profile); err != nil {
+		return nil, err
+	}
+
+	return s.repo.CreateExporterProfile(ctx, profile)
+}
+
+func (s *exporterProfileService) GetAllExporterProfiles(ctx context.Context, templateCode, customerUUID string) ([]ExporterProfile, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+	return s.repo.GetAllExporterProfiles(ctx, templateCode, customerUUID)
+}
+
+func (s *exporterProfileService) GetExporterProfileByUUID(ctx context.Context, uuid string) (*ExporterProfile, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+	return s.repo.GetExporterProfileByUUID(ctx, uuid)
+}
+
+func (s *exporterProfileService) UpdateExporterProfile(ctx context.Context, profile *ExporterProfile) (*ExporterProfile, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	if profile.UUID == "" {
+		return nil, errors.New("uuid is required")
+	}
+	if err := validateExporterProfile(profile); err != nil {
+		return nil, err
+	}
+
+	return s.repo.UpdateExporterProfile(ctx, profile)
+}
+
+func (s *exporterProfileService) DeleteExporterProfile(ctx context.Context, uuid string) error {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+	return s.repo.DeleteExporterProfile(ctx, uuid)
+}
+
+// GetExporterProfileSet loads the profiles a template upload for the customer can resolve to.
+// customerUUID may be empty when the upload is not tied to a customer.
+func (s *exporterProfileService) GetExporterProfileSet(ctx context.Context, templateCode, customerUUID string) (*ExporterProfileSet, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	profiles, err := s.repo.GetTemplateExporterProfiles(ctx, templateCode, customerUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewExporterProfileSet(templateCode, profiles), nil
+}
+
+func validateExporterProfile(profile *ExporterProfile) error {
+	profile.TemplateCode = strings.ToUpper(strings.TrimSpace(profile.TemplateCode))
+	profile.SenderName = strings.TrimSpace(profile.SenderName)
+	if profile.CustomerUUID != nil && *profile.CustomerUUID == "" {
+		profile.CustomerUUID = nil
+	}
+	if profile.FreightValue < 0 || profile.InsuranceValue < 0 {
+		return errors.New("freight and insurance values must not be negative")
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package setting
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	FeeCodeCustoms         = "customs_fee"
+	FeeCodeOTCustoms       = "ot_customs_fee"
+	FeeCodeBank            = "bank_fee"
+	FeeCodeCargoPermit     = "cargo_permit_fee"
+	FeeCodeExpressDelivery = "express_delivery_fee"
+)
+
+const (
+	FeeRulePerDeclaration = "per_declaration"
+	FeeRulePerHawb        = "per_hawb"
+	FeeRuleTiered         = "tiered"
+	FeeRuleFlatMinimum    = "flat_minimum"
+)
+
+// FeeSchedule is one fee rule for an inbound MAWB, valid between EffectiveFrom and EffectiveTo.
+// A schedule with CustomerUUID set overrides the default schedule for that customer.
+type FeeSchedule struct {
+	tableName          struct{}        `pg:"master_fee_schedules,alias:mfs"`
+	UUID               string          `json:"uuid" pg:"uuid,pk"`
+	FeeCode            string          `json:"feeCode" pg:"fee_code" validate:"required,oneof=customs_fee ot_customs_fee bank_fee cargo_permit_fee express_delivery_fee"`
+	RuleType           string          `json:"ruleType" pg:"rule_type" validate:"required,oneof=per_declaration per_hawb tiered flat_minimum"`
+	CustomerUUID       *string         `json:"customerUuid" pg:"customer_uuid"`
+	HawbPerDeclaration int             `json:"hawbPerDeclaration" pg:"hawb_per_declaration,use_zero"`
+	Amount             decimal.Decimal `json:"amount" pg:"amount,use_zero"`
+	MinimumAmount      decimal.Decimal `json:"minimumAmount" pg:"minimum_amount,use_zero"`
+	Tiers              []*FeeTier      `json:"tiers" pg:"tiers"`
+	EffectiveFrom      time.Time       `json:"effectiveFrom" pg:"effective_from" validate:"required"`
+	EffectiveTo        *time.Time      `json:"effectiveTo" pg:"effective_to"`
+	CreatedAt          time.Time       `json:"createdAt" pg:"created_at"`
+	UpdatedAt          time.Time       `json:"updatedAt" pg:"updated_at"`
+}
+
+// FeeTier charges Amount for the whole MAWB when its HAWB count is at most UpToHawb.
+// The last tier may leave UpToHawb at 0 to cover everything above the previous tier.
+type FeeTier struct {
+	UpToHawb int             `json:"upToHawb"`
+	Amount   decimal.Decimal `json:"amount"`
+}
+
+func (fs *FeeSchedule) Bind(r *http.Request) error {
+	return nil
+}
+
+// Calculate returns the number of declarations and the total fee for a MAWB with totalHawb HAWBs.
+func (fs *FeeSchedule) Calculate(totalHawb int) (int, decimal.Decimal) {
+	if totalHawb <= 0 {
+		return 0, decimal.Zero
+	}
+
+	declarations := 1
+	if fs.HawbPerDeclaration > 0 {
+		declarations = (totalHawb + fs.HawbPerDeclaration - 1) / fs.HawbPerDeclaration
+	}
+
+	var total decimal.Decimal
+	switch fs.RuleType {
+	case FeeRulePerDeclaration:
+		total = fs.Amount.Mul(decimal.NewFromInt(int64(declarations)))
+	case FeeRulePerHawb:
+		total = fs.Amount.Mul(decimal.NewFromInt(int64(totalHawb)))
+	case FeeRuleTiered:
+		for _, tier := range fs.Tiers {
+			total = tier.Amount
+			if tier.UpToHawb == 0 || totalHawb <= tier.UpToHawb {
+				break
+			}
+		}
+	case FeeRuleFlatMinimum:
+		total = fs.Amount
+	}
+
+	if total.LessThan(fs.MinimumAmount) {
+		total = fs.MinimumAmount
+	}
+
+	return declarations, total
+}
+
+// DefaultFeeSchedules are the rates that apply when no schedule is configured for a fee.
+func DefaultFeeSchedules() map[string]*FeeSchedule {
+	return map[string]*FeeSchedule{
+		FeeCodeCustoms: {
+			FeeCode:            FeeCodeCustoms,
+			RuleType:           FeeRulePerDeclaration,
+			HawbPerDeclaration: 40,
+			Amount:             decimal.NewFromInt(200),
+		},
+		FeeCodeOTCustoms: {
+			FeeCode:            FeeCodeOTCustoms,
+			RuleType:           FeeRulePerDeclaration,
+			HawbPerDeclaration: 40,
+			Amount:             decimal.NewFromInt(200),
+		},
+		FeeCodeBank: {
+			FeeCode:            FeeCodeBank,
+			RuleType:           FeeRulePerDeclaration,
+			HawbPerDeclaration: 40,
+			Amount:             decimal.NewFromInt(70),
+		},
+		FeeCodeCargoPermit: {
+			FeeCode:            FeeCodeCargoPermit,
+			RuleType:           FeeRulePerDeclaration,
+			HawbPerDeclaration: 40,
+			Amount:             decimal.NewFromInt(150),
+		},
+		FeeCodeExpressDelivery: {
+			FeeCode:  FeeCodeExpressDelivery,
+			RuleType: FeeRuleFlatMinimum,
+			Amount:   decimal.NewFromInt(380),
+		},
+	}
+}
@@ -0,0 +1,103 @@
+package setting
+
+import (
+	"context"
+	"hpc-express-service/common"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type FeeScheduleRepository interface {
+	CreateFeeSchedule(ctx context.Context, schedule *FeeSchedule) (*FeeSchedule, error)
+	GetAllFeeSchedules(ctx context.Context, feeCode, customerUUID string) ([]FeeSchedule, error)
+	GetFeeScheduleByUUID(ctx context.Context, uuid string) (*FeeSchedule, error)
+	UpdateFeeSchedule(ctx context.Context, schedule *FeeSchedule) (*FeeSchedule, error)
+	DeleteFeeSchedule(ctx context.Context, uuid string) error
+	GetEffectiveFeeSchedules(ctx context.Context, customerUUID string, on time.Time) ([]FeeSchedule, error)
+}
+
+type feeScheduleRepository struct{}
+
+func NewFeeScheduleRepository() FeeScheduleRepository {
+	return &feeScheduleRepository{}
+}
+
+func (r *feeScheduleRepository) CreateFeeSchedule(ctx context.Context, schedule *FeeSchedule) (*FeeSchedule, error) {
+	db, err := common.GetQer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	schedule.UUID = uuid.New().String()
+	schedule.CreatedAt = time.Now()
+	schedule.UpdatedAt = time.Now()
+	_, err = db.Model(schedule).Insert()
+	return schedule, err
+}
+
+func (r *feeScheduleRepository) GetAllFeeSchedules(ctx context.Context, feeCode, customerUUID string) ([]FeeSchedule, error) {
+	db, err := common.GetQer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var schedules []FeeSchedule
+	q := db.Model(&schedules).Order("fee_code", "effective_from DESC")
+	if feeCode != "" {
+		q = q.Where("fee_code = ?", feeCode)
+	}
+	if customerUUID != "" {
+		q = q.Where("customer_uuid = ?", customerUUID)
+	}
+	err = q.Select()
+	return schedules, err
+}
+
+func (r *feeScheduleRepository) GetFeeScheduleByUUID(ctx context.Context, uuid string) (*FeeSchedule, error) {
+	db, err := common.GetQer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	schedule := new(FeeSchedule)
+	err = db.Model(schedule).Where("uuid = ?", uuid).Select()
+	return schedule, err
+}
+
+func (r *feeScheduleRepository) UpdateFeeSchedule(ctx context.Context, schedule *FeeSchedule) (*FeeSchedule, error) {
+	db, err := common.GetQer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	schedule.UpdatedAt = time.Now()
+	_, err = db.Model(schedule).ExcludeColumn("created_at").WherePK().Update()
+	return schedule, err
+}
+
+func (r *feeScheduleRepository) DeleteFeeSchedule(ctx context.Context, uuid string) error {
+	db, err := common.GetQer(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = db.Model(&FeeSchedule{}).Where("uuid = ?", uuid).Delete()
+	return err
+}
+
+// GetEffectiveFeeSchedules returns the default schedules and the customer's overrides that apply on the given date.
+// Without a customer UUID only the default schedules are returned.
+func (r *feeScheduleRepository) GetEffectiveFeeSchedules(ctx context.Context, customerUUID string, on time.Time) ([]FeeSchedule, error) {
+	db, err := common.GetQer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var schedules []FeeSchedule
+	q := db.Model(&schedules).
+		Where("effective_from <= ?", on).
+		Where("effective_to IS NULL OR effective_to >= ?", on).
+		Order("effective_from DESC")
+	if customerUUID != "" {
+		q = q.Where("customer_uuid IS NULL OR customer_uuid = ?", customerUUID)
+	} else {
+		q = q.Where("customer_uuid IS NULL")
+	}
+	err = q.Select()
+	return schedules, err
+}
@@ -0,0 +1,142 @@
+package setting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+type FeeScheduleService interface {
+	CreateFeeSchedule(ctx context.Context, schedule *FeeSchedule) (*FeeSchedule, error)
+	GetAllFeeSchedules(ctx context.Context, feeCode, customerUUID string) ([]FeeSchedule, error)
+	GetFeeScheduleByUUID(ctx context.Context, uuid string) (*FeeSchedule, error)
+	UpdateFeeSchedule(ctx context.Context, schedule *FeeSchedule) (*FeeSchedule, error)
+	DeleteFeeSchedule(ctx context.Context, uuid string) error
+	GetEffectiveFeeSchedules(ctx context.Context, customerUUID string, on time.Time) (map[string]*FeeSchedule, error)
+}
+
+type feeScheduleService struct {
+	repo           FeeScheduleRepository
+	contextTimeout time.Duration
+}
+
+func NewFeeScheduleService(repo FeeScheduleRepository, timeout time.Duration) FeeScheduleService {
+	return &feeScheduleService{
+		repo:           repo,
+		contextTimeout: timeout,
+	}
+}
+
+func (s *feeScheduleService) CreateFeeSchedule(ctx context.Context, schedule *FeeSchedule) (*FeeSchedule, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	if err := validateFeeSchedule(schedule); err != nil {
+		return nil, err
+	}
+
+	return s.repo.CreateFeeSchedule(ctx, schedule)
+}
+
+func (s *feeScheduleService) GetAllFeeSchedules(ctx context.Context, feeCode, customerUUID string) ([]FeeSchedule, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+	return s.repo.GetAllFeeSchedules(ctx, feeCode, customerUUID)
+}
+
+func (s *feeScheduleService) GetFeeScheduleByUUID(ctx context.Context, uuid string) (*FeeSchedule, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+	return s.repo.GetFeeScheduleByUUID(ctx, uuid)
+}
+
+func (s *feeScheduleService) UpdateFeeSchedule(ctx context.Context, schedule *FeeSchedule) (*FeeSchedule, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	if schedule.UUID == "" {
+		return nil, errors.New("uuid is required")
+	}
+	if err := validateFeeSchedule(schedule); err != nil {
+		return nil, err
+	}
+
+	return s.repo.UpdateFeeSchedule(ctx, schedule)
+}
+
+func (s *feeScheduleService) DeleteFeeSchedule(ctx context.Context, uuid string) error {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+	return s.repo.DeleteFeeSchedule(ctx, uuid)
+}
+
+// GetEffectiveFeeSchedules returns one schedule per fee code for the customer on the given date.
+// A customer override wins over the default schedule, the most recent effective date wins
+// within each, and fees with nothing configured fall back to DefaultFeeSchedules.
+func (s *feeScheduleService) GetEffectiveFeeSchedules(ctx context.Context, customerUUID string, on time.Time) (map[string]*FeeSchedule, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	schedules, err := s.repo.GetEffectiveFeeSchedules(ctx, customerUUID, on)
+	if err != nil {
+		return nil, err
+	}
+
+	result := DefaultFeeSchedules()
+	configured := map[string]bool{}
+	// schedules are ordered by effective_from DESC, so the first match per fee code is the newest
+	for i := range schedules {
+		schedule := &schedules[i]
+		if schedule.CustomerUUID == nil && !configured[schedule.FeeCode] {
+			result[schedule.FeeCode] = schedule
+			configured[schedule.FeeCode] = true
+		}
+	}
+	overridden := map[string]bool{}
+	for i := range schedules {
+		schedule := &schedules[i]
+		if schedule.CustomerUUID != nil && !overridden[schedule.FeeCode] {
+			result[schedule.FeeCode] = schedule
+			overridden[schedule.FeeCode] = true
+		}
+	}
+
+	return result, nil
+}
+
+func validateFeeSchedule(schedule *FeeSchedule) error {
+	if schedule.EffectiveTo != nil && schedule.EffectiveTo.Before(schedule.EffectiveFrom) {
+		return errors.New("effectiveTo must not be before effectiveFrom")
+	}
+	if schedule.HawbPerDeclaration < 0 {
+		return errors.New("hawbPerDeclaration must not be negative")
+	}
+	if schedule.Amount.IsNegative() || schedule.MinimumAmount.IsNegative() {
+		return errors.New("amounts must not be negative")
+	}
+	if schedule.CustomerUUID != nil && *schedule.CustomerUUID == "" {
+		schedule.CustomerUUID = nil
+	}
+
+	switch schedule.RuleType {
+	case FeeRuleTiered:
+		if len(schedule.Tiers) == 0 {
+			return errors.New("tiered schedule requires at least one tier")
+		}
+		for i, tier := range schedule.Tiers {
+			if tier.UpToHawb == 0 && i != len(schedule.Tiers)-1 {
+				return fmt.Errorf("tier %d: only the last tier may be open-ended", i+1)
+			}
+			if i > 0 && tier.UpToHawb != 0 && tier.UpToHawb <= schedule.Tiers[i-1].UpToHawb {
+				return fmt.Errorf("tier %d: upToHawb must be greater than the previous tier", i+1)
+			}
+		}
+	case FeeRuleFlatMinimum:
+		if schedule.Amount.IsZero() && schedule.MinimumAmount.IsZero() {
+			return errors.New("flat_minimum schedule requires an amount")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,78 @@
+package setting
+
+import (
+	"hpc-express-service/hsmatch"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// HsCodeAlias maps a goods description, as a shipper writes it, onto the HS code an operator chose for it.
+// Aliases are learned from corrected raw pre-import uploads and consulted before the HS master.
+// An empty ShipperName or OriginCountryCode matches any shipper or origin.
+type HsCodeAlias struct {
+	tableName         struct{}   `pg:"master_hs_code_aliases,alias:mhca"`
+	UUID              string     `json:"uuid" pg:"uuid,pk"`
+	GoodsText         string     `json:"goodsText" pg:"goods_text" validate:"required"`
+	NormalizedGoods   string     `json:"normalizedGoods" pg:"normalized_goods"`
+	ShipperName       string     `json:"shipperName" pg:"shipper_name,use_zero"`
+	OriginCountryCode string     `json:"originCountryCode" pg:"origin_country_code,use_zero"`
+	TariffCode        string     `json:"tariffCode" pg:"tariff_code" validate:"required"`
+	TariffSequence    string     `json:"tariffSequence" pg:"tariff_sequence"`
+	StatisticalCode   string     `json:"statisticalCode" pg:"statistical_code"`
+	UsageCount        int64      `json:"usageCount" pg:"usage_count,use_zero"`
+	LastUsedAt        *time.Time `json:"lastUsedAt" pg:"last_used_at"`
+	CreatedBy         string     `json:"createdBy" pg:"created_by"`
+	CreatedAt         time.Time  `json:"createdAt" pg:"created_at"`
+	UpdatedAt         time.Time  `json:"updatedAt" pg:"updated_at"`
+}
+
+func (a *HsCodeAlias) Bind(r *http.Request) error {
+	return nil
+}
+
+// HsCodeAliasSet holds the aliases loaded for one conversion, grouped by normalised goods text.
+type HsCodeAliasSet struct {
+	byGoods map[string][]*HsCodeAlias
+}
+
+func NewHsCodeAliasSet(aliases []HsCodeAlias) *HsCodeAliasSet {
+	set := &HsCodeAliasSet{byGoods: map[string][]*HsCodeAlias{}}
+	for i := range aliases {
+		a := &aliases[i]
+		set.byGoods[a.NormalizedGoods] = append(set.byGoods[a.NormalizedGoods], a)
+	}
+	return set
+}
+
+// Lookup returns the most specific alias for the goods from this shipper and origin:
+// shipper and origin match beats shipper only, which beats origin only, which beats a generic alias.
+// Ties go to the alias used most often.
+func (set *HsCodeAliasSet) Lookup(goods, shipperName, originCountryCode string) *HsCodeAlias {
+	if set == nil {
+		return nil
+	}
+
+	var best *HsCodeAlias
+	bestRank := -1
+	for _, a := range set.byGoods[hsmatch.Normalize(goods)] {
+		rank := 0
+		if a.ShipperName != "" {
+			if !strings.EqualFold(a.ShipperName, strings.TrimSpace(shipperName)) {
+				continue
+			}
+			rank += 2
+		}
+		if a.OriginCountryCode != "" {
+			if !strings.EqualFold(a.OriginCountryCode, strings.TrimSpace(originCountryCode)) {
+				continue
+			}
+			rank++
+		}
+		if rank > bestRank || (rank == bestRank && a.UsageCount > best.UsageCount) {
+			best, bestRank = a, rank
+		}
+	}
+
+	return best
+}
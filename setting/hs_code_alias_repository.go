@@ -0,0 +1,136 @@
+package setting
+
+import (
+	"context"
+	"hpc-express-service/common"
+	"time"
+
+	"github.com/go-pg/pg/v9"
+	"github.com/go-pg/pg/v9/orm"
+	"github.com/google/uuid"
+)
+
+type HsCodeAliasRepository interface {
+	CreateHsCodeAlias(ctx context.Context, alias *HsCodeAlias) (*HsCodeAlias, error)
+	GetAllHsCodeAliases(ctx context.Context, search string) ([]HsCodeAlias, error)
+	GetHsCodeAliasByUUID(ctx context.Context, uuid string) (*HsCodeAlias, error)
+	GetHsCodeAliasByKey(ctx context.Context, normalizedGoods, shipperName, originCountryCode string) (*HsCodeAlias, error)
+	GetHsCodeAliasesByGoods(ctx context.Context, normalizedGoods []string) ([]HsCodeAlias, error)
+	UpdateHsCodeAlias(ctx context.Context, alias *HsCodeAlias) (*HsCodeAlias, error)
+	DeleteHsCodeAlias(ctx context.Context, uuid string) error
+	IncrementHsCodeAliasUsage(ctx context.Context, uuid string, count int64) error
+}
+
+type hsCodeAliasRepository struct{}
+
+func NewHsCodeAliasRepository() HsCodeAliasRepository {
+	return &hsCodeAliasRepository{}
+}
+
+func (r *hsCodeAliasRepository) CreateHsCodeAlias(ctx context.Context, alias *HsCodeAlias) (*HsCodeAlias, error) {
+	db, err := common.GetQer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	alias.UUID = uuid.New().String()
+	alias.CreatedAt = time.Now()
+	alias.UpdatedAt = time.Now()
+	_, err = db.Model(alias).Insert()
+	return alias, err
+}
+
+func (r *hsCodeAliasRepository) GetAllHsCodeAliases(ctx context.Context, search string) ([]HsCodeAlias, error) {
+	db, err := common.GetQer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var aliases []HsCodeAlias
+	q := db.Model(&aliases).Order("usage_count DESC", "goods_text")
+	if search != "" {
+		q = q.WhereGroup(func(q *orm.Query) (*orm.Query, error) {
+			q = q.WhereOr("goods_text ILIKE ?", "%"+search+"%").
+				WhereOr("shipper_name ILIKE ?", "%"+search+"%").
+				WhereOr("tariff_code ILIKE ?", search+"%")
+			return q, nil
+		})
+	}
+	err = q.Select()
+	return aliases, err
+}
+
+func (r *hsCodeAliasRepository) GetHsCodeAliasByUUID(ctx context.Context, uuid string) (*HsCodeAlias, error) {
+	db, err := common.GetQer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	alias := new(HsCodeAlias)
+	err = db.Model(alias).Where("uuid = ?", uuid).Select()
+	return alias, err
+}
+
+// GetHsCodeAliasByKey returns the alias for exactly this goods, shipper and origin, or nil when there is none.
+func (r *hsCodeAliasRepository) GetHsCodeAliasByKey(ctx context.Context, normalizedGoods, shipperName, originCountryCode string) (*HsCodeAlias, error) {
+	db, err := common.GetQer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	alias := new(HsCodeAlias)
+	err = db.Model(alias).
+		Where("normalized_goods = ?", normalizedGoods).
+		Where("shipper_name = ?", shipperName).
+		Where("origin_country_code = ?", originCountryCode).
+		Limit(1).
+		Select()
+	if err == pg.ErrNoRows {
+		return nil, nil
+	}
+	return alias, err
+}
+
+func (r *hsCodeAliasRepository) GetHsCodeAliasesByGoods(ctx context.Context, normalizedGoods []string) ([]HsCodeAlias, error) {
+	db, err := common.GetQer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var aliases []HsCodeAlias
+	if len(normalizedGoods) == 0 {
+		return aliases, nil
+	}
+	err = db.Model(&aliases).Where("normalized_goods IN (?)", pg.In(normalizedGoods)).Select()
+	return aliases, err
+}
+
+func (r *hsCodeAliasRepository) UpdateHsCodeAlias(ctx context.Context, alias *HsCodeAlias) (*HsCodeAlias, error) {
+	db, err := common.GetQer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	alias.UpdatedAt = time.Now()
+	_, err = db.Model(alias).
+		ExcludeColumn("created_at", "created_by", "usage_count", "last_used_at").
+		WherePK().
+		Update()
+	return alias, err
+}
+
+func (r *hsCodeAliasRepository) DeleteHsCodeAlias(ctx context.Context, uuid string) error {
+	db, err := common.GetQer(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = db.Model(&HsCodeAlias{}).Where("uuid = ?", uuid).Delete()
+	return err
+}
+
+func (r *hsCodeAliasRepository) IncrementHsCodeAliasUsage(ctx context.Context, uuid string, count int64) error {
+	db, err := common.GetQer(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = db.Model(&HsCodeAlias{}).
+		Set("usage_count = usage_count + ?", count).
+		Set("last_used_at = ?", time.Now()).
+		Where("uuid = ?", uuid).
+		Update()
+	return err
+}
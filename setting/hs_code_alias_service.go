@@ -0,0 +1,171 @@
+package setting
+
+import (
+	"context"
+	"errors"
+	"hpc-express-service/hsmatch"
+	"strings"
+	"time"
+)
+
+type HsCodeAliasService interface {
+	CreateHsCodeAlias(ctx context.Context, alias *HsCodeAlias) (*HsCodeAlias, error)
+	GetAllHsCodeAliases(ctx context.Context, search string) ([]HsCodeAlias, error)
+	GetHsCodeAliasByUUID(ctx context.Context, uuid string) (*HsCodeAlias, error)
+	UpdateHsCodeAlias(ctx context.Context, alias *HsCodeAlias) (*HsCodeAlias, error)
+	DeleteHsCodeAlias(ctx context.Context, uuid string) error
+	RecordHsCodeCorrection(ctx context.Context, alias *HsCodeAlias) (*HsCodeAlias, error)
+	GetHsCodeAliasSet(ctx context.Context, goods []string) (*HsCodeAliasSet, error)
+	IncrementHsCodeAliasUsage(ctx context.Context, usage map[string]int64) error
+}
+
+type hsCodeAliasService struct {
+	repo           HsCodeAliasRepository
+	contextTimeout time.Duration
+}
+
+func NewHsCodeAliasService(repo HsCodeAliasRepository, timeout time.Duration) HsCodeAliasService {
+	return &hsCodeAliasService{
+		repo:           repo,
+		contextTimeout: timeout,
+	}
+}
+
+func (s *hsCodeAliasService) CreateHsCodeAlias(ctx context.Context, alias *HsCodeAlias) (*HsCodeAlias, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	if err := normalizeHsCodeAlias(alias); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.repo.GetHsCodeAliasByKey(ctx, alias.NormalizedGoods, alias.ShipperName, alias.OriginCountryCode)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, errors.New("alias already exists")
+	}
+
+	return s.repo.CreateHsCodeAlias(ctx, alias)
+}
+
+func (s *hsCodeAliasService) GetAllHsCodeAliases(ctx context.Context, search string) ([]HsCodeAlias, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+	return s.repo.GetAllHsCodeAliases(ctx, strings.TrimSpace(search))
+}
+
+func (s *hsCodeAliasService) GetHsCodeAliasByUUID(ctx context.Context, uuid string) (*HsCodeAlias, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+	return s.repo.GetHsCodeAliasByUUID(ctx, uuid)
+}
+
+func (s *hsCodeAliasService) UpdateHsCodeAlias(ctx context.Context, alias *HsCodeAlias) (*HsCodeAlias, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	if alias.UUID == "" {
+		return nil, errors.New("uuid is required")
+	}
+	if err := normalizeHsCodeAlias(alias); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.repo.GetHsCodeAliasByKey(ctx, alias.NormalizedGoods, alias.ShipperName, alias.OriginCountryCode)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil && existing.UUID != alias.UUID {
+		return nil, errors.New("alias already exists")
+	}
+
+	return s.repo.UpdateHsCodeAlias(ctx, alias)
+}
+
+func (s *hsCodeAliasService) DeleteHsCodeAlias(ctx context.Context, uuid string) error {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+	return s.repo.DeleteHsCodeAlias(ctx, uuid)
+}
+
+// RecordHsCodeCorrection stores an operator's HS code choice for a goods description,
+// overwriting the HS code of an alias already learned for the same goods, shipper and origin.
+func (s *hsCodeAliasService) RecordHsCodeCorrection(ctx context.Context, alias *HsCodeAlias) (*HsCodeAlias, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	if err := normalizeHsCodeAlias(alias); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.repo.GetHsCodeAliasByKey(ctx, alias.NormalizedGoods, alias.ShipperName, alias.OriginCountryCode)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return s.repo.CreateHsCodeAlias(ctx, alias)
+	}
+
+	existing.GoodsText = alias.GoodsText
+	existing.TariffCode = alias.TariffCode
+	existing.TariffSequence = alias.TariffSequence
+	existing.StatisticalCode = alias.StatisticalCode
+	return s.repo.UpdateHsCodeAlias(ctx, existing)
+}
+
+// GetHsCodeAliasSet loads every alias learned for the given goods descriptions.
+func (s *hsCodeAliasService) GetHsCodeAliasSet(ctx context.Context, goods []string) (*HsCodeAliasSet, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	seen := map[string]bool{}
+	normalized := []string{}
+	for _, g := range goods {
+		n := hsmatch.Normalize(g)
+		if n == "" || seen[n] {
+			continue
+		}
+		seen[n] = true
+		normalized = append(normalized, n)
+	}
+
+	aliases, err := s.repo.GetHsCodeAliasesByGoods(ctx, normalized)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewHsCodeAliasSet(aliases), nil
+}
+
+// IncrementHsCodeAliasUsage adds how many manifest lines each alias (by uuid) resolved.
+func (s *hsCodeAliasService) IncrementHsCodeAliasUsage(ctx context.Context, usage map[string]int64) error {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	for uuid, count := range usage {
+		if err := s.repo.IncrementHsCodeAliasUsage(ctx, uuid, count); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func normalizeHsCodeAlias(alias *HsCodeAlias) error {
+	alias.GoodsText = strings.TrimSpace(alias.GoodsText)
+	alias.NormalizedGoods = hsmatch.Normalize(alias.GoodsText)
+	alias.ShipperName = strings.TrimSpace(alias.ShipperName)
+	alias.OriginCountryCode = strings.ToUpper(strings.TrimSpace(alias.OriginCountryCode))
+	alias.TariffCode = strings.TrimSpace(alias.TariffCode)
+	alias.TariffSequence = strings.TrimSpace(alias.TariffSequence)
+	alias.StatisticalCode = strings.TrimSpace(alias.StatisticalCode)
+
+	if alias.NormalizedGoods == "" {
+		return errors.New("goodsText is required")
+	}
+	if alias.TariffCode == "" {
+		return errors.New("tariffCode is required")
+	}
+	return nil
+}
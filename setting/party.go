@@ -0,0 +1,109 @@
+package setting
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	PartyRoleShipper   = "shipper"
+	PartyRoleConsignee = "consignee"
+	PartyRoleNotify    = "notify"
+)
+
+// Party is an entry of the address book of shippers, consignees and notify parties. Forms that
+// reference a party keep its TextBlock, so they print the same after the party changes. Roles
+// lists what the party may be used as.
+type Party struct {
+	tableName    struct{}  `pg:"master_parties,alias:mp"`
+	UUID         string    `json:"uuid" pg:"uuid,pk"`
+	Name         string    `json:"name" pg:"name" validate:"required"`
+	ContactName  string    `json:"contactName" pg:"contact_name"`
+	AddressLine1 string    `json:"addressLine1" pg:"address_line1" validate:"required"`
+	AddressLine2 string    `json:"addressLine2" pg:"address_line2"`
+	AddressLine3 string    `json:"addressLine3" pg:"address_line3"`
+	City         string    `json:"city" pg:"city"`
+	Postcode     string    `json:"postcode" pg:"postcode"`
+	CountryCode  string    `json:"countryCode" pg:"country_code" validate:"required,len=2"`
+	TaxID        string    `json:"taxId" pg:"tax_id"`
+	Phone        string    `json:"phone" pg:"phone"`
+	Email        string    `json:"email" pg:"email" validate:"omitempty,email"`
+	AccountNo    string    `json:"accountNo" pg:"account_no"`
+	Roles        []string  `json:"roles" pg:"roles,array" validate:"min=1,dive,oneof=shipper consignee notify"`
+	IsActive     bool      `json:"isActive" pg:"is_active,use_zero"`
+	CreatedAt    time.Time `json:"createdAt" pg:"created_at"`
+	UpdatedAt    time.Time `json:"updatedAt" pg:"updated_at"`
+}
+
+func (p *Party) Bind(r *http.Request) error {
+	return nil
+}
+
+// HasRole reports whether the party may be used as role.
+func (p *Party) HasRole(role string) bool {
+	for _, r := range p.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// TextBlock is the party as typed in a name and address box: the name, the address lines,
+// city and postcode, country, then the tax ID and contact details when known.
+func (p *Party) TextBlock() string {
+	lines := []string{p.Name}
+	for _, line := range []string{p.AddressLine1, p.AddressLine2, p.AddressLine3, strings.TrimSpace(p.City + " " + p.Postcode), p.CountryCode} {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if p.TaxID != "" {
+		lines = append(lines, "TAX ID: "+p.TaxID)
+	}
+	contact := []string{}
+	if p.ContactName != "" {
+		contact = append(contact, p.ContactName)
+	}
+	if p.Phone != "" {
+		contact = append(contact, "TEL: "+p.Phone)
+	}
+	if p.Email != "" {
+		contact = append(contact, p.Email)
+	}
+	if len(contact) > 0 {
+		lines = append(lines, strings.Join(contact, " "))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Address is the address lines of the party on one line.
+func (p *Party) Address() string {
+	parts := []string{}
+	for _, line := range []string{p.AddressLine1, p.AddressLine2, p.AddressLine3} {
+		if line = strings.TrimSpace(line); line != "" {
+			parts = append(parts, line)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// PartySearch filters the address book. Query matches the name, account number, tax ID or
+// city; Role and CountryCode must match exactly when set.
+type PartySearch struct {
+	Query       string
+	Role        string
+	CountryCode string
+	Limit       int
+}
+
+// PartySuggestion is the short form of a party listed while typing a name.
+type PartySuggestion struct {
+	UUID        string `json:"uuid"`
+	Name        string `json:"name"`
+	AccountNo   string `json:"accountNo"`
+	City        string `json:"city"`
+	CountryCode string `json:"countryCode"`
+	TextBlock   string `json:"textBlock"`
+}
@@ -0,0 +1,150 @@
+package setting
+
+import (
+	"context"
+	"hpc-express-service/common"
+	"time"
+
+	"github.com/go-pg/pg/v9"
+	"github.com/go-pg/pg/v9/orm"
+	"github.com/google/uuid"
+)
+
+type PartyRepository interface {
+	CreateParty(ctx context.Context, party *Party) (*Party, error)
+	SearchParties(ctx context.Context, search PartySearch) ([]Party, error)
+	GetPartyByUUID(ctx context.Context, uuid string) (*Party, error)
+	GetPartyByAccountNo(ctx context.Context, accountNo string) (*Party, error)
+	UpdateParty(ctx context.Context, party *Party) (*Party, error)
+	DeleteParty(ctx context.Context, uuid string) error
+	AutocompleteParties(ctx context.Context, search PartySearch) ([]Party, error)
+}
+
+type partyRepository struct{}
+
+func NewPartyRepository() PartyRepository {
+	return &partyRepository{}
+}
+
+func (r *partyRepository) CreateParty(ctx context.Context, party *Party) (*Party, error) {
+	db, err := common.GetQer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	party.UUID = uuid.New().String()
+	party.CreatedAt = time.Now()
+	party.UpdatedAt = time.Now()
+	_, err = db.Model(party).Insert()
+	return party, err
+}
+
+func (r *partyRepository) SearchParties(ctx context.Context, search PartySearch) ([]Party, error) {
+	db, err := common.GetQer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var parties []Party
+	q := db.Model(&parties).Order("name")
+	if search.Query != "" {
+		q = q.WhereGroup(func(q *orm.Query) (*orm.Query, error) {
+			q = q.WhereOr("name ILIKE ?", "%"+search.Query+"%").
+				WhereOr("account_no ILIKE ?", search.Query+"%").
+				WhereOr("tax_id ILIKE ?", search.Query+"%").
+				WhereOr("city ILIKE ?", search.Query+"%")
+			return q, nil
+		})
+	}
+	if search.Role != "" {
+		q = q.Where("? = ANY(roles)", search.Role)
+	}
+	if search.CountryCode != "" {
+		q = q.Where("country_code = ?", search.CountryCode)
+	}
+	if search.Limit > 0 {
+		q = q.Limit(search.Limit)
+	}
+	err = q.Select()
+	return parties, err
+}
+
+// GetPartyByUUID returns the party, or nil when there is none.
+func (r *partyRepository) GetPartyByUUID(ctx context.Context, uuid string) (*Party, error) {
+	db, err := common.GetQer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	party := new(Party)
+	err = db.Model(party).Where("uuid = ?", uuid).Select()
+	if err == pg.ErrNoRows {
+		return nil, nil
+	}
+	return party, err
+}
+
+// GetPartyByAccountNo returns the active party with the account number, or nil.
+func (r *partyRepository) GetPartyByAccountNo(ctx context.Context, accountNo string) (*Party, error) {
+	db, err := common.GetQer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	party := new(Party)
+	err = db.Model(party).
+		Where("account_no = ?", accountNo).
+		Where("is_active = ?", true).
+		Limit(1).
+		Select()
+	if err == pg.ErrNoRows {
+		return nil, nil
+	}
+	return party, err
+}
+
+// UpdateParty saves the party's details. Whether it is active is left as it is.
+func (r *partyRepository) UpdateParty(ctx context.Context, party *Party) (*Party, error) {
+	db, err := common.GetQer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	party.UpdatedAt = time.Now()
+	_, err = db.Model(party).ExcludeColumn("created_at", "is_active").WherePK().Returning("is_active").Update()
+	return party, err
+}
+
+// DeleteParty deactivates the party rather than deleting it, as saved forms may still
+// reference it.
+func (r *partyRepository) DeleteParty(ctx context.Context, uuid string) error {
+	db, err := common.GetQer(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = db.Model(&Party{}).
+		Set("is_active = ?", false).
+		Set("updated_at = ?", time.Now()).
+		Where("uuid = ?", uuid).
+		Update()
+	return err
+}
+
+// AutocompleteParties returns active parties whose name or account number starts with the
+// query, then those with the query anywhere in the name.
+func (r *partyRepository) AutocompleteParties(ctx context.Context, search PartySearch) ([]Party, error) {
+	db, err := common.GetQer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var parties []Party
+	q := db.Model(&parties).
+		Where("is_active = ?", true).
+		WhereGroup(func(q *orm.Query) (*orm.Query, error) {
+			q = q.WhereOr("name ILIKE ?", "%"+search.Query+"%").
+				WhereOr("account_no ILIKE ?", search.Query+"%")
+			return q, nil
+		}).
+		OrderExpr("(name ILIKE ? OR account_no ILIKE ?) DESC, name", search.Query+"%", search.Query+"%").
+		Limit(search.Limit)
+	if search.Role != "" {
+		q = q.Where("? = ANY(roles)", search.Role)
+	}
+	err = q.Select()
+	return parties, err
+}
@@ -0,0 +1,189 @@
+package setting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultPartySuggestions = 10
+	maxPartySuggestions     = 50
+)
+
+type PartyService interface {
+	CreateParty(ctx context.Context, party *Party) (*Party, error)
+	SearchParties(ctx context.Context, search PartySearch) ([]Party, error)
+	GetPartyByUUID(ctx context.Context, uuid string) (*Party, error)
+	UpdateParty(ctx context.Context, party *Party) (*Party, error)
+	DeleteParty(ctx context.Context, uuid string) error
+	AutocompleteParties(ctx context.Context, search PartySearch) ([]PartySuggestion, error)
+	ResolveParty(ctx context.Context, partyUUID, role string) (*Party, error)
+	FindPartyByRef(ctx context.Context, ref, role string) (*Party, error)
+}
+
+type partyService struct {
+	repo           PartyRepository
+	contextTimeout time.Duration
+}
+
+func NewPartyService(repo PartyRepository, timeout time.Duration) PartyService {
+	return &partyService{
+		repo:           repo,
+		contextTimeout: timeout,
+	}
+}
+
+func (s *partyService) CreateParty(ctx context.Context, party *Party) (*Party, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	normalizeParty(party)
+	party.IsActive = true
+
+	return s.repo.CreateParty(ctx, party)
+}
+
+func (s *partyService) SearchParties(ctx context.Context, search PartySearch) ([]Party, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	search.Query = strings.TrimSpace(search.Query)
+	search.CountryCode = strings.ToUpper(strings.TrimSpace(search.CountryCode))
+	return s.repo.SearchParties(ctx, search)
+}
+
+func (s *partyService) GetPartyByUUID(ctx context.Context, uuid string) (*Party, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+	return s.repo.GetPartyByUUID(ctx, uuid)
+}
+
+func (s *partyService) UpdateParty(ctx context.Context, party *Party) (*Party, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	if party.UUID == "" {
+		return nil, errors.New("uuid is required")
+	}
+	normalizeParty(party)
+
+	return s.repo.UpdateParty(ctx, party)
+}
+
+func (s *partyService) DeleteParty(ctx context.Context, uuid string) error {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+	return s.repo.DeleteParty(ctx, uuid)
+}
+
+// AutocompleteParties lists the parties matching what was typed so far, best matches first.
+// Nothing is listed until at least two characters are typed.
+func (s *partyService) AutocompleteParties(ctx context.Context, search PartySearch) ([]PartySuggestion, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	suggestions := []PartySuggestion{}
+	search.Query = strings.TrimSpace(search.Query)
+	if len([]rune(search.Query)) < 2 {
+		return suggestions, nil
+	}
+	if search.Limit <= 0 {
+		search.Limit = defaultPartySuggestions
+	}
+	if search.Limit > maxPartySuggestions {
+		search.Limit = maxPartySuggestions
+	}
+
+	parties, err := s.repo.AutocompleteParties(ctx, search)
+	if err != nil {
+		return nil, err
+	}
+	for i := range parties {
+		p := &parties[i]
+		suggestions = append(suggestions, PartySuggestion{
+			UUID:        p.UUID,
+			Name:        p.Name,
+			AccountNo:   p.AccountNo,
+			City:        p.City,
+			CountryCode: p.CountryCode,
+			TextBlock:   p.TextBlock(),
+		})
+	}
+	return suggestions, nil
+}
+
+// ResolveParty returns the party a form references as role, failing when it does not exist,
+// is no longer active or may not be used as role.
+func (s *partyService) ResolveParty(ctx context.Context, partyUUID, role string) (*Party, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	notFound := fmt.Errorf("party '%s' is not in the address book", partyUUID)
+	if _, err := uuid.Parse(partyUUID); err != nil {
+		return nil, notFound
+	}
+	party, err := s.repo.GetPartyByUUID(ctx, partyUUID)
+	if err != nil {
+		return nil, err
+	}
+	if party == nil || !party.IsActive {
+		return nil, notFound
+	}
+	if !party.HasRole(role) {
+		return nil, fmt.Errorf("party '%s' is not a %s", party.Name, role)
+	}
+	return party, nil
+}
+
+// FindPartyByRef returns the active party a manifest row refers to as role by UUID or account
+// number, or nil when there is none. A party that may not be used as role is an error.
+func (s *partyService) FindPartyByRef(ctx context.Context, ref, role string) (*Party, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return nil, nil
+	}
+	var party *Party
+	if _, err := uuid.Parse(ref); err == nil {
+		if party, err = s.repo.GetPartyByUUID(ctx, ref); err != nil {
+			return nil, err
+		}
+		if party != nil && !party.IsActive {
+			party = nil
+		}
+	}
+	if party == nil {
+		var err error
+		if party, err = s.repo.GetPartyByAccountNo(ctx, ref); err != nil || party == nil {
+			return nil, err
+		}
+	}
+	if !party.HasRole(role) {
+		return nil, fmt.Errorf("party '%s' is not a %s", party.Name, role)
+	}
+	return party, nil
+}
+
+func normalizeParty(party *Party) {
+	party.Name = strings.TrimSpace(party.Name)
+	party.CountryCode = strings.ToUpper(strings.TrimSpace(party.CountryCode))
+	party.Email = strings.TrimSpace(party.Email)
+	party.AccountNo = strings.TrimSpace(party.AccountNo)
+	party.TaxID = strings.TrimSpace(party.TaxID)
+	roles := []string{}
+	seen := map[string]bool{}
+	for _, role := range party.Roles {
+		if role = strings.ToLower(strings.TrimSpace(role)); role != "" && !seen[role] {
+			seen[role] = true
+			roles = append(roles, role)
+		}
+	}
+	party.Roles = roles
+}
@@ -0,0 +1,130 @@
+package setting
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	ChargeBasisPerKg  = "per_kg"
+	ChargeBasisPerAwb = "per_awb"
+)
+
+const (
+	ChargeOwnerCarrier = "carrier"
+	ChargeOwnerAgent   = "agent"
+)
+
+// AirlineRateCard is an airline's tariff from Origin to Destination for one rate class, valid
+// between EffectiveFrom and EffectiveTo. A general cargo card (N) prices the first break as a
+// normal rate and the later ones as quantity rates.
+type AirlineRateCard struct {
+	tableName     struct{}        `pg:"airline_rate_cards,alias:arc"`
+	UUID          string          `json:"uuid" pg:"uuid,pk"`
+	AirlineUUID   string          `json:"airlineUuid" pg:"airline_uuid" validate:"required"`
+	Origin        string          `json:"origin" pg:"origin" validate:"required,len=3"`
+	Destination   string          `json:"destination" pg:"destination" validate:"required,len=3"`
+	RateClass     string          `json:"rateClass" pg:"rate_class" validate:"required,oneof=N C S R"`
+	Currency      string          `json:"currency" pg:"currency" validate:"required,len=3"`
+	MinimumCharge decimal.Decimal `json:"minimumCharge" pg:"minimum_charge,use_zero"`
+	Breaks        []*RateBreak    `json:"breaks" pg:"breaks"`
+	EffectiveFrom time.Time       `json:"effectiveFrom" pg:"effective_from" validate:"required"`
+	EffectiveTo   *time.Time      `json:"effectiveTo" pg:"effective_to"`
+	IsActive      bool            `json:"isActive" pg:"is_active,use_zero"`
+	CreatedAt     time.Time       `json:"createdAt" pg:"created_at"`
+	UpdatedAt     time.Time       `json:"updatedAt" pg:"updated_at"`
+}
+
+// RateBreak charges Rate per kilo for a chargeable weight of FromWeight kilos or more, up to
+// the next break. The first break starts at 0.
+type RateBreak struct {
+	FromWeight decimal.Decimal `json:"fromWeight"`
+	Rate       decimal.Decimal `json:"rate"`
+}
+
+func (c *AirlineRateCard) Bind(r *http.Request) error {
+	return nil
+}
+
+// Calculate returns the rate class and rate of a chargeable weight in kilos, the weight they
+// are charged on and the charge they come to. When a higher break comes cheaper at its own
+// weight, the weight is charged at that break instead. A charge under the minimum is the
+// minimum, rated M.
+func (c *AirlineRateCard) Calculate(weight decimal.Decimal) (string, decimal.Decimal, decimal.Decimal, decimal.Decimal) {
+	current := 0
+	for i, b := range c.Breaks {
+		if i > 0 && weight.LessThan(b.FromWeight) {
+			break
+		}
+		current = i
+	}
+
+	var rate decimal.Decimal
+	if len(c.Breaks) > 0 {
+		rate = c.Breaks[current].Rate
+	}
+	chargedWeight := weight
+	total := rate.Mul(weight).Round(2)
+	charged := current
+	for i := current + 1; i < len(c.Breaks); i++ {
+		b := c.Breaks[i]
+		if t := b.Rate.Mul(b.FromWeight).Round(2); t.LessThan(total) {
+			rate, chargedWeight, total, charged = b.Rate, b.FromWeight, t, i
+		}
+	}
+
+	if total.LessThan(c.MinimumCharge) {
+		return "M", c.MinimumCharge, weight, c.MinimumCharge
+	}
+	rateClass := c.RateClass
+	if c.RateClass == "N" && charged > 0 {
+		rateClass = "Q"
+	}
+	return rateClass, rate, chargedWeight, total
+}
+
+// AirlineOtherCharge is a charge an airline bills on every AWB, such as the AWB fee or the
+// fuel surcharge. An empty Origin applies it at every station.
+type AirlineOtherCharge struct {
+	tableName     struct{}        `pg:"airline_other_charges,alias:aoc"`
+	UUID          string          `json:"uuid" pg:"uuid,pk"`
+	AirlineUUID   string          `json:"airlineUuid" pg:"airline_uuid" validate:"required"`
+	Origin        string          `json:"origin" pg:"origin" validate:"omitempty,len=3"`
+	Code          string          `json:"code" pg:"code" validate:"required,len=2,alpha"`
+	Name          string          `json:"name" pg:"name" validate:"required"`
+	Basis         string          `json:"basis" pg:"basis" validate:"required,oneof=per_kg per_awb"`
+	Owner         string          `json:"owner" pg:"owner" validate:"required,oneof=carrier agent"`
+	Amount        decimal.Decimal `json:"amount" pg:"amount,use_zero"`
+	MinimumAmount decimal.Decimal `json:"minimumAmount" pg:"minimum_amount,use_zero"`
+	IsActive      bool            `json:"isActive" pg:"is_active,use_zero"`
+	CreatedAt     time.Time       `json:"createdAt" pg:"created_at"`
+	UpdatedAt     time.Time       `json:"updatedAt" pg:"updated_at"`
+}
+
+func (c *AirlineOtherCharge) Bind(r *http.Request) error {
+	return nil
+}
+
+// Key is the charge as written on the AWB: the IATA code followed by C when it is due carrier
+// or A when it is due agent, such as MYC.
+func (c *AirlineOtherCharge) Key() string {
+	if c.Owner == ChargeOwnerAgent {
+		return strings.ToUpper(c.Code) + "A"
+	}
+	return strings.ToUpper(c.Code) + "C"
+}
+
+// Calculate returns the charge for an AWB of the given chargeable weight in kilos.
+func (c *AirlineOtherCharge) Calculate(weight decimal.Decimal) decimal.Decimal {
+	total := c.Amount
+	if c.Basis == ChargeBasisPerKg {
+		total = c.Amount.Mul(weight).Round(2)
+	}
+	if total.LessThan(c.MinimumAmount) {
+		total = c.MinimumAmount
+	}
+	return total
+}
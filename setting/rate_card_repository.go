@@ -0,0 +1,183 @@
+package setting
+
+import (
+	"context"
+	"hpc-express-service/common"
+	"time"
+
+	"github.com/go-pg/pg/v9"
+	"github.com/google/uuid"
+)
+
+type RateCardRepository interface {
+	CreateRateCard(ctx context.Context, card *AirlineRateCard) (*AirlineRateCard, error)
+	GetAllRateCards(ctx context.Context, airlineUUID string) ([]AirlineRateCard, error)
+	GetRateCardByUUID(ctx context.Context, uuid string) (*AirlineRateCard, error)
+	UpdateRateCard(ctx context.Context, card *AirlineRateCard) (*AirlineRateCard, error)
+	DeleteRateCard(ctx context.Context, uuid string) error
+	GetEffectiveRateCard(ctx context.Context, airlineUUID, origin, destination, rateClass string, on time.Time) (*AirlineRateCard, error)
+	CreateOtherCharge(ctx context.Context, charge *AirlineOtherCharge) (*AirlineOtherCharge, error)
+	GetAllOtherCharges(ctx context.Context, airlineUUID string) ([]AirlineOtherCharge, error)
+	GetOtherChargeByUUID(ctx context.Context, uuid string) (*AirlineOtherCharge, error)
+	UpdateOtherCharge(ctx context.Context, charge *AirlineOtherCharge) (*AirlineOtherCharge, error)
+	DeleteOtherCharge(ctx context.Context, uuid string) error
+	GetActiveOtherCharges(ctx context.Context, airlineUUID, origin string) ([]AirlineOtherCharge, error)
+}
+
+type rateCardRepository struct{}
+
+func NewRateCardRepository() RateCardRepository {
+	return &rateCardRepository{}
+}
+
+func (r *rateCardRepository) CreateRateCard(ctx context.Context, card *AirlineRateCard) (*AirlineRateCard, error) {
+	db, err := common.GetQer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	card.UUID = uuid.New().String()
+	card.CreatedAt = time.Now()
+	card.UpdatedAt = time.Now()
+	_, err = db.Model(card).Insert()
+	return card, err
+}
+
+func (r *rateCardRepository) GetAllRateCards(ctx context.Context, airlineUUID string) ([]AirlineRateCard, error) {
+	db, err := common.GetQer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var cards []AirlineRateCard
+	q := db.Model(&cards).Order("origin", "destination", "rate_class", "effective_from DESC")
+	if airlineUUID != "" {
+		q = q.Where("airline_uuid = ?", airlineUUID)
+	}
+	err = q.Select()
+	return cards, err
+}
+
+func (r *rateCardRepository) GetRateCardByUUID(ctx context.Context, uuid string) (*AirlineRateCard, error) {
+	db, err := common.GetQer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	card := new(AirlineRateCard)
+	err = db.Model(card).Where("uuid = ?", uuid).Select()
+	return card, err
+}
+
+func (r *rateCardRepository) UpdateRateCard(ctx context.Context, card *AirlineRateCard) (*AirlineRateCard, error) {
+	db, err := common.GetQer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	card.UpdatedAt = time.Now()
+	_, err = db.Model(card).ExcludeColumn("created_at").WherePK().Update()
+	return card, err
+}
+
+func (r *rateCardRepository) DeleteRateCard(ctx context.Context, uuid string) error {
+	db, err := common.GetQer(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = db.Model(&AirlineRateCard{}).Where("uuid = ?", uuid).Delete()
+	return err
+}
+
+// GetEffectiveRateCard returns the newest active card of the lane and rate class on the given
+// date, or nil when there is none.
+func (r *rateCardRepository) GetEffectiveRateCard(ctx context.Context, airlineUUID, origin, destination, rateClass string, on time.Time) (*AirlineRateCard, error) {
+	db, err := common.GetQer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	card := new(AirlineRateCard)
+	err = db.Model(card).
+		Where("airline_uuid = ?", airlineUUID).
+		Where("origin = ?", origin).
+		Where("destination = ?", destination).
+		Where("rate_class = ?", rateClass).
+		Where("is_active = ?", true).
+		Where("effective_from <= ?", on).
+		Where("effective_to IS NULL OR effective_to >= ?", on).
+		Order("effective_from DESC").
+		Limit(1).
+		Select()
+	if err == pg.ErrNoRows {
+		return nil, nil
+	}
+	return card, err
+}
+
+func (r *rateCardRepository) CreateOtherCharge(ctx context.Context, charge *AirlineOtherCharge) (*AirlineOtherCharge, error) {
+	db, err := common.GetQer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	charge.UUID = uuid.New().String()
+	charge.CreatedAt = time.Now()
+	charge.UpdatedAt = time.Now()
+	_, err = db.Model(charge).Insert()
+	return charge, err
+}
+
+func (r *rateCardRepository) GetAllOtherCharges(ctx context.Context, airlineUUID string) ([]AirlineOtherCharge, error) {
+	db, err := common.GetQer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var charges []AirlineOtherCharge
+	q := db.Model(&charges).Order("origin", "code")
+	if airlineUUID != "" {
+		q = q.Where("airline_uuid = ?", airlineUUID)
+	}
+	err = q.Select()
+	return charges, err
+}
+
+func (r *rateCardRepository) GetOtherChargeByUUID(ctx context.Context, uuid string) (*AirlineOtherCharge, error) {
+	db, err := common.GetQer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	charge := new(AirlineOtherCharge)
+	err = db.Model(charge).Where("uuid = ?", uuid).Select()
+	return charge, err
+}
+
+func (r *rateCardRepository) UpdateOtherCharge(ctx context.Context, charge *AirlineOtherCharge) (*AirlineOtherCharge, error) {
+	db, err := common.GetQer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	charge.UpdatedAt = time.Now()
+	_, err = db.Model(charge).ExcludeColumn("created_at").WherePK().Update()
+	return charge, err
+}
+
+func (r *rateCardRepository) DeleteOtherCharge(ctx context.Context, uuid string) error {
+	db, err := common.GetQer(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = db.Model(&AirlineOtherCharge{}).Where("uuid = ?", uuid).Delete()
+	return err
+}
+
+// GetActiveOtherCharges returns the airline's active charges for the origin, the station's own
+// charges ordered before those applying everywhere.
+func (r *rateCardRepository) GetActiveOtherCharges(ctx context.Context, airlineUUID, origin string) ([]AirlineOtherCharge, error) {
+	db, err := common.GetQer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var charges []AirlineOtherCharge
+	err = db.Model(&charges).
+		Where("airline_uuid = ?", airlineUUID).
+		Where("is_active = ?", true).
+		Where("origin = '' OR origin IS NULL OR origin = ?", origin).
+		OrderExpr("origin DESC NULLS LAST, code").
+		Select()
+	return charges, err
+}
@@ -0,0 +1,196 @@
+package setting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type RateCardService interface {
+	CreateRateCard(ctx context.Context, card *AirlineRateCard) (*AirlineRateCard, error)
+	GetAllRateCards(ctx context.Context, airlineUUID string) ([]AirlineRateCard, error)
+	GetRateCardByUUID(ctx context.Context, uuid string) (*AirlineRateCard, error)
+	UpdateRateCard(ctx context.Context, card *AirlineRateCard) (*AirlineRateCard, error)
+	DeleteRateCard(ctx context.Context, uuid string) error
+	FindRateCard(ctx context.Context, airlineUUID, origin, destination, rateClass string, on time.Time) (*AirlineRateCard, error)
+	CreateOtherCharge(ctx context.Context, charge *AirlineOtherCharge) (*AirlineOtherCharge, error)
+	GetAllOtherCharges(ctx context.Context, airlineUUID string) ([]AirlineOtherCharge, error)
+	GetOtherChargeByUUID(ctx context.Context, uuid string) (*AirlineOtherCharge, error)
+	UpdateOtherCharge(ctx context.Context, charge *AirlineOtherCharge) (*AirlineOtherCharge, error)
+	DeleteOtherCharge(ctx context.Context, uuid string) error
+	GetAirlineOtherCharges(ctx context.Context, airlineUUID, origin string) ([]AirlineOtherCharge, error)
+}
+
+type rateCardService struct {
+	repo           RateCardRepository
+	contextTimeout time.Duration
+}
+
+func NewRateCardService(repo RateCardRepository, timeout time.Duration) RateCardService {
+	return &rateCardService{
+		repo:           repo,
+		contextTimeout: timeout,
+	}
+}
+
+func (s *rateCardService) CreateRateCard(ctx context.Context, card *AirlineRateCard) (*AirlineRateCard, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	if err := validateRateCard(card); err != nil {
+		return nil, err
+	}
+	card.IsActive = true
+
+	return s.repo.CreateRateCard(ctx, card)
+}
+
+func (s *rateCardService) GetAllRateCards(ctx context.Context, airlineUUID string) ([]AirlineRateCard, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+	return s.repo.GetAllRateCards(ctx, airlineUUID)
+}
+
+func (s *rateCardService) GetRateCardByUUID(ctx context.Context, uuid string) (*AirlineRateCard, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+	return s.repo.GetRateCardByUUID(ctx, uuid)
+}
+
+func (s *rateCardService) UpdateRateCard(ctx context.Context, card *AirlineRateCard) (*AirlineRateCard, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	if card.UUID == "" {
+		return nil, errors.New("uuid is required")
+	}
+	if err := validateRateCard(card); err != nil {
+		return nil, err
+	}
+
+	return s.repo.UpdateRateCard(ctx, card)
+}
+
+func (s *rateCardService) DeleteRateCard(ctx context.Context, uuid string) error {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+	return s.repo.DeleteRateCard(ctx, uuid)
+}
+
+// FindRateCard returns the card that prices the lane and rate class on the given date, or nil.
+func (s *rateCardService) FindRateCard(ctx context.Context, airlineUUID, origin, destination, rateClass string, on time.Time) (*AirlineRateCard, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+	return s.repo.GetEffectiveRateCard(ctx, airlineUUID, strings.ToUpper(origin), strings.ToUpper(destination), strings.ToUpper(rateClass), on)
+}
+
+func (s *rateCardService) CreateOtherCharge(ctx context.Context, charge *AirlineOtherCharge) (*AirlineOtherCharge, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	if err := validateOtherCharge(charge); err != nil {
+		return nil, err
+	}
+	charge.IsActive = true
+
+	return s.repo.CreateOtherCharge(ctx, charge)
+}
+
+func (s *rateCardService) GetAllOtherCharges(ctx context.Context, airlineUUID string) ([]AirlineOtherCharge, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+	return s.repo.GetAllOtherCharges(ctx, airlineUUID)
+}
+
+func (s *rateCardService) GetOtherChargeByUUID(ctx context.Context, uuid string) (*AirlineOtherCharge, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+	return s.repo.GetOtherChargeByUUID(ctx, uuid)
+}
+
+func (s *rateCardService) UpdateOtherCharge(ctx context.Context, charge *AirlineOtherCharge) (*AirlineOtherCharge, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	if charge.UUID == "" {
+		return nil, errors.New("uuid is required")
+	}
+	if err := validateOtherCharge(charge); err != nil {
+		return nil, err
+	}
+
+	return s.repo.UpdateOtherCharge(ctx, charge)
+}
+
+func (s *rateCardService) DeleteOtherCharge(ctx context.Context, uuid string) error {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+	return s.repo.DeleteOtherCharge(ctx, uuid)
+}
+
+// GetAirlineOtherCharges returns the charges the airline bills on an AWB from the origin. A
+// charge set up for the station replaces the one of the same code set up for every station.
+func (s *rateCardService) GetAirlineOtherCharges(ctx context.Context, airlineUUID, origin string) ([]AirlineOtherCharge, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	charges, err := s.repo.GetActiveOtherCharges(ctx, airlineUUID, strings.ToUpper(origin))
+	if err != nil {
+		return nil, err
+	}
+
+	// charges are ordered with the station's own first, so the first of each code wins
+	result := []AirlineOtherCharge{}
+	seen := map[string]bool{}
+	for _, charge := range charges {
+		if seen[charge.Key()] {
+			continue
+		}
+		seen[charge.Key()] = true
+		result = append(result, charge)
+	}
+	return result, nil
+}
+
+func validateRateCard(card *AirlineRateCard) error {
+	card.Origin = strings.ToUpper(card.Origin)
+	card.Destination = strings.ToUpper(card.Destination)
+	card.RateClass = strings.ToUpper(card.RateClass)
+	card.Currency = strings.ToUpper(card.Currency)
+
+	if card.EffectiveTo != nil && card.EffectiveTo.Before(card.EffectiveFrom) {
+		return errors.New("effectiveTo must not be before effectiveFrom")
+	}
+	if card.MinimumCharge.IsNegative() {
+		return errors.New("minimumCharge must not be negative")
+	}
+	if len(card.Breaks) == 0 {
+		return errors.New("rate card requires at least one weight break")
+	}
+	for i, b := range card.Breaks {
+		if b.Rate.IsNegative() {
+			return fmt.Errorf("break %d: rate must not be negative", i+1)
+		}
+		if i == 0 && !b.FromWeight.IsZero() {
+			return errors.New("break 1: fromWeight must be 0")
+		}
+		if i > 0 && !b.FromWeight.GreaterThan(card.Breaks[i-1].FromWeight) {
+			return fmt.Errorf("break %d: fromWeight must be greater than the previous break", i+1)
+		}
+	}
+
+	return nil
+}
+
+func validateOtherCharge(charge *AirlineOtherCharge) error {
+	charge.Origin = strings.ToUpper(strings.TrimSpace(charge.Origin))
+	charge.Code = strings.ToUpper(charge.Code)
+
+	if charge.Amount.IsNegative() || charge.MinimumAmount.IsNegative() {
+		return errors.New("amounts must not be negative")
+	}
+
+	return nil
+}
@@ -0,0 +1,67 @@
+package setting
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func rateBreaks(breaks ...float64) []*RateBreak {
+	result := []*RateBreak{}
+	for i := 0; i+1 < len(breaks); i += 2 {
+		result = append(result, &RateBreak{
+			FromWeight: decimal.NewFromFloat(breaks[i]),
+			Rate:       decimal.NewFromFloat(breaks[i+1]),
+		})
+	}
+	return result
+}
+
+func TestAirlineRateCardCalculate(t *testing.T) {
+	general := &AirlineRateCard{
+		RateClass:     "N",
+		MinimumCharge: decimal.NewFromInt(1000),
+		Breaks:        rateBreaks(0, 100, 45, 80, 100, 70),
+	}
+	commodity := &AirlineRateCard{
+		RateClass: "C",
+		Breaks:    rateBreaks(0, 50, 100, 40),
+	}
+
+	tests := []struct {
+		name        string
+		card        *AirlineRateCard
+		weight      float64
+		wantClass   string
+		wantRate    float64
+		wantCharged float64
+		wantTotal   float64
+	}{
+		{"under the minimum", general, 5, "M", 1000, 5, 1000},
+		{"normal rate", general, 20, "N", 100, 20, 2000},
+		{"cheaper higher break", general, 40, "Q", 80, 45, 3600},
+		{"quantity rate", general, 50, "Q", 80, 50, 4000},
+		{"cheapest of the higher breaks", general, 95, "Q", 70, 100, 7000},
+		{"on a break", general, 100, "Q", 70, 100, 7000},
+		{"commodity card keeps its class", commodity, 90, "C", 40, 100, 4000},
+		{"no breaks", &AirlineRateCard{RateClass: "N", MinimumCharge: decimal.NewFromInt(500)}, 10, "M", 500, 10, 500},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			class, rate, charged, total := tt.card.Calculate(decimal.NewFromFloat(tt.weight))
+			if class != tt.wantClass {
+				t.Errorf("class = %s, want %s", class, tt.wantClass)
+			}
+			if !rate.Equal(decimal.NewFromFloat(tt.wantRate)) {
+				t.Errorf("rate = %s, want %v", rate, tt.wantRate)
+			}
+			if !charged.Equal(decimal.NewFromFloat(tt.wantCharged)) {
+				t.Errorf("charged weight = %s, want %v", charged, tt.wantCharged)
+			}
+			if !total.Equal(decimal.NewFromFloat(tt.wantTotal)) {
+				t.Errorf("total = %s, want %v", total, tt.wantTotal)
+			}
+		})
+	}
+}
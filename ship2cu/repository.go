@@ -2,6 +2,7 @@ package ship2cu
 
 import (
 	"context"
+	"encoding/json"
 	"hpc-express-service/utils"
 	"time"
 
@@ -13,7 +14,8 @@ type Repository interface {
 	GetMawb(ctx context.Context, timestamp string) (*utils.GetMawb, error)
 	GetShipperBrands(ctx context.Context) ([]*GetShipperBrandModel, error)
 	GetMasterHsCode(ctx context.Context) ([]*GetMasterHsCodeModel, error)
-	GetFreightData(ctx context.Context, uploadLogUUID, countryCode, currencyCode string) (*GetFreightDataModel, error)
+	GetFreightData(ctx context.Context, countryCode string) (*GetFreightDataModel, error)
+	GetColumnMapping(ctx context.Context, templateCode string) (*utils.ColumnMappingTemplate, error)
 }
 
 type repository struct {
@@ -267,38 +269,24 @@ func (r repository) GetMawb(ctx context.Context, mawb string) (*utils.GetMawb, e
 	return &x, nil
 }
 
-func (r repository) GetFreightData(ctx context.Context, uploadLogUUID, countryCode, currencyCode string) (*GetFreightDataModel, error) {
+// GetFreightData returns the freight zone rate of the origin country.
+// The exchange rate is not read here; it depends on the arrival date and comes from common.Service.RateOn.
+func (r repository) GetFreightData(ctx context.Context, countryCode string) (*GetFreightDataModel, error) {
 	db := ctx.Value("postgreSQLConn").(*pg.DB)
 	ctx, _ = context.WithTimeout(context.Background(), 5*time.Second)
 
 	x := GetFreightDataModel{}
 	_, err := db.QueryOneContext(ctx, pg.Scan(
 		&x.FreightZone,
-		&x.FreightRate,
 	), `
 			SELECT (
 				SELECT 
 					rate
 				FROM public.master_inbound_express_freight_zones 
-				WHERE country_code = ?1
+				WHERE country_code = ?0
 				LIMIT 1
-			) AS freight_zone,
-			(
-				SELECT
-						CASE
-								WHEN mct."type" = 'inbound' THEN import_exchange_rate / cer.ratio::numeric
-								WHEN mct."type" = 'outbound' THEN export_exchange_rate / cer.ratio::numeric
-								ELSE 0
-						end as exchange_rate
-				FROM
-						ship2cu.customs_exchange_rate cer
-						join public.tbl_upload_loggings tul on tul.uuid = ?0
-						join public.master_convert_templates mct on mct.code  = tul.template_code
-				WHERE
-						cer.currency_code = ?2
-				LIMIT 1
-			) AS freight_rate
-	`, uploadLogUUID, countryCode, currencyCode)
+			) AS freight_zone
+	`, countryCode)
 
 	if err != nil {
 		return nil, err
@@ -306,3 +294,35 @@ func (r repository) GetFreightData(ctx context.Context, uploadLogUUID, countryCo
 
 	return &x, nil
 }
+
+func (r repository) GetColumnMapping(ctx context.Context, templateCode string) (*utils.ColumnMappingTemplate, error) {
+	db := ctx.Value("postgreSQLConn").(*pg.DB)
+	ctx, _ = context.WithTimeout(context.Background(), 5*time.Second)
+
+	var mapping string
+	_, err := db.QueryOneContext(ctx, pg.Scan(&mapping), `
+			SELECT COALESCE(mct.mapping::text, '')
+			FROM public.master_convert_templates mct
+			WHERE mct.code = ?
+			AND mct.deleted_at IS NULL
+			LIMIT 1
+	`, templateCode)
+
+	if err != nil {
+		if err == pg.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if mapping == "" {
+		return nil, nil
+	}
+
+	x := utils.ColumnMappingTemplate{}
+	if err := json.Unmarshal([]byte(mapping), &x); err != nil {
+		return nil, err
+	}
+
+	return &x, nil
+}
@@ -1,148 +1,112 @@
 package ship2cu
 
 import (
-	"bytes"
 	"context"
 	"errors"
-	"fmt"
+	"hpc-express-service/common"
+	"hpc-express-service/hsmatch"
+	"hpc-express-service/setting"
 	"hpc-express-service/utils"
 	"log"
 	"time"
-
-	"github.com/xuri/excelize/v2"
 )
 
+// TemplateCode is the master_convert_templates code handled by this converter.
+const TemplateCode = "SHIP2CU"
+
 type Service interface {
-	ConvertToPreImportDetails(ctx context.Context, uploadLogUUID string, fileBytes []byte) ([]*utils.InsertPreImportDetailManifestModel, error)
+	TemplateCode() string
+	ColumnMapping(ctx context.Context) (*utils.ColumnMappingTemplate, error)
+	ConvertToPreImportDetails(ctx context.Context, uploadLogUUID string, arrivalDate time.Time, fileBytes []byte) ([]*utils.InsertPreImportDetailManifestModel, error)
+	ConvertRows(ctx context.Context, uploadLogUUID string, arrivalDate time.Time, rows []*utils.InsertPreImportDetailManifestModel) ([]*utils.InsertPreImportDetailManifestModel, error)
 }
 
 type service struct {
 	selfRepo       Repository
 	contextTimeout time.Duration
+	commonSvc      common.Service
+	aliasSvc       setting.HsCodeAliasService
+	matchThreshold float64
 }
 
+// NewService creates the SHIP2CU converter. Goods with a learned alias get the alias HS code;
+// the rest are matched to the HS master fuzzily and assigned when the best score reaches
+// matchThreshold (hsmatch.DefaultThreshold when zero).
 func NewService(
 	selfRepo Repository,
 	timeout time.Duration,
+	commonSvc common.Service,
+	aliasSvc setting.HsCodeAliasService,
+	matchThreshold float64,
 ) Service {
+	if matchThreshold <= 0 {
+		matchThreshold = hsmatch.DefaultThreshold
+	}
 	return &service{
 		selfRepo:       selfRepo,
 		contextTimeout: timeout,
+		commonSvc:      commonSvc,
+		aliasSvc:       aliasSvc,
+		matchThreshold: matchThreshold,
 	}
 }
 
-func (s *service) ConvertToPreImportDetails(ctx context.Context, uploadLogUUID string, fileBytes []byte) ([]*utils.InsertPreImportDetailManifestModel, error) {
-	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
-	defer cancel()
-
-	file := bytes.NewReader(fileBytes)
-
-	if file.Len() == 0 {
-		return nil, errors.New("empty")
-	}
+func (s *service) TemplateCode() string {
+	return TemplateCode
+}
 
-	x, err := excelize.OpenReader(file)
+// ColumnMapping returns the mapping stored on the SHIP2CU template, or the built-in layout when none is stored.
+func (s *service) ColumnMapping(ctx context.Context) (*utils.ColumnMappingTemplate, error) {
+	mapping, err := s.selfRepo.GetColumnMapping(ctx, TemplateCode)
 	if err != nil {
+		log.Println("GetColumnMapping: ", err.Error())
 		return nil, err
 	}
+	if mapping == nil {
+		return defaultColumnMapping, nil
+	}
 
-	// Validate Sheet name
-	sheetName := "Output" //TODO:
+	return mapping, nil
+}
 
-	rows, err := x.GetRows(sheetName)
+// ConvertToPreImportDetails converts a SHIP2CU manifest, valuing goods at the customs rate in force on arrivalDate.
+func (s *service) ConvertToPreImportDetails(ctx context.Context, uploadLogUUID string, arrivalDate time.Time, fileBytes []byte) ([]*utils.InsertPreImportDetailManifestModel, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	mapping, err := s.ColumnMapping(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	if len(rows) == 0 {
-		return nil, errors.New("Excel file is empty!")
+	rows, report, err := utils.ReadMappedSheet[utils.InsertPreImportDetailManifestModel](fileBytes, mapping)
+	if err != nil {
+		return nil, err
 	}
-
-	headerRow := rows[0]
-	if err := validateHeadersUploadPreImportManifests(headerRow); err != nil {
-		return nil, fmt.Errorf("Header validation failed: %vs", err)
+	if report.HasIssues() {
+		return nil, report
 	}
 
-	list := []*UploadManifestModel{}
-	resultMap := make(map[string]int64)
-	// var mawb, countryCode, currencyCode string
-	var countryCode, currencyCode string
-	var hawbTotal int64
-	for j := 2; j <= len(rows); j++ {
-		data := UploadManifestModel{}
-
-		data.Mawb, _ = x.GetCellValue(sheetName, fmt.Sprintf("B%d", j))
-		data.BagNo, _ = x.GetCellValue(sheetName, fmt.Sprintf("C%d", j))
-		data.Hawb, _ = x.GetCellValue(sheetName, fmt.Sprintf("D%d", j))
-		data.HsCode, _ = x.GetCellValue(sheetName, fmt.Sprintf("E%d", j))
-		data.Origin, _ = x.GetCellValue(sheetName, fmt.Sprintf("F%d", j))
-		data.ShipperName, _ = x.GetCellValue(sheetName, fmt.Sprintf("G%d", j))
-		data.ConsigneeName, _ = x.GetCellValue(sheetName, fmt.Sprintf("H%d", j))
-
-		wgt, _ := x.GetCellValue(sheetName, fmt.Sprintf("I%d", j))
-		data.WgtValue = utils.ConvertStringToFloat(wgt)
-
-		data.WgtUnit, _ = x.GetCellValue(sheetName, fmt.Sprintf("J%d", j))
-		data.Packaging, _ = x.GetCellValue(sheetName, fmt.Sprintf("K%d", j))
-		data.ShipperAddress, _ = x.GetCellValue(sheetName, fmt.Sprintf("L%d", j))
-		data.ConsigneeAddress, _ = x.GetCellValue(sheetName, fmt.Sprintf("M%d", j))
-		data.Province, _ = x.GetCellValue(sheetName, fmt.Sprintf("N%d", j))
-		data.District, _ = x.GetCellValue(sheetName, fmt.Sprintf("O%d", j))
-		data.Postcode, _ = x.GetCellValue(sheetName, fmt.Sprintf("P%d", j))
-		data.Pcs, _ = x.GetCellValue(sheetName, fmt.Sprintf("Q%d", j))
-
-		qty, _ := x.GetCellValue(sheetName, fmt.Sprintf("R%d", j))
-		data.Qty = utils.ConvertStringToInt(qty)
-
-		data.Goods, _ = x.GetCellValue(sheetName, fmt.Sprintf("S%d", j))
-		data.GoodsEN, _ = x.GetCellValue(sheetName, fmt.Sprintf("T%d", j))
-		data.Currency, _ = x.GetCellValue(sheetName, fmt.Sprintf("U%d", j))
-		totalPrice, _ := x.GetCellValue(sheetName, fmt.Sprintf("V%d", j))
-		data.TotalPrice = utils.ConvertStringToFloat(totalPrice)
-
-		fob, _ := x.GetCellValue(sheetName, fmt.Sprintf("W%d", j))
-		data.FOB = utils.ConvertStringToFloat(fob)
-
-		freight, _ := x.GetCellValue(sheetName, fmt.Sprintf("X%d", j))
-		data.Freight = utils.ConvertStringToFloat(freight)
-
-		insurance, _ := x.GetCellValue(sheetName, fmt.Sprintf("Y%d", j))
-		data.Insurance = utils.ConvertStringToFloat(insurance)
-
-		cif, _ := x.GetCellValue(sheetName, fmt.Sprintf("Z%d", j))
-		data.CIF = utils.ConvertStringToFloat(cif)
-
-		data.Cat, _ = x.GetCellValue(sheetName, fmt.Sprintf("AA%d", j))
-
-		duty, _ := x.GetCellValue(sheetName, fmt.Sprintf("AB%d", j))
-		data.Duty = utils.ConvertStringToFloat(duty)
-
-		vat, _ := x.GetCellValue(sheetName, fmt.Sprintf("AC%d", j))
-		data.Vat = utils.ConvertStringToFloat(vat)
-
-		cost, _ := x.GetCellValue(sheetName, fmt.Sprintf("AD%d", j))
-		data.Cost = utils.ConvertStringToFloat(cost)
-
-		data.LocalTrackingNo, _ = x.GetCellValue(sheetName, fmt.Sprintf("AE%d", j))
-		data.Reference1, _ = x.GetCellValue(sheetName, fmt.Sprintf("AF%d", j))
-		data.Reference2, _ = x.GetCellValue(sheetName, fmt.Sprintf("AG%d", j))
-		data.CustomerCode, _ = x.GetCellValue(sheetName, fmt.Sprintf("AH%d", j))
-		data.ShipperTel, _ = x.GetCellValue(sheetName, fmt.Sprintf("AI%d", j))
-		data.ConsigneeTel, _ = x.GetCellValue(sheetName, fmt.Sprintf("AJ%d", j))
-		data.Dimension, _ = x.GetCellValue(sheetName, fmt.Sprintf("AK%d", j))
-		data.DimensionRepacking, _ = x.GetCellValue(sheetName, fmt.Sprintf("AL%d", j))
-
-		width, _ := x.GetCellValue(sheetName, fmt.Sprintf("AM%d", j))
-		data.Width = utils.ConvertStringToFloat(width)
+	return s.convertRows(ctx, uploadLogUUID, arrivalDate, utils.MappedData(rows))
+}
 
-		length, _ := x.GetCellValue(sheetName, fmt.Sprintf("AN%d", j))
-		data.Length = utils.ConvertStringToFloat(length)
+// ConvertRows values and classifies rows read with another template's column mapping the way
+// a SHIP2CU manifest is: HS code, customs rate on arrivalDate, freight, insurance and CIF.
+func (s *service) ConvertRows(ctx context.Context, uploadLogUUID string, arrivalDate time.Time, rows []*utils.InsertPreImportDetailManifestModel) ([]*utils.InsertPreImportDetailManifestModel, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
 
-		height, _ := x.GetCellValue(sheetName, fmt.Sprintf("AO%d", j))
-		data.Height = utils.ConvertStringToFloat(height)
+	return s.convertRows(ctx, uploadLogUUID, arrivalDate, rows)
+}
 
-		volumeWeight, _ := x.GetCellValue(sheetName, fmt.Sprintf("AP%d", j))
-		data.VolumeWeight = utils.ConvertStringToFloat(volumeWeight)
+func (s *service) convertRows(ctx context.Context, uploadLogUUID string, arrivalDate time.Time, rows []*utils.InsertPreImportDetailManifestModel) ([]*utils.InsertPreImportDetailManifestModel, error) {
+	list := []*UploadManifestModel{}
+	resultMap := make(map[string]int64)
+	var countryCode, currencyCode string
+	goods := []string{}
+	for _, row := range rows {
+		data := newUploadManifestModel(row)
+		goods = append(goods, data.Goods)
 
 		if _, exists := resultMap[data.Mawb]; !exists {
 			resultMap[data.Mawb] = 1
@@ -153,9 +117,7 @@ func (s *service) ConvertToPreImportDetails(ctx context.Context, uploadLogUUID s
 		countryCode = data.Origin
 		currencyCode = data.Currency
 
-		// mawb = data.Mawb
-		hawbTotal++
-		list = append(list, &data)
+		list = append(list, data)
 	}
 
 	if len(resultMap) > 1 {
@@ -183,21 +145,64 @@ func (s *service) ConvertToPreImportDetails(ctx context.Context, uploadLogUUID s
 		return nil, err
 	}
 
+	aliases, err := s.aliasSvc.GetHsCodeAliasSet(ctx, goods)
+	if err != nil {
+		log.Println("GetHsCodeAliasSet: ", err.Error())
+		return nil, err
+	}
+
 	// Half
-	freightConfig, err := s.selfRepo.GetFreightData(ctx, uploadLogUUID, countryCode, currencyCode)
+	freightConfig, err := s.selfRepo.GetFreightData(ctx, countryCode)
 	if err != nil {
 		log.Println("GetFreightData: ", err.Error())
 		return nil, err
 	}
 
+	rate, err := s.commonSvc.RateOn(ctx, currencyCode, arrivalDate)
+	if err != nil {
+		log.Println("RateOn: ", err.Error())
+		return nil, err
+	}
+	freightConfig.FreightRate = rate.ImportExchangeRate
+
+	matcher := hsmatch.NewMatcher(masterHsCodeData, func(row *GetMasterHsCodeModel) string { return row.GoodsEN })
+	matcher.Threshold = s.matchThreshold
+
+	unitCodes := map[string]string{}
+	for _, row := range masterHsCodeData {
+		if _, exists := unitCodes[row.TariffCode]; !exists {
+			unitCodes[row.TariffCode] = row.QuantityUnitCode
+		}
+	}
+
 	details := []*utils.InsertPreImportDetailManifestModel{}
+	aliasUsage := map[string]int64{}
 	for k, v := range list {
 
 		if err != nil {
 			log.Println("dataOther: ", k, "=> ", err)
 			continue
 		}
-		details = append(details, v.ConvertToManifest(shipperBrandsData, masterHsCodeData, freightConfig))
+
+		var aliasHsCode *GetMasterHsCodeModel
+		if alias := aliases.Lookup(v.Goods, v.ShipperName, v.Origin); alias != nil {
+			aliasHsCode = &GetMasterHsCodeModel{
+				GoodsEN:          alias.GoodsText,
+				TariffCode:       alias.TariffCode,
+				TariffSequence:   alias.TariffSequence,
+				StatisticalCode:  alias.StatisticalCode,
+				QuantityUnitCode: unitCodes[alias.TariffCode],
+			}
+			aliasUsage[alias.UUID]++
+		}
+		details = append(details, v.ConvertToManifest(shipperBrandsData, aliasHsCode, matcher, freightConfig))
+	}
+
+	// Dry runs (no upload log) do not count as alias usage
+	if uploadLogUUID != "" && len(aliasUsage) > 0 {
+		if err := s.aliasSvc.IncrementHsCodeAliasUsage(ctx, aliasUsage); err != nil {
+			log.Println("IncrementHsCodeAliasUsage: ", err.Error())
+		}
 	}
 
 	return details, nil
@@ -1,9 +1,9 @@
 package ship2cu
 
 import (
-	"fmt"
+	"hpc-express-service/hsmatch"
+	"hpc-express-service/tax"
 	"hpc-express-service/utils"
-	"strings"
 )
 
 type UploadManifestModel struct {
@@ -48,6 +48,8 @@ type UploadManifestModel struct {
 	Length             float64
 	Height             float64
 	VolumeWeight       float64
+	ShipperPartyID     string
+	ConsigneePartyID   string
 	// FreightRate        float64
 	// FreightZone        float64
 }
@@ -57,7 +59,9 @@ type ResponseUploadManifestModel struct {
 	Amount int64
 }
 
-func (d *UploadManifestModel) ConvertToManifest(shipperBrands []*GetShipperBrandModel, masterHsCodeData []*GetMasterHsCodeModel, freightConfig *GetFreightDataModel) *utils.InsertPreImportDetailManifestModel {
+// ConvertToManifest builds the pre-import line. aliasHsCode, the HS code learned from earlier
+// corrections of the same goods, wins over the HS master match when set.
+func (d *UploadManifestModel) ConvertToManifest(shipperBrands []*GetShipperBrandModel, aliasHsCode *GetMasterHsCodeModel, hsCodeMatcher *hsmatch.Matcher[*GetMasterHsCodeModel], freightConfig *GetFreightDataModel) *utils.InsertPreImportDetailManifestModel {
 	//cif_value_foreign = (d.TotalPrice * exchange_rate) + (d.WgtValue + freight_rate) + ((d.TotalPrice * exchange_rate) * 0.01)
 
 	foundShipperBrands := &GetShipperBrandModel{}
@@ -70,10 +74,22 @@ func (d *UploadManifestModel) ConvertToManifest(shipperBrands []*GetShipperBrand
 		}
 	}
 
-	for _, row := range masterHsCodeData {
-		if strings.ToUpper(row.GoodsEN) == strings.ToUpper(d.Goods) {
-			foundHsCode = row
-			break
+	// Close variants (plurals, typos, spacing) still find their HS code; weak matches are left
+	// for the broker, who gets the ranked candidates to choose from
+	var hsCodeCandidates []*utils.HsCodeCandidate
+	if aliasHsCode != nil {
+		foundHsCode = aliasHsCode
+	} else if candidate, ok := hsCodeMatcher.Best(d.Goods); ok {
+		foundHsCode = candidate.Item
+	} else {
+		for _, c := range hsCodeMatcher.Match(d.Goods) {
+			hsCodeCandidates = append(hsCodeCandidates, &utils.HsCodeCandidate{
+				TariffCode:      c.Item.TariffCode,
+				TariffSequence:  c.Item.TariffSequence,
+				StatisticalCode: c.Item.StatisticalCode,
+				Description:     c.Text,
+				Score:           c.Score,
+			})
 		}
 	}
 
@@ -92,15 +108,11 @@ func (d *UploadManifestModel) ConvertToManifest(shipperBrands []*GetShipperBrand
 	// 	cif = d.CIF
 	// }
 
-	if cif <= 1500 {
-		category = "2"
+	category = tax.NewEngine().Category(cif)
+	if category == tax.CategoryLowValue {
 		tariffSequence = "68001"
-	} else if cif >= 1500 {
-		category = "3"
-		tariffSequence = foundHsCode.TariffSequence
 	} else {
-		category = ""
-		tariffSequence = ""
+		tariffSequence = foundHsCode.TariffSequence
 	}
 
 	return &utils.InsertPreImportDetailManifestModel{
@@ -128,6 +140,8 @@ func (d *UploadManifestModel) ConvertToManifest(shipperBrands []*GetShipperBrand
 		ShipperCountryCode:       d.Origin,
 		ShipperEmail:             "",
 		ShipperPhoneNumber:       d.ShipperTel,
+		ShipperPartyID:           d.ShipperPartyID,
+		ConsigneePartyID:         d.ConsigneePartyID,
 		TariffCode:               foundHsCode.TariffCode,      // FROM master_hs_code
 		TariffSequence:           tariffSequence,              // FROM master_hs_code,
 		StatisticalCode:          foundHsCode.StatisticalCode, // FROM master_hs_code
@@ -155,6 +169,7 @@ func (d *UploadManifestModel) ConvertToManifest(shipperBrands []*GetShipperBrand
 		OtherChargeCurrencyCode:  "",
 		InvoiceNo:                d.Hawb,
 		InvoiceDate:              "", // to_char(now() AT TIME ZONE 'utc' AT TIME ZONE 'Asia/Bangkok', 'DD/MM/YYYY') AS invoice_date
+		HsCodeCandidates:         hsCodeCandidates,
 	}
 }
 
@@ -178,20 +193,55 @@ type GetMasterHsCodeModel struct {
 	QuantityUnitCode string
 }
 
-var expectedHeadersUploadPreImportManifests = []string{
-	"No.", "MAWB", "BAG No.", "AWB", "HS CODE", "Origin", "Shipper name", "Cnee name", "Wgt Value", "Wgt Unit", "Packaging Type", "Shpr add", "Cnee add", "Province", "District", "Postcode", "Pcs", "QTY", "Goods", "Goods(EN)", "Currency", "total price", "FOB(THB)", "Freight", "Insurance", "CIF", "CAT", "Duty", "Vat", "Cost (THB)", "Local Tracking No. / EMS no.", "Reference1", "Reference2", "Customer Code", "Shpr's tel", "Cnee's tel", "Dimension", "Dimension Repacking", "Width", "Length", "Height", "Volume Weight",
+// defaultColumnMapping is the SHIP2CU "Output" sheet layout, used when
+// master_convert_templates has no mapping for the template.
+var defaultColumnMapping = &utils.ColumnMappingTemplate{
+	SheetName:      "Output",
+	HeaderRowIndex: 1,
+	Columns: []*utils.ColumnMapping{
+		{Source: "MAWB", Target: "MasterAirWaybill", Required: true},
+		{Source: "AWB", Target: "HouseAirWaybill"},
+		{Source: "Origin", Target: "ShipperCountryCode"},
+		{Source: "Shipper name", Target: "ShipperName"},
+		{Source: "Cnee name", Target: "ConsigneeName"},
+		{Source: "Wgt Value", Target: "NetWeight", Type: utils.ColumnTypeFloat},
+		{Source: "Cnee add", Target: "ConsigneeAddress"},
+		{Source: "Province", Target: "ConsigneeProvince"},
+		{Source: "District", Target: "ConsigneeDistrict"},
+		{Source: "Postcode", Target: "ConsigneePostcode"},
+		{Source: "QTY", Target: "Quantity", Type: utils.ColumnTypeInt},
+		{Source: "Goods", Target: "EnglishDescriptionOfGood"},
+		{Source: "Goods(EN)", Target: "ThaiDescriptionOfGood"},
+		{Source: "Currency", Target: "CurrencyCode"},
+		{Source: "total price", Target: "FobValueForeign", Type: utils.ColumnTypeFloat},
+		{Source: "Shpr's tel", Target: "ShipperPhoneNumber"},
+		{Source: "Cnee's tel", Target: "ConsigneePhoneNumber"},
+	},
 }
 
-func validateHeadersUploadPreImportManifests(headers []string) error {
-	for i, expected := range expectedHeadersUploadPreImportManifests {
-		if i >= len(headers) {
-			return fmt.Errorf("missing header at column %d: expected '%s'", i+1, expected)
-		}
-		if headers[i] != expected {
-			return fmt.Errorf("header mismatch at column %d: expected '%s', got '%s'", i+1, expected, headers[i])
-		}
+// newUploadManifestModel restores the fields ConvertToManifest reads from a mapped row.
+func newUploadManifestModel(row *utils.InsertPreImportDetailManifestModel) *UploadManifestModel {
+	return &UploadManifestModel{
+		Mawb:             row.MasterAirWaybill,
+		Hawb:             row.HouseAirWaybill,
+		Origin:           row.ShipperCountryCode,
+		ShipperName:      row.ShipperName,
+		ConsigneeName:    row.ConsigneeName,
+		WgtValue:         row.NetWeight,
+		ConsigneeAddress: row.ConsigneeAddress,
+		Province:         row.ConsigneeProvince,
+		District:         row.ConsigneeDistrict,
+		Postcode:         row.ConsigneePostcode,
+		Qty:              row.Quantity,
+		Goods:            row.EnglishDescriptionOfGood,
+		GoodsEN:          row.ThaiDescriptionOfGood,
+		Currency:         row.CurrencyCode,
+		TotalPrice:       row.FobValueForeign,
+		ShipperTel:       row.ShipperPhoneNumber,
+		ConsigneeTel:     row.ConsigneePhoneNumber,
+		ShipperPartyID:   row.ShipperPartyID,
+		ConsigneePartyID: row.ConsigneePartyID,
 	}
-	return nil
 }
 
 type GetFreightDataModel struct {
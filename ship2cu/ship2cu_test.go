@@ -0,0 +1,53 @@
+package ship2cu
+
+import (
+	"testing"
+
+	"hpc-express-service/hsmatch"
+)
+
+var masterHsCodes = []*GetMasterHsCodeModel{
+	{GoodsEN: "MOBILE PHONE CASE", TariffCode: "39269099", TariffSequence: "001"},
+	{GoodsEN: "MOBILE PHONE", TariffCode: "85171300", TariffSequence: "001"},
+	{GoodsEN: "T-SHIRT", TariffCode: "61091010", TariffSequence: "001"},
+}
+
+func newTestMatcher() *hsmatch.Matcher[*GetMasterHsCodeModel] {
+	return hsmatch.NewMatcher(masterHsCodes, func(row *GetMasterHsCodeModel) string { return row.GoodsEN })
+}
+
+func TestConvertToManifestHsCodeCandidates(t *testing.T) {
+	tests := []struct {
+		name           string
+		goods          string
+		alias          *GetMasterHsCodeModel
+		wantTariffCode string
+		wantCandidates bool
+	}{
+		{"confident match is assigned", "MOBILE PHONE CASES", nil, "39269099", false},
+		{"alias wins", "MOBILE PHONE CASES", &GetMasterHsCodeModel{TariffCode: "39269090"}, "39269090", false},
+		{"weak match keeps candidates", "PHONE HOLDER", nil, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &UploadManifestModel{Goods: tt.goods, Currency: "USD", TotalPrice: 10, Qty: 1}
+			got := d.ConvertToManifest(nil, tt.alias, newTestMatcher(), &GetFreightDataModel{FreightRate: 35})
+
+			if got.TariffCode != tt.wantTariffCode {
+				t.Errorf("TariffCode = %q, want %q", got.TariffCode, tt.wantTariffCode)
+			}
+			if (len(got.HsCodeCandidates) > 0) != tt.wantCandidates {
+				t.Fatalf("HsCodeCandidates = %d, want candidates %v", len(got.HsCodeCandidates), tt.wantCandidates)
+			}
+			for i, c := range got.HsCodeCandidates {
+				if c.Score >= hsmatch.DefaultThreshold {
+					t.Errorf("candidate %s scored %v, at or above the threshold", c.TariffCode, c.Score)
+				}
+				if i > 0 && c.Score > got.HsCodeCandidates[i-1].Score {
+					t.Errorf("candidates not ranked best first: %v after %v", c.Score, got.HsCodeCandidates[i-1].Score)
+				}
+			}
+		})
+	}
+}
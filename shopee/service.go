@@ -5,6 +5,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"hpc-express-service/setting"
 	"hpc-express-service/utils"
 	"strconv"
 	"time"
@@ -12,26 +13,44 @@ import (
 	"github.com/xuri/excelize/v2"
 )
 
+// TemplateCode is the convert template code handled by this converter.
+const TemplateCode = "SHOPEE"
+
+// TemplateName is the name the convert template is listed under.
+const TemplateName = "Shopee"
+
 type Service interface {
-	UploadPreImportManifests(ctx context.Context, uploadLogUUID string, fileBytes []byte) ([]*ResponseUploadManifestModel, error)
+	TemplateCode() string
+	TemplateName() string
+	ConvertToPreExportDetails(ctx context.Context, customerUUID string, fileBytes []byte) ([]*utils.InsertPreExportDetailManifestModel, error)
 }
 
 type service struct {
-	selfRepo       Repository
 	contextTimeout time.Duration
+	profileSvc     setting.ExporterProfileService
 }
 
 func NewService(
-	selfRepo Repository,
 	timeout time.Duration,
+	profileSvc setting.ExporterProfileService,
 ) Service {
 	return &service{
-		selfRepo:       selfRepo,
 		contextTimeout: timeout,
+		profileSvc:     profileSvc,
 	}
 }
 
-func (s *service) UploadPreImportManifests(ctx context.Context, uploadLogUUID string, fileBytes []byte) ([]*ResponseUploadManifestModel, error) {
+func (s *service) TemplateCode() string {
+	return TemplateCode
+}
+
+func (s *service) TemplateName() string {
+	return TemplateName
+}
+
+// ConvertToPreExportDetails converts a Shopee manifest into pre-export lines. The consignor of each
+// line comes from the SHOPEE exporter profile of customerUUID (optional) and the row's sender.
+func (s *service) ConvertToPreExportDetails(ctx context.Context, customerUUID string, fileBytes []byte) ([]*utils.InsertPreExportDetailManifestModel, error) {
 	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
 	defer cancel()
 
@@ -252,7 +271,7 @@ func (s *service) UploadPreImportManifests(ctx context.Context, uploadLogUUID st
 			if j < len(row) {
 				tempDetail := &DeclaredDetailModel{}
 				tempDetail.DeclaredName = row[j]
-				if j+6 < len(row) {
+				if j+1 < len(row) {
 					tempDetail.HSCode = row[j+1]
 				}
 				if j+2 < len(row) {
@@ -284,41 +303,19 @@ func (s *service) UploadPreImportManifests(ctx context.Context, uploadLogUUID st
 		data = append(data, temp)
 	}
 
-	var totalNetWeight int64
-	var totalGrossWeight float64
-	details := []*utils.InsertPreExportDetailManifestModel{}
-	for _, v := range data {
-		details = append(details, v.ConvertToManifest())
-		totalNetWeight += int64(v.ParcelWeight)
-		totalGrossWeight += v.ParcelWeight
-	}
-
-	manifest := &utils.InsertPreExportHeaderManifestModel{
-		UploadLoggingUUID:        uploadLogUUID,
-		VasselName:               "TG620",
-		DepartureDate:            "10/4/2017", // Outbound Time
-		ReleasePort:              1193,
-		LoadingPort:              1190,
-		TotalPackage:             int64(len(details)),
-		TotalPackageUnitCode:     "PK",
-		TotalNetWeight:           totalNetWeight,
-		TotalNetWeightUnitCode:   "KGM",
-		TotalGrossWeight:         totalGrossWeight,
-		TotalGrossWeightUnitCode: "KGM",
-		Details:                  details,
-	}
-
-	err = s.selfRepo.InsertPreExportManifest(ctx, manifest, 200)
+	profiles, err := s.profileSvc.GetExporterProfileSet(ctx, TemplateCode, customerUUID)
 	if err != nil {
 		return nil, err
 	}
 
-	result := []*ResponseUploadManifestModel{}
-
-	result = append(result, &ResponseUploadManifestModel{
-		// Mawb:   ,
-		Amount: manifest.TotalPackage,
-	})
+	details := []*utils.InsertPreExportDetailManifestModel{}
+	for i, v := range data {
+		profile, err := profiles.Resolve(v.SenderName)
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %v", i+2, err)
+		}
+		details = append(details, v.ConvertToManifest(profile)...)
+	}
 
-	return result, nil
+	return details, nil
 }
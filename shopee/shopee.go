@@ -2,8 +2,9 @@ package shopee
 
 import (
 	"fmt"
+	"hpc-express-service/preexport"
+	"hpc-express-service/setting"
 	"hpc-express-service/utils"
-	"strings"
 )
 
 type UploadManifestModel struct {
@@ -381,76 +382,31 @@ type DeclaredDetailModel struct {
 	DeclaredNameLocal  string
 }
 
-type ResponseUploadManifestModel struct {
-	Mawb   string
-	Amount int64
-}
-
-func (d *UploadManifestModel) ConvertToManifest() *utils.InsertPreExportDetailManifestModel {
-	// var thaiDescriptionOfGoods string
-	var englishDescriptionOfGoods []string
-	var category int64
-	var quantity int64
-	var fobValueBaht float64
-	for _, v := range d.DeclaredDetails {
-		englishDescriptionOfGoods = append(englishDescriptionOfGoods, v.DeclaredName)
-		quantity += v.DeclaredQTY
-		fobValueBaht += v.DeclaredValue
+// ConvertToManifest builds the pre-export lines of a parcel, one per declared item, declaring profile as the consignor.
+func (d *UploadManifestModel) ConvertToManifest(profile *setting.ExporterProfile) []*utils.InsertPreExportDetailManifestModel {
+	parcel := &preexport.Parcel{
+		Hawb:                   d.ShopeeTracking,
+		ReceiverName:           d.ReceiverName,
+		ReceiverAddress:        d.ReceiverAddress + " " + d.ReceiverTelephone,
+		ReceiverDistrict:       d.ReceiverCity,
+		ReceiverProvince:       d.ReceiverProvinceOrState,
+		ReceiverPostcode:       d.PostalCode,
+		DestinationCountryCode: d.DestinationCode,
+		PurchaseCountryCode:    d.SenderCountry,
+		Weight:                 d.ParcelWeight,
+		Packages:               d.ParcelVolume,
 	}
-
-	if fobValueBaht > 1500 {
-		category = 3
-	} else {
-		category = 2
+	for _, v := range d.DeclaredDetails {
+		parcel.Items = append(parcel.Items, &preexport.Item{
+			Description:      v.DeclaredName,
+			DescriptionLocal: v.DeclaredNameLocal,
+			HSCode:           v.HSCode,
+			Quantity:         v.DeclaredQTY,
+			Value:            v.DeclaredValue,
+		})
 	}
 
-	return &utils.InsertPreExportDetailManifestModel{
-		HeaderUUID:                  "",
-		MasterAirWaybill:            "",
-		HouseAirWaybill:             d.ShopeeTracking,
-		Category:                    category,
-		ConsignorCompanyTaxNumber:   "01234567890123",
-		ConsignorCompanyBranch:      "",
-		ConsignorName:               "TEST CONSIGNEE NAME",
-		ConsignorStreetAndAddress:   "161 SUKSAWAD ROAD",
-		ConsignorDistrict:           "CSN_DISTRICT",
-		ConsignorSubProvince:        "CSN_SUBPROVINCE",
-		ConsignorProvince:           "CSN_PROVINCE",
-		ConsignorPostcode:           "10140",
-		ConsignorEmail:              "SHP_EMAIL",
-		ConsigneeName:               d.ReceiverName,
-		ConsigneeStreetAndAddress:   d.ReceiverAddress + " " + d.ReceiverTelephone,
-		ConsigneeDistrict:           d.ReceiverCity,
-		ConsigneeSubProvince:        "",
-		ConsigneeProvince:           d.ReceiverProvinceOrState,
-		ConsigneePostcode:           d.PostalCode,
-		ConsigneeCountryCode:        d.DestinationCode,
-		ConsigneeEmail:              "",
-		PurchaseCountryCode:         d.SenderCountry,
-		DestinationCountryCode:      d.DestinationCode,
-		ThaiDescriptionOfGoods:      "",
-		EnglishDescriptionOfGoods:   strings.Join(englishDescriptionOfGoods, ", "),
-		Quantity:                    quantity,
-		QuantityUnitCode:            "C62",
-		NetWeight:                   d.ParcelWeight,
-		NetWeightUnitCode:           "KGM",
-		GrossWeight:                 d.ParcelWeight,
-		GrossWeightUnitCode:         "KGM",
-		PackageAmount:               d.ParcelVolume,
-		PackageUnitCode:             "PK",
-		Remark:                      "REMARK",
-		FobValueBaht:                fobValueBaht, // Declared Value 1 (AS) to 40
-		FobValueForeign:             0,            // X
-		CurrencyCode:                "THB",
-		ExchangeRate:                1,
-		FreightAmount:               1,
-		FreightAmountCurrencyCode:   "THB",
-		InsuranceAmount:             1,
-		InsuranceAmountCurrencyCode: "THB",
-		TariffCode:                  "000049111090",
-		StatCode:                    "000",
-		TariffSequence:              "50001",
-	}
+	return preexport.BuildLines(parcel, profile)
 }
 
 var expectedHeadersUploadPreImportManifests = []string{
@@ -0,0 +1,71 @@
+package tax
+
+import "math"
+
+const (
+	// CategoryLowValue is a consignment at or below the de minimis value: VAT only, no duty.
+	CategoryLowValue = "2"
+	// CategoryDutiable is a consignment above the de minimis value: duty and VAT.
+	CategoryDutiable = "3"
+)
+
+const (
+	DefaultDeMinimis = 1500
+	DefaultVatRate   = 7
+)
+
+// Engine computes import duty and VAT for one HAWB.
+// Amounts are in THB; DutyRate and VatRate are percentages.
+type Engine struct {
+	DeMinimis float64
+	VatRate   float64
+}
+
+func NewEngine() *Engine {
+	return &Engine{
+		DeMinimis: DefaultDeMinimis,
+		VatRate:   DefaultVatRate,
+	}
+}
+
+// Breakdown is the tax assessed on one HAWB.
+type Breakdown struct {
+	Category   string  `json:"category"`
+	Cif        float64 `json:"cif"`
+	DutyRate   float64 `json:"dutyRate"`
+	Duty       float64 `json:"duty"`
+	VatBase    float64 `json:"vatBase"`
+	Vat        float64 `json:"vat"`
+	DutyAndVat float64 `json:"dutyAndVat"`
+}
+
+// Category returns the customs category of a HAWB with the given CIF value.
+func (e *Engine) Category(cif float64) string {
+	if cif <= e.DeMinimis {
+		return CategoryLowValue
+	}
+	return CategoryDutiable
+}
+
+// Assess returns the duty and VAT of a HAWB with the given CIF value and the duty rate of its HS code.
+// Duty is only charged above de minimis; VAT is charged on CIF plus duty.
+func (e *Engine) Assess(cif, dutyRate float64) *Breakdown {
+	b := &Breakdown{
+		Category: e.Category(cif),
+		Cif:      cif,
+		DutyRate: dutyRate,
+	}
+
+	if b.Category == CategoryDutiable {
+		b.Duty = round(cif * dutyRate / 100)
+	}
+	b.VatBase = round(cif + b.Duty)
+	b.Vat = round(b.VatBase * e.VatRate / 100)
+	b.DutyAndVat = round(b.Duty + b.Vat)
+
+	return b
+}
+
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
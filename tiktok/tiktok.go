@@ -0,0 +1,126 @@
+package tiktok
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"hpc-express-service/preexport"
+	"hpc-express-service/setting"
+	"hpc-express-service/utils"
+)
+
+// requiredHeaders are the columns of the TikTok Shop order export the converter reads. The export
+// has one row per SKU of an order; "Warehouse Name" and "HS Code" are optional.
+var requiredHeaders = []string{
+	"Order ID", "Tracking ID", "Seller SKU", "Product Name", "Quantity", "SKU Subtotal After Discount",
+	"Recipient", "Phone #", "Country", "Province", "District", "Zipcode", "Detail Address", "Weight(kg)",
+}
+
+// OrderModel is one parcel of a TikTok Shop export: the SKU rows sharing a tracking ID.
+type OrderModel struct {
+	OrderID       string
+	WarehouseName string
+	Parcel        *preexport.Parcel
+}
+
+// parseOrders groups the SKU rows of a TikTok Shop export by tracking ID, merging rows of the
+// same SKU in a parcel. The column description row TikTok puts under the headers is skipped.
+func parseOrders(rows [][]string) ([]*OrderModel, error) {
+	if len(rows) == 0 {
+		return nil, errors.New("Excel file is empty!")
+	}
+
+	columns, err := preexport.NewColumns(rows[0], requiredHeaders)
+	if err != nil {
+		return nil, fmt.Errorf("Header validation failed: %v", err)
+	}
+
+	orders := []*OrderModel{}
+	byTracking := map[string]*OrderModel{}
+	for i := 1; i < len(rows); i++ {
+		row := rows[i]
+		orderID := columns.Cell(row, "Order ID")
+		if i == 1 && !isNumber(orderID) {
+			continue
+		}
+
+		tracking := columns.Cell(row, "Tracking ID")
+		if tracking == "" {
+			if orderID == "" {
+				continue
+			}
+			return nil, fmt.Errorf("row %d: missing tracking ID", i+1)
+		}
+
+		quantity, err := strconv.ParseInt(columns.Cell(row, "Quantity"), 10, 64)
+		if err != nil || quantity <= 0 {
+			return nil, fmt.Errorf("row %d: '%s' is not a quantity", i+1, columns.Cell(row, "Quantity"))
+		}
+		amount, err := preexport.ParseAmount(columns.Cell(row, "SKU Subtotal After Discount"))
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %v", i+1, err)
+		}
+
+		order, ok := byTracking[tracking]
+		if !ok {
+			order = &OrderModel{
+				OrderID:       orderID,
+				WarehouseName: columns.Cell(row, "Warehouse Name"),
+				Parcel: &preexport.Parcel{
+					Hawb:                   tracking,
+					ReceiverName:           columns.Cell(row, "Recipient"),
+					ReceiverAddress:        columns.Cell(row, "Detail Address") + " " + columns.Cell(row, "Phone #"),
+					ReceiverDistrict:       columns.Cell(row, "District"),
+					ReceiverProvince:       columns.Cell(row, "Province"),
+					ReceiverPostcode:       columns.Cell(row, "Zipcode"),
+					DestinationCountryCode: preexport.CountryCode(columns.Cell(row, "Country")),
+					PurchaseCountryCode:    "TH",
+					Packages:               1,
+				},
+			}
+			byTracking[tracking] = order
+			orders = append(orders, order)
+		}
+
+		// the order weight is repeated on every SKU row of the parcel
+		if weight := columns.Cell(row, "Weight(kg)"); weight != "" && order.Parcel.Weight == 0 {
+			order.Parcel.Weight, err = strconv.ParseFloat(weight, 64)
+			if err != nil {
+				return nil, fmt.Errorf("row %d: '%s' is not a weight", i+1, weight)
+			}
+		}
+
+		order.Parcel.Add(&preexport.Item{
+			SKU:         columns.Cell(row, "Seller SKU"),
+			Description: columns.Cell(row, "Product Name"),
+			HSCode:      columns.Cell(row, "HS Code"),
+			Quantity:    quantity,
+			Value:       amount,
+		})
+	}
+
+	if len(orders) == 0 {
+		return nil, errors.New("Excel file is empty!")
+	}
+
+	return orders, nil
+}
+
+// convertOrders builds the pre-export lines of every parcel, declaring the profile resolved from its warehouse.
+func convertOrders(orders []*OrderModel, profiles *setting.ExporterProfileSet) ([]*utils.InsertPreExportDetailManifestModel, error) {
+	details := []*utils.InsertPreExportDetailManifestModel{}
+	for _, v := range orders {
+		profile, err := profiles.Resolve(v.WarehouseName)
+		if err != nil {
+			return nil, fmt.Errorf("tracking %s: %v", v.Parcel.Hawb, err)
+		}
+		details = append(details, preexport.BuildLines(v.Parcel, profile)...)
+	}
+	return details, nil
+}
+
+func isNumber(s string) bool {
+	return s != "" && strings.Trim(s, "0123456789") == ""
+}
@@ -0,0 +1,121 @@
+package tiktok
+
+import (
+	"strings"
+	"testing"
+
+	"hpc-express-service/setting"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func readFixture(t *testing.T, name string) [][]string {
+	t.Helper()
+
+	f, err := excelize.OpenFile("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	rows, err := f.GetRows(f.GetSheetName(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rows
+}
+
+func TestParseOrders(t *testing.T) {
+	orders, err := parseOrders(readFixture(t, "orders.xlsx"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(orders) != 2 {
+		t.Fatalf("got %d parcels, want 2 (description row skipped)", len(orders))
+	}
+
+	first := orders[0]
+	if first.Parcel.Hawb != "TTVN0001" || first.WarehouseName != "BKK Main" {
+		t.Errorf("unexpected parcel %+v", first)
+	}
+	if first.Parcel.DestinationCountryCode != "VN" || first.Parcel.Weight != 0.5 {
+		t.Errorf("unexpected country %q or weight %v", first.Parcel.DestinationCountryCode, first.Parcel.Weight)
+	}
+	if len(first.Parcel.Items) != 2 {
+		t.Fatalf("got %d items, want 2 (identical SKUs merged)", len(first.Parcel.Items))
+	}
+	if item := first.Parcel.Items[0]; item.SKU != "SERUM-30" || item.Quantity != 3 || item.Value != 1200 {
+		t.Errorf("unexpected merged item %+v", item)
+	}
+	if item := first.Parcel.Items[1]; item.SKU != "MASK-10" || item.Value != 1000 {
+		t.Errorf("unexpected item %+v", item)
+	}
+
+	if second := orders[1]; second.Parcel.Hawb != "TTPH0002" || second.Parcel.DestinationCountryCode != "PH" {
+		t.Errorf("unexpected parcel %+v", second.Parcel)
+	}
+}
+
+func TestParseOrdersMissingHeader(t *testing.T) {
+	rows := readFixture(t, "orders.xlsx")
+	for i, header := range rows[0] {
+		if header == "Weight(kg)" {
+			rows[0][i] = "Weight"
+		}
+	}
+
+	_, err := parseOrders(rows)
+	if err == nil || !strings.Contains(err.Error(), "missing header 'Weight(kg)'") {
+		t.Fatalf("got %v, want missing header error", err)
+	}
+}
+
+func TestParseOrdersRejectsForeignCurrency(t *testing.T) {
+	rows := readFixture(t, "orders.xlsx")
+	for i, header := range rows[0] {
+		if header == "SKU Subtotal After Discount" {
+			rows[5][i] = "VND 150000"
+		}
+	}
+
+	_, err := parseOrders(rows)
+	if err == nil || !strings.Contains(err.Error(), "currency 'VND'") {
+		t.Fatalf("got %v, want currency error", err)
+	}
+}
+
+func TestConvertOrders(t *testing.T) {
+	orders, err := parseOrders(readFixture(t, "orders.xlsx"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	profiles := setting.NewExporterProfileSet(TemplateCode, []setting.ExporterProfile{
+		{Name: "HPC DEFAULT", TaxNumber: "0105500000001", DefaultTariffCode: "000033049900", InsuranceRule: setting.ChargeRulePercentFob, InsuranceValue: 1},
+	})
+
+	details, err := convertOrders(orders, profiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(details) != 3 {
+		t.Fatalf("got %d lines, want 3", len(details))
+	}
+
+	serum, mask, soap := details[0], details[1], details[2]
+	if serum.Category != 3 || mask.Category != 3 {
+		t.Errorf("parcel of 2200 THB should be category 3, got %d/%d", serum.Category, mask.Category)
+	}
+	if serum.Quantity != 3 || serum.FobValueBaht != 1200 || serum.TariffCode != "000033049900" {
+		t.Errorf("unexpected line %+v", serum)
+	}
+	if serum.InsuranceAmount+mask.InsuranceAmount != 22 {
+		t.Errorf("insurance split %d+%d, want 22", serum.InsuranceAmount, mask.InsuranceAmount)
+	}
+	if serum.NetWeight+mask.NetWeight != 0.5 {
+		t.Errorf("weight split %v+%v, want 0.5", serum.NetWeight, mask.NetWeight)
+	}
+	if soap.HouseAirWaybill != "TTPH0002" || soap.ItemNo != 1 || soap.Category != 2 || soap.Quantity != 3 {
+		t.Errorf("unexpected line %+v", soap)
+	}
+}
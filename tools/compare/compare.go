@@ -15,10 +15,26 @@ type DBDetails struct {
 }
 
 type ExcelItem struct {
-	Value     string     `json:"value"`
-	IsMatch   bool       `json:"isMatch"`
-	MatchedBy string     `json:"matchedBy,omitempty"`
-	DBDetails *DBDetails `json:"dbDetails,omitempty"`
+	Value      string            `json:"value"`
+	IsMatch    bool              `json:"isMatch"`
+	MatchedBy  string            `json:"matchedBy,omitempty"`
+	Score      float64           `json:"score,omitempty"`
+	DBDetails  *DBDetails        `json:"dbDetails,omitempty"`
+	Candidates []*MatchCandidate `json:"candidates,omitempty"`
+}
+
+// MatchCandidate is a master row suggested for a goods description that had no exact match.
+type MatchCandidate struct {
+	Value  string  `json:"value"`
+	HSCode string  `json:"hs_code"`
+	Score  float64 `json:"score"`
+}
+
+// CompareOptions tunes the fuzzy matching of goods descriptions.
+// Zero values fall back to the service defaults.
+type CompareOptions struct {
+	TopN      int
+	Threshold float64
 }
 
 type CompareResponse struct {
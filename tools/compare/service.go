@@ -7,10 +7,12 @@ import (
 	"log"
 
 	"github.com/xuri/excelize/v2"
+
+	"hpc-express-service/hsmatch"
 )
 
 type ExcelServiceInterface interface {
-	CompareExcelWithDB(ctx context.Context, excelFileBytes []byte, columnName string) (*CompareResponse, error)
+	CompareExcelWithDB(ctx context.Context, excelFileBytes []byte, columnName string, opts CompareOptions) (*CompareResponse, error)
 }
 
 type ExcelValue struct {
@@ -77,14 +79,18 @@ func readExcelColumnFromBytes(fileBytes []byte, columnName string) (map[string]E
 }
 
 type excelService struct {
-	repo ExcelRepositoryInterface
+	repo           ExcelRepositoryInterface
+	matchThreshold float64
 }
 
-func NewExcelService(repo ExcelRepositoryInterface) ExcelServiceInterface {
-	return &excelService{repo: repo}
+func NewExcelService(repo ExcelRepositoryInterface, matchThreshold float64) ExcelServiceInterface {
+	if matchThreshold <= 0 {
+		matchThreshold = hsmatch.DefaultThreshold
+	}
+	return &excelService{repo: repo, matchThreshold: matchThreshold}
 }
 
-func (s *excelService) CompareExcelWithDB(ctx context.Context, excelFileBytes []byte, columnName string) (*CompareResponse, error) {
+func (s *excelService) CompareExcelWithDB(ctx context.Context, excelFileBytes []byte, columnName string, opts CompareOptions) (*CompareResponse, error) {
 	// Validate columnName first
 	if columnName == "" {
 		return nil, fmt.Errorf("columnName cannot be empty") // Or a more specific error type
@@ -159,6 +165,27 @@ func (s *excelService) CompareExcelWithDB(ctx context.Context, excelFileBytes []
 		}
 	}
 
+	// Goods descriptions that match neither exactly nor by hs_code are ranked fuzzily
+	var matcher *hsmatch.Matcher[*DBDetails]
+	if columnName == "goods_en" || columnName == "goods_th" {
+		matcher = hsmatch.NewMatcher(dbValuesSlice, func(row *DBDetails) string {
+			if row == nil {
+				return ""
+			}
+			if columnName == "goods_th" {
+				return row.GoodsTH
+			}
+			return row.GoodsEN
+		})
+		matcher.Threshold = s.matchThreshold
+		if opts.Threshold > 0 {
+			matcher.Threshold = opts.Threshold
+		}
+		if opts.TopN > 0 {
+			matcher.TopN = opts.TopN
+		}
+	}
+
 	matchedRows := 0
 	excelItems := make([]ExcelItem, 0, len(excelValues))
 
@@ -204,6 +231,23 @@ func (s *excelService) CompareExcelWithDB(ctx context.Context, excelFileBytes []
 				item.MatchedBy = matchType
 			}
 		}
+		if !item.IsMatch && matcher != nil {
+			candidates := matcher.Match(excelVal.Value)
+			for _, c := range candidates {
+				item.Candidates = append(item.Candidates, &MatchCandidate{
+					Value:  c.Text,
+					HSCode: c.Item.HSCode,
+					Score:  c.Score,
+				})
+			}
+			if best, ok := matcher.Best(excelVal.Value); ok {
+				item.IsMatch = true
+				item.MatchedBy = "fuzzy"
+				item.Score = best.Score
+				item.DBDetails = best.Item
+				matchedRows++
+			}
+		}
 		excelItems = append(excelItems, item)
 	}
 
@@ -2,6 +2,7 @@ package uploadlog
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"hpc-express-service/utils"
 	"time"
@@ -14,6 +15,8 @@ type Repository interface {
 	GetAllUploadloggingsByCategoryAndSubCategory(ctx context.Context, startDate, endDate, category, subCategory string) ([]*GetUploadloggingModel, error)
 	Insert(ctx context.Context, data *InsertModel) (string, error)
 	Update(ctx context.Context, data *UpdateModel) error
+	UpdateValidationReport(ctx context.Context, uuid, reportJSON, fileURL string) error
+	GetValidationReport(ctx context.Context, uuid string) (*GetValidationReportModel, error)
 }
 
 type repository struct {
@@ -195,3 +198,66 @@ func (r repository) Update(ctx context.Context, data *UpdateModel) error {
 	return nil
 
 }
+
+func (r repository) UpdateValidationReport(ctx context.Context, uuid, reportJSON, fileURL string) error {
+	db := ctx.Value("postgreSQLConn").(*pg.DB)
+	ctx, _ = context.WithTimeout(context.Background(), 5*time.Second)
+
+	result, err := db.ExecOneContext(ctx,
+		`
+			UPDATE public.tbl_upload_loggings
+				SET  validation_report=?1, validation_file_url=?2, updated_at=NOW()
+			WHERE "uuid" = ?0;
+		`,
+		uuid,
+		reportJSON,
+		utils.NewNullString(fileURL),
+	)
+
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return errors.New("not found")
+	}
+
+	return nil
+}
+
+func (r repository) GetValidationReport(ctx context.Context, uuid string) (*GetValidationReportModel, error) {
+	db := ctx.Value("postgreSQLConn").(*pg.DB)
+	ctx, _ = context.WithTimeout(context.Background(), 5*time.Second)
+
+	var reportJSON string
+	x := GetValidationReportModel{}
+	_, err := db.QueryOneContext(ctx, pg.Scan(
+		&x.UUID,
+		&x.FileName,
+		&x.FileURL,
+		&reportJSON,
+	), `
+		SELECT
+			ul."uuid",
+			ul.file_name,
+			COALESCE(ul.validation_file_url, ''),
+			COALESCE(ul.validation_report::text, '')
+		FROM public.tbl_upload_loggings ul
+		WHERE ul.uuid = ?
+	`, uuid)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if reportJSON == "" {
+		return nil, errors.New("no validation report for this upload")
+	}
+
+	x.Report = &utils.ValidationReport{}
+	if err := json.Unmarshal([]byte(reportJSON), x.Report); err != nil {
+		return nil, err
+	}
+
+	return &x, nil
+}
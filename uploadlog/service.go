@@ -3,10 +3,12 @@ package uploadlog
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"hpc-express-service/gcs"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	randomUUID "github.com/satori/go.uuid"
@@ -17,6 +19,9 @@ type Service interface {
 	GetAllUploadloggings(ctx context.Context, startDate, endDate, category, subCategory string) ([]*GetUploadloggingModel, error)
 	UploadLogFile(ctx context.Context, data *UploadFileModel) (string, error)
 	Update(ctx context.Context, data *UpdateModel) error
+	SaveValidationReport(ctx context.Context, data *SaveValidationReportModel) error
+	GetValidationReport(ctx context.Context, uuid string) (*GetValidationReportModel, error)
+	DownloadValidationReport(ctx context.Context, uuid string) (string, *bytes.Buffer, string, error)
 }
 
 type service struct {
@@ -133,3 +138,75 @@ func (s *service) Update(ctx context.Context, data *UpdateModel) error {
 
 	return nil
 }
+
+// SaveValidationReport stores the annotated sheet next to the uploaded file and keeps the report on the upload log.
+func (s *service) SaveValidationReport(ctx context.Context, data *SaveValidationReportModel) error {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	fileURL := ""
+	if len(data.FileBytes) > 0 {
+		var extension = filepath.Ext(data.FileName)
+		newFileName := data.UUID + "_validation" + extension
+
+		if s.gcsClient == nil {
+			localDir := filepath.Join("assets", "uploadlog", data.Category, data.TemplateCode)
+			if err := os.MkdirAll(localDir, os.ModePerm); err != nil {
+				return err
+			}
+			fileURL = filepath.Join(localDir, newFileName)
+			if err := os.WriteFile(fileURL, data.FileBytes, 0644); err != nil {
+				return err
+			}
+		} else {
+			fullPath := fmt.Sprintf("%s/%s/%s", data.Category, data.TemplateCode, newFileName)
+			_, objAttrs, err := s.gcsClient.UploadToGCS(ctx, bytes.NewReader(data.FileBytes), fullPath, true, "application/octet-stream")
+			if err != nil {
+				return err
+			}
+			fileURL = objAttrs.MediaLink
+		}
+	}
+
+	reportJSON, err := json.Marshal(data.Report)
+	if err != nil {
+		return err
+	}
+
+	return s.selfRepo.UpdateValidationReport(ctx, data.UUID, string(reportJSON), fileURL)
+}
+
+func (s *service) GetValidationReport(ctx context.Context, uuid string) (*GetValidationReportModel, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	return s.selfRepo.GetValidationReport(ctx, uuid)
+}
+
+// DownloadValidationReport returns the annotated sheet of an upload.
+// Files kept in GCS are returned as a URL to redirect to instead of being read back.
+func (s *service) DownloadValidationReport(ctx context.Context, uuid string) (string, *bytes.Buffer, string, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	result, err := s.selfRepo.GetValidationReport(ctx, uuid)
+	if err != nil {
+		return "", nil, "", err
+	}
+
+	if result.FileURL == "" {
+		return "", nil, "", fmt.Errorf("no annotated file for upload %s", uuid)
+	}
+
+	fileName := strings.TrimSuffix(result.FileName, filepath.Ext(result.FileName)) + "_validation" + filepath.Ext(result.FileName)
+	if strings.HasPrefix(result.FileURL, "http") {
+		return fileName, nil, result.FileURL, nil
+	}
+
+	fileBytes, err := os.ReadFile(result.FileURL)
+	if err != nil {
+		return "", nil, "", err
+	}
+
+	return fileName, bytes.NewBuffer(fileBytes), "", nil
+}
@@ -1,5 +1,7 @@
 package uploadlog
 
+import "hpc-express-service/utils"
+
 type GetUploadloggingModel struct {
 	UUID         string `json:"uuid"`
 	Mawb         string `json:"mawb"`
@@ -45,3 +47,19 @@ type UpdateModel struct {
 	Status string
 	Remark string
 }
+
+type SaveValidationReportModel struct {
+	UUID         string
+	FileName     string
+	TemplateCode string
+	Category     string
+	Report       *utils.ValidationReport
+	FileBytes    []byte
+}
+
+type GetValidationReportModel struct {
+	UUID     string                  `json:"uuid"`
+	FileName string                  `json:"fileName"`
+	FileURL  string                  `json:"fileURL"`
+	Report   *utils.ValidationReport `json:"report"`
+}
@@ -0,0 +1,263 @@
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	ColumnTypeString = "string"
+	ColumnTypeUpper  = "upper"
+	ColumnTypeLower  = "lower"
+	ColumnTypeFloat  = "float"
+	ColumnTypeInt    = "int"
+)
+
+// ColumnMappingTemplate describes how a customer spreadsheet maps onto a row model.
+// It is stored as JSON in master_convert_templates.mapping.
+type ColumnMappingTemplate struct {
+	SheetName      string           `json:"sheetName"`
+	HeaderRowIndex int              `json:"headerRowIndex"`
+	Columns        []*ColumnMapping `json:"columns" validate:"required,min=1,dive"`
+}
+
+// ColumnMapping maps one source header onto a target field.
+// A column without Source always takes its Default value.
+type ColumnMapping struct {
+	Source   string `json:"source"`
+	Target   string `json:"target" validate:"required"`
+	Type     string `json:"type"`
+	Default  string `json:"default"`
+	Required bool   `json:"required"`
+}
+
+// Headers returns the source headers the sheet must contain.
+func (m *ColumnMappingTemplate) Headers() []string {
+	headers := []string{}
+	for _, c := range m.Columns {
+		if c.Source != "" {
+			headers = append(headers, c.Source)
+		}
+	}
+	return headers
+}
+
+// Validate checks that every target is a settable field of target and that the coercion fits the field type.
+func (m *ColumnMappingTemplate) Validate(target interface{}) error {
+	if len(m.Columns) == 0 {
+		return errors.New("mapping has no columns")
+	}
+	if m.HeaderRowIndex < 0 {
+		return fmt.Errorf("invalid header row index %d", m.HeaderRowIndex)
+	}
+
+	t := reflect.TypeOf(target)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	seen := map[string]bool{}
+	for _, c := range m.Columns {
+		field, ok := t.FieldByName(c.Target)
+		if !ok || field.PkgPath != "" {
+			return fmt.Errorf("unknown target field '%s'", c.Target)
+		}
+		if seen[c.Target] {
+			return fmt.Errorf("target field '%s' is mapped more than once", c.Target)
+		}
+		seen[c.Target] = true
+
+		switch field.Type.Kind() {
+		case reflect.String:
+			if c.Type != "" && c.Type != ColumnTypeString && c.Type != ColumnTypeUpper && c.Type != ColumnTypeLower {
+				return fmt.Errorf("type '%s' cannot be used for text field '%s'", c.Type, c.Target)
+			}
+		case reflect.Float32, reflect.Float64:
+			if c.Type != "" && c.Type != ColumnTypeFloat {
+				return fmt.Errorf("type '%s' cannot be used for number field '%s'", c.Type, c.Target)
+			}
+		case reflect.Int, reflect.Int32, reflect.Int64:
+			if c.Type != "" && c.Type != ColumnTypeInt {
+				return fmt.Errorf("type '%s' cannot be used for integer field '%s'", c.Type, c.Target)
+			}
+		default:
+			return fmt.Errorf("target field '%s' is not supported", c.Target)
+		}
+	}
+
+	return nil
+}
+
+// MappedRow is one data row read through a ColumnMappingTemplate.
+type MappedRow[T any] struct {
+	RowNumber int
+	Data      *T
+	cells     map[string]string
+	values    map[string]string
+}
+
+// Cell returns the cell reference the target field was read from.
+func (r *MappedRow[T]) Cell(target string) string {
+	return r.cells[target]
+}
+
+// Value returns the raw text read for the target field.
+func (r *MappedRow[T]) Value(target string) string {
+	return r.values[target]
+}
+
+// MappedData returns the row models of rows.
+func MappedData[T any](rows []*MappedRow[T]) []*T {
+	list := make([]*T, 0, len(rows))
+	for _, row := range rows {
+		list = append(list, row.Data)
+	}
+	return list
+}
+
+// ReadMappedSheet reads every data row below the header row of fileBytes into T according to m.
+// Headers are matched by name, so the column order of the sheet does not matter.
+// Problems with individual cells are collected into the report instead of stopping the read;
+// the returned error is reserved for files that cannot be read at all.
+func ReadMappedSheet[T any](fileBytes []byte, m *ColumnMappingTemplate) ([]*MappedRow[T], *ValidationReport, error) {
+	if err := m.Validate(new(T)); err != nil {
+		return nil, nil, err
+	}
+
+	file := bytes.NewReader(fileBytes)
+	if file.Len() == 0 {
+		return nil, nil, errors.New("empty")
+	}
+
+	x, err := excelize.OpenReader(file)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer x.Close()
+
+	sheetName := m.SheetName
+	if sheetName == "" {
+		sheetName = x.GetSheetName(0)
+	}
+
+	rows, err := x.GetRows(sheetName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	headerRowIndex := m.HeaderRowIndex
+	if headerRowIndex == 0 {
+		headerRowIndex = 1
+	}
+	if len(rows) < headerRowIndex {
+		return nil, nil, errors.New("Excel file is empty!")
+	}
+
+	report := NewValidationReport(sheetName)
+
+	columnIndex := map[string]int{}
+	for i, header := range rows[headerRowIndex-1] {
+		header = strings.TrimSpace(header)
+		if _, exists := columnIndex[header]; !exists {
+			columnIndex[header] = i
+		}
+	}
+	for _, header := range m.Headers() {
+		if _, ok := columnIndex[header]; !ok {
+			report.Add(headerRowIndex, header, "", "", fmt.Sprintf("missing header '%s'", header))
+		}
+	}
+	if report.HasIssues() {
+		return nil, report, nil
+	}
+
+	list := []*MappedRow[T]{}
+	for i := headerRowIndex; i < len(rows); i++ {
+		row := rows[i]
+		if isBlankRow(row) {
+			continue
+		}
+
+		mapped := &MappedRow[T]{
+			RowNumber: i + 1,
+			Data:      new(T),
+			cells:     map[string]string{},
+			values:    map[string]string{},
+		}
+		v := reflect.ValueOf(mapped.Data).Elem()
+		for _, c := range m.Columns {
+			value, cell := "", ""
+			if c.Source != "" {
+				idx := columnIndex[c.Source]
+				cell, _ = excelize.CoordinatesToCellName(idx+1, i+1)
+				if idx < len(row) {
+					value = strings.TrimSpace(row[idx])
+				}
+			}
+			if value == "" {
+				value = c.Default
+			}
+			mapped.cells[c.Target] = cell
+			mapped.values[c.Target] = value
+
+			if value == "" && c.Required {
+				report.Add(i+1, c.Source, cell, value, fmt.Sprintf("'%s' is required", c.Source))
+			}
+			if err := setMappedValue(v.FieldByName(c.Target), c.Type, value); err != nil {
+				report.Add(i+1, c.Source, cell, value, fmt.Sprintf("'%s' is not a number", c.Source))
+			}
+		}
+
+		list = append(list, mapped)
+	}
+	report.TotalRows = len(list)
+
+	return list, report, nil
+}
+
+func setMappedValue(field reflect.Value, columnType, value string) error {
+	switch field.Kind() {
+	case reflect.String:
+		switch columnType {
+		case ColumnTypeUpper:
+			value = strings.ToUpper(value)
+		case ColumnTypeLower:
+			value = strings.ToLower(value)
+		}
+		field.SetString(value)
+	case reflect.Float32, reflect.Float64:
+		if value == "" {
+			return nil
+		}
+		f, err := strconv.ParseFloat(strings.ReplaceAll(value, ",", ""), 64)
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		if value == "" {
+			return nil
+		}
+		i, err := strconv.ParseInt(strings.ReplaceAll(value, ",", ""), 10, 64)
+		if err != nil {
+			return err
+		}
+		field.SetInt(i)
+	}
+	return nil
+}
+
+func isBlankRow(row []string) bool {
+	for _, cell := range row {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -36,6 +36,8 @@ type InsertPreImportDetailManifestModel struct {
 	ShipperCountryCode       string
 	ShipperEmail             string
 	ShipperPhoneNumber       string
+	ShipperPartyID           string // UUID or account number of a party in the address book
+	ConsigneePartyID         string // UUID or account number of a party in the address book
 	TariffCode               string
 	TariffSequence           string
 	StatisticalCode          string
@@ -63,4 +65,25 @@ type InsertPreImportDetailManifestModel struct {
 	OtherChargeCurrencyCode  string
 	InvoiceNo                string
 	InvoiceDate              string
+	ControlFlags             []*ControlFlag
+	HsCodeCandidates         []*HsCodeCandidate // ranked HS codes when none scored high enough to assign
+}
+
+// HsCodeCandidate is an HS master entry suggested for goods whose best match scored below the
+// auto-assignment threshold, so the broker can pick one instead of searching the master.
+type HsCodeCandidate struct {
+	TariffCode      string  `json:"tariffCode"`
+	TariffSequence  string  `json:"tariffSequence"`
+	StatisticalCode string  `json:"statisticalCode"`
+	Description     string  `json:"description"`
+	Score           float64 `json:"score"`
+}
+
+// ControlFlag records a manifest line that breaks one of its HS code's control limits.
+// Declared and Limit are in the unit of the check: THB for FOB and CIF, KGM for weight.
+type ControlFlag struct {
+	Code     string  `json:"code"`
+	Message  string  `json:"message"`
+	Declared float64 `json:"declared"`
+	Limit    float64 `json:"limit"`
 }
@@ -19,6 +19,7 @@ type InsertPreExportDetailManifestModel struct {
 	HeaderUUID                  string
 	MasterAirWaybill            string
 	HouseAirWaybill             string
+	ItemNo                      int64
 	Category                    int64
 	ConsignorCompanyTaxNumber   string
 	ConsignorCompanyBranch      string
@@ -87,6 +88,7 @@ type GetDetailManifestPreExport struct {
 	HeaderUUID                  string
 	MasterAirWaybill            string
 	HouseAirWaybill             string
+	ItemNo                      int64
 	Category                    int64
 	ConsignorCompanyTaxNumber   string
 	ConsignorCompanyBranch      string
@@ -0,0 +1,121 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// ValidationIssue is one problem found in an uploaded sheet.
+// Cell is empty when the problem is not tied to a single cell.
+type ValidationIssue struct {
+	Row     int    `json:"row"`
+	Column  string `json:"column"`
+	Cell    string `json:"cell,omitempty"`
+	Value   string `json:"value"`
+	Message string `json:"message"`
+}
+
+// ValidationReport collects every problem in an uploaded sheet so the uploader can fix them in one round.
+// It implements error so it can travel through the existing upload flow.
+type ValidationReport struct {
+	UploadLogUUID string             `json:"uploadLogUUID,omitempty"`
+	SheetName     string             `json:"sheetName"`
+	TotalRows     int                `json:"totalRows"`
+	Issues        []*ValidationIssue `json:"issues"`
+}
+
+func NewValidationReport(sheetName string) *ValidationReport {
+	return &ValidationReport{
+		SheetName: sheetName,
+		Issues:    []*ValidationIssue{},
+	}
+}
+
+func (r *ValidationReport) Add(row int, column, cell, value, message string) {
+	r.Issues = append(r.Issues, &ValidationIssue{
+		Row:     row,
+		Column:  column,
+		Cell:    cell,
+		Value:   value,
+		Message: message,
+	})
+}
+
+// HasIssueAt reports whether the cell already has a problem recorded.
+func (r *ValidationReport) HasIssueAt(cell string) bool {
+	for _, issue := range r.Issues {
+		if issue.Cell != "" && issue.Cell == cell {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *ValidationReport) HasIssues() bool {
+	return len(r.Issues) > 0
+}
+
+func (r *ValidationReport) Error() string {
+	rows := map[int]bool{}
+	for _, issue := range r.Issues {
+		rows[issue.Row] = true
+	}
+	return fmt.Sprintf("validation failed: %d problems in %d rows", len(r.Issues), len(rows))
+}
+
+// AnnotateExcel returns a copy of fileBytes with every reported cell highlighted and
+// commented, plus a "Validation" sheet listing all issues.
+func (r *ValidationReport) AnnotateExcel(fileBytes []byte) (*bytes.Buffer, error) {
+	x, err := excelize.OpenReader(bytes.NewReader(fileBytes))
+	if err != nil {
+		return nil, err
+	}
+	defer x.Close()
+
+	styleID, err := x.NewStyle(&excelize.Style{
+		Fill: excelize.Fill{Type: "pattern", Color: []string{"#FFC7CE"}, Pattern: 1},
+		Font: &excelize.Font{Color: "#9C0006"},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	messages := map[string][]string{}
+	cells := []string{}
+	for _, issue := range r.Issues {
+		if issue.Cell == "" {
+			continue
+		}
+		if _, ok := messages[issue.Cell]; !ok {
+			cells = append(cells, issue.Cell)
+		}
+		messages[issue.Cell] = append(messages[issue.Cell], issue.Message)
+	}
+
+	for _, cell := range cells {
+		if err := x.SetCellStyle(r.SheetName, cell, cell, styleID); err != nil {
+			return nil, err
+		}
+		if err := x.AddComment(r.SheetName, excelize.Comment{
+			Author: "Validation",
+			Cell:   cell,
+			Text:   strings.Join(messages[cell], "\n"),
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	reportSheet := "Validation"
+	if _, err := x.NewSheet(reportSheet); err != nil {
+		return nil, err
+	}
+	x.SetSheetRow(reportSheet, "A1", &[]interface{}{"Row", "Column", "Cell", "Value", "Message"})
+	for i, issue := range r.Issues {
+		x.SetSheetRow(reportSheet, fmt.Sprintf("A%d", i+2), &[]interface{}{issue.Row, issue.Column, issue.Cell, issue.Value, issue.Message})
+	}
+
+	return x.WriteToBuffer()
+}